@@ -0,0 +1,190 @@
+// Package archive packages plugin outputs and downloaded files into
+// tar.zst archives with a manifest, for long-term storage.
+package archive
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ManifestName is the name of the manifest entry inside an archive.
+const ManifestName = "manifest.json"
+
+// Result is a single plugin run to be archived.
+type Result struct {
+	Plugin     string
+	Params     map[string]any
+	Output     map[string]any
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Manifest describes the contents of an archive.
+type Manifest struct {
+	CreatedAt time.Time        `json:"createdAt"`
+	Results   []ManifestResult `json:"results"`
+	Files     []ManifestFile   `json:"files"`
+}
+
+// ManifestResult describes an archived plugin run.
+type ManifestResult struct {
+	Path       string         `json:"path"`
+	Plugin     string         `json:"plugin"`
+	Params     map[string]any `json:"params,omitempty"`
+	StartedAt  time.Time      `json:"startedAt"`
+	FinishedAt time.Time      `json:"finishedAt"`
+	DurationMs int64          `json:"durationMs"`
+	SHA256     string         `json:"sha256"`
+}
+
+// ManifestFile describes an archived file.
+type ManifestFile struct {
+	ID     string `json:"id"`
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Writer writes a tar.zst archive. Entries are written as they are added
+// and the manifest is appended on Close.
+type Writer struct {
+	zw       *zstd.Encoder
+	tw       *tar.Writer
+	manifest Manifest
+	names    map[string]bool
+	now      func() time.Time
+	closed   bool
+}
+
+// NewWriter creates an archive Writer writing to w.
+func NewWriter(w io.Writer) (*Writer, error) {
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd writer: %w", err)
+	}
+	return &Writer{
+		zw:    zw,
+		tw:    tar.NewWriter(zw),
+		names: map[string]bool{},
+		now:   time.Now,
+	}, nil
+}
+
+// AddResult adds a plugin run to the archive.
+func (w *Writer) AddResult(r Result) error {
+	data, err := json.MarshalIndent(r.Output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to JSON encode output: %w", err)
+	}
+	name := path.Join(
+		"results",
+		strconv.Itoa(len(w.manifest.Results)+1)+"-"+path.Base(r.Plugin)+".json",
+	)
+	sum, err := w.writeEntry(name, data)
+	if err != nil {
+		return err
+	}
+	w.manifest.Results = append(w.manifest.Results, ManifestResult{
+		Path:       name,
+		Plugin:     r.Plugin,
+		Params:     r.Params,
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+		DurationMs: r.FinishedAt.Sub(r.StartedAt).Milliseconds(),
+		SHA256:     sum,
+	})
+	return nil
+}
+
+// AddFile adds a downloaded file to the archive. The file is stored
+// under the base name of its ID, with a counter suffix if another file
+// has the same name.
+func (w *Writer) AddFile(fileID string, data []byte) error {
+	base := path.Base(fileID)
+	switch {
+	case fileID == "":
+		return errors.New("file ID is required")
+	case base == "." || base == ".." || base == "/":
+		return fmt.Errorf("invalid file ID %q", fileID)
+	}
+	name := w.entryName("files", base)
+	sum, err := w.writeEntry(name, data)
+	if err != nil {
+		return err
+	}
+	w.manifest.Files = append(w.manifest.Files, ManifestFile{
+		ID:     fileID,
+		Path:   name,
+		Size:   int64(len(data)),
+		SHA256: sum,
+	})
+	return nil
+}
+
+// Close writes the manifest and flushes the archive.
+// It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return errors.New("archive is already closed")
+	}
+
+	w.manifest.CreatedAt = w.now().UTC()
+	data, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to JSON encode manifest: %w", err)
+	}
+	if _, err := w.writeEntry(ManifestName, data); err != nil {
+		return err
+	}
+	w.closed = true
+	if err := w.tw.Close(); err != nil {
+		return fmt.Errorf("failed to close tar writer: %w", err)
+	}
+	if err := w.zw.Close(); err != nil {
+		return fmt.Errorf("failed to close zstd writer: %w", err)
+	}
+	return nil
+}
+
+// entryName returns the name of base in dir, suffixed with a counter
+// if an earlier entry has that name.
+func (w *Writer) entryName(dir, base string) string {
+	ext := path.Ext(base)
+	name := path.Join(dir, base)
+	for i := 2; w.names[name]; i++ {
+		name = path.Join(dir, strings.TrimSuffix(base, ext)+"-"+strconv.Itoa(i)+ext)
+	}
+	return name
+}
+
+func (w *Writer) writeEntry(name string, data []byte) (string, error) {
+	if w.closed {
+		return "", errors.New("archive is already closed")
+	}
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: w.now(),
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return "", fmt.Errorf("failed to write %s header: %w", name, err)
+	}
+	if _, err := w.tw.Write(data); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	w.names[name] = true
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,97 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+
+	started := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, w.AddResult(Result{
+		Plugin:     "screenshot",
+		Params:     map[string]any{"urls": []string{"https://example.com"}},
+		Output:     map[string]any{"screenshot": "file1"},
+		StartedAt:  started,
+		FinishedAt: started.Add(1500 * time.Millisecond),
+	}))
+	require.NoError(t, w.AddFile("file1", []byte("png")))
+	require.NoError(t, w.Close())
+	require.Error(t, w.Close())
+	require.Error(t, w.AddFile("file2", nil))
+
+	entries := readArchive(t, &buf)
+	require.Contains(t, entries, "results/1-screenshot.json")
+	assert.Equal(t, "png", string(entries["files/file1"]))
+	require.Contains(t, entries, ManifestName)
+
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(entries[ManifestName], &manifest))
+	require.Len(t, manifest.Results, 1)
+	assert.Equal(t, "screenshot", manifest.Results[0].Plugin)
+	assert.Equal(t, int64(1500), manifest.Results[0].DurationMs)
+	require.Len(t, manifest.Files, 1)
+	assert.Equal(t, ManifestFile{
+		ID:     "file1",
+		Path:   "files/file1",
+		Size:   3,
+		SHA256: "8f8cbb7dcf46e0bc7d53265749a6c17d116093a6ba95e442764060c76fd4a86c",
+	}, manifest.Files[0])
+}
+
+func TestWriter_AddFile(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+
+	require.EqualError(t, w.AddFile("", nil), "file ID is required")
+	require.EqualError(t, w.AddFile("a/..", nil), `invalid file ID "a/.."`)
+	for _, id := range []string{"shot.png", "shot.png", "jobs/1/shot.png", "shot-2.png"} {
+		require.NoError(t, w.AddFile(id, []byte(id)))
+	}
+	require.NoError(t, w.Close())
+
+	entries := readArchive(t, &buf)
+	assert.Equal(t, "shot.png", string(entries["files/shot.png"]))
+	assert.Equal(t, "shot.png", string(entries["files/shot-2.png"]))
+	assert.Equal(t, "jobs/1/shot.png", string(entries["files/shot-3.png"]))
+	assert.Equal(t, "shot-2.png", string(entries["files/shot-2-2.png"]))
+
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(entries[ManifestName], &manifest))
+	require.Len(t, manifest.Files, 4)
+	assert.Equal(t, "files/shot-3.png", manifest.Files[2].Path)
+}
+
+func readArchive(t *testing.T, r io.Reader) map[string][]byte {
+	t.Helper()
+
+	zr, err := zstd.NewReader(r)
+	require.NoError(t, err)
+	defer zr.Close()
+
+	entries := map[string][]byte{}
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		entries[hdr.Name] = data
+	}
+	return entries
+}
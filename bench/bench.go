@@ -0,0 +1,99 @@
+// Package bench benchmarks the client hot paths (param marshaling,
+// output decoding and file downloads) against an in-process fake server.
+//
+// Baseline numbers are kept in baseline.txt. Compare a change against
+// them with benchstat:
+//
+//	go test ./bench -run '^$' -bench . -count 10 > new.txt
+//	benchstat bench/baseline.txt new.txt
+//
+// TestAllocationBudget fails when a benchmark allocates noticeably more
+// per operation than its baseline, which catches most regressions without
+// depending on the speed of the machine running the tests. After an
+// intended change, refresh the baseline with:
+//
+//	go test ./bench -run '^$' -bench . -count 1 > bench/baseline.txt
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// FileSize is the size of the file served by the fake server.
+const FileSize = 1 << 20
+
+// CrawlerPages is the number of pages in the fake crawler output.
+const CrawlerPages = 500
+
+// NewServer starts a fake BrowserBro server with canned responses:
+//
+//	POST /api/v1/plugins/echo     echoes the request params
+//	POST /api/v1/plugins/crawler  returns CrawlerPages pages
+//	GET  /api/v1/files/{id}       returns FileSize bytes
+func NewServer() *httptest.Server {
+	crawler := CrawlerOutput(CrawlerPages)
+	file := []byte(strings.Repeat("f", FileSize))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/plugins/echo", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var params map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		_ = json.NewEncoder(w).Encode(params)
+	})
+	mux.HandleFunc("POST /api/v1/plugins/crawler", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(crawler)
+	})
+	mux.HandleFunc("GET /api/v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(file)
+	})
+	return httptest.NewServer(mux)
+}
+
+// CrawlerOutput returns a crawler plugin output with the given number
+// of pages, each with a few KB of HTML.
+func CrawlerOutput(pages int) []byte {
+	type page struct {
+		URL   string `json:"url"`
+		Title string `json:"title"`
+		HTML  string `json:"html"`
+	}
+	output := struct {
+		Site  string `json:"site"`
+		Pages []page `json:"pages"`
+		Count int    `json:"count"`
+	}{Site: "example.com", Count: pages}
+	html := "<html>" + strings.Repeat("<p>lorem ipsum dolor sit amet</p>", 64) + "</html>"
+	for i := 0; i < pages; i++ {
+		output.Pages = append(output.Pages, page{
+			URL:   fmt.Sprintf("https://example.com/%d", i),
+			Title: fmt.Sprintf("Page %d", i),
+			HTML:  html,
+		})
+	}
+	data, _ := json.Marshal(output)
+	return data
+}
+
+// Params returns run params of roughly the given size in bytes, shaped
+// like the params of batch jobs: a target URL and a few cookies.
+func Params(size int) map[string]any {
+	cookies := make([]map[string]any, 0, size/128+1)
+	for n := 0; n < size; n += 128 {
+		cookies = append(cookies, map[string]any{
+			"name":   fmt.Sprintf("cookie%d", len(cookies)),
+			"value":  strings.Repeat("v", 80),
+			"domain": "example.com",
+		})
+	}
+	return map[string]any{
+		"url":     "https://example.com/",
+		"cookies": cookies,
+	}
+}
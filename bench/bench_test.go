@@ -0,0 +1,136 @@
+package bench
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+var benchmarks = []struct {
+	name string
+	fn   func(b *testing.B)
+}{
+	{"RunPluginSmallParams", benchmarkRunPlugin(Params(256))},
+	{"RunPluginLargeParams", benchmarkRunPlugin(Params(8 << 10))},
+	{"DecodeCrawlerOutput", benchmarkCrawler()},
+	{"DecodeCrawlerOutputItems", benchmarkCrawler(
+		client.WithOutputItems("pages", func(map[string]any) error { return nil }),
+	)},
+	{"DownloadFile", benchmarkDownload},
+}
+
+func BenchmarkClient(b *testing.B) {
+	for _, bench := range benchmarks {
+		b.Run(bench.name, bench.fn)
+	}
+}
+
+func newClient(b *testing.B) *client.Client {
+	b.Helper()
+
+	server := NewServer()
+	b.Cleanup(server.Close)
+	c, err := client.New(server.URL, nil)
+	require.NoError(b, err)
+	return c
+}
+
+func benchmarkRunPlugin(params map[string]any) func(b *testing.B) {
+	return func(b *testing.B) {
+		c := newClient(b)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := c.RunPlugin(context.Background(), "echo", params); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func benchmarkCrawler(opts ...client.RunOption) func(b *testing.B) {
+	return func(b *testing.B) {
+		c := newClient(b)
+		b.ReportAllocs()
+		b.SetBytes(int64(len(CrawlerOutput(CrawlerPages))))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := c.RunPlugin(context.Background(), "crawler", nil, opts...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func benchmarkDownload(b *testing.B) {
+	c := newClient(b)
+	b.ReportAllocs()
+	b.SetBytes(FileSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.DownloadFile(context.Background(), "file"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// readBaseline returns the allocs/op of every benchmark in baseline.txt.
+func readBaseline(t *testing.T) map[string]int64 {
+	t.Helper()
+
+	f, err := os.Open("baseline.txt")
+	require.NoError(t, err)
+	defer f.Close()
+
+	allocs := map[string]int64{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "BenchmarkClient/") {
+			continue
+		}
+		name := strings.TrimPrefix(fields[0], "BenchmarkClient/")
+		if i := strings.LastIndex(name, "-"); i > 0 {
+			name = name[:i]
+		}
+		for i := 1; i < len(fields); i++ {
+			if fields[i] == "allocs/op" {
+				n, err := strconv.ParseInt(fields[i-1], 10, 64)
+				require.NoError(t, err)
+				allocs[name] = n
+			}
+		}
+	}
+	require.NoError(t, scanner.Err())
+	return allocs
+}
+
+func TestAllocationBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs benchmarks")
+	}
+	if raceEnabled {
+		t.Skip("allocations differ under the race detector")
+	}
+	baseline := readBaseline(t)
+	for _, bench := range benchmarks {
+		t.Run(bench.name, func(t *testing.T) {
+			want, ok := baseline[bench.name]
+			require.True(t, ok, "no baseline for %s", bench.name)
+
+			result := testing.Benchmark(bench.fn)
+			// Allow 10% and a few allocations of noise.
+			budget := want + want/10 + 5
+			assert.LessOrEqual(t, result.AllocsPerOp(), budget,
+				"allocations regressed: baseline %d allocs/op", want)
+		})
+	}
+}
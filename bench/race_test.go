@@ -0,0 +1,6 @@
+//go:build race
+
+package bench
+
+// The race detector adds allocations of its own.
+const raceEnabled = true
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AIMDController adjusts a concurrency limit to what the server can
+// handle: every successful call below the latency target raises the limit
+// by about one per window of calls (additive increase), and every 429 or
+// 503 response or call slower than the target scales it down by the
+// backoff factor (multiplicative decrease). A controller may be shared
+// by several batches; the limit then applies to all of them together.
+type AIMDController struct {
+	min, max      int
+	latencyTarget time.Duration
+	backoff       float64
+
+	mu           sync.Mutex
+	limit        float64
+	inflight     int
+	lastDecrease time.Time
+	// changed is closed and replaced whenever a slot may have become free.
+	changed chan struct{}
+}
+
+// NewAIMDController creates a controller whose limit stays within
+// [min, max] and starts at min. A zero latencyTarget only reacts to
+// 429 and 503 responses.
+func NewAIMDController(min, max int, latencyTarget time.Duration) *AIMDController {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	return &AIMDController{
+		min:           min,
+		max:           max,
+		latencyTarget: latencyTarget,
+		backoff:       0.5,
+		limit:         float64(min),
+		changed:       make(chan struct{}),
+	}
+}
+
+// Limit returns the current concurrency limit.
+func (a *AIMDController) Limit() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return int(a.limit)
+}
+
+// Observe records the outcome of a call started at start and adjusts the
+// limit. Calls started before the last decrease cannot decrease the limit
+// again, so a burst of overload responses backs off only once.
+func (a *AIMDController) Observe(start time.Time, err error) {
+	latency := time.Since(start)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch {
+	case overloaded(err) || (a.latencyTarget > 0 && latency > a.latencyTarget):
+		if start.Before(a.lastDecrease) {
+			return
+		}
+		a.limit = max(float64(a.min), a.limit*a.backoff)
+		a.lastDecrease = time.Now()
+	case err == nil:
+		a.limit = min(float64(a.max), a.limit+1/a.limit)
+		a.notifyLocked()
+	}
+}
+
+// acquire waits until the number of calls in flight is below the limit.
+// It returns the start time to pass to release.
+func (a *AIMDController) acquire(ctx context.Context) (time.Time, error) {
+	for {
+		a.mu.Lock()
+		if a.inflight < int(a.limit) {
+			a.inflight++
+			a.mu.Unlock()
+			return time.Now(), nil
+		}
+		changed := a.changed
+		a.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return time.Time{}, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// release frees the slot taken by acquire and observes the call outcome.
+func (a *AIMDController) release(start time.Time, err error) {
+	a.mu.Lock()
+	a.inflight--
+	a.notifyLocked()
+	a.mu.Unlock()
+	a.Observe(start, err)
+}
+
+func (a *AIMDController) notifyLocked() {
+	close(a.changed)
+	a.changed = make(chan struct{})
+}
+
+// overloaded reports whether err is a 429 or 503 response.
+func overloaded(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusTooManyRequests ||
+		apiErr.StatusCode == http.StatusServiceUnavailable
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAIMDController(t *testing.T) {
+	t.Run("additive increase", func(t *testing.T) {
+		ctrl := NewAIMDController(1, 4, 0)
+		assert.Equal(t, 1, ctrl.Limit())
+		for i := 0; i < 20; i++ {
+			ctrl.Observe(time.Now(), nil)
+		}
+		assert.Equal(t, 4, ctrl.Limit())
+	})
+
+	t.Run("multiplicative decrease", func(t *testing.T) {
+		ctrl := NewAIMDController(1, 8, 0)
+		for i := 0; i < 50; i++ {
+			ctrl.Observe(time.Now(), nil)
+		}
+		require.Equal(t, 8, ctrl.Limit())
+
+		start := time.Now()
+		overload := &APIError{StatusCode: http.StatusTooManyRequests}
+		ctrl.Observe(start, overload)
+		assert.Equal(t, 4, ctrl.Limit())
+		// Calls in flight during the decrease do not back off again.
+		ctrl.Observe(start, &APIError{StatusCode: http.StatusServiceUnavailable})
+		assert.Equal(t, 4, ctrl.Limit())
+
+		ctrl.Observe(time.Now(), overload)
+		ctrl.Observe(time.Now(), overload)
+		ctrl.Observe(time.Now(), overload)
+		assert.Equal(t, 1, ctrl.Limit())
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		ctrl := NewAIMDController(2, 8, 0)
+		ctrl.Observe(time.Now(), &APIError{StatusCode: http.StatusBadRequest})
+		assert.Equal(t, 2, ctrl.Limit())
+	})
+
+	t.Run("latency target", func(t *testing.T) {
+		ctrl := NewAIMDController(1, 8, time.Second)
+		for i := 0; i < 50; i++ {
+			ctrl.Observe(time.Now(), nil)
+		}
+		ctrl.Observe(time.Now().Add(-2*time.Second), nil)
+		assert.Equal(t, 4, ctrl.Limit())
+	})
+
+	t.Run("bounds", func(t *testing.T) {
+		ctrl := NewAIMDController(0, -1, 0)
+		assert.Equal(t, 1, ctrl.Limit())
+		ctrl.Observe(time.Now(), nil)
+		assert.Equal(t, 1, ctrl.Limit())
+	})
+}
+
+func TestWithAdaptiveConcurrency(t *testing.T) {
+	var inflight, peak, calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inflight.Add(1)
+		defer inflight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		// Every third call is throttled.
+		if calls.Add(1)%3 == 0 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	ctrl := NewAIMDController(1, 3, 0)
+	params := make([]map[string]any, 30)
+	for i := range params {
+		params[i] = map[string]any{}
+	}
+	_, err = c.RunBatch(context.Background(), "echo", params, WithAdaptiveConcurrency(ctrl))
+	var multiErr *MultiError
+	require.ErrorAs(t, err, &multiErr)
+	assert.Len(t, multiErr.Failed(), 10)
+	assert.Empty(t, multiErr.Skipped())
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	assert.GreaterOrEqual(t, ctrl.Limit(), 1)
+
+	t.Run("context done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := c.RunBatch(ctx, "echo", params[:2], WithAdaptiveConcurrency(ctrl))
+		require.ErrorAs(t, err, &multiErr)
+		assert.Len(t, multiErr.Skipped(), 2)
+	})
+}
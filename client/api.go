@@ -0,0 +1,16 @@
+package client
+
+import "context"
+
+// API is the method set shared by BrowserBro client implementations.
+// Code that depends on API rather than *Client can switch to an
+// alternative transport without changes.
+type API interface {
+	Plugins(ctx context.Context) ([]string, error)
+	RunPlugin(ctx context.Context, pluginName string, params map[string]any, opts ...RunOption) (map[string]any, error)
+	DownloadFile(ctx context.Context, fileID string) ([]byte, error)
+	DeleteFile(ctx context.Context, fileID string) error
+	Healthcheck(ctx context.Context) error
+}
+
+var _ API = (*Client)(nil)
@@ -0,0 +1,341 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ArtifactManager tracks files produced by plugin runs, downloads them
+// through the client (and therefore its ResultSink), and deletes them
+// from the server once the download succeeded. Files whose download or
+// deletion failed stay pending and are retried by Reconcile.
+// With WithJobStore, pending jobs and files survive process restarts.
+// It is safe for concurrent use.
+type ArtifactManager struct {
+	client *Client
+	store  JobStore
+
+	mu      sync.Mutex
+	pending map[string]artifactState
+	// jobs are the jobs with pending files or still running, by ID.
+	jobs map[string]*PendingJob
+	// jobOf maps pending files to the ID of the job that produced them.
+	jobOf map[string]string
+}
+
+type artifactState int
+
+const (
+	artifactTracked artifactState = iota
+	artifactDownloaded
+)
+
+// ArtifactOption configures an ArtifactManager.
+type ArtifactOption func(*ArtifactManager)
+
+// WithJobStore persists the pending jobs and files of the manager in s.
+// Call Restore after a restart to resume tracking them.
+func WithJobStore(s JobStore) ArtifactOption {
+	return func(m *ArtifactManager) {
+		m.store = s
+	}
+}
+
+// NewArtifactManager creates an ArtifactManager using c.
+func NewArtifactManager(c *Client, opts ...ArtifactOption) *ArtifactManager {
+	m := &ArtifactManager{
+		client:  c,
+		pending: make(map[string]artifactState),
+		jobs:    make(map[string]*PendingJob),
+		jobOf:   make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Restore loads the pending jobs from the job store, so that Collect
+// resumes downloading and deleting their files. Use Jobs to find the
+// jobs still running when the process stopped.
+func (m *ArtifactManager) Restore() error {
+	if m.store == nil {
+		return nil
+	}
+	jobs, err := m.store.List()
+	if err != nil {
+		return fmt.Errorf("failed to restore jobs: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, job := range jobs {
+		job := job
+		m.jobs[job.ID] = &job
+		for _, id := range job.Files {
+			state := artifactTracked
+			if slices.Contains(job.Downloaded, id) {
+				state = artifactDownloaded
+			}
+			m.pending[id] = state
+			m.jobOf[id] = job.ID
+		}
+	}
+	return nil
+}
+
+// Run runs a plugin like Client.RunPlugin and tracks every file
+// referenced by its output.
+func (m *ArtifactManager) Run(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	opts ...RunOption,
+) (map[string]any, error) {
+	result, err := m.client.RunPluginResult(ctx, pluginName, params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	jobID := result.JobID
+	if jobID == "" {
+		jobID = newJobID()
+	}
+	if err := m.track(jobID, pluginName, FileIDs(result.Output), false); err != nil {
+		return result.Output, err
+	}
+	return result.Output, nil
+}
+
+// TrackJob tracks a submitted job and the files it produced. A job
+// tracked without files is still running; track it again with its
+// files once it finished. A job without files stays pending until
+// Forget is called.
+func (m *ArtifactManager) TrackJob(jobID, pluginName string, fileIDs ...string) error {
+	return m.track(jobID, pluginName, fileIDs, true)
+}
+
+// Submit submits a job like Client.SubmitPlugin and tracks it as
+// running, so that WaitForJob can collect its files, also after a
+// restart with WithJobStore.
+func (m *ArtifactManager) Submit(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	opts ...RunOption,
+) (JobID, error) {
+	jobID, err := m.client.SubmitPlugin(ctx, pluginName, params, opts...)
+	if err != nil {
+		return "", err
+	}
+	return jobID, m.TrackJob(string(jobID), pluginName)
+}
+
+// WaitForJob waits for a job like Client.WaitForJob and tracks every
+// file referenced by its output. A job that finished without files or
+// without success is forgotten.
+func (m *ArtifactManager) WaitForJob(ctx context.Context, jobID JobID, pollInterval time.Duration) (*RunResult, error) {
+	result, err := m.client.WaitForJob(ctx, jobID, pollInterval)
+	var jobErr *JobError
+	if errors.As(err, &jobErr) {
+		return nil, errors.Join(err, m.Forget(string(jobID)))
+	}
+	if err != nil {
+		return nil, err
+	}
+	files := FileIDs(result.Output)
+	if len(files) == 0 {
+		return result, m.Forget(string(jobID))
+	}
+	return result, m.track(string(jobID), result.pluginName, files, true)
+}
+
+// track tracks the files of a job not already tracked. Unless running
+// is set, a new job is only tracked if it has such files.
+func (m *ArtifactManager) track(jobID, pluginName string, fileIDs []string, running bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var files []string
+	for _, id := range fileIDs {
+		if _, ok := m.pending[id]; !ok && id != "" && !slices.Contains(files, id) {
+			files = append(files, id)
+		}
+	}
+	job, ok := m.jobs[jobID]
+	if !ok {
+		if len(files) == 0 && !running {
+			return nil
+		}
+		job = &PendingJob{ID: jobID, Plugin: pluginName, SubmittedAt: time.Now().UTC()}
+		m.jobs[jobID] = job
+	}
+	for _, id := range files {
+		m.pending[id] = artifactTracked
+		m.jobOf[id] = jobID
+	}
+	job.Files = append(job.Files, files...)
+	return m.saveLocked(job)
+}
+
+// Forget stops tracking a job and its pending files.
+func (m *ArtifactManager) Forget(jobID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[jobID]
+	if !ok {
+		return nil
+	}
+	for _, id := range job.Files {
+		delete(m.pending, id)
+		delete(m.jobOf, id)
+	}
+	delete(m.jobs, jobID)
+	if m.store != nil {
+		return m.store.Delete(jobID)
+	}
+	return nil
+}
+
+// Jobs returns the tracked jobs, sorted by submission time.
+func (m *ArtifactManager) Jobs() []PendingJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]PendingJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		j := *job
+		j.Files = slices.Clone(job.Files)
+		j.Downloaded = slices.Clone(job.Downloaded)
+		jobs = append(jobs, j)
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].SubmittedAt.Before(jobs[j].SubmittedAt)
+	})
+	return jobs
+}
+
+// Track starts tracking the given file IDs. They are tracked in memory
+// even if saving them to the job store fails; use TrackJob to handle
+// store errors.
+func (m *ArtifactManager) Track(fileIDs ...string) {
+	_ = m.track(newJobID(), "", fileIDs, false)
+}
+
+// TrackOutput tracks every file referenced by a plugin output
+// and returns their IDs.
+func (m *ArtifactManager) TrackOutput(output map[string]any) []string {
+	ids := extractFileIDs(output)
+	m.Track(ids...)
+	return ids
+}
+
+// Pending returns the IDs of tracked files not yet deleted from the server.
+func (m *ArtifactManager) Pending() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]string, 0, len(m.pending))
+	for id := range m.pending {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Collect downloads every pending file and deletes it from the server
+// once downloaded. It returns the joined errors of all failed files.
+func (m *ArtifactManager) Collect(ctx context.Context) error {
+	var errs []error
+	for _, id := range m.Pending() {
+		if err := m.collect(ctx, id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Reconcile retries pending files left over by failed Collect calls:
+// files never downloaded are downloaded and deleted, and files
+// downloaded but not deleted are only deleted.
+func (m *ArtifactManager) Reconcile(ctx context.Context) error {
+	return m.Collect(ctx)
+}
+
+func (m *ArtifactManager) collect(ctx context.Context, id string) error {
+	m.mu.Lock()
+	state, ok := m.pending[id]
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	if state == artifactTracked {
+		if _, err := m.client.DownloadFile(ctx, id); err != nil {
+			return fmt.Errorf("file %s: %w", id, err)
+		}
+		if err := m.setDownloaded(id); err != nil {
+			return fmt.Errorf("file %s: %w", id, err)
+		}
+	}
+	if err := m.client.DeleteFile(ctx, id); err != nil {
+		return fmt.Errorf("file %s: %w", id, err)
+	}
+	if err := m.remove(id); err != nil {
+		return fmt.Errorf("file %s: %w", id, err)
+	}
+	return nil
+}
+
+func (m *ArtifactManager) setDownloaded(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.pending[id]; !ok {
+		return nil
+	}
+	m.pending[id] = artifactDownloaded
+	job := m.jobs[m.jobOf[id]]
+	job.Downloaded = append(job.Downloaded, id)
+	return m.saveLocked(job)
+}
+
+// remove stops tracking a deleted file, and its job once all of the
+// job's files are deleted.
+func (m *ArtifactManager) remove(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobID, ok := m.jobOf[id]
+	if !ok {
+		return nil
+	}
+	delete(m.pending, id)
+	delete(m.jobOf, id)
+	job := m.jobs[jobID]
+	job.Files = slices.DeleteFunc(job.Files, func(f string) bool { return f == id })
+	job.Downloaded = slices.DeleteFunc(job.Downloaded, func(f string) bool { return f == id })
+	if len(job.Files) > 0 {
+		return m.saveLocked(job)
+	}
+	delete(m.jobs, jobID)
+	if m.store != nil {
+		return m.store.Delete(jobID)
+	}
+	return nil
+}
+
+func (m *ArtifactManager) saveLocked(job *PendingJob) error {
+	if m.store == nil {
+		return nil
+	}
+	if err := m.store.Put(*job); err != nil {
+		return fmt.Errorf("failed to save job %s: %w", job.ID, err)
+	}
+	return nil
+}
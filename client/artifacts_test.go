@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fileServer is a fake BrowserBro server storing files in memory.
+type fileServer struct {
+	mu         sync.Mutex
+	files      map[string]string
+	failDelete map[string]bool
+}
+
+func (s *fileServer) start(t testing.TB) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/plugins/screenshot", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"screenshot": {"fileIds": ["f1", "f2"]}}`))
+	})
+	mux.HandleFunc("POST /api/v1/plugins/screenshot/jobs", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"id": "job-1", "plugin": "screenshot", "state": "queued"}`))
+	})
+	mux.HandleFunc("GET /api/v1/jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		state := JobSucceeded
+		if r.PathValue("id") == "failed" {
+			state = JobFailed
+		}
+		_, _ = fmt.Fprintf(w, `{"id": %q, "plugin": "screenshot", "state": %q}`, r.PathValue("id"), state)
+	})
+	mux.HandleFunc("GET /api/v1/jobs/{id}/result", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"screenshot": {"fileIds": ["f1", "f2"]}}`))
+	})
+	mux.HandleFunc("GET /api/v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		content, ok := s.files[r.PathValue("id")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	})
+	mux.HandleFunc("DELETE /api/v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		id := r.PathValue("id")
+		if s.failDelete[id] {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		delete(s.files, id)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestArtifactManager(t *testing.T) {
+	files := &fileServer{
+		files:      map[string]string{"f1": "one", "f2": "two"},
+		failDelete: map[string]bool{"f2": true},
+	}
+	server := files.start(t)
+	defer server.Close()
+
+	sink, err := NewDiskSink(t.TempDir())
+	require.NoError(t, err)
+	c, err := New(server.URL, nil, WithResultSink(sink))
+	require.NoError(t, err)
+
+	m := NewArtifactManager(c)
+	_, err = m.Run(context.Background(), "screenshot", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"f1", "f2"}, m.Pending())
+
+	err = m.Collect(context.Background())
+	require.EqualError(t, err, "file f2: unexpected response status: 503 Service Unavailable")
+	assert.Equal(t, []string{"f2"}, m.Pending())
+	assert.FileExists(t, sink.Path("f1"))
+	assert.FileExists(t, sink.Path("f2"))
+	assert.NotContains(t, files.files, "f1")
+
+	files.mu.Lock()
+	files.failDelete["f2"] = false
+	files.files["f2"] = "changed"
+	files.mu.Unlock()
+
+	require.NoError(t, m.Reconcile(context.Background()))
+	assert.Empty(t, m.Pending())
+	assert.Empty(t, files.files)
+}
+
+func TestArtifactManager_Jobs(t *testing.T) {
+	files := &fileServer{files: map[string]string{"f1": "one", "f2": "two"}}
+	server := files.start(t)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+	store, err := NewFileJobStore(t.TempDir())
+	require.NoError(t, err)
+
+	m := NewArtifactManager(c, WithJobStore(store))
+	jobID, err := m.Submit(context.Background(), "screenshot", nil)
+	require.NoError(t, err)
+	jobs := m.Jobs()
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "job-1", jobs[0].ID)
+	assert.Empty(t, jobs[0].Files)
+
+	result, err := m.WaitForJob(context.Background(), jobID, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, "job-1", result.JobID)
+	assert.Equal(t, []string{"f1", "f2"}, m.Pending())
+	stored, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, []string{"f1", "f2"}, stored[0].Files)
+
+	require.NoError(t, m.Collect(context.Background()))
+	assert.Empty(t, m.Pending())
+	assert.Empty(t, files.files)
+
+	require.NoError(t, m.TrackJob("failed", "screenshot"))
+	_, err = m.WaitForJob(context.Background(), "failed", time.Millisecond)
+	assert.ErrorIs(t, err, ErrJobFailed)
+	assert.Empty(t, m.Jobs())
+}
+
+func TestArtifactManager_DownloadFailure(t *testing.T) {
+	files := &fileServer{files: map[string]string{}}
+	server := files.start(t)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	m := NewArtifactManager(c)
+	m.Track("missing", "missing")
+	err = m.Collect(context.Background())
+	require.EqualError(t, err, "file missing: unexpected response status: 404 Not Found")
+	assert.Equal(t, []string{"missing"}, m.Pending())
+}
+
+func TestArtifactManager_Restore(t *testing.T) {
+	files := &fileServer{
+		files:      map[string]string{"f1": "one", "f2": "two"},
+		failDelete: map[string]bool{"f2": true},
+	}
+	server := files.start(t)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+	store, err := NewFileJobStore(t.TempDir())
+	require.NoError(t, err)
+
+	m := NewArtifactManager(c, WithJobStore(store))
+	_, err = m.Run(context.Background(), "screenshot", nil)
+	require.NoError(t, err)
+	require.NoError(t, m.TrackJob("job-2", "crawler"))
+	require.Error(t, m.Collect(context.Background()))
+
+	// A new process resumes with the downloaded but undeleted file and
+	// the running job.
+	restored := NewArtifactManager(c, WithJobStore(store))
+	require.NoError(t, restored.Restore())
+	assert.Equal(t, []string{"f2"}, restored.Pending())
+	jobs := restored.Jobs()
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "screenshot", jobs[0].Plugin)
+	assert.Equal(t, []string{"f2"}, jobs[0].Files)
+	assert.Equal(t, []string{"f2"}, jobs[0].Downloaded)
+	assert.Equal(t, PendingJob{ID: "job-2", Plugin: "crawler", SubmittedAt: jobs[1].SubmittedAt}, jobs[1])
+
+	// The downloaded file is only deleted: downloading it again would
+	// fail now.
+	files.mu.Lock()
+	files.failDelete["f2"] = false
+	delete(files.files, "f2")
+	files.mu.Unlock()
+
+	require.NoError(t, restored.Reconcile(context.Background()))
+	assert.Empty(t, restored.Pending())
+
+	require.NoError(t, restored.TrackJob("job-2", "crawler", "f3"))
+	require.NoError(t, restored.Forget("job-2"))
+	stored, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+}
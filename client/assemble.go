@@ -0,0 +1,270 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// AssembledFile is a file referenced by a plugin output.
+type AssembledFile struct {
+	// Key is the URL the file was created for, or its file ID when the
+	// output does not tell.
+	Key    string
+	FileID string
+	// Data is the file content, unless it was written to Path.
+	Data []byte
+	// Path is where the file was written with AssembleToDir.
+	Path string
+	// Err is set when the file was not downloaded.
+	Err error
+}
+
+// Assembly holds the files of a plugin output, in output order.
+type Assembly struct {
+	Files []AssembledFile
+
+	client *Client
+}
+
+// File returns the first file with the given key.
+func (a *Assembly) File(key string) (AssembledFile, bool) {
+	for _, f := range a.Files {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return AssembledFile{}, false
+}
+
+// Bytes returns the content of the downloaded files by key. Files
+// written to a directory are not read back.
+func (a *Assembly) Bytes() map[string][]byte {
+	files := make(map[string][]byte)
+	for _, f := range a.Files {
+		if f.Err == nil && f.Data != nil {
+			if _, ok := files[f.Key]; !ok {
+				files[f.Key] = f.Data
+			}
+		}
+	}
+	return files
+}
+
+// Paths returns the paths of the files written with AssembleToDir by key.
+func (a *Assembly) Paths() map[string]string {
+	paths := make(map[string]string)
+	for _, f := range a.Files {
+		if f.Err == nil && f.Path != "" {
+			if _, ok := paths[f.Key]; !ok {
+				paths[f.Key] = f.Path
+			}
+		}
+	}
+	return paths
+}
+
+// Failed returns the files that were not downloaded.
+func (a *Assembly) Failed() []AssembledFile {
+	var failed []AssembledFile
+	for _, f := range a.Files {
+		if f.Err != nil {
+			failed = append(failed, f)
+		}
+	}
+	return failed
+}
+
+// Cleanup removes the files written to disk and deletes every file of
+// the assembly from the server.
+func (a *Assembly) Cleanup(ctx context.Context) error {
+	var errs []error
+	ids := make([]string, len(a.Files))
+	for i, f := range a.Files {
+		ids[i] = f.FileID
+		if f.Path != "" {
+			if err := os.Remove(f.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, fmt.Errorf("failed to remove file: %w", err))
+			}
+		}
+	}
+	if err := a.client.DeleteFiles(ctx, ids); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// AssembleOption configures Assemble.
+type AssembleOption func(*assembleConfig)
+
+type assembleConfig struct {
+	dir          string
+	deleteRemote bool
+	batch        []BatchOption
+}
+
+// AssembleToDir writes the files to dir, named by their file IDs,
+// instead of keeping them in memory. dir is created if needed.
+func AssembleToDir(dir string) AssembleOption {
+	return func(cfg *assembleConfig) {
+		cfg.dir = dir
+	}
+}
+
+// AssembleDeleteRemote deletes the files from the server once all of
+// them were downloaded. If any download fails, the files are kept so
+// Assemble can be retried.
+func AssembleDeleteRemote() AssembleOption {
+	return func(cfg *assembleConfig) {
+		cfg.deleteRemote = true
+	}
+}
+
+// AssembleBatch configures the parallel downloads.
+func AssembleBatch(opts ...BatchOption) AssembleOption {
+	return func(cfg *assembleConfig) {
+		cfg.batch = append(cfg.batch, opts...)
+	}
+}
+
+// Assemble downloads every file referenced by a plugin output. Files
+// are keyed by URL when the output pairs "url" and "fileId" keys, or
+// when the "urls" param holds one URL per file ID, e.g. one screenshot
+// per URL. If some files were not downloaded, the assembly holds the
+// others and the error is a *MultiError.
+func (c *Client) Assemble(
+	ctx context.Context,
+	output map[string]any,
+	params map[string]any,
+	opts ...AssembleOption,
+) (*Assembly, error) {
+	var cfg assembleConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.dir != "" {
+		if err := os.MkdirAll(cfg.dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+
+	a := &Assembly{Files: assembledFiles(output, params), client: c}
+	ids := make([]string, len(a.Files))
+	for i, f := range a.Files {
+		ids[i] = f.FileID
+	}
+	err := runBatch(ctx, ids, cfg.batch, func(i int) error {
+		f := &a.Files[i]
+		data, err := c.DownloadFile(ctx, f.FileID)
+		if err != nil {
+			f.Err = err
+			return err
+		}
+		if cfg.dir == "" {
+			f.Data = data
+			return nil
+		}
+		p := filepath.Join(cfg.dir, filepath.Base(f.FileID))
+		if err := os.WriteFile(p, data, 0o644); err != nil {
+			_ = os.Remove(p)
+			f.Err = fmt.Errorf("failed to write file: %w", err)
+			return f.Err
+		}
+		f.Path = p
+		return nil
+	})
+	var multi *MultiError
+	if errors.As(err, &multi) {
+		for _, item := range multi.Skipped() {
+			a.Files[item.Index].Err = item.Err
+		}
+		return a, err
+	}
+	if cfg.deleteRemote {
+		if err := c.DeleteFiles(ctx, ids, cfg.batch...); err != nil {
+			return a, fmt.Errorf("failed to delete files: %w", err)
+		}
+	}
+	return a, nil
+}
+
+// RunAssembled runs a plugin and assembles the files of its output.
+func (c *Client) RunAssembled(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	opts ...AssembleOption,
+) (*Assembly, error) {
+	output, err := c.RunPlugin(ctx, pluginName, params)
+	if err != nil {
+		return nil, err
+	}
+	return c.Assemble(ctx, output, params, opts...)
+}
+
+// assembledFiles lists the files of output with their keys.
+func assembledFiles(output map[string]any, params map[string]any) []AssembledFile {
+	urls := make(map[string]string)
+	var walk func(v any)
+	walk = func(v any) {
+		switch t := v.(type) {
+		case map[string]any:
+			url, _ := t["url"].(string)
+			id, _ := t["fileId"].(string)
+			if url != "" && id != "" {
+				if _, ok := urls[id]; !ok {
+					urls[id] = url
+				}
+			}
+			for _, child := range t {
+				walk(child)
+			}
+		case []any:
+			for _, child := range t {
+				walk(child)
+			}
+		}
+	}
+	walk(output)
+
+	ids := extractFileIDs(output)
+	var unkeyed []int
+	files := make([]AssembledFile, len(ids))
+	for i, id := range ids {
+		files[i] = AssembledFile{Key: urls[id], FileID: id}
+		if files[i].Key == "" {
+			unkeyed = append(unkeyed, i)
+		}
+	}
+	paramURLs := stringList(params["urls"])
+	for n, i := range unkeyed {
+		if len(paramURLs) == len(unkeyed) {
+			files[i].Key = paramURLs[n]
+		} else {
+			files[i].Key = files[i].FileID
+		}
+	}
+	return files
+}
+
+func stringList(v any) []string {
+	switch t := v.(type) {
+	case []string:
+		return t
+	case []any:
+		list := make([]string, 0, len(t))
+		for _, item := range t {
+			s, ok := item.(string)
+			if !ok {
+				return nil
+			}
+			list = append(list, s)
+		}
+		return list
+	case string:
+		return []string{t}
+	}
+	return nil
+}
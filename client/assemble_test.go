@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assembleServer(t *testing.T, files map[string]string) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var deleted []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/plugins/screenshot", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"screenshot": {"fileIds": ["f1", "f2"]}}`))
+	})
+	mux.HandleFunc("GET /api/v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.PathValue("id")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(data))
+	})
+	mux.HandleFunc("DELETE /api/v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		deleted = append(deleted, r.PathValue("id"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return deleted
+	}
+}
+
+func TestClient_RunAssembled(t *testing.T) {
+	server, deleted := assembleServer(t, map[string]string{"f1": "one", "f2": "two"})
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	params := map[string]any{"urls": []string{"https://a.example", "https://b.example"}}
+	a, err := c.RunAssembled(context.Background(), "screenshot", params, AssembleDeleteRemote())
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"https://a.example": []byte("one"),
+		"https://b.example": []byte("two"),
+	}, a.Bytes())
+	assert.ElementsMatch(t, []string{"f1", "f2"}, deleted())
+}
+
+func TestClient_Assemble_PartialFailure(t *testing.T) {
+	server, deleted := assembleServer(t, map[string]string{"f1": "one"})
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	dir := filepath.Join(t.TempDir(), "out")
+	output := map[string]any{"screenshot": map[string]any{"results": []any{
+		map[string]any{"url": "https://a.example", "fileId": "f1"},
+		map[string]any{"url": "https://b.example", "fileId": "f2"},
+	}}}
+	a, err := c.Assemble(context.Background(), output, nil, AssembleToDir(dir), AssembleDeleteRemote())
+	var multi *MultiError
+	require.ErrorAs(t, err, &multi)
+	assert.Empty(t, deleted())
+
+	require.Len(t, a.Failed(), 1)
+	assert.Equal(t, "https://b.example", a.Failed()[0].Key)
+	paths := a.Paths()
+	require.Contains(t, paths, "https://a.example")
+	data, err := os.ReadFile(paths["https://a.example"])
+	require.NoError(t, err)
+	assert.Equal(t, "one", string(data))
+
+	require.NoError(t, a.Cleanup(context.Background()))
+	assert.NoFileExists(t, paths["https://a.example"])
+	assert.ElementsMatch(t, []string{"f1", "f2"}, deleted())
+}
+
+func TestAssembledFiles_KeyedByFileID(t *testing.T) {
+	output := map[string]any{"screenshot": map[string]any{"fileIds": []any{"f1", "f2"}}}
+	files := assembledFiles(output, map[string]any{"urls": "https://a.example"})
+	assert.Equal(t, []AssembledFile{{Key: "f1", FileID: "f1"}, {Key: "f2", FileID: "f2"}}, files)
+}
@@ -0,0 +1,254 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrAuditChainBroken is returned by VerifyAuditLog for tampered logs.
+var ErrAuditChainBroken = errors.New("hash chain broken")
+
+// AuditRecord is a single entry of an audit log. Every record includes
+// the hash of the previous one, so editing, removing or reordering
+// records breaks the chain; see VerifyAuditLog.
+type AuditRecord struct {
+	Seq       int64     `json:"seq"`
+	Time      time.Time `json:"time"`
+	Actor     string    `json:"actor,omitempty"`
+	Tenant    string    `json:"tenant,omitempty"`
+	Operation string    `json:"operation"`
+	RequestID string    `json:"requestId,omitempty"`
+	Plugin    string    `json:"plugin,omitempty"`
+	FileID    string    `json:"fileId,omitempty"`
+	// ParamsHash and ResultHash are SHA-256 hashes of the JSON encoded
+	// params and output of a run, or of the content of a downloaded file.
+	ParamsHash string `json:"paramsHash,omitempty"`
+	ResultHash string `json:"resultHash,omitempty"`
+	Error      string `json:"error,omitempty"`
+	PrevHash   string `json:"prevHash"`
+	Hash       string `json:"hash"`
+}
+
+// AuditLog records every client call as a JSONL AuditRecord.
+// It is safe for concurrent use.
+type AuditLog struct {
+	actor string
+
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	seq    int64
+	prev   string
+	err    error
+}
+
+// NewAuditLog creates an audit log writing to w. Actor identifies who
+// makes the calls, e.g. a service account.
+func NewAuditLog(w io.Writer, actor string) *AuditLog {
+	return &AuditLog{w: w, actor: actor}
+}
+
+// OpenAuditLog opens or creates an append-only audit log file and
+// continues the hash chain of its existing records. The existing
+// records are verified first.
+func OpenAuditLog(path, actor string) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	last, err := verifyAuditLog(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	l := &AuditLog{w: f, closer: f, actor: actor}
+	if last != nil {
+		l.seq = last.Seq
+		l.prev = last.Hash
+	}
+	return l, nil
+}
+
+// WithAuditLog records every call of the client in l.
+func WithAuditLog(l *AuditLog) Option {
+	return func(c *Client) {
+		c.audit = l
+	}
+}
+
+// Err returns the first error that occurred while writing records.
+// Calls are not failed by audit write errors.
+func (l *AuditLog) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Close closes the file of a log opened with OpenAuditLog.
+func (l *AuditLog) Close() error {
+	if l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
+
+// Write appends rec to the log, filling in its sequence number,
+// actor, previous hash and hash.
+func (l *AuditLog) Write(rec AuditRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rec.Seq = l.seq + 1
+	if rec.Actor == "" {
+		rec.Actor = l.actor
+	}
+	rec.PrevHash = l.prev
+	rec.Hash = ""
+	hash, err := auditHash(rec)
+	if err != nil {
+		return l.fail(err)
+	}
+	rec.Hash = hash
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return l.fail(err)
+	}
+	if _, err := l.w.Write(append(line, '\n')); err != nil {
+		return l.fail(fmt.Errorf("failed to write audit record: %w", err))
+	}
+	// Records that failed to be written don't take a sequence number.
+	l.seq, l.prev = rec.Seq, hash
+	return nil
+}
+
+func (l *AuditLog) fail(err error) error {
+	if l.err == nil {
+		l.err = err
+	}
+	return err
+}
+
+func (l *AuditLog) record(op *operation, err error) {
+	rec := AuditRecord{
+		Time:      time.Now().UTC(),
+		Operation: op.Name,
+		RequestID: op.RequestID,
+		Plugin:    op.Plugin,
+		FileID:    op.FileID,
+		Tenant:    op.Metadata.Tenant,
+	}
+	if op.params != nil {
+		rec.ParamsHash = payloadHash(op.params)
+	}
+	if op.result != nil && err == nil {
+		rec.ResultHash = payloadHash(op.result)
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	_ = l.Write(rec)
+}
+
+// VerifyAuditLog checks the hash chain of a JSONL audit log and reports
+// the first record that was altered, removed or reordered.
+func VerifyAuditLog(r io.Reader) error {
+	_, err := verifyAuditLog(r)
+	return err
+}
+
+// AuditRecords returns a Pager over the records of a JSONL audit log,
+// reading as many records per page as the page size. The records are
+// not verified; use VerifyAuditLog for that.
+func AuditRecords(r io.Reader) *Pager[AuditRecord] {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	line := 0
+	return NewPager(func(ctx context.Context, _ string, pageSize int) ([]AuditRecord, string, error) {
+		var records []AuditRecord
+		for pageSize <= 0 || len(records) < pageSize {
+			if err := ctx.Err(); err != nil {
+				return nil, "", err
+			}
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return nil, "", fmt.Errorf("failed to read audit log: %w", err)
+				}
+				return records, "", nil
+			}
+			line++
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var rec AuditRecord
+			if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+				return nil, "", fmt.Errorf("audit log line %d: %w", line, err)
+			}
+			records = append(records, rec)
+		}
+		// The cursor only marks that there may be more records.
+		return records, strconv.FormatInt(records[len(records)-1].Seq, 10), nil
+	})
+}
+
+// verifyAuditLog verifies r and returns its last record.
+func verifyAuditLog(r io.Reader) (*AuditRecord, error) {
+	var last *AuditRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var rec AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("audit log line %d: %w", line, err)
+		}
+		var prevSeq int64
+		var prevHash string
+		if last != nil {
+			prevSeq, prevHash = last.Seq, last.Hash
+		}
+		if rec.Seq != prevSeq+1 || rec.PrevHash != prevHash {
+			return nil, fmt.Errorf("audit log line %d: %w", line, ErrAuditChainBroken)
+		}
+		hash := rec.Hash
+		rec.Hash = ""
+		if want, err := auditHash(rec); err != nil || want != hash {
+			return nil, fmt.Errorf("audit log line %d: %w", line, ErrAuditChainBroken)
+		}
+		rec.Hash = hash
+		last = &rec
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return last, nil
+}
+
+func auditHash(rec AuditRecord) (string, error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return "", fmt.Errorf("failed to JSON encode audit record: %w", err)
+	}
+	return contentHash(data), nil
+}
+
+func payloadHash(v any) string {
+	if data, ok := v.([]byte); ok {
+		return contentHash(data)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return contentHash(data)
+}
@@ -0,0 +1,171 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAuditRecords(t *testing.T, data []byte) []AuditRecord {
+	t.Helper()
+
+	var records []AuditRecord
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rec AuditRecord
+		require.NoError(t, json.Unmarshal([]byte(line), &rec))
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestWithAuditLog(t *testing.T) {
+	server := mockServer(t, http.StatusOK, `{"screenshot": "ok"}`)
+	defer server.Close()
+
+	var buf bytes.Buffer
+	c, err := New(server.URL, nil, WithAuditLog(NewAuditLog(&buf, "svc-reports")))
+	require.NoError(t, err)
+
+	_, err = c.RunPlugin(context.Background(), "screenshot", map[string]any{"urls": []string{"https://example.com"}})
+	require.NoError(t, err)
+	_, err = c.DownloadFile(context.Background(), "file1")
+	require.NoError(t, err)
+
+	records := readAuditRecords(t, buf.Bytes())
+	require.Len(t, records, 2)
+
+	run := records[0]
+	assert.Equal(t, int64(1), run.Seq)
+	assert.Equal(t, "svc-reports", run.Actor)
+	assert.Equal(t, "RunPlugin", run.Operation)
+	assert.Equal(t, "screenshot", run.Plugin)
+	assert.NotEmpty(t, run.RequestID)
+	assert.Equal(t, payloadHash(map[string]any{"urls": []string{"https://example.com"}}), run.ParamsHash)
+	assert.Equal(t, payloadHash(map[string]any{"screenshot": "ok"}), run.ResultHash)
+	assert.Empty(t, run.PrevHash)
+
+	download := records[1]
+	assert.Equal(t, "DownloadFile", download.Operation)
+	assert.Equal(t, "file1", download.FileID)
+	assert.Equal(t, contentHash([]byte(`{"screenshot": "ok"}`)), download.ResultHash)
+	assert.Equal(t, run.Hash, download.PrevHash)
+
+	require.NoError(t, VerifyAuditLog(bytes.NewReader(buf.Bytes())))
+}
+
+func TestAuditLog_FailedCall(t *testing.T) {
+	server := mockServer(t, http.StatusNotFound, `{"message": "no such file"}`)
+	defer server.Close()
+
+	var buf bytes.Buffer
+	c, err := New(server.URL, nil, WithAuditLog(NewAuditLog(&buf, "")))
+	require.NoError(t, err)
+
+	require.Error(t, c.DeleteFile(context.Background(), "file1"))
+	records := readAuditRecords(t, buf.Bytes())
+	require.Len(t, records, 1)
+	assert.Equal(t, "unexpected response status: 404 Not Found; message: no such file", records[0].Error)
+	assert.Empty(t, records[0].ResultHash)
+}
+
+func TestVerifyAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewAuditLog(&buf, "alice")
+	for _, op := range []string{"Plugins", "RunPlugin", "Healthcheck"} {
+		require.NoError(t, l.Write(AuditRecord{Operation: op}))
+	}
+	log := buf.String()
+	require.NoError(t, VerifyAuditLog(strings.NewReader(log)))
+
+	lines := strings.SplitAfter(log, "\n")
+	for name, tampered := range map[string]string{
+		"edited":    strings.Replace(log, `"RunPlugin"`, `"DeleteFile"`, 1),
+		"removed":   lines[0] + lines[2],
+		"reordered": lines[1] + lines[0] + lines[2],
+	} {
+		err := VerifyAuditLog(strings.NewReader(tampered))
+		assert.ErrorIs(t, err, ErrAuditChainBroken, name)
+	}
+}
+
+// flakyWriter fails the writes whose number is in fail.
+type flakyWriter struct {
+	bytes.Buffer
+	n    int
+	fail map[int]bool
+}
+
+func (w *flakyWriter) Write(b []byte) (int, error) {
+	w.n++
+	if w.fail[w.n] {
+		return 0, errors.New("disk full")
+	}
+	return w.Buffer.Write(b)
+}
+
+func TestAuditLog_FailedWrite(t *testing.T) {
+	w := &flakyWriter{fail: map[int]bool{2: true}}
+	l := NewAuditLog(w, "alice")
+	require.NoError(t, l.Write(AuditRecord{Operation: "Plugins"}))
+	require.Error(t, l.Write(AuditRecord{Operation: "RunPlugin"}))
+	require.NoError(t, l.Write(AuditRecord{Operation: "Healthcheck"}))
+	assert.ErrorContains(t, l.Err(), "disk full")
+
+	records := readAuditRecords(t, w.Bytes())
+	require.Len(t, records, 2)
+	assert.Equal(t, int64(2), records[1].Seq)
+	require.NoError(t, VerifyAuditLog(bytes.NewReader(w.Bytes())))
+}
+
+func TestAuditRecords(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewAuditLog(&buf, "alice")
+	for _, op := range []string{"Plugins", "RunPlugin", "Healthcheck"} {
+		require.NoError(t, l.Write(AuditRecord{Operation: op}))
+	}
+
+	p := AuditRecords(&buf).PageSize(2)
+	var ops []string
+	for p.Next(context.Background()) {
+		ops = append(ops, p.Item().Operation)
+	}
+	require.NoError(t, p.Err())
+	assert.Equal(t, []string{"Plugins", "RunPlugin", "Healthcheck"}, ops)
+
+	_, err := AuditRecords(strings.NewReader("{\n")).All(context.Background())
+	assert.ErrorContains(t, err, "audit log line 1")
+}
+
+func TestOpenAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	l, err := OpenAuditLog(path, "alice")
+	require.NoError(t, err)
+	require.NoError(t, l.Write(AuditRecord{Operation: "Plugins"}))
+	require.NoError(t, l.Close())
+
+	l, err = OpenAuditLog(path, "alice")
+	require.NoError(t, err)
+	require.NoError(t, l.Write(AuditRecord{Operation: "Healthcheck"}))
+	require.NoError(t, l.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	records := readAuditRecords(t, data)
+	require.Len(t, records, 2)
+	assert.Equal(t, int64(2), records[1].Seq)
+	require.NoError(t, VerifyAuditLog(bytes.NewReader(data)))
+
+	require.NoError(t, os.WriteFile(path, bytes.Replace(data, []byte("alice"), []byte("mallory"), 1), 0o600))
+	_, err = OpenAuditLog(path, "alice")
+	assert.ErrorIs(t, err, ErrAuditChainBroken)
+}
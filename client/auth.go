@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// DefaultTokenRefresh is how long before its expiry a token of a
+// TokenSource is refreshed.
+const DefaultTokenRefresh = 10 * time.Second
+
+// StaticCredentials are credentials that never change.
+type StaticCredentials Credentials
+
+// Credentials implements CredentialProvider.
+func (s StaticCredentials) Credentials(context.Context) (Credentials, error) {
+	return Credentials(s), nil
+}
+
+// WithAPIKey sends key in APIKeyHeader with every request.
+// It combines with WithBearerToken and WithBasicAuth, e.g. for a server
+// behind an authenticating proxy, and replaces any other credential
+// provider.
+func WithAPIKey(key string) Option {
+	return staticCredentials(func(s *StaticCredentials) {
+		s.APIKey = key
+	})
+}
+
+// WithBearerToken sends token as a bearer token in the Authorization
+// header of every request. It takes precedence over WithBasicAuth.
+func WithBearerToken(token string) Option {
+	return staticCredentials(func(s *StaticCredentials) {
+		s.BearerToken = token
+	})
+}
+
+// WithBasicAuth authenticates every request with HTTP basic
+// authentication.
+func WithBasicAuth(username, password string) Option {
+	return staticCredentials(func(s *StaticCredentials) {
+		s.Username = username
+		s.Password = password
+	})
+}
+
+func staticCredentials(set func(*StaticCredentials)) Option {
+	return func(c *Client) {
+		creds, _ := c.credentials.(StaticCredentials)
+		set(&creds)
+		c.credentials = creds
+	}
+}
+
+// Token is an access token, with the fields of an oauth2.Token.
+type Token struct {
+	AccessToken string
+	// TokenType is the scheme of the Authorization header, "Bearer"
+	// if empty.
+	TokenType string
+	// Expiry is when the token expires. Zero means it does not.
+	Expiry time.Time
+}
+
+// TokenSource supplies access tokens. An oauth2.TokenSource is
+// adapted with OAuth2TokenSource.
+type TokenSource interface {
+	Token() (*Token, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource.
+type TokenSourceFunc func() (*Token, error)
+
+// Token implements TokenSource.
+func (f TokenSourceFunc) Token() (*Token, error) {
+	return f()
+}
+
+// OAuth2TokenSource adapts an oauth2.TokenSource to a TokenSource:
+//
+//	client.WithTokenSource(client.OAuth2TokenSource(conf.TokenSource(ctx, tok)))
+//
+// The module does not depend on golang.org/x/oauth2, so ts is accepted
+// as any source of pointers to structs with the AccessToken, TokenType
+// and Expiry fields of an oauth2.Token, which are read by reflection.
+// A token of another shape fails the request instead of failing to
+// compile. The token type is that of the token's Type method, if any.
+func OAuth2TokenSource[T any](ts interface{ Token() (T, error) }) TokenSource {
+	return TokenSourceFunc(func() (*Token, error) {
+		t, err := ts.Token()
+		if err != nil {
+			return nil, err
+		}
+		return fromOAuth2Token(t)
+	})
+}
+
+// fromOAuth2Token converts the fields of an oauth2.Token.
+func fromOAuth2Token(t any) (*Token, error) {
+	v := reflect.ValueOf(t)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported token type %T", t)
+	}
+	access, ok := tokenField[string](v, "AccessToken")
+	if !ok {
+		return nil, fmt.Errorf("unsupported token type %T: no AccessToken field", t)
+	}
+	tok := &Token{AccessToken: access}
+	if typed, ok := t.(interface{ Type() string }); ok {
+		tok.TokenType = typed.Type()
+	} else {
+		tok.TokenType, _ = tokenField[string](v, "TokenType")
+	}
+	tok.Expiry, _ = tokenField[time.Time](v, "Expiry")
+	return tok, nil
+}
+
+// tokenField returns the exported field name of the struct v.
+func tokenField[F any](v reflect.Value, name string) (F, bool) {
+	f := v.FieldByName(name)
+	if !f.IsValid() || !f.CanInterface() {
+		var zero F
+		return zero, false
+	}
+	x, ok := f.Interface().(F)
+	return x, ok
+}
+
+// WithTokenSource sends a token of ts as a bearer token with every
+// request. Tokens are cached until DefaultTokenRefresh ahead of their
+// expiry, and fetched again when the server answers 401. It replaces
+// any other credential provider.
+func WithTokenSource(ts TokenSource) Option {
+	return func(c *Client) {
+		c.credentials = NewCachedCredentials(tokenCredentials{ts}, DefaultTokenRefresh)
+	}
+}
+
+// tokenCredentials provides the tokens of a TokenSource as credentials.
+type tokenCredentials struct {
+	source TokenSource
+}
+
+func (t tokenCredentials) Credentials(context.Context) (Credentials, error) {
+	tok, err := t.source.Token()
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to get token: %w", err)
+	}
+	if tok == nil || tok.AccessToken == "" {
+		return Credentials{}, errors.New("token source returned no token")
+	}
+	return Credentials{
+		BearerToken: tok.AccessToken,
+		TokenType:   tok.TokenType,
+		Expiry:      tok.Expiry,
+	}, nil
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func headerServer(t *testing.T, headers *http.Header) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*headers = r.Header.Clone()
+		if r.Header.Get("Authorization") == "Bearer expired" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWithAPIKey(t *testing.T) {
+	var headers http.Header
+	server := headerServer(t, &headers)
+
+	c, err := New(server.URL, nil, WithAPIKey("key"), WithBasicAuth("proxy", "secret"))
+	require.NoError(t, err)
+	require.NoError(t, c.Healthcheck(context.Background()))
+
+	assert.Equal(t, "key", headers.Get(APIKeyHeader))
+	user, pass, ok := (&http.Request{Header: headers}).BasicAuth()
+	require.True(t, ok)
+	assert.Equal(t, "proxy", user)
+	assert.Equal(t, "secret", pass)
+	assert.Equal(t, "api key, basic auth (proxy)", c.Describe().Auth)
+}
+
+func TestWithBearerToken(t *testing.T) {
+	var headers http.Header
+	server := headerServer(t, &headers)
+
+	c, err := New(server.URL, nil, WithBasicAuth("proxy", "secret"), WithBearerToken("token"))
+	require.NoError(t, err)
+	require.NoError(t, c.Healthcheck(context.Background()))
+
+	assert.Equal(t, "Bearer token", headers.Get("Authorization"))
+	assert.Empty(t, headers.Get(APIKeyHeader))
+	assert.Equal(t, "bearer token", c.Describe().Auth)
+}
+
+func TestWithTokenSource(t *testing.T) {
+	var headers http.Header
+	server := headerServer(t, &headers)
+
+	tokens := []*Token{
+		{AccessToken: "expired", Expiry: time.Now().Add(time.Hour)},
+		{AccessToken: "fresh", TokenType: "bearer"},
+	}
+	var calls int
+	c, err := New(server.URL, nil, WithTokenSource(TokenSourceFunc(func() (*Token, error) {
+		if calls == len(tokens) {
+			return nil, errors.New("token endpoint down")
+		}
+		calls++
+		return tokens[calls-1], nil
+	})))
+	require.NoError(t, err)
+
+	// The first token is rejected, so the next request fetches another.
+	require.Error(t, c.Healthcheck(context.Background()))
+	require.NoError(t, c.Healthcheck(context.Background()))
+	assert.Equal(t, "Bearer fresh", headers.Get("Authorization"))
+	require.NoError(t, c.Healthcheck(context.Background()))
+	assert.Equal(t, 2, calls)
+	assert.Contains(t, c.Describe().Auth, "cached token source")
+}
+
+func TestWithTokenSource_Error(t *testing.T) {
+	var headers http.Header
+	server := headerServer(t, &headers)
+
+	c, err := New(server.URL, nil, WithTokenSource(TokenSourceFunc(func() (*Token, error) {
+		return &Token{}, nil
+	})))
+	require.NoError(t, err)
+	assert.ErrorContains(t, c.Healthcheck(context.Background()), "token source returned no token")
+	assert.Nil(t, headers)
+}
+
+// oauth2Token has the fields and Type method of an oauth2.Token.
+type oauth2Token struct {
+	AccessToken  string
+	TokenType    string
+	RefreshToken string
+	Expiry       time.Time
+}
+
+func (t *oauth2Token) Type() string {
+	if strings.EqualFold(t.TokenType, "bearer") || t.TokenType == "" {
+		return "Bearer"
+	}
+	return t.TokenType
+}
+
+type oauth2TokenSource interface {
+	Token() (*oauth2Token, error)
+}
+
+func TestOAuth2TokenSource(t *testing.T) {
+	expiry := time.Now().Add(time.Hour)
+	var ts oauth2TokenSource = staticSource[*oauth2Token]{&oauth2Token{AccessToken: "abc", TokenType: "bearer", Expiry: expiry}}
+
+	tok, err := OAuth2TokenSource(ts).Token()
+	require.NoError(t, err)
+	assert.Equal(t, &Token{AccessToken: "abc", TokenType: "Bearer", Expiry: expiry}, tok)
+
+	var headers http.Header
+	server := headerServer(t, &headers)
+	c, err := New(server.URL, nil, WithTokenSource(OAuth2TokenSource(ts)))
+	require.NoError(t, err)
+	require.NoError(t, c.Healthcheck(context.Background()))
+	assert.Equal(t, "Bearer abc", headers.Get("Authorization"))
+
+	_, err = OAuth2TokenSource(TokenSourceFunc(func() (*Token, error) {
+		return nil, errors.New("token endpoint down")
+	})).Token()
+	assert.EqualError(t, err, "token endpoint down")
+
+	type opaque struct{ Value string }
+	_, err = OAuth2TokenSource(staticSource[*opaque]{&opaque{}}).Token()
+	assert.EqualError(t, err, "unsupported token type *client.opaque: no AccessToken field")
+}
+
+type staticSource[T any] struct{ tok T }
+
+func (s staticSource[T]) Token() (T, error) { return s.tok, nil }
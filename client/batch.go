@@ -0,0 +1,242 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultBatchConcurrency is the number of batch items processed in
+// parallel unless WithBatchConcurrency is given.
+const DefaultBatchConcurrency = 4
+
+// ErrBatchItemSkipped is the error of a batch item that was never
+// attempted because the batch failed fast or its context was done.
+var ErrBatchItemSkipped = errors.New("batch item skipped")
+
+// BatchStatus is the outcome of a single batch item.
+type BatchStatus string
+
+const (
+	BatchSucceeded BatchStatus = "succeeded"
+	BatchFailed    BatchStatus = "failed"
+	BatchSkipped   BatchStatus = "skipped"
+)
+
+// BatchItem is the status of a single item of a batch call.
+type BatchItem struct {
+	// Index is the position of the item in the batch input.
+	Index int
+	// Key identifies the item: the plugin name for runs and the file ID
+	// for downloads and deletes.
+	Key    string
+	Status BatchStatus
+	// Err is set for failed and skipped items.
+	Err error
+}
+
+// MultiError is returned by batch calls when at least one item did not
+// succeed. Items holds the status of every item of the batch, in input
+// order, so callers can tell which items succeeded, failed or were skipped.
+type MultiError struct {
+	Items []BatchItem
+}
+
+func (e *MultiError) Error() string {
+	failed := e.Failed()
+	skipped := len(e.Items) - len(failed) - len(e.succeeded())
+	msg := fmt.Sprintf("%d of %d batch items failed", len(failed), len(e.Items))
+	if skipped > 0 {
+		msg += fmt.Sprintf(", %d skipped", skipped)
+	}
+	if len(failed) > 0 {
+		msg += ": " + failed[0].Key + ": " + failed[0].Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the errors of the failed items, so errors.Is and
+// errors.As match any of them.
+func (e *MultiError) Unwrap() []error {
+	var errs []error
+	for _, item := range e.Failed() {
+		errs = append(errs, item.Err)
+	}
+	return errs
+}
+
+// Failed returns the items that were attempted and failed.
+func (e *MultiError) Failed() []BatchItem {
+	return e.filter(BatchFailed)
+}
+
+// Skipped returns the items that were never attempted.
+func (e *MultiError) Skipped() []BatchItem {
+	return e.filter(BatchSkipped)
+}
+
+func (e *MultiError) succeeded() []BatchItem {
+	return e.filter(BatchSucceeded)
+}
+
+func (e *MultiError) filter(status BatchStatus) []BatchItem {
+	var items []BatchItem
+	for _, item := range e.Items {
+		if item.Status == status {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// BatchOption configures a batch call.
+type BatchOption func(*batchConfig)
+
+type batchConfig struct {
+	concurrency int
+	failFast    bool
+	controller  *AIMDController
+}
+
+// WithBatchConcurrency sets the number of items processed in parallel.
+func WithBatchConcurrency(n int) BatchOption {
+	return func(cfg *batchConfig) {
+		if n > 0 {
+			cfg.concurrency = n
+		}
+	}
+}
+
+// WithFailFast stops a batch at the first failed item. Items not yet
+// started are reported as skipped. By default every item is attempted.
+func WithFailFast() BatchOption {
+	return func(cfg *batchConfig) {
+		cfg.failFast = true
+	}
+}
+
+// WithAdaptiveConcurrency lets ctrl adjust the number of items processed
+// in parallel, instead of the fixed WithBatchConcurrency limit.
+func WithAdaptiveConcurrency(ctrl *AIMDController) BatchOption {
+	return func(cfg *batchConfig) {
+		cfg.controller = ctrl
+	}
+}
+
+// RunBatch runs the plugin once per params entry. Results are returned
+// in input order; the result of an item that did not succeed is nil.
+// If any item did not succeed, the error is a *MultiError.
+func (c *Client) RunBatch(
+	ctx context.Context,
+	pluginName string,
+	params []map[string]any,
+	opts ...BatchOption,
+) ([]*RunResult, error) {
+	results := make([]*RunResult, len(params))
+	keys := make([]string, len(params))
+	for i := range keys {
+		keys[i] = pluginName
+	}
+	err := runBatch(ctx, keys, opts, func(i int) error {
+		result, err := c.RunPluginResult(ctx, pluginName, params[i])
+		results[i] = result
+		return err
+	})
+	return results, err
+}
+
+// DownloadFiles downloads the files with the given IDs. File contents are
+// returned in input order; the entry of a file that was not downloaded is nil.
+// If any file was not downloaded, the error is a *MultiError.
+func (c *Client) DownloadFiles(
+	ctx context.Context,
+	fileIDs []string,
+	opts ...BatchOption,
+) ([][]byte, error) {
+	files := make([][]byte, len(fileIDs))
+	err := runBatch(ctx, fileIDs, opts, func(i int) error {
+		data, err := c.DownloadFile(ctx, fileIDs[i])
+		files[i] = data
+		return err
+	})
+	return files, err
+}
+
+// runBatch calls fn for the index of every key and collects per-item
+// statuses. It returns a *MultiError unless every item succeeded.
+// A panic in fn fails its item with a *PanicError.
+func runBatch(ctx context.Context, keys []string, opts []BatchOption, fn func(i int) error) error {
+	cfg := batchConfig{concurrency: DefaultBatchConcurrency}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	items := make([]BatchItem, len(keys))
+	for i, key := range keys {
+		items[i] = BatchItem{Index: i, Key: key, Status: BatchSkipped, Err: ErrBatchItemSkipped}
+	}
+
+	acquire, release := cfg.limiter()
+	var wg sync.WaitGroup
+	for i := range keys {
+		start, err := acquire(ctx)
+		if err != nil {
+			break
+		}
+		if ctx.Err() != nil {
+			release(start, ctx.Err())
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := safeCall(func() error { return fn(i) })
+			release(start, err)
+			if err != nil {
+				items[i].Status = BatchFailed
+				items[i].Err = err
+				if cfg.failFast {
+					cancel()
+				}
+				return
+			}
+			items[i].Status = BatchSucceeded
+			items[i].Err = nil
+		}()
+	}
+	wg.Wait()
+
+	for _, item := range items {
+		if item.Status != BatchSucceeded {
+			return &MultiError{Items: items}
+		}
+	}
+	return nil
+}
+
+// limiter returns the functions bounding the items in flight: the
+// adaptive controller if set, or a fixed concurrency limit.
+func (cfg batchConfig) limiter() (
+	acquire func(context.Context) (time.Time, error),
+	release func(time.Time, error),
+) {
+	if cfg.controller != nil {
+		return cfg.controller.acquire, cfg.controller.release
+	}
+	sem := make(chan struct{}, cfg.concurrency)
+	acquire = func(ctx context.Context) (time.Time, error) {
+		select {
+		case <-ctx.Done():
+			return time.Time{}, ctx.Err()
+		case sem <- struct{}{}:
+			return time.Now(), nil
+		}
+	}
+	release = func(time.Time, error) { <-sem }
+	return acquire, release
+}
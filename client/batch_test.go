@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchServer fails runs with {"fail": true} and files whose ID starts
+// with "bad".
+func batchServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/plugins/echo", func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		if params["fail"] == true {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message": "bad params"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"echo": params["n"]})
+	})
+	file := func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.PathValue("id"), "bad") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(r.PathValue("id")))
+	}
+	mux.HandleFunc("GET /api/v1/files/{id}", file)
+	mux.HandleFunc("DELETE /api/v1/files/{id}", file)
+	return httptest.NewServer(mux)
+}
+
+func TestClient_RunBatch(t *testing.T) {
+	server := batchServer(t)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	t.Run("all succeed", func(t *testing.T) {
+		results, err := c.RunBatch(context.Background(), "echo", []map[string]any{
+			{"n": 1.0}, {"n": 2.0}, {"n": 3.0},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		for i, result := range results {
+			assert.Equal(t, float64(i+1), result.Output["echo"])
+		}
+	})
+
+	t.Run("partial failure", func(t *testing.T) {
+		results, err := c.RunBatch(context.Background(), "echo", []map[string]any{
+			{"n": 1.0}, {"fail": true}, {"n": 3.0},
+		})
+		var multi *MultiError
+		require.True(t, errors.As(err, &multi))
+		require.Len(t, multi.Items, 3)
+		assert.Equal(t, BatchSucceeded, multi.Items[0].Status)
+		assert.Equal(t, BatchFailed, multi.Items[1].Status)
+		assert.Equal(t, BatchSucceeded, multi.Items[2].Status)
+		assert.EqualError(t, err, "1 of 3 batch items failed: echo: "+
+			"unexpected response status: 400 Bad Request; message: bad params")
+
+		var apiErr *APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+
+		assert.NotNil(t, results[0])
+		assert.Nil(t, results[1])
+		assert.NotNil(t, results[2])
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		_, err := c.RunBatch(context.Background(), "echo", []map[string]any{
+			{"fail": true}, {"n": 2.0}, {"n": 3.0},
+		}, WithFailFast(), WithBatchConcurrency(1))
+		var multi *MultiError
+		require.True(t, errors.As(err, &multi))
+		assert.Len(t, multi.Failed(), 1)
+		assert.Len(t, multi.Skipped(), 2)
+		for _, item := range multi.Skipped() {
+			assert.ErrorIs(t, item.Err, ErrBatchItemSkipped)
+		}
+		assert.EqualError(t, err, "1 of 3 batch items failed, 2 skipped: echo: "+
+			"unexpected response status: 400 Bad Request; message: bad params")
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := c.RunBatch(ctx, "echo", []map[string]any{{"n": 1.0}})
+		var multi *MultiError
+		require.True(t, errors.As(err, &multi))
+		assert.Len(t, multi.Skipped(), 1)
+	})
+}
+
+func TestClient_DownloadFiles(t *testing.T) {
+	server := batchServer(t)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	files, err := c.DownloadFiles(context.Background(), []string{"a", "bad1", "c"})
+	var multi *MultiError
+	require.True(t, errors.As(err, &multi))
+	assert.Equal(t, []BatchItem{
+		{Index: 0, Key: "a", Status: BatchSucceeded},
+		{Index: 1, Key: "bad1", Status: BatchFailed, Err: multi.Items[1].Err},
+		{Index: 2, Key: "c", Status: BatchSucceeded},
+	}, multi.Items)
+	assert.Equal(t, [][]byte{[]byte("a"), nil, []byte("c")}, files)
+}
+
+func TestClient_DeleteFiles(t *testing.T) {
+	server := batchServer(t)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, c.DeleteFiles(context.Background(), []string{"a", "b"}))
+
+	err = c.DeleteFiles(context.Background(), []string{"a", "bad1", "bad2"})
+	var multi *MultiError
+	require.True(t, errors.As(err, &multi))
+	assert.Len(t, multi.Failed(), 2)
+	assert.Equal(t, "bad1", multi.Failed()[0].Key)
+	assert.Equal(t, "bad2", multi.Failed()[1].Key)
+}
+
+func TestClient_DeleteFiles_FallbackIsNotAnError(t *testing.T) {
+	server := batchServer(t)
+	defer server.Close()
+
+	var ops []string
+	c, err := New(server.URL, nil, WithErrorHook(func(ctx context.Context, op Operation, err error) {
+		ops = append(ops, op.Name)
+	}))
+	require.NoError(t, err)
+
+	require.NoError(t, c.DeleteFiles(context.Background(), []string{"a", "b"}))
+	assert.Empty(t, ops)
+
+	require.Error(t, c.DeleteFiles(context.Background(), []string{"a", "bad1"}))
+	assert.Equal(t, []string{"DeleteFiles"}, ops)
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// BlobStore is the minimal object storage interface used by BlobSink.
+// Adapters for S3, GCS, MinIO and similar stores only need to implement Put.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+}
+
+// BlobExister is an optional BlobStore extension. When implemented,
+// BlobSink skips uploading objects that already exist.
+type BlobExister interface {
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// BlobSink is a ResultSink uploading plugin outputs and downloaded files
+// to a BlobStore under content-addressed keys:
+//
+//	<prefix>/results/<plugin>/<sha256>.json
+//	<prefix>/files/<sha256>
+//
+// Uploads use the context of the run or download through ContextSink.
+// It is safe for concurrent use.
+type BlobSink struct {
+	store  BlobStore
+	prefix string
+
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+// NewBlobSink creates a BlobSink writing to store under prefix.
+func NewBlobSink(store BlobStore, prefix string) (*BlobSink, error) {
+	if store == nil {
+		return nil, errors.New("blob store is required")
+	}
+	return &BlobSink{
+		store:  store,
+		prefix: prefix,
+		keys:   make(map[string]string),
+	}, nil
+}
+
+// WriteResult implements ResultSink.
+func (s *BlobSink) WriteResult(pluginName string, output map[string]any) error {
+	return s.WriteResultContext(context.Background(), pluginName, output)
+}
+
+// WriteResultContext implements ContextSink. The plugin name must be
+// usable as a single segment of the key.
+func (s *BlobSink) WriteResultContext(ctx context.Context, pluginName string, output map[string]any) error {
+	if pluginName == "" || pluginName == "." || pluginName == ".." || strings.ContainsAny(pluginName, `/\`) {
+		return fmt.Errorf("invalid plugin name %q", pluginName)
+	}
+	data, err := json.Marshal(output)
+	if err != nil {
+		return fmt.Errorf("failed to JSON encode result: %w", err)
+	}
+	key := path.Join(s.prefix, "results", pluginName, contentHash(data)+".json")
+	return s.put(ctx, key, bytes.NewReader(data), int64(len(data)), "application/json")
+}
+
+// WriteFile implements ResultSink.
+func (s *BlobSink) WriteFile(fileID string, data []byte) error {
+	return s.WriteFileContext(context.Background(), fileID, bytes.NewReader(data))
+}
+
+// WriteFileContext implements ContextSink. The content is hashed before
+// it is uploaded, so a reader that is not an io.ReadSeeker is first
+// copied to a temporary file.
+func (s *BlobSink) WriteFileContext(ctx context.Context, fileID string, r io.Reader) error {
+	rs, ok := r.(io.ReadSeeker)
+	if !ok {
+		f, err := os.CreateTemp("", "blobsink-*")
+		if err != nil {
+			return fmt.Errorf("failed to buffer file: %w", err)
+		}
+		defer func() {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}()
+		if _, err := io.Copy(f, r); err != nil {
+			return fmt.Errorf("failed to buffer file: %w", err)
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to buffer file: %w", err)
+		}
+		rs = f
+	}
+	sum, size, err := hashContent(rs)
+	if err != nil {
+		return fmt.Errorf("failed to hash file: %w", err)
+	}
+	key := path.Join(s.prefix, "files", sum)
+	if err := s.put(ctx, key, rs, size, "application/octet-stream"); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.keys[fileID] = key
+	s.mu.Unlock()
+	return nil
+}
+
+// Key returns the blob key a file was stored under.
+func (s *BlobSink) Key(fileID string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[fileID]
+	return key, ok
+}
+
+func (s *BlobSink) put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if e, ok := s.store.(BlobExister); ok {
+		exists, err := e.Exists(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to check blob: %w", err)
+		}
+		if exists {
+			return nil
+		}
+	}
+	if err := s.store.Put(ctx, key, r, size, contentType); err != nil {
+		return fmt.Errorf("failed to upload blob: %w", err)
+	}
+	return nil
+}
+
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hashContent returns the SHA-256 digest and size of the rest of rs,
+// and seeks back to where it started.
+func hashContent(rs io.ReadSeeker) (string, int64, error) {
+	start, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", 0, err
+	}
+	h := sha256.New()
+	size, err := io.Copy(h, rs)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err := rs.Seek(start, io.SeekStart); err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryBlobStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	puts    int
+	err     error
+}
+
+func (m *memoryBlobStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if m.err != nil {
+		return m.err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.objects == nil {
+		m.objects = make(map[string][]byte)
+	}
+	m.objects[key] = data
+	m.puts++
+	return nil
+}
+
+func (m *memoryBlobStore) Exists(ctx context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.objects[key]
+	return ok, nil
+}
+
+func TestNewBlobSink(t *testing.T) {
+	s, err := NewBlobSink(nil, "")
+	require.Error(t, err)
+	require.Nil(t, s)
+}
+
+func TestBlobSink(t *testing.T) {
+	t.Run("content addressed", func(t *testing.T) {
+		store := &memoryBlobStore{}
+		s, err := NewBlobSink(store, "artifacts")
+		require.NoError(t, err)
+
+		require.NoError(t, s.WriteFile("file1", []byte("png")))
+		require.NoError(t, s.WriteFile("file2", []byte("png")))
+		assert.Equal(t, 1, store.puts, "identical content is uploaded once")
+
+		key1, ok := s.Key("file1")
+		require.True(t, ok)
+		key2, _ := s.Key("file2")
+		assert.Equal(t, key1, key2)
+		assert.Equal(t, "artifacts/files/"+contentHash([]byte("png")), key1)
+		assert.Equal(t, []byte("png"), store.objects[key1])
+
+		require.NoError(t, s.WriteResult("screenshot", map[string]any{"a": 1}))
+		assert.Contains(t, store.objects, "artifacts/results/screenshot/"+contentHash([]byte(`{"a":1}`))+".json")
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		store := &memoryBlobStore{}
+		s, err := NewBlobSink(store, "")
+		require.NoError(t, err)
+
+		require.NoError(t, s.WriteFileContext(context.Background(), "file1", io.MultiReader(strings.NewReader("p"), strings.NewReader("ng"))))
+		key, ok := s.Key("file1")
+		require.True(t, ok)
+		assert.Equal(t, "files/"+contentHash([]byte("png")), key)
+		assert.Equal(t, []byte("png"), store.objects[key])
+	})
+
+	t.Run("context", func(t *testing.T) {
+		s, err := NewBlobSink(&memoryBlobStore{}, "")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, s.WriteFileContext(ctx, "file1", strings.NewReader("png")), context.Canceled)
+		assert.ErrorIs(t, s.WriteResultContext(ctx, "screenshot", nil), context.Canceled)
+	})
+
+	t.Run("invalid plugin name", func(t *testing.T) {
+		store := &memoryBlobStore{}
+		s, err := NewBlobSink(store, "artifacts")
+		require.NoError(t, err)
+
+		for _, name := range []string{"", "..", "../../etc", `a\b`} {
+			assert.EqualError(t, s.WriteResult(name, nil), fmt.Sprintf("invalid plugin name %q", name))
+		}
+		assert.Zero(t, store.puts)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		s, err := NewBlobSink(&memoryBlobStore{err: errors.New("denied")}, "")
+		require.NoError(t, err)
+
+		err = s.WriteFile("file1", []byte("png"))
+		require.EqualError(t, err, "failed to upload blob: denied")
+		_, ok := s.Key("file1")
+		assert.False(t, ok)
+	})
+}
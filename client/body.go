@@ -0,0 +1,59 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+const (
+	// maxErrorBodySize caps how much of an error response is decoded.
+	maxErrorBodySize = 64 << 10
+	// maxDrainSize caps how much of an unread response body is discarded
+	// before closing it. Larger remainders cost more to read than a new
+	// connection, so their connection is dropped instead of reused.
+	maxDrainSize = 256 << 10
+)
+
+// closeBody drains what is left of a response body and closes it,
+// so that the keep-alive connection can be reused.
+func closeBody(body io.ReadCloser) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, maxDrainSize))
+	_ = body.Close()
+}
+
+// newBodyRequest creates a request with a replayable body. GetBody returns
+// a fresh reader over body, so redirects and retries resend it in full
+// instead of an empty body.
+func newBodyRequest(
+	ctx context.Context,
+	method, url, contentType string,
+	body []byte,
+) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.Header.Set("Content-Type", contentType)
+	return req, nil
+}
+
+// readBody reads a response body in full. A known content length sizes
+// the buffer up front, so the memory used matches the file size instead
+// of growing by doubling.
+func readBody(resp *http.Response) ([]byte, error) {
+	if resp.ContentLength <= 0 {
+		return io.ReadAll(resp.Body)
+	}
+	// The transport never returns more than the content length.
+	data := make([]byte, resp.ContentLength)
+	if _, err := io.ReadFull(resp.Body, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
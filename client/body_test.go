@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionReuseOnErrors(t *testing.T) {
+	// The JSON error is followed by padding the decoder never reads,
+	// like a verbose error page or a trailing debug payload.
+	body := `{"message": "internal error"}` + strings.Repeat(" ", 200<<10)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(body))
+	}))
+	var conns atomic.Int32
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		_, err := c.Plugins(context.Background())
+		require.EqualError(t, err, "unexpected response status: 500 Internal Server Error; message: internal error")
+		_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+		require.Error(t, err)
+		require.Error(t, c.Healthcheck(context.Background()))
+	}
+	assert.Equal(t, int32(1), conns.Load())
+}
+
+func TestNewBodyRequest(t *testing.T) {
+	req, err := newBodyRequest(context.Background(), http.MethodPost, "http://example.com", "application/json", []byte(`{"a":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), req.ContentLength)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+	for i := 0; i < 2; i++ {
+		body, err := req.GetBody()
+		require.NoError(t, err)
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, string(data))
+	}
+}
+
+func TestRunPluginBodyReplay(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/plugins/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/api/v1/plugins/new", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("POST /api/v1/plugins/new", func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		_ = json.NewEncoder(w).Encode(map[string]any{"new": params})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	output, err := c.RunPlugin(context.Background(), "old", map[string]any{"query": "golang"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"query": "golang"}, output["new"])
+}
@@ -0,0 +1,80 @@
+package client
+
+import "context"
+
+// AttemptsKey is the output key under which RunPlugin reports every
+// attempt made when a run was repeated because of bot detection.
+// The value is a []Attempt in the order the attempts were made.
+const AttemptsKey = "_attempts"
+
+// BotDetector reports whether a plugin output indicates that the target
+// site detected and blocked the browser.
+type BotDetector func(output map[string]any) bool
+
+// BotDetectionPolicy re-runs a plugin once when its output is flagged
+// by Detect.
+type BotDetectionPolicy struct {
+	// Detect inspects the plugin output. It is required.
+	Detect BotDetector
+	// Rotate returns the params for the re-run, typically with a new
+	// fingerprint or proxy. It receives a copy of the original params.
+	// Params managed by the client's rotation pools, such as fingerprints,
+	// user agents and proxies, are rotated before Rotate is called.
+	Rotate func(params map[string]any) map[string]any
+}
+
+// Attempt is a single plugin execution made under a BotDetectionPolicy.
+type Attempt struct {
+	Params      map[string]any
+	Output      map[string]any
+	BotDetected bool
+}
+
+// WithBotDetectionRetry enables the given bot-detection policy for the run.
+func WithBotDetectionRetry(p BotDetectionPolicy) RunOption {
+	return func(cfg *runConfig) {
+		if p.Detect != nil {
+			cfg.botPolicy = &p
+		}
+	}
+}
+
+func (c *Client) rerunIfBotDetected(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	result *RunResult,
+	cfg *runConfig,
+) (*RunResult, error) {
+	policy := cfg.botPolicy
+	if !policy.Detect(result.Output) {
+		return result, nil
+	}
+	first := Attempt{Params: params, Output: result.Output, BotDetected: true}
+
+	retryParams := copyParams(params)
+	if err := c.rotate(retryParams); err != nil {
+		return nil, err
+	}
+	if policy.Rotate != nil {
+		retryParams = policy.Rotate(retryParams)
+	}
+	retry, err := c.runPlugin(ctx, pluginName, retryParams, cfg.output)
+	if err != nil {
+		return nil, err
+	}
+	second := Attempt{
+		Params:      retryParams,
+		Output:      copyParams(retry.Output),
+		BotDetected: policy.Detect(retry.Output),
+	}
+	retry.Attempts = []Attempt{first, second}
+	retry.Retries = 1
+	retry.Warnings = append(result.Warnings, retry.Warnings...)
+	// A null output decodes to a nil map.
+	if retry.Output == nil {
+		retry.Output = map[string]any{}
+	}
+	retry.Output[AttemptsKey] = retry.Attempts
+	return retry, nil
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithBotDetectionRetry(t *testing.T) {
+	detect := func(output map[string]any) bool {
+		return output["blocked"] == true
+	}
+	rotate := func(params map[string]any) map[string]any {
+		params["proxy"] = "http://proxy2:8080"
+		return params
+	}
+
+	t.Run("re-runs once with rotated params", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var params map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			if calls.Add(1) == 1 {
+				_, _ = w.Write([]byte(`{"blocked": true}`))
+				return
+			}
+			assert.Equal(t, "http://proxy2:8080", params["proxy"])
+			_, _ = w.Write([]byte(`{"screenshot": "ok"}`))
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		output, err := c.RunPlugin(context.Background(),
+			"screenshot",
+			map[string]any{"proxy": "http://proxy1:8080"},
+			WithBotDetectionRetry(BotDetectionPolicy{Detect: detect, Rotate: rotate}),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Equal(t, "ok", output["screenshot"])
+
+		attempts := output[AttemptsKey].([]Attempt)
+		require.Len(t, attempts, 2)
+		assert.True(t, attempts[0].BotDetected)
+		assert.Equal(t, "http://proxy1:8080", attempts[0].Params["proxy"])
+		assert.False(t, attempts[1].BotDetected)
+		assert.Equal(t, "http://proxy2:8080", attempts[1].Params["proxy"])
+	})
+
+	t.Run("null retry output", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				_, _ = w.Write([]byte(`{"blocked": true}`))
+				return
+			}
+			_, _ = w.Write([]byte(`null`))
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		output, err := c.RunPlugin(context.Background(), "screenshot", nil,
+			WithBotDetectionRetry(BotDetectionPolicy{Detect: detect}),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), calls.Load())
+		require.Len(t, output[AttemptsKey], 2)
+	})
+
+	t.Run("no re-run when not detected", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			_, _ = w.Write([]byte(`{"screenshot": "ok"}`))
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		output, err := c.RunPlugin(context.Background(),
+			"screenshot",
+			nil,
+			WithBotDetectionRetry(BotDetectionPolicy{Detect: detect}),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+		assert.NotContains(t, output, AttemptsKey)
+	})
+
+	t.Run("re-run error", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				_, _ = w.Write([]byte(`{"blocked": true}`))
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		output, err := c.RunPlugin(context.Background(),
+			"screenshot",
+			nil,
+			WithBotDetectionRetry(BotDetectionPolicy{Detect: detect}),
+		)
+		require.ErrorContains(t, err, "unexpected response status: 500 Internal Server Error")
+		assert.Nil(t, output)
+	})
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// DownloadBudget limits the bytes held by downloads in flight. A download
+// reserves the size of its file before reading the body and releases it
+// when DownloadFile returns, so new downloads block while the budget is
+// exhausted. A budget may be shared by several clients to bound the
+// memory of a whole process. It is safe for concurrent use.
+type DownloadBudget struct {
+	total int64
+
+	mu   sync.Mutex
+	used int64
+	// changed is closed and replaced whenever bytes are released.
+	changed chan struct{}
+}
+
+// NewDownloadBudget creates a budget of total bytes.
+func NewDownloadBudget(total int64) *DownloadBudget {
+	if total < 1 {
+		total = 1
+	}
+	return &DownloadBudget{total: total, changed: make(chan struct{})}
+}
+
+// WithDownloadBudget makes DownloadFile, and the helpers built on it,
+// reserve file sizes from b. Files larger than the whole budget are
+// downloaded alone. A file of unknown size reserves the whole budget.
+func WithDownloadBudget(b *DownloadBudget) Option {
+	return func(c *Client) {
+		c.downloadBudget = b
+	}
+}
+
+// InUse returns the number of bytes reserved by downloads in flight.
+func (b *DownloadBudget) InUse() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used
+}
+
+// reserve waits until n bytes, capped at the total, are available and
+// reserves them. A negative n reserves the whole budget. It returns the
+// function releasing the reservation.
+func (b *DownloadBudget) reserve(ctx context.Context, n int64) (release func(), err error) {
+	if n < 0 || n > b.total {
+		n = b.total
+	}
+	for {
+		b.mu.Lock()
+		if b.used+n <= b.total {
+			b.used += n
+			b.mu.Unlock()
+			var once sync.Once
+			return func() { once.Do(func() { b.release(n) }) }, nil
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (b *DownloadBudget) release(n int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used -= n
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadBudget(t *testing.T) {
+	b := NewDownloadBudget(100)
+	ctx := context.Background()
+
+	release, err := b.reserve(ctx, 60)
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), b.InUse())
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = b.reserve(timeout, 50)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	reserved := make(chan func())
+	go func() {
+		// Larger than the budget: waits for all of it.
+		r, _ := b.reserve(ctx, 500)
+		reserved <- r
+	}()
+	release()
+	release()
+	whole := <-reserved
+	assert.Equal(t, int64(100), b.InUse())
+	whole()
+
+	unknown, err := b.reserve(ctx, -1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), b.InUse())
+	unknown()
+	assert.Equal(t, int64(0), b.InUse())
+}
+
+func TestWithDownloadBudget(t *testing.T) {
+	body := strings.Repeat("x", 100)
+	unblock := make(chan struct{})
+	headers := make(chan struct{}, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		headers <- struct{}{}
+		<-unblock
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	budget := NewDownloadBudget(150)
+	c, err := New(server.URL, nil, WithDownloadBudget(budget))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := c.DownloadFile(context.Background(), "file")
+			if assert.NoError(t, err) {
+				assert.Equal(t, body, string(data))
+			}
+		}()
+	}
+	<-headers
+	<-headers
+	// Both responses started, but only one fits in the budget.
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int64(100), budget.InUse())
+
+	close(unblock)
+	wg.Wait()
+	assert.Equal(t, int64(0), budget.InUse())
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// maxPooledBufferSize caps the size of buffers kept for reuse, so a single
+// huge run does not pin its buffer for the lifetime of the process.
+const maxPooledBufferSize = 1 << 20
+
+var paramBuffers = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// WithoutBufferPooling disables the reuse of the buffers used to
+// JSON-encode run params. Pooling is enabled by default and saves an
+// allocation per run for batch workloads with multi-KB params; disabling
+// it may help when profiling allocations or debugging body corruption.
+func WithoutBufferPooling() Option {
+	return func(c *Client) {
+		c.noBufferPool = true
+	}
+}
+
+// marshalParams JSON-encodes params like json.Marshal. The returned body
+// is only valid until release is called. Once a request using it was
+// created, pass release to releaseAfterBody instead of calling it.
+func (c *Client) marshalParams(params map[string]any) (body []byte, release func(), err error) {
+	if c.noBufferPool {
+		body, err = json.Marshal(params)
+		return body, func() {}, err
+	}
+	buf := paramBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	release = func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			paramBuffers.Put(buf)
+		}
+	}
+	if err := json.NewEncoder(buf).Encode(params); err != nil {
+		release()
+		return nil, nil, err
+	}
+	// Encode terminates the value with a newline, which Marshal does not.
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), release, nil
+}
+
+// releaseAfterBody returns a function to call once req is done, which
+// calls release as soon as every body of req, including those returned
+// by GetBody for retries, was closed. The transport may still read a
+// request body after the response arrived, so the buffer it reads
+// from must not be reused before.
+func releaseAfterBody(req *http.Request, release func()) (done func()) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return release
+	}
+	var (
+		mu       sync.Mutex
+		open     int
+		finished bool
+	)
+	// releaseLocked calls release once nothing can read the body anymore.
+	releaseLocked := func() {
+		if finished && open == 0 && release != nil {
+			release()
+			release = nil
+		}
+	}
+	track := func(body io.ReadCloser) io.ReadCloser {
+		open++
+		return &closeHook{ReadCloser: body, fn: func() {
+			mu.Lock()
+			defer mu.Unlock()
+			open--
+			releaseLocked()
+		}}
+	}
+
+	req.Body = track(req.Body)
+	if getBody := req.GetBody; getBody != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if finished {
+				return nil, errors.New("request body already released")
+			}
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return track(body), nil
+		}
+	}
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		finished = true
+		releaseLocked()
+	}
+}
+
+// closeHook calls fn when the body it wraps is first closed.
+type closeHook struct {
+	io.ReadCloser
+	once sync.Once
+	fn   func()
+}
+
+func (h *closeHook) Close() error {
+	err := h.ReadCloser.Close()
+	h.once.Do(h.fn)
+	return err
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_marshalParams(t *testing.T) {
+	params := map[string]any{
+		"url":  "https://example.com/?a=1&b=<2>",
+		"data": strings.Repeat("x", 4096),
+	}
+	want, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	for _, opts := range [][]Option{nil, {WithoutBufferPooling()}} {
+		c, err := New("http://localhost", nil, opts...)
+		require.NoError(t, err)
+
+		body, release, err := c.marshalParams(params)
+		require.NoError(t, err)
+		assert.Equal(t, want, body)
+		release()
+
+		_, _, err = c.marshalParams(map[string]any{"bad": func() {}})
+		assert.Error(t, err)
+	}
+}
+
+func TestBufferPooling(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.NewEncoder(w).Encode(map[string]any{"echo": string(body)})
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	// Concurrent runs must never observe each other's params.
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			params := map[string]any{"n": float64(i), "pad": strings.Repeat("p", i*100)}
+			want, _ := json.Marshal(params)
+			output, err := c.RunPlugin(context.Background(), "echo", params)
+			if assert.NoError(t, err) {
+				assert.Equal(t, string(want), output["echo"])
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestBufferPooling_BodyReadAfterResponse(t *testing.T) {
+	// The transport answers before reading the body, and reads it later.
+	var bodies []io.ReadCloser
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		bodies = append(bodies, r.Body)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{}`)),
+			Request:    r,
+		}, nil
+	})
+	c, err := New("http://localhost", &http.Client{Transport: transport})
+	require.NoError(t, err)
+
+	var want []string
+	for i := 0; i < 3; i++ {
+		params := map[string]any{"n": float64(i), "pad": strings.Repeat("p", 512)}
+		data, _ := json.Marshal(params)
+		want = append(want, string(data))
+		_, err := c.RunPlugin(context.Background(), "echo", params)
+		require.NoError(t, err)
+	}
+	for i, body := range bodies {
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, want[i], string(data))
+		require.NoError(t, body.Close())
+	}
+}
+
+func TestReleaseAfterBody(t *testing.T) {
+	req, err := newBodyRequest(context.Background(), http.MethodPost, "http://localhost", "application/json", []byte("{}"))
+	require.NoError(t, err)
+	released := 0
+	done := releaseAfterBody(req, func() { released++ })
+
+	retry, err := req.GetBody()
+	require.NoError(t, err)
+	require.NoError(t, req.Body.Close())
+	done()
+	assert.Zero(t, released)
+	require.NoError(t, retry.Close())
+	require.NoError(t, retry.Close())
+	assert.Equal(t, 1, released)
+
+	_, err = req.GetBody()
+	assert.Error(t, err)
+}
+
+func BenchmarkClient_marshalParams(b *testing.B) {
+	params := map[string]any{
+		"url":     "https://example.com",
+		"cookies": strings.Repeat("c", 8<<10),
+	}
+	for _, bench := range []struct {
+		name string
+		opts []Option
+	}{
+		{"pooled", nil},
+		{"unpooled", []Option{WithoutBufferPooling()}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			c, _ := New("http://localhost", nil, bench.opts...)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, release, _ := c.marshalParams(params)
+				release()
+			}
+		})
+	}
+}
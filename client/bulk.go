@@ -0,0 +1,323 @@
+package client
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/bazuker/browserbro-go-api/files"
+)
+
+// maxBulkFileIDs is the number of file IDs sent per bulk request.
+const maxBulkFileIDs = 1000
+
+var errNoBulkEndpoint = errors.New("no bulk endpoint")
+
+// DeleteFiles deletes the files with the given IDs. They are deleted with
+// one request per 1000 files, or one by one if the server has no bulk
+// delete endpoint. The batch options opts apply to the requests either
+// way, except that the first bulk request is always sent on its own, to
+// find out whether the server has the endpoint. If any file was not
+// deleted, the error is a *MultiError with the error of every file.
+func (c *Client) DeleteFiles(
+	ctx context.Context,
+	fileIDs []string,
+	opts ...BatchOption,
+) (err error) {
+	ctx, op := c.startOperation(ctx, "DeleteFiles")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	errs, ok := c.deleteFilesBulk(ctx, fileIDs, opts)
+	if !ok {
+		return runBatch(ctx, fileIDs, opts, func(i int) error {
+			return c.deleteFile(ctx, fileIDs[i])
+		})
+	}
+	return batchResult(fileIDs, errs)
+}
+
+// deleteFilesBulk deletes files with the bulk delete endpoint and returns
+// the error of every file. It reports false, before deleting any file,
+// if the server has no such endpoint.
+func (c *Client) deleteFilesBulk(ctx context.Context, fileIDs []string, opts []BatchOption) ([]error, bool) {
+	errs := make([]error, len(fileIDs))
+	var valid []int
+	for i, id := range fileIDs {
+		if err := files.FileID(id).Validate(); err != nil {
+			errs[i] = err
+			continue
+		}
+		valid = append(valid, i)
+	}
+	var chunks [][]int
+	for len(valid) > 0 {
+		chunk := valid[:min(len(valid), maxBulkFileIDs)]
+		chunks = append(chunks, chunk)
+		valid = valid[len(chunk):]
+	}
+	if len(chunks) == 0 {
+		return errs, true
+	}
+
+	// send deletes the files of a chunk and returns the first error.
+	send := func(chunk []int) error {
+		ids := make([]string, len(chunk))
+		for j, i := range chunk {
+			ids[j] = fileIDs[i]
+		}
+		results, err := c.deleteFilesChunk(ctx, ids)
+		if err != nil {
+			for _, i := range chunk {
+				errs[i] = err
+			}
+			return err
+		}
+		var first error
+		for j, i := range chunk {
+			errs[i] = results[ids[j]]
+			if first == nil {
+				first = errs[i]
+			}
+		}
+		return first
+	}
+
+	// The first request finds out whether the server has the endpoint.
+	err := send(chunks[0])
+	if errors.Is(err, errNoBulkEndpoint) {
+		return nil, false
+	}
+	rest := chunks[1:]
+	var cfg batchConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	var skipped [][]int
+	if err != nil && cfg.failFast {
+		skipped, rest = rest, nil
+	}
+	keys := make([]string, len(rest))
+	for k, chunk := range rest {
+		keys[k] = fileIDs[chunk[0]]
+	}
+	var multi *MultiError
+	if errors.As(runBatch(ctx, keys, opts, func(k int) error { return send(rest[k]) }), &multi) {
+		for _, item := range multi.Skipped() {
+			skipped = append(skipped, rest[item.Index])
+		}
+	}
+	for _, chunk := range skipped {
+		for _, i := range chunk {
+			errs[i] = ErrBatchItemSkipped
+		}
+	}
+	for i, err := range errs {
+		if errors.Is(err, errNoBulkEndpoint) {
+			errs[i] = errors.New("failed to delete files: bulk delete rejected")
+		}
+	}
+	return errs, true
+}
+
+// deleteFilesChunk sends a bulk delete request and returns the errors of
+// the files that were not deleted, by file ID.
+func (c *Client) deleteFilesChunk(ctx context.Context, ids []string) (map[string]error, error) {
+	body, err := json.Marshal(FileIDsRequest{IDs: ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to JSON encode file IDs: %w", err)
+	}
+	body, contentType, err := c.sealBody(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newBodyRequest(ctx, http.MethodPost, c.addr+"/files/delete", contentType, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create delete request: %w", err)
+	}
+	if c.kms != nil {
+		req.Header.Set("Accept", EnvelopeContentType)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete files: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		if err := c.bulkError(resp); err != nil {
+			return nil, err
+		}
+		return nil, errNoBulkEndpoint
+	}
+
+	var payload DeleteFilesResponse
+	if err := c.decodeBody(ctx, resp, &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode deleted files: %w", err)
+	}
+	errs := make(map[string]error, len(ids))
+	for _, id := range ids {
+		errs[id] = fmt.Errorf("failed to delete file %s: server returned no result", id)
+	}
+	for _, r := range payload.Results {
+		if _, ok := errs[r.ID]; !ok {
+			continue
+		}
+		if r.Status >= 200 && r.Status <= 299 {
+			delete(errs, r.ID)
+			continue
+		}
+		errs[r.ID] = c.itemAPIError(req, r)
+	}
+	return errs, nil
+}
+
+// itemAPIError builds the APIError of a single file of a bulk request.
+func (c *Client) itemAPIError(req *http.Request, r DeleteFileResult) *APIError {
+	e := &APIError{
+		StatusCode: r.Status,
+		Status:     fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		Method:     req.Method,
+		URL:        maskURL(req.URL.String()),
+		RequestID:  req.Header.Get(RequestIDHeader),
+		policy:     c.statusPolicies[r.Status],
+	}
+	if msg := r.Error; msg != nil {
+		e.Message, e.Code, e.Param, e.Hint = msg.Message, msg.Code, msg.Param, msg.Hint
+		e.Fields = msg.Errors
+	}
+	return e
+}
+
+// bulkError returns the error of a failed bulk request, or nil if the
+// response means that the server has no bulk endpoint.
+func (c *Client) bulkError(resp *http.Response) error {
+	apiErr := c.newAPIError(resp)
+	switch apiErr.StatusCode {
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return nil
+	case http.StatusNotFound:
+		// Errors of the endpoint have a code; a bare 404 means the
+		// server predates it.
+		if apiErr.Code == "" {
+			return nil
+		}
+	}
+	return apiErr
+}
+
+// DownloadArchive writes a zip archive of the files with the given IDs to
+// w, with one entry per file named after its ID. The archive is streamed
+// by the server's archive endpoint or, if it has none, assembled by the
+// client from one download per file, in which case files that could not
+// be downloaded are left out and reported in a *MultiError.
+func (c *Client) DownloadArchive(ctx context.Context, fileIDs []string, w io.Writer) (err error) {
+	ctx, op := c.startOperation(ctx, "DownloadArchive")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	for _, id := range fileIDs {
+		if err := files.FileID(id).Validate(); err != nil {
+			return err
+		}
+	}
+	err = c.downloadArchive(ctx, fileIDs, w)
+	if !errors.Is(err, errNoBulkEndpoint) {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	errs := make([]error, len(fileIDs))
+	for i, id := range fileIDs {
+		if ctx.Err() != nil {
+			errs[i] = ErrBatchItemSkipped
+			continue
+		}
+		body, _, err := c.downloadFileStream(ctx, id)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		err = writeArchiveEntry(zw, id, body)
+		closeBody(body)
+		if err != nil {
+			// The archive is broken once an entry is cut short.
+			return fmt.Errorf("failed to write archive entry %s: %w", id, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return batchResult(fileIDs, errs)
+}
+
+// downloadArchive copies the archive of the server's archive endpoint
+// to w, or returns errNoBulkEndpoint if the server has none.
+func (c *Client) downloadArchive(ctx context.Context, fileIDs []string, w io.Writer) error {
+	body, err := json.Marshal(FileIDsRequest{IDs: fileIDs})
+	if err != nil {
+		return fmt.Errorf("failed to JSON encode file IDs: %w", err)
+	}
+	// The archive itself is file content, which is never encrypted.
+	body, contentType, err := c.sealBody(ctx, body)
+	if err != nil {
+		return err
+	}
+	req, err := newBodyRequest(ctx, http.MethodPost, c.addr+"/files/archive", contentType, body)
+	if err != nil {
+		return fmt.Errorf("failed to create archive request: %w", err)
+	}
+	req.Header.Set("Accept", "application/zip")
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download archive: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		if err := c.bulkError(resp); err != nil {
+			return err
+		}
+		return errNoBulkEndpoint
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to download archive: %w", transportError(err))
+	}
+	return nil
+}
+
+func writeArchiveEntry(zw *zip.Writer, name string, r io.Reader) error {
+	entry, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, r)
+	return err
+}
+
+// batchResult returns a *MultiError with the statuses of keys, given the
+// error of every key, unless all of them are nil. ErrBatchItemSkipped
+// marks keys that were not attempted.
+func batchResult(keys []string, errs []error) error {
+	items := make([]BatchItem, len(keys))
+	failed := false
+	for i, key := range keys {
+		items[i] = BatchItem{Index: i, Key: key, Status: BatchSucceeded}
+		switch err := errs[i]; {
+		case err == nil:
+			continue
+		case errors.Is(err, ErrBatchItemSkipped):
+			items[i].Status = BatchSkipped
+		default:
+			items[i].Status = BatchFailed
+		}
+		items[i].Err = errs[i]
+		failed = true
+	}
+	if failed {
+		return &MultiError{Items: items}
+	}
+	return nil
+}
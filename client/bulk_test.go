@@ -0,0 +1,155 @@
+package client
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_DeleteFiles_Bulk(t *testing.T) {
+	var requests atomic.Int32
+	var sent []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/files/delete", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var req FileIDsRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		sent = append(sent, req.IDs...)
+		var resp DeleteFilesResponse
+		for _, id := range req.IDs {
+			switch {
+			case strings.HasPrefix(id, "missing"):
+				resp.Results = append(resp.Results, DeleteFileResult{
+					ID:     id,
+					Status: http.StatusNotFound,
+					Error:  &ErrorResponse{Message: "file not found", Code: "file_not_found"},
+				})
+			case id == "silent":
+			default:
+				resp.Results = append(resp.Results, DeleteFileResult{ID: id, Status: http.StatusNoContent})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("DELETE /api/v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected single delete of %s", r.PathValue("id"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	ids := make([]string, 1500)
+	for i := range ids {
+		ids[i] = "shot" + string(rune('a'+i%26)) + ".png"
+	}
+	require.NoError(t, c.DeleteFiles(context.Background(), ids))
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, ids, sent)
+
+	err = c.DeleteFiles(context.Background(), []string{"a.png", "missing.png", "../etc", "silent"})
+	var multi *MultiError
+	require.ErrorAs(t, err, &multi)
+	assert.Equal(t, BatchSucceeded, multi.Items[0].Status)
+	require.Len(t, multi.Failed(), 3)
+	assert.ErrorIs(t, multi.Items[1].Err, ErrNotFound)
+	var apiErr *APIError
+	require.ErrorAs(t, multi.Items[1].Err, &apiErr)
+	assert.Equal(t, "file_not_found", apiErr.Code)
+	assert.Equal(t, http.MethodPost, apiErr.Method)
+	assert.ErrorContains(t, multi.Items[2].Err, "invalid file ID")
+	assert.ErrorContains(t, multi.Items[3].Err, "server returned no result")
+}
+
+func TestClient_DeleteFiles_BulkFailFast(t *testing.T) {
+	var requests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/files/delete", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var req FileIDsRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var resp DeleteFilesResponse
+		for _, id := range req.IDs {
+			resp.Results = append(resp.Results, DeleteFileResult{ID: id, Status: http.StatusForbidden})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	ids := make([]string, 2500)
+	for i := range ids {
+		ids[i] = "shot.png"
+	}
+	err = c.DeleteFiles(context.Background(), ids, WithFailFast())
+	var multi *MultiError
+	require.ErrorAs(t, err, &multi)
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Len(t, multi.Failed(), maxBulkFileIDs)
+	assert.Len(t, multi.Skipped(), 1500)
+}
+
+func TestClient_DownloadArchive(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/files/archive", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/zip", r.Header.Get("Accept"))
+		var req FileIDsRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/zip")
+		_, _ = w.Write([]byte("zip:" + strings.Join(req.IDs, ",")))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.DownloadArchive(context.Background(), []string{"a.png", "b.png"}, &buf))
+	assert.Equal(t, "zip:a.png,b.png", buf.String())
+
+	assert.Error(t, c.DownloadArchive(context.Background(), []string{"a/b"}, &buf))
+}
+
+func TestClient_DownloadArchive_Fallback(t *testing.T) {
+	server := batchServer(t)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = c.DownloadArchive(context.Background(), []string{"a.png", "bad.png", "c.png"}, &buf)
+	var multi *MultiError
+	require.ErrorAs(t, err, &multi)
+	require.Len(t, multi.Failed(), 1)
+	assert.Equal(t, "bad.png", multi.Failed()[0].Key)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 2)
+	names := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		_ = rc.Close()
+		names[f.Name] = string(data)
+	}
+	assert.Equal(t, map[string]string{"a.png": "a.png", "c.png": "c.png"}, names)
+}
@@ -0,0 +1,213 @@
+package client
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// DefaultCacheSize is the number of response bytes a ResponseCache
+// keeps unless another size is given.
+const DefaultCacheSize = 64 << 20
+
+// CacheStats counts the lookups of a ResponseCache.
+type CacheStats struct {
+	// Hits is the number of responses served from the cache after the
+	// server confirmed they are unchanged.
+	Hits int64
+	// Misses is the number of GET and HEAD requests answered in full.
+	Misses int64
+	// Evictions is the number of responses dropped to stay within size.
+	Evictions int64
+}
+
+// ResponseCache keeps the bodies of GET responses carrying an ETag or
+// Last-Modified header, such as the plugin list, file listings and file
+// contents. Repeated requests are sent with If-None-Match and
+// If-Modified-Since, and a 304 answer is served from the cache, so
+// unchanged files are not downloaded again. Least recently used
+// responses are evicted first. It is safe for concurrent use and may be
+// shared by several clients of the same server and credentials.
+type ResponseCache struct {
+	size int64
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	used    int64
+	stats   CacheStats
+}
+
+type cacheEntry struct {
+	key    string
+	header http.Header
+	body   []byte
+}
+
+// NewResponseCache creates a ResponseCache keeping up to size bytes
+// of response bodies. A size of zero means DefaultCacheSize.
+func NewResponseCache(size int64) *ResponseCache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &ResponseCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// WithResponseCache caches GET responses in cache and revalidates them
+// with conditional requests.
+func WithResponseCache(cache *ResponseCache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// Stats returns the lookup counts.
+func (rc *ResponseCache) Stats() CacheStats {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.stats
+}
+
+// Clear drops every cached response.
+func (rc *ResponseCache) Clear() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.entries = make(map[string]*list.Element)
+	rc.lru.Init()
+	rc.used = 0
+}
+
+// cacheable reports whether req may be answered from the cache.
+func cacheable(req *http.Request) bool {
+	return (req.Method == http.MethodGet || req.Method == http.MethodHead) &&
+		req.Header.Get("Range") == "" &&
+		req.Header.Get("If-None-Match") == "" &&
+		req.Header.Get("If-Modified-Since") == ""
+}
+
+// prepare adds the validators of the cached response for req, if any,
+// and returns that response.
+func (rc *ResponseCache) prepare(req *http.Request) *cacheEntry {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	el, ok := rc.entries[req.URL.String()]
+	if !ok {
+		return nil
+	}
+	e := el.Value.(*cacheEntry)
+	if etag := e.header.Get("ETag"); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if modified := e.header.Get("Last-Modified"); modified != "" {
+		req.Header.Set("If-Modified-Since", modified)
+	}
+	return e
+}
+
+// handle serves a 304 response to req from e, the response returned by
+// prepare, and arranges for a full GET response to be cached once its
+// body is read.
+func (rc *ResponseCache) handle(req *http.Request, resp *http.Response, e *cacheEntry) *http.Response {
+	key := req.URL.String()
+	if resp.StatusCode == http.StatusNotModified && e != nil {
+		rc.mu.Lock()
+		if el, ok := rc.entries[key]; ok && el.Value == e {
+			rc.lru.MoveToFront(el)
+		}
+		rc.stats.Hits++
+		rc.mu.Unlock()
+
+		closeBody(resp.Body)
+		header := e.header.Clone()
+		for name, values := range resp.Header {
+			header[name] = values
+		}
+		cached := &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          http.NoBody,
+			ContentLength: int64(len(e.body)),
+			Request:       req,
+		}
+		if req.Method == http.MethodGet {
+			cached.Body = io.NopCloser(bytes.NewReader(e.body))
+		}
+		return cached
+	}
+
+	rc.mu.Lock()
+	rc.stats.Misses++
+	rc.mu.Unlock()
+	if req.Method != http.MethodGet || resp.StatusCode != http.StatusOK ||
+		resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" ||
+		resp.ContentLength > rc.size {
+		return resp
+	}
+	resp.Body = &cachingBody{
+		ReadCloser: resp.Body,
+		rc:         rc,
+		entry:      &cacheEntry{key: key, header: resp.Header.Clone()},
+		length:     resp.ContentLength,
+	}
+	return resp
+}
+
+func (rc *ResponseCache) store(e *cacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if el, ok := rc.entries[e.key]; ok {
+		rc.removeLocked(el)
+	}
+	rc.entries[e.key] = rc.lru.PushFront(e)
+	rc.used += int64(len(e.body))
+	for rc.used > rc.size {
+		rc.removeLocked(rc.lru.Back())
+		rc.stats.Evictions++
+	}
+}
+
+func (rc *ResponseCache) removeLocked(el *list.Element) {
+	e := rc.lru.Remove(el).(*cacheEntry)
+	delete(rc.entries, e.key)
+	rc.used -= int64(len(e.body))
+}
+
+// cachingBody caches a response body once it has been read in full.
+type cachingBody struct {
+	io.ReadCloser
+	rc     *ResponseCache
+	entry  *cacheEntry
+	buf    bytes.Buffer
+	length int64
+	done   bool
+}
+
+func (b *cachingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.done {
+		return n, err
+	}
+	b.buf.Write(p[:n])
+	switch {
+	case int64(b.buf.Len()) > b.rc.size || err != nil && err != io.EOF:
+		b.done = true
+		b.buf = bytes.Buffer{}
+	case err == io.EOF || b.length >= 0 && int64(b.buf.Len()) == b.length:
+		b.done = true
+		b.entry.body = b.buf.Bytes()
+		b.rc.store(b.entry)
+	}
+	return n, err
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithResponseCache(t *testing.T) {
+	content := "first"
+	var downloads int
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		etag := `"` + content + `"`
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		_, _ = w.Write([]byte(content))
+	})
+	mux.HandleFunc("GET /api/v1/plugins", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		if r.Header.Get("If-Modified-Since") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(`{"plugins": ["screenshot"]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cache := NewResponseCache(0)
+	c, err := New(server.URL, nil, WithResponseCache(cache))
+	require.NoError(t, err)
+
+	for range 3 {
+		data, err := c.DownloadFile(context.Background(), "f1")
+		require.NoError(t, err)
+		assert.Equal(t, "first", string(data))
+	}
+	assert.Equal(t, 1, downloads)
+
+	content = "second"
+	data, err := c.DownloadFile(context.Background(), "f1")
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+	assert.Equal(t, 2, downloads)
+
+	for range 2 {
+		plugins, err := c.Plugins(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"screenshot"}, plugins)
+	}
+	assert.Equal(t, CacheStats{Hits: 3, Misses: 3}, cache.Stats())
+}
+
+func TestResponseCache_Evicts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(strings.Repeat("x", 6)))
+	}))
+	defer server.Close()
+
+	cache := NewResponseCache(10)
+	c, err := New(server.URL, nil, WithResponseCache(cache))
+	require.NoError(t, err)
+
+	for _, id := range []string{"a", "b", "b"} {
+		_, err := c.DownloadFile(context.Background(), id)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 2, Evictions: 1}, cache.Stats())
+
+	cache.Clear()
+	_, err = c.DownloadFile(context.Background(), "b")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), cache.Stats().Misses)
+}
+
+func TestResponseCache_Retry(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("ETag", `"v1"`)
+		switch {
+		case r.Header.Get("If-None-Match") == "":
+			_, _ = w.Write([]byte("content"))
+		case requests == 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNotModified)
+		}
+	}))
+	defer server.Close()
+
+	cache := NewResponseCache(0)
+	c, err := New(server.URL, nil, WithResponseCache(cache), WithRetry(2, testBackoff))
+	require.NoError(t, err)
+
+	for range 2 {
+		data, err := c.DownloadFile(context.Background(), "f1")
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(data))
+	}
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, int64(1), cache.Stats().Hits)
+}
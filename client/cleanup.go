@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FileFilter reports whether a file may be deleted by CleanupFiles.
+type FileFilter func(FileInfo) bool
+
+// CleanupOption configures CleanupFiles.
+type CleanupOption func(*cleanupConfig)
+
+type cleanupConfig struct {
+	dryRun bool
+	batch  []BatchOption
+}
+
+// CleanupDryRun makes CleanupFiles report the files it would delete
+// without deleting them.
+func CleanupDryRun() CleanupOption {
+	return func(cfg *cleanupConfig) {
+		cfg.dryRun = true
+	}
+}
+
+// CleanupBatch configures the parallel deletes.
+func CleanupBatch(opts ...BatchOption) CleanupOption {
+	return func(cfg *cleanupConfig) {
+		cfg.batch = append(cfg.batch, opts...)
+	}
+}
+
+// CleanupReport lists the files matched by CleanupFiles.
+type CleanupReport struct {
+	// DryRun reports whether the files were left in place.
+	DryRun bool
+	// Matched are the files past the retention age accepted by the filter.
+	Matched []FileInfo
+	// Deleted are the matched files that were deleted.
+	Deleted []FileInfo
+	// Bytes is the total size of the deleted files, or of the matched
+	// files in a dry run.
+	Bytes int64
+}
+
+// CleanupFiles deletes the files stored on the server that were created
+// more than olderThan ago and are accepted by filter, if not nil. Files
+// without a creation time are kept. All files are listed before any is
+// deleted. If some files were not deleted, the report lists the others
+// and the error is a *MultiError.
+func (c *Client) CleanupFiles(
+	ctx context.Context,
+	olderThan time.Duration,
+	filter FileFilter,
+	opts ...CleanupOption,
+) (*CleanupReport, error) {
+	if olderThan <= 0 {
+		return nil, errors.New("retention age must be positive")
+	}
+	var cfg cleanupConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	files, err := c.ListFiles(ctx, ListFilesOptions{OlderThan: olderThan})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	report := &CleanupReport{DryRun: cfg.dryRun}
+	for _, f := range files {
+		if filter != nil && !filter(f) {
+			continue
+		}
+		report.Matched = append(report.Matched, f)
+	}
+	if cfg.dryRun {
+		for _, f := range report.Matched {
+			report.Bytes += f.Size
+		}
+		return report, nil
+	}
+
+	ids := make([]string, len(report.Matched))
+	for i, f := range report.Matched {
+		ids[i] = f.ID
+	}
+	err = c.DeleteFiles(ctx, ids, cfg.batch...)
+	var multi *MultiError
+	if err != nil && !errors.As(err, &multi) {
+		return report, err
+	}
+	for i, f := range report.Matched {
+		if multi != nil && multi.Items[i].Status != BatchSucceeded {
+			continue
+		}
+		report.Deleted = append(report.Deleted, f)
+		report.Bytes += f.Size
+	}
+	return report, err
+}
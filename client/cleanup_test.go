@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_CleanupFiles(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	recent := time.Now().UTC().Format(time.RFC3339)
+	var mu sync.Mutex
+	var deleted []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"files": [
+			{"id": "a.png", "size": 10, "createdAt": %q},
+			{"id": "b.pdf", "size": 20, "createdAt": %q},
+			{"id": "c.png", "size": 30, "createdAt": %q},
+			{"id": "d.png", "size": 40, "createdAt": %[1]q},
+			{"id": "e.png", "size": 50}
+		]}`, old, old, recent)
+	})
+	mux.HandleFunc("DELETE /api/v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "d.png" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		deleted = append(deleted, r.PathValue("id"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+	pngs := func(f FileInfo) bool { return strings.HasSuffix(f.ID, ".png") }
+
+	report, err := c.CleanupFiles(context.Background(), 24*time.Hour, pngs, CleanupDryRun())
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	require.Len(t, report.Matched, 2)
+	assert.Equal(t, "a.png", report.Matched[0].ID)
+	assert.Equal(t, "d.png", report.Matched[1].ID)
+	assert.Empty(t, report.Deleted)
+	assert.Equal(t, int64(50), report.Bytes)
+	assert.Empty(t, deleted)
+
+	report, err = c.CleanupFiles(context.Background(), 24*time.Hour, pngs)
+	var multi *MultiError
+	require.ErrorAs(t, err, &multi)
+	assert.Equal(t, "d.png", multi.Failed()[0].Key)
+	require.Len(t, report.Deleted, 1)
+	assert.Equal(t, "a.png", report.Deleted[0].ID)
+	assert.Equal(t, int64(10), report.Bytes)
+	assert.Equal(t, []string{"a.png"}, deleted)
+
+	_, err = c.CleanupFiles(context.Background(), 0, nil)
+	assert.Error(t, err)
+}
@@ -1,61 +1,119 @@
 package client
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
 type Client struct {
-	addr   string
-	client *http.Client
-}
+	addr         string
+	client       *http.Client
+	fingerprints *FingerprintRotator
+	userAgents   *UserAgentPool
+	proxies      *ProxyPool
+	sink         ResultSink
+	publisher    ResultPublisher
+	events       EventSink
+	eventSource  string
+
+	statusPolicies map[int]*StatusPolicy
+	errorHook      ErrorHook
+	kms            KMS
+	scrubber       *Scrubber
+	audit          *AuditLog
+	credentials    CredentialProvider
+	policy         *Policy
+	noBufferPool   bool
+	downloadBudget *DownloadBudget
+	paramSchemas   map[string]ParamSchema
+	prefetcher     *prefetcher
+	logger         *slog.Logger
+	recorder       *Recorder
+	pacer          *pacer
+	cache          *ResponseCache
+	retry          *retrier
+
+	// deprecationsLogged holds the method and path of every deprecated
+	// resource already logged.
+	deprecationsLogged *sync.Map
+	versionCheck       *versionCheck
 
-type httpMessage struct {
-	Message string `json:"message"`
+	region          string
+	regionalServers []regionalServer
+
+	// optErr is the first error reported by an option.
+	optErr error
 }
 
-func New(serverAddress string, client *http.Client) (*Client, error) {
+// Option configures a Client.
+type Option func(*Client)
+
+func New(serverAddress string, client *http.Client, opts ...Option) (*Client, error) {
 	if serverAddress == "" {
 		return nil, errors.New("server address is required")
 	}
-	if !strings.HasSuffix(serverAddress, "/") {
-		serverAddress += "/"
-	}
 	if client == nil {
 		client = &http.Client{
 			Timeout: 30 * time.Second,
 		}
 	}
-	return &Client{
-		addr:   serverAddress + "api/v1",
-		client: client,
-	}, nil
+	c := &Client{
+		addr:               apiAddress(serverAddress),
+		client:             client,
+		deprecationsLogged: &sync.Map{},
+		versionCheck:       &versionCheck{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.optErr != nil {
+		return nil, c.optErr
+	}
+	return c, nil
+}
+
+// apiAddress returns the API base URL of a server.
+func apiAddress(serverAddress string) string {
+	if !strings.HasSuffix(serverAddress, "/") {
+		serverAddress += "/"
+	}
+	return serverAddress + "api/v1"
+}
+
+// optionError makes New fail with err, unless an earlier option failed.
+func (c *Client) optionError(err error) {
+	if c.optErr == nil {
+		c.optErr = err
+	}
 }
 
 // Plugins fetches a list of available plugins.
-func (c *Client) Plugins() ([]string, error) {
-	resp, err := c.client.Get(c.addr + "/plugins")
+func (c *Client) Plugins(ctx context.Context) (_ []string, err error) {
+	ctx, op := c.startOperation(ctx, "Plugins")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.addr+"/plugins", nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch plugins: %w", err)
+		return nil, fmt.Errorf("failed to create plugins request: %w", err)
 	}
-	defer resp.Body.Close()
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch plugins: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(
-			"unexpected response status: %s",
-			resp.Status,
-		)
+		return nil, c.newAPIError(resp)
 	}
 
-	var plugins struct {
-		Plugins []string `json:"plugins"`
-	}
+	var plugins PluginsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&plugins); err != nil {
 		return nil, fmt.Errorf("failed to decode plugins: %w", err)
 	}
@@ -64,93 +122,310 @@ func (c *Client) Plugins() ([]string, error) {
 }
 
 // RunPlugin runs a plugin with the given name and parameters.
-// It returns a result of the plugin execution.
-func (c *Client) RunPlugin(pluginName string, params map[string]any) (map[string]any, error) {
-	body, err := json.Marshal(params)
+// It returns a result of the plugin execution. Canceling ctx aborts the
+// run; its deadline bounds the run in addition to the HTTP client timeout.
+func (c *Client) RunPlugin(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	opts ...RunOption,
+) (_ map[string]any, err error) {
+	ctx, op := c.startOperation(ctx, "RunPlugin")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	result, err := c.run(ctx, pluginName, params, opts)
+	if err != nil {
+		return nil, err
+	}
+	return result.Output, nil
+}
+
+// RunPluginResult runs a plugin like RunPlugin and returns its output
+// together with execution metadata.
+func (c *Client) RunPluginResult(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	opts ...RunOption,
+) (_ *RunResult, err error) {
+	ctx, op := c.startOperation(ctx, "RunPluginResult")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	return c.run(ctx, pluginName, params, opts)
+}
+
+func (c *Client) run(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	opts []RunOption,
+) (*RunResult, error) {
+	c.emit(EventJobSubmitted, pluginName, map[string]any{"plugin": pluginName})
+	result, err := c.execute(ctx, pluginName, params, opts)
+	if err != nil {
+		c.emit(EventJobFailed, pluginName, map[string]any{
+			"plugin": pluginName,
+			"error":  err.Error(),
+		})
+		return nil, err
+	}
+	c.emit(EventJobCompleted, pluginName, NewResultEvent(pluginName, result))
+	fileIDs := extractFileIDs(result.Output)
+	for _, id := range fileIDs {
+		c.emit(EventFileCreated, id, map[string]any{
+			"plugin": pluginName,
+			"jobId":  result.JobID,
+			"fileId": id,
+		})
+	}
+	if c.prefetcher != nil {
+		c.prefetch(fileIDs)
+	}
+	return result, nil
+}
+
+func (c *Client) execute(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	opts []RunOption,
+) (*RunResult, error) {
+	start := time.Now()
+	cfg := newRunConfig(params, opts)
+	c.coerceParams(pluginName, cfg)
+	op := operationFrom(ctx)
+	op.Plugin = pluginName
+	op.params = cfg.params
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if err := c.checkPolicy(pluginName, cfg.params); err != nil {
+		return nil, err
+	}
+	if err := c.applyRotation(cfg.params); err != nil {
+		return nil, err
+	}
+	rc, err := c.resident(cfg.residency, cfg.prober)
+	if err != nil {
+		return nil, err
+	}
+	result, err := rc.runPlugin(ctx, pluginName, cfg.params, cfg.output)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.botPolicy != nil {
+		result, err = rc.rerunIfBotDetected(ctx, pluginName, cfg.params, result, cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	result.Duration = time.Since(start)
+	c.scrubResult(result)
+	op.result = result.Output
+	if err := c.deliverResult(ctx, pluginName, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// deliverResult writes the result of a run to the result sink and
+// publishes it, if the client has them.
+func (c *Client) deliverResult(ctx context.Context, pluginName string, result *RunResult) error {
+	if c.sink != nil {
+		if err := c.writeResult(ctx, pluginName, result.Output); err != nil {
+			return fmt.Errorf("failed to write result: %w", err)
+		}
+	}
+	if c.publisher != nil {
+		if err := c.publisher.PublishResult(NewResultEvent(pluginName, result)); err != nil {
+			return fmt.Errorf("failed to publish result: %w", err)
+		}
+	}
+	return nil
+}
+
+// runPlugin sends a run request. The output is decoded by dec, if set,
+// or in full.
+func (c *Client) runPlugin(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	dec *outputDecoder,
+) (*RunResult, error) {
+	body, release, err := c.marshalParams(params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to JSON encode params: %w", err)
 	}
-	resp, err := c.client.Post(
+	defer func() { release() }()
+	body, contentType, err := c.sealBody(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newBodyRequest(
+		ctx,
+		http.MethodPost,
 		c.addr+"/plugins/"+pluginName,
-		"application/json",
-		bytes.NewReader(body),
+		contentType,
+		body,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to run plugin: %w", err)
+		return nil, fmt.Errorf("failed to create run request: %w", err)
 	}
-	defer resp.Body.Close()
+	release = releaseAfterBody(req, release)
+	if c.kms != nil {
+		req.Header.Set("Accept", EnvelopeContentType)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run plugin: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		var msg httpMessage
-		_ = json.NewDecoder(resp.Body).Decode(&msg)
-		return nil, fmt.Errorf(
-			"unexpected response status: %s; message: %s",
-			resp.Status, msg.Message,
-		)
+		return nil, c.newAPIError(resp)
 	}
 
 	var output map[string]any
-	if err := json.NewDecoder(resp.Body).Decode(&output); err != nil {
+	if dec != nil {
+		r, err := c.bodyReader(ctx, resp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode plugin output: %w", err)
+		}
+		if output, err = dec.decode(r, c.scrubber); err != nil {
+			return nil, err
+		}
+	} else if err := c.decodeBody(ctx, resp, &output); err != nil {
 		return nil, fmt.Errorf("failed to decode plugin output: %w", err)
 	}
 
-	return output, nil
+	result := c.newRunResult(pluginName, resp.Header)
+	result.Output = output
+	return result, nil
 }
 
 // DownloadFile downloads a file with the given ID.
-func (c *Client) DownloadFile(fileID string) ([]byte, error) {
-	resp, err := c.client.Get(c.addr + "/files/" + fileID)
+func (c *Client) DownloadFile(ctx context.Context, fileID string) (_ []byte, err error) {
+	ctx, op := c.startOperation(ctx, "DownloadFile")
+	defer func() { c.finishOperation(ctx, op, err) }()
+	op.FileID = fileID
+
+	if c.prefetcher != nil {
+		if data, ok := c.prefetcher.take(ctx, fileID); ok {
+			op.result = data
+			return data, nil
+		}
+	}
+	data, err := c.downloadFile(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	op.result = data
+	return data, nil
+}
+
+// downloadFile downloads a file and writes it to the sink, if any.
+func (c *Client) downloadFile(ctx context.Context, fileID string) ([]byte, error) {
+	data, release, err := c.holdFile(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	release()
+	return data, nil
+}
+
+// holdFile downloads a file like downloadFile, keeping its download
+// budget reservation until release is called.
+func (c *Client) holdFile(ctx context.Context, fileID string) (_ []byte, release func(), err error) {
+	u, err := c.fileURL(fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create download request: %w", err)
+	}
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to download file: %w", err)
+		return nil, nil, fmt.Errorf("failed to download file: %w", transportError(err))
 	}
-	defer resp.Body.Close()
+	defer closeBody(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(
-			"unexpected response status: %s",
-			resp.Status,
-		)
+		return nil, nil, c.newAPIError(resp)
 	}
 
-	return io.ReadAll(resp.Body)
+	release = func() {}
+	if c.downloadBudget != nil {
+		if release, err = c.downloadBudget.reserve(ctx, resp.ContentLength); err != nil {
+			return nil, nil, fmt.Errorf("failed to reserve download budget: %w", err)
+		}
+		defer func() {
+			if err != nil {
+				release()
+			}
+		}()
+	}
+	data, err := readBody(resp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if c.sink != nil {
+		if err := c.writeFile(ctx, fileID, data); err != nil {
+			return nil, nil, fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+	return data, release, nil
 }
 
 // DeleteFile deletes a file with the given ID.
-func (c *Client) DeleteFile(fileID string) error {
-	req, err := http.NewRequest(http.MethodDelete, c.addr+"/files/"+fileID, nil)
+func (c *Client) DeleteFile(ctx context.Context, fileID string) (err error) {
+	ctx, op := c.startOperation(ctx, "DeleteFile")
+	defer func() { c.finishOperation(ctx, op, err) }()
+	op.FileID = fileID
+
+	return c.deleteFile(ctx, fileID)
+}
+
+func (c *Client) deleteFile(ctx context.Context, fileID string) error {
+	u, err := c.fileURL(fileID)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create delete request: %w", err)
 	}
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return fmt.Errorf("failed to delete file: %w", err)
+		return fmt.Errorf("failed to delete file: %w", transportError(err))
 	}
-	defer resp.Body.Close()
+	defer closeBody(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf(
-			"unexpected response status: %s",
-			resp.Status,
-		)
+		return c.newAPIError(resp)
 	}
 
 	return nil
 }
 
 // Healthcheck performs a health check on the server.
-func (c *Client) Healthcheck() error {
-	resp, err := c.client.Get(c.addr + "/health")
+func (c *Client) Healthcheck(ctx context.Context) (err error) {
+	ctx, op := c.startOperation(ctx, "Healthcheck")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.addr+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create health check request: %w", err)
+	}
+	resp, err := c.do(req)
 	if err != nil {
-		return fmt.Errorf("failed to perform health check: %w", err)
+		return fmt.Errorf("failed to perform health check: %w", transportError(err))
 	}
-	defer resp.Body.Close()
+	defer closeBody(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf(
-			"unexpected response status: %s",
-			resp.Status,
-		)
+		return c.newAPIError(resp)
 	}
 
 	return nil
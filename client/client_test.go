@@ -2,10 +2,13 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -45,7 +48,7 @@ func TestClient_Plugins(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		plugins, err := c.Plugins()
+		plugins, err := c.Plugins(context.Background())
 		require.NoError(t, err)
 		assert.Equal(t, []string{"plugin1", "plugin2"}, plugins)
 	})
@@ -58,7 +61,7 @@ func TestClient_Plugins(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		_, err = c.Plugins()
+		_, err = c.Plugins(context.Background())
 		require.ErrorContains(t, err, "failed to fetch plugins:")
 	})
 
@@ -70,7 +73,7 @@ func TestClient_Plugins(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		plugins, err := c.Plugins()
+		plugins, err := c.Plugins(context.Background())
 		require.EqualError(t, err, "unexpected response status: 500 Internal Server Error")
 		require.Nil(t, plugins)
 	})
@@ -83,7 +86,7 @@ func TestClient_Plugins(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		results, err := c.Plugins()
+		results, err := c.Plugins(context.Background())
 		require.ErrorContains(t, err, "failed to decode plugins:")
 		require.Nil(t, results)
 	})
@@ -98,7 +101,7 @@ func TestClient_RunPlugin(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		results, err := c.RunPlugin("plugin1", map[string]any{"my": "params"})
+		results, err := c.RunPlugin(context.Background(), "plugin1", map[string]any{"my": "params"})
 		require.NoError(t, err)
 		assert.Equal(
 			t,
@@ -119,7 +122,7 @@ func TestClient_RunPlugin(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		_, err = c.RunPlugin("plugin1", nil)
+		_, err = c.RunPlugin(context.Background(), "plugin1", nil)
 		require.ErrorContains(t, err, "failed to run plugin:")
 	})
 
@@ -131,7 +134,7 @@ func TestClient_RunPlugin(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		results, err := c.RunPlugin("plugin1", nil)
+		results, err := c.RunPlugin(context.Background(), "plugin1", nil)
 		require.EqualError(
 			t,
 			err,
@@ -148,10 +151,43 @@ func TestClient_RunPlugin(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		results, err := c.RunPlugin("plugin1", nil)
+		results, err := c.RunPlugin(context.Background(), "plugin1", nil)
 		require.ErrorContains(t, err, "failed to decode plugin output:")
 		require.Nil(t, results)
 	})
+
+	t.Run("context deadline", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = c.RunPlugin(ctx, "plugin1", nil)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = c.RunPlugin(ctx, "plugin1", nil)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Zero(t, requests)
+	})
 }
 
 func TestClient_DownloadFile(t *testing.T) {
@@ -163,7 +199,7 @@ func TestClient_DownloadFile(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		content, err := c.DownloadFile("file1")
+		content, err := c.DownloadFile(context.Background(), "file1")
 		require.NoError(t, err)
 		assert.Equal(t, "file content", string(content))
 	})
@@ -176,7 +212,7 @@ func TestClient_DownloadFile(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		_, err = c.DownloadFile("file1")
+		_, err = c.DownloadFile(context.Background(), "file1")
 		require.ErrorContains(t, err, "failed to download file:")
 	})
 
@@ -188,10 +224,25 @@ func TestClient_DownloadFile(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		content, err := c.DownloadFile("file1")
+		content, err := c.DownloadFile(context.Background(), "file1")
 		require.EqualError(t, err, "unexpected response status: 500 Internal Server Error")
 		require.Nil(t, content)
 	})
+
+	t.Run("escaped ID", func(t *testing.T) {
+		var path string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.EscapedPath()
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		_, err = c.DownloadFile(context.Background(), "100%25 done.png")
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/files/100%2525%20done.png", path)
+	})
 }
 
 func TestClient_DeleteFile(t *testing.T) {
@@ -203,7 +254,7 @@ func TestClient_DeleteFile(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		err = c.DeleteFile("file1")
+		err = c.DeleteFile(context.Background(), "file1")
 		require.NoError(t, err)
 	})
 
@@ -215,7 +266,7 @@ func TestClient_DeleteFile(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		err = c.DeleteFile("file1")
+		err = c.DeleteFile(context.Background(), "file1")
 		require.ErrorContains(t, err, "failed to delete file:")
 	})
 
@@ -227,7 +278,7 @@ func TestClient_DeleteFile(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		err = c.DeleteFile("file1")
+		err = c.DeleteFile(context.Background(), "file1")
 		require.EqualError(t, err, "unexpected response status: 500 Internal Server Error")
 	})
 }
@@ -241,7 +292,7 @@ func TestClient_Healthcheck(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		err = c.Healthcheck()
+		err = c.Healthcheck(context.Background())
 		require.NoError(t, err)
 	})
 
@@ -253,7 +304,7 @@ func TestClient_Healthcheck(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		err = c.Healthcheck()
+		err = c.Healthcheck(context.Background())
 		require.ErrorContains(t, err, "failed to perform health check:")
 	})
 
@@ -265,7 +316,7 @@ func TestClient_Healthcheck(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, c)
 
-		err = c.Healthcheck()
+		err = c.Healthcheck(context.Background())
 		require.EqualError(t, err, "unexpected response status: 500 Internal Server Error")
 	})
 }
@@ -278,3 +329,20 @@ func mockServer(t testing.TB, status int, body string) *httptest.Server {
 		_, _ = io.Copy(w, bytes.NewBufferString(body))
 	}))
 }
+
+// captureServer responds like mockServer and stores the decoded JSON body
+// of the last request in params.
+func captureServer(
+	t testing.TB,
+	status int,
+	body string,
+	params *map[string]any,
+) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(params)
+		w.WriteHeader(status)
+		_, _ = io.Copy(w, bytes.NewBufferString(body))
+	}))
+}
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParamType is the expected type of a plugin param.
+type ParamType int
+
+const (
+	ParamString ParamType = iota + 1
+	ParamStrings
+	ParamInt
+	ParamFloat
+	ParamBool
+)
+
+func (t ParamType) String() string {
+	switch t {
+	case ParamString:
+		return "string"
+	case ParamStrings:
+		return "list of strings"
+	case ParamInt:
+		return "integer"
+	case ParamFloat:
+		return "number"
+	case ParamBool:
+		return "boolean"
+	default:
+		return "ParamType(" + strconv.Itoa(int(t)) + ")"
+	}
+}
+
+// ParamSchema maps the top-level params of a plugin to their types.
+// Params not in the schema are sent unchanged.
+type ParamSchema map[string]ParamType
+
+// DefaultParamSchemas are the schemas of the plugins bundled with the server.
+var DefaultParamSchemas = map[string]ParamSchema{
+	"screenshot":   {"urls": ParamStrings},
+	"googlesearch": {"query": ParamString},
+}
+
+// WithParamSchemas coerces the params of every run of the plugins in
+// schemas before submission, e.g. WithParamSchemas(DefaultParamSchemas).
+// See WithCoercion for the coercions applied.
+func WithParamSchemas(schemas map[string]ParamSchema) Option {
+	return func(c *Client) {
+		c.paramSchemas = schemas
+	}
+}
+
+// WithCoercion coerces the params of a run to schema before submission,
+// fixing trivial type mismatches the server would reject:
+//
+//   - a single string becomes a list for ParamStrings;
+//   - numeric strings become numbers for ParamInt and ParamFloat, and
+//     integral numbers become integers for ParamInt;
+//   - "true" and "false" become booleans for ParamBool;
+//   - numbers and booleans become strings for ParamString.
+//
+// Values that cannot be coerced fail the run with a *ValidationError.
+func WithCoercion(schema ParamSchema) RunOption {
+	return func(cfg *runConfig) {
+		schema.coerce(cfg)
+	}
+}
+
+// CoerceParams returns a copy of params coerced to schema, as WithCoercion
+// does, or a *ValidationError.
+func CoerceParams(schema ParamSchema, params map[string]any) (map[string]any, error) {
+	return ApplyRunOptions(params, WithCoercion(schema))
+}
+
+func (s ParamSchema) coerce(cfg *runConfig) {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		typ := s[name]
+		v, ok := cfg.params[name]
+		if !ok || v == nil {
+			continue
+		}
+		coerced, ok := coerceValue(typ, v)
+		if !ok {
+			cfg.invalid(name, CodeInvalid, fmt.Sprintf("cannot coerce %T to %s", v, typ))
+			continue
+		}
+		cfg.params[name] = coerced
+	}
+}
+
+// coerceParams applies the client-wide schema of a plugin, if any.
+func (c *Client) coerceParams(pluginName string, cfg *runConfig) {
+	if s, ok := c.paramSchemas[pluginName]; ok {
+		s.coerce(cfg)
+	}
+}
+
+func coerceValue(typ ParamType, v any) (any, bool) {
+	switch typ {
+	case ParamString:
+		switch t := v.(type) {
+		case string:
+			return t, true
+		case bool:
+			return strconv.FormatBool(t), true
+		default:
+			if f, ok := toFloat(v); ok {
+				return strconv.FormatFloat(f, 'f', -1, 64), true
+			}
+		}
+	case ParamStrings:
+		switch t := v.(type) {
+		case string:
+			return []string{t}, true
+		case []string:
+			return t, true
+		case []any:
+			list := make([]string, len(t))
+			for i, item := range t {
+				s, ok := item.(string)
+				if !ok {
+					return nil, false
+				}
+				list[i] = s
+			}
+			return list, true
+		}
+	case ParamInt:
+		if s, ok := v.(string); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(s))
+			return n, err == nil
+		}
+		if f, ok := toFloat(v); ok && f == math.Trunc(f) && math.Abs(f) <= 1<<53 {
+			return int(f), true
+		}
+	case ParamFloat:
+		if s, ok := v.(string); ok {
+			f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			return f, err == nil
+		}
+		if f, ok := toFloat(v); ok {
+			return f, true
+		}
+	case ParamBool:
+		switch t := v.(type) {
+		case bool:
+			return t, true
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(t))
+			return b, err == nil
+		}
+	}
+	return nil, false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch t := v.(type) {
+	case int:
+		return float64(t), true
+	case int32:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case float32:
+		return float64(t), true
+	case float64:
+		return t, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoerceParams(t *testing.T) {
+	schema := ParamSchema{
+		"urls":    ParamStrings,
+		"query":   ParamString,
+		"limit":   ParamInt,
+		"scale":   ParamFloat,
+		"mobile":  ParamBool,
+		"missing": ParamInt,
+	}
+	params := map[string]any{
+		"urls":   "https://example.com",
+		"query":  42.0,
+		"limit":  " 10 ",
+		"scale":  "1.5",
+		"mobile": "true",
+		"other":  "unchanged",
+	}
+	got, err := CoerceParams(schema, params)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"urls":   []string{"https://example.com"},
+		"query":  "42",
+		"limit":  10,
+		"scale":  1.5,
+		"mobile": true,
+		"other":  "unchanged",
+	}, got)
+	assert.Equal(t, "https://example.com", params["urls"], "params must not be modified")
+
+	got, err = CoerceParams(schema, map[string]any{
+		"urls":  []any{"a", "b"},
+		"limit": 3.0,
+		"scale": 2,
+		"query": true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"urls":  []string{"a", "b"},
+		"limit": 3,
+		"scale": 2.0,
+		"query": "true",
+	}, got)
+
+	_, err = CoerceParams(schema, map[string]any{
+		"urls":   []any{"a", 1.0},
+		"limit":  2.5,
+		"mobile": "maybe",
+	})
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.EqualError(t, err, "invalid params: "+
+		"limit: cannot coerce float64 to integer; "+
+		"mobile: cannot coerce string to boolean; "+
+		"urls: cannot coerce []interface {} to list of strings")
+}
+
+func TestWithParamSchemas(t *testing.T) {
+	var params map[string]any
+	server := captureServer(t, http.StatusOK, `{}`, &params)
+	defer server.Close()
+
+	c, err := New(server.URL, nil, WithParamSchemas(DefaultParamSchemas))
+	require.NoError(t, err)
+
+	_, err = c.RunPlugin(context.Background(), "screenshot", map[string]any{"urls": "https://example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"https://example.com"}, params["urls"])
+
+	_, err = c.RunPlugin(context.Background(), "googlesearch", map[string]any{"query": []any{"go"}})
+	assert.ErrorIs(t, err, ErrInvalidParams)
+
+	_, err = c.RunPlugin(context.Background(), "other", map[string]any{"urls": "https://example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", params["urls"])
+}
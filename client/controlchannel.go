@@ -0,0 +1,231 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrControlChannelClosed is returned for jobs that were in flight or
+// submitted after the control channel was closed.
+var ErrControlChannelClosed = errors.New("control channel closed")
+
+// controlMessage is the frame exchanged over the control channel.
+// Clients send "submit" and "cancel"; the server replies with "result"
+// or "error" carrying the ID of the submitted job.
+type controlMessage struct {
+	ID      string         `json:"id"`
+	Type    string         `json:"type"`
+	Plugin  string         `json:"plugin,omitempty"`
+	Params  map[string]any `json:"params,omitempty"`
+	Output  map[string]any `json:"output,omitempty"`
+	Message string         `json:"message,omitempty"`
+}
+
+// ControlChannel multiplexes plugin job submissions, cancellations and
+// results over a single WebSocket connection. It is safe for concurrent use.
+type ControlChannel struct {
+	client *Client
+	conn   *websocket.Conn
+	nextID atomic.Uint64
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string]chan controlMessage
+	err     error
+	done    chan struct{}
+}
+
+// DialControlChannel opens a control channel to the server.
+func (c *Client) DialControlChannel(ctx context.Context) (_ *ControlChannel, err error) {
+	ctx, op := c.startOperation(ctx, "DialControlChannel")
+	defer func() { c.finishOperation(ctx, op, err) }()
+	if c.kms != nil {
+		return nil, fmt.Errorf("failed to dial control channel: %w", ErrEncryptionUnsupported)
+	}
+
+	wsURL := c.addr + "/ws"
+	switch {
+	case strings.HasPrefix(wsURL, "https://"):
+		wsURL = "wss://" + strings.TrimPrefix(wsURL, "https://")
+	case strings.HasPrefix(wsURL, "http://"):
+		wsURL = "ws://" + strings.TrimPrefix(wsURL, "http://")
+	}
+
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: c.client.Timeout,
+		Jar:              c.client.Jar,
+	}
+	if t, ok := c.client.Transport.(*http.Transport); ok {
+		dialer.TLSClientConfig = t.TLSClientConfig
+		dialer.Proxy = t.Proxy
+	}
+	op.Attempts++
+	header := http.Header{RequestIDHeader: {op.RequestID}}
+	if err := c.authorize(ctx, header); err != nil {
+		return nil, err
+	}
+	conn, resp, err := dialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		if resp != nil {
+			defer closeBody(resp.Body)
+			return nil, c.newAPIError(resp)
+		}
+		return nil, fmt.Errorf("failed to open control channel: %w", transportError(err))
+	}
+
+	ch := &ControlChannel{
+		client:  c,
+		conn:    conn,
+		pending: make(map[string]chan controlMessage),
+		done:    make(chan struct{}),
+	}
+	go ch.readLoop()
+	return ch, nil
+}
+
+// Run submits a plugin job and waits for its result. If ctx is done
+// before the result arrives, the job is canceled on the server.
+// Params are validated, checked against the plugin policy and rotated
+// as by RunPlugin; options that don't apply to jobs are rejected.
+func (ch *ControlChannel) Run(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	opts ...RunOption,
+) (_ map[string]any, err error) {
+	c := ch.client
+	ctx, op := c.startOperation(ctx, "ControlChannelRun")
+	defer func() { c.finishOperation(ctx, op, err) }()
+	op.Plugin = pluginName
+
+	cfg := newRunConfig(params, opts)
+	c.coerceParams(pluginName, cfg)
+	op.params = cfg.params
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.checkJob(); err != nil {
+		return nil, err
+	}
+	if err := c.checkPolicy(pluginName, cfg.params); err != nil {
+		return nil, err
+	}
+	if err := c.applyRotation(cfg.params); err != nil {
+		return nil, err
+	}
+
+	id := strconv.FormatUint(ch.nextID.Add(1), 10)
+	replies := make(chan controlMessage, 1)
+
+	ch.mu.Lock()
+	if ch.err != nil {
+		ch.mu.Unlock()
+		return nil, ch.err
+	}
+	ch.pending[id] = replies
+	ch.mu.Unlock()
+	defer func() {
+		ch.mu.Lock()
+		delete(ch.pending, id)
+		ch.mu.Unlock()
+	}()
+
+	err = ch.write(controlMessage{ID: id, Type: "submit", Plugin: pluginName, Params: cfg.params})
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit job: %w", err)
+	}
+
+	select {
+	case msg, ok := <-replies:
+		if !ok {
+			return nil, ch.closeErr()
+		}
+		if msg.Type == "error" {
+			return nil, fmt.Errorf("job %s failed: %s", id, msg.Message)
+		}
+		result := c.newRunResult(pluginName, nil)
+		result.Output = msg.Output
+		c.scrubResult(result)
+		op.result = result.Output
+		if err := c.deliverResult(ctx, pluginName, result); err != nil {
+			return nil, err
+		}
+		return result.Output, nil
+	case <-ctx.Done():
+		_ = ch.write(controlMessage{ID: id, Type: "cancel"})
+		return nil, ctx.Err()
+	}
+}
+
+// Close closes the connection. Jobs still waiting for results fail
+// with ErrControlChannelClosed.
+func (ch *ControlChannel) Close() error {
+	ch.writeMu.Lock()
+	_ = ch.conn.WriteMessage(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+	)
+	ch.writeMu.Unlock()
+	err := ch.conn.Close()
+	<-ch.done
+	return err
+}
+
+func (ch *ControlChannel) write(msg controlMessage) error {
+	ch.writeMu.Lock()
+	defer ch.writeMu.Unlock()
+	return ch.conn.WriteJSON(msg)
+}
+
+func (ch *ControlChannel) readLoop() {
+	defer close(ch.done)
+	err := safeCall(ch.read)
+	if err == nil {
+		err = ErrControlChannelClosed
+	} else {
+		_ = ch.conn.Close()
+		err = fmt.Errorf("%w: %w", ErrControlChannelClosed, err)
+	}
+	ch.mu.Lock()
+	ch.err = err
+	for id, replies := range ch.pending {
+		close(replies)
+		delete(ch.pending, id)
+	}
+	ch.mu.Unlock()
+}
+
+// read dispatches replies to pending jobs until the connection fails.
+func (ch *ControlChannel) read() error {
+	for {
+		var msg controlMessage
+		if err := ch.conn.ReadJSON(&msg); err != nil {
+			return nil
+		}
+		ch.mu.Lock()
+		replies, ok := ch.pending[msg.ID]
+		ch.mu.Unlock()
+		if ok {
+			select {
+			case replies <- msg:
+			default:
+			}
+		}
+	}
+}
+
+func (ch *ControlChannel) closeErr() error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.err
+}
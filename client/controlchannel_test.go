@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func controlServer(t testing.TB, canceled chan<- string) *httptest.Server {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/ws" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var writeMu sync.Mutex
+		for {
+			var msg controlMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			switch {
+			case msg.Type == "cancel":
+				canceled <- msg.ID
+			case msg.Plugin == "slow":
+				// never replies
+			case msg.Plugin == "drop":
+				return
+			case msg.Plugin == "broken":
+				writeMu.Lock()
+				_ = conn.WriteJSON(controlMessage{ID: msg.ID, Type: "error", Message: "plugin crashed"})
+				writeMu.Unlock()
+			default:
+				go func(msg controlMessage) {
+					writeMu.Lock()
+					defer writeMu.Unlock()
+					_ = conn.WriteJSON(controlMessage{
+						ID:     msg.ID,
+						Type:   "result",
+						Output: map[string]any{msg.Plugin: msg.Params["query"]},
+					})
+				}(msg)
+			}
+		}
+	}))
+}
+
+func TestControlChannel(t *testing.T) {
+	canceled := make(chan string, 1)
+	server := controlServer(t, canceled)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	ch, err := c.DialControlChannel(context.Background())
+	require.NoError(t, err)
+
+	t.Run("concurrent jobs", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for _, query := range []string{"golang", "rust", "python"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				output, err := ch.Run(context.Background(), "googlesearch", map[string]any{"query": query})
+				assert.NoError(t, err)
+				assert.Equal(t, map[string]any{"googlesearch": query}, output)
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("job error", func(t *testing.T) {
+		_, err := ch.Run(context.Background(), "broken", nil)
+		require.ErrorContains(t, err, "plugin crashed")
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := ch.Run(ctx, "slow", nil)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Fatal("cancel was not sent")
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		require.NoError(t, ch.Close())
+		_, err := ch.Run(context.Background(), "googlesearch", nil)
+		require.ErrorIs(t, err, ErrControlChannelClosed)
+	})
+}
+
+func TestControlChannel_Preflight(t *testing.T) {
+	server := controlServer(t, make(chan string, 1))
+	defer server.Close()
+
+	c, err := New(server.URL, nil, WithPolicy(Policy{Plugins: []string{"googlesearch"}}))
+	require.NoError(t, err)
+	ch, err := c.DialControlChannel(context.Background())
+	require.NoError(t, err)
+	defer ch.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err = ch.Run(ctx, "slow", nil)
+	require.ErrorIs(t, err, ErrPolicyDenied)
+
+	_, err = ch.Run(ctx, "googlesearch", nil, WithBotDetectionRetry(BotDetectionPolicy{
+		Detect: func(map[string]any) bool { return false },
+	}))
+	require.EqualError(t, err, "bot detection policies are not supported for jobs")
+
+	output, err := ch.Run(ctx, "googlesearch", map[string]any{"query": "golang"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"googlesearch": "golang"}, output)
+}
+
+func TestClient_DialControlChannel(t *testing.T) {
+	t.Run("server error", func(t *testing.T) {
+		server := mockServer(t, http.StatusNotFound, "")
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		_, err = c.DialControlChannel(context.Background())
+		require.EqualError(t, err, "unexpected response status: 404 Not Found")
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, "")
+		server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		_, err = c.DialControlChannel(context.Background())
+		require.ErrorContains(t, err, "failed to open control channel:")
+	})
+
+	t.Run("in-flight jobs fail when the connection drops", func(t *testing.T) {
+		server := controlServer(t, make(chan string, 1))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		ch, err := c.DialControlChannel(context.Background())
+		require.NoError(t, err)
+
+		errs := make(chan error, 1)
+		go func() {
+			_, err := ch.Run(context.Background(), "drop", nil)
+			errs <- err
+		}()
+
+		select {
+		case err := <-errs:
+			require.ErrorIs(t, err, ErrControlChannelClosed)
+		case <-time.After(time.Second):
+			t.Fatal("in-flight job did not fail")
+		}
+		_ = ch.Close()
+	})
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// SessionCookieJar is an http.CookieJar that mirrors cookies between
+// a local jar and a BrowserBro browser session. Cookies received by
+// Go HTTP clients using the jar are pushed to the session, and Pull
+// brings cookies set inside the browser back into the local jar.
+type SessionCookieJar struct {
+	client    *Client
+	sessionID string
+	jar       http.CookieJar
+
+	mu  sync.Mutex
+	err error
+}
+
+var _ http.CookieJar = (*SessionCookieJar)(nil)
+
+// NewSessionCookieJar creates a jar synchronized with the given session.
+// If jar is nil, an empty in-memory jar is used for local storage.
+func NewSessionCookieJar(
+	client *Client,
+	sessionID string,
+	jar http.CookieJar,
+) (*SessionCookieJar, error) {
+	if sessionID == "" {
+		return nil, errors.New("session ID is required")
+	}
+	if jar == nil {
+		var err error
+		jar, err = cookiejar.New(nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &SessionCookieJar{
+		client:    client,
+		sessionID: sessionID,
+		jar:       jar,
+	}, nil
+}
+
+// SetCookies stores cookies locally and pushes them to the browser session.
+// Push failures are recorded and reported by Err. As http.CookieJar has
+// no context, the push is bounded only by the HTTP client timeout.
+func (j *SessionCookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	scoped := make([]*http.Cookie, 0, len(cookies))
+	for _, cookie := range cookies {
+		cp := *cookie
+		if cp.Domain == "" {
+			cp.Domain = u.Hostname()
+		}
+		if cp.Path == "" {
+			cp.Path = "/"
+		}
+		scoped = append(scoped, &cp)
+	}
+	if err := j.client.SetSessionCookies(context.Background(), j.sessionID, scoped); err != nil {
+		j.mu.Lock()
+		j.err = err
+		j.mu.Unlock()
+	}
+}
+
+// Cookies returns the locally stored cookies to send to u.
+func (j *SessionCookieJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// Pull fetches the browser session's cookies into the local jar.
+func (j *SessionCookieJar) Pull(ctx context.Context) error {
+	cookies, err := j.client.SessionCookies(ctx, j.sessionID)
+	if err != nil {
+		return err
+	}
+	for _, cookie := range cookies {
+		if cookie.Domain == "" {
+			continue
+		}
+		scheme := "http"
+		if cookie.Secure {
+			scheme = "https"
+		}
+		path := cookie.Path
+		if path == "" {
+			path = "/"
+		}
+		host := strings.TrimPrefix(cookie.Domain, ".")
+		u := &url.URL{Scheme: scheme, Host: host, Path: path}
+		j.jar.SetCookies(u, []*http.Cookie{cookie})
+	}
+	return nil
+}
+
+// Err returns the last error encountered while pushing cookies to
+// the browser session and clears it.
+func (j *SessionCookieJar) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	err := j.err
+	j.err = nil
+	return err
+}
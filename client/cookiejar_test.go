@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSessionCookieJar(t *testing.T) {
+	c, err := New("http://localhost:10001", nil)
+	require.NoError(t, err)
+
+	jar, err := NewSessionCookieJar(c, "", nil)
+	require.Error(t, err)
+	require.Nil(t, jar)
+}
+
+func TestSessionCookieJar(t *testing.T) {
+	session := &sessionServer{}
+	server := session.start(t)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	jar, err := NewSessionCookieJar(c, "s1", nil)
+	require.NoError(t, err)
+
+	site, err := url.Parse("https://example.com/login")
+	require.NoError(t, err)
+
+	t.Run("set cookies pushes to session", func(t *testing.T) {
+		jar.SetCookies(site, []*http.Cookie{{Name: "sid", Value: "from-go"}})
+		require.NoError(t, jar.Err())
+
+		assert.Equal(t, "from-go", jar.Cookies(site)[0].Value)
+		require.Len(t, session.cookies, 1)
+		assert.Equal(t, "example.com", session.cookies[0].Domain)
+		assert.Equal(t, "/", session.cookies[0].Path)
+	})
+
+	t.Run("pull brings browser cookies back", func(t *testing.T) {
+		session.mu.Lock()
+		session.cookies = append(session.cookies, SessionCookie{
+			Name:   "pref",
+			Value:  "from-browser",
+			Domain: ".example.com",
+			Path:   "/",
+		})
+		session.mu.Unlock()
+
+		require.NoError(t, jar.Pull(context.Background()))
+		names := map[string]string{}
+		for _, cookie := range jar.Cookies(site) {
+			names[cookie.Name] = cookie.Value
+		}
+		assert.Equal(t, map[string]string{"sid": "from-go", "pref": "from-browser"}, names)
+	})
+
+	t.Run("push error is reported", func(t *testing.T) {
+		other, err := NewSessionCookieJar(c, "unknown", nil)
+		require.NoError(t, err)
+
+		other.SetCookies(site, []*http.Cookie{{Name: "sid", Value: "x"}})
+		require.EqualError(t, other.Err(), "unexpected response status: 404 Not Found")
+		require.NoError(t, other.Err())
+		assert.Len(t, other.Cookies(site), 1, "cookies are still stored locally")
+	})
+
+	t.Run("pull error", func(t *testing.T) {
+		other, err := NewSessionCookieJar(c, "unknown", nil)
+		require.NoError(t, err)
+		require.Error(t, other.Pull(context.Background()))
+	})
+}
@@ -0,0 +1,214 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIKeyHeader carries the API key of a request.
+const APIKeyHeader = "X-API-Key"
+
+// Credentials authenticate the client to the server.
+type Credentials struct {
+	// APIKey is sent in APIKeyHeader.
+	APIKey string
+	// BearerToken is sent in the Authorization header, with TokenType
+	// as its scheme, "Bearer" if empty.
+	BearerToken string
+	TokenType   string
+	// Username and Password are sent in the Authorization header with
+	// basic authentication, unless BearerToken is set.
+	Username string
+	Password string
+	// Certificate is presented for mutual TLS.
+	Certificate *tls.Certificate
+	// Expiry is when the credentials must be fetched again.
+	// Zero means they do not expire.
+	Expiry time.Time
+}
+
+// CredentialProvider supplies credentials, e.g. from a secret store,
+// so they never live in environment variables or config files.
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// WithCredentialProvider authenticates every request with credentials
+// from p. Wrap p with CachedCredentials unless it caches by itself.
+// Client certificates are presented only when the HTTP client uses an
+// *http.Transport. The HTTP client passed to New is not modified.
+func WithCredentialProvider(p CredentialProvider) Option {
+	return func(c *Client) {
+		c.credentials = p
+		rt := c.client.Transport
+		if rt == nil {
+			rt = http.DefaultTransport
+		}
+		base, ok := rt.(*http.Transport)
+		if !ok {
+			return
+		}
+		t := base.Clone()
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.GetClientCertificate = func(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			creds, err := p.Credentials(info.Context())
+			if err != nil {
+				return nil, fmt.Errorf("failed to get credentials: %w", err)
+			}
+			if creds.Certificate == nil {
+				return &tls.Certificate{}, nil
+			}
+			return creds.Certificate, nil
+		}
+		client := *c.client
+		client.Transport = t
+		c.client = &client
+	}
+}
+
+// authorize adds the credentials of the client to header.
+func (c *Client) authorize(ctx context.Context, header http.Header) error {
+	if c.credentials == nil {
+		return nil
+	}
+	creds, err := c.credentials.Credentials(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get credentials: %w", err)
+	}
+	if creds.APIKey != "" {
+		header.Set(APIKeyHeader, creds.APIKey)
+	}
+	switch {
+	case creds.BearerToken != "":
+		scheme := creds.TokenType
+		if scheme == "" || strings.EqualFold(scheme, "bearer") {
+			scheme = "Bearer"
+		}
+		header.Set("Authorization", scheme+" "+creds.BearerToken)
+	case creds.Username != "" || creds.Password != "":
+		auth := base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password))
+		header.Set("Authorization", "Basic "+auth)
+	}
+	return nil
+}
+
+// rejected is called with every 401 response so rotated credentials
+// are fetched again by the next request.
+func (c *Client) rejected() {
+	if inv, ok := c.credentials.(interface{ Invalidate() }); ok {
+		inv.Invalidate()
+	}
+}
+
+// CachedCredentials caches the credentials of a provider until
+// refreshBefore ahead of their expiry, or until invalidated.
+// The client invalidates the cache when the server answers 401, so
+// credentials rotated in the secret store are picked up.
+// It is safe for concurrent use.
+type CachedCredentials struct {
+	provider      CredentialProvider
+	refreshBefore time.Duration
+
+	mu    sync.Mutex
+	creds *Credentials
+}
+
+// NewCachedCredentials creates a CachedCredentials.
+func NewCachedCredentials(p CredentialProvider, refreshBefore time.Duration) *CachedCredentials {
+	return &CachedCredentials{provider: p, refreshBefore: refreshBefore}
+}
+
+// Credentials returns the cached credentials, fetching them if
+// needed.
+func (c *CachedCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.creds != nil &&
+		(c.creds.Expiry.IsZero() || time.Now().Add(c.refreshBefore).Before(c.creds.Expiry)) {
+		return *c.creds, nil
+	}
+	creds, err := c.provider.Credentials(ctx)
+	if err != nil {
+		return Credentials{}, err
+	}
+	c.creds = &creds
+	return creds, nil
+}
+
+// Invalidate drops the cached credentials.
+func (c *CachedCredentials) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.creds = nil
+}
+
+// SecretFetcher reads a secret from a secret manager. AWS Secrets
+// Manager, Google Secret Manager and Azure Key Vault clients are
+// adapted by implementing it around their get-secret-value calls.
+type SecretFetcher interface {
+	GetSecret(ctx context.Context, name string) ([]byte, error)
+}
+
+// SecretCredentials are parsed from a secret holding either a bare API
+// key or a JSON object with "apiKey", "certificate" and "privateKey"
+// (PEM) fields.
+type SecretCredentials struct {
+	Fetcher SecretFetcher
+	// Name identifies the secret.
+	Name string
+	// TTL is how long the fetched credentials are valid.
+	// Zero means they do not expire.
+	TTL time.Duration
+}
+
+type secretPayload struct {
+	APIKey      string `json:"apiKey"`
+	Certificate string `json:"certificate"`
+	PrivateKey  string `json:"privateKey"`
+}
+
+// Credentials fetches and parses the secret.
+func (s SecretCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	data, err := s.Fetcher.GetSecret(ctx, s.Name)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to fetch secret %s: %w", s.Name, err)
+	}
+	var payload secretPayload
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") {
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return Credentials{}, fmt.Errorf("failed to decode secret %s: %w", s.Name, err)
+		}
+	} else {
+		payload.APIKey = trimmed
+	}
+	return newCredentials(payload, s.TTL)
+}
+
+func newCredentials(p secretPayload, ttl time.Duration) (Credentials, error) {
+	creds := Credentials{APIKey: p.APIKey}
+	if p.Certificate != "" || p.PrivateKey != "" {
+		cert, err := tls.X509KeyPair([]byte(p.Certificate), []byte(p.PrivateKey))
+		if err != nil {
+			return Credentials{}, fmt.Errorf("failed to parse client certificate: %w", err)
+		}
+		creds.Certificate = &cert
+	}
+	if creds.APIKey == "" && creds.Certificate == nil {
+		return Credentials{}, errors.New("secret holds no credentials")
+	}
+	if ttl > 0 {
+		creds.Expiry = time.Now().Add(ttl)
+	}
+	return creds, nil
+}
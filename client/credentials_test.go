@@ -0,0 +1,178 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type credentialsFunc func(ctx context.Context) (Credentials, error)
+
+func (f credentialsFunc) Credentials(ctx context.Context) (Credentials, error) { return f(ctx) }
+
+type secretFetcherFunc func(ctx context.Context, name string) ([]byte, error)
+
+func (f secretFetcherFunc) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	return f(ctx, name)
+}
+
+// testCertificatePEM returns a self-signed client certificate and key.
+func testCertificatePEM(t *testing.T) (certPEM, keyPEM string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "browserbro-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestWithCredentialProvider(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(APIKeyHeader)
+		keys = append(keys, key)
+		if key != "new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"plugins": ["screenshot"]}`))
+	}))
+	defer server.Close()
+
+	current := "old"
+	var fetches atomic.Int32
+	provider := NewCachedCredentials(credentialsFunc(func(ctx context.Context) (Credentials, error) {
+		fetches.Add(1)
+		return Credentials{APIKey: current}, nil
+	}), time.Minute)
+
+	c, err := New(server.URL, nil, WithCredentialProvider(provider))
+	require.NoError(t, err)
+
+	_, err = c.Plugins(context.Background())
+	require.Error(t, err)
+
+	// The key is rotated in the store; the 401 dropped the cached one.
+	current = "new"
+	_, err = c.Plugins(context.Background())
+	require.NoError(t, err)
+	_, err = c.Plugins(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"old", "new", "new"}, keys)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestWithCredentialProvider_Error(t *testing.T) {
+	server := mockServer(t, http.StatusOK, `{}`)
+	defer server.Close()
+
+	c, err := New(server.URL, nil, WithCredentialProvider(credentialsFunc(func(ctx context.Context) (Credentials, error) {
+		return Credentials{}, errors.New("vault sealed")
+	})))
+	require.NoError(t, err)
+
+	err = c.Healthcheck(context.Background())
+	assert.EqualError(t, err, "failed to perform health check: failed to get credentials: vault sealed")
+}
+
+func TestWithCredentialProvider_ClientCertificate(t *testing.T) {
+	certPEM, keyPEM := testCertificatePEM(t)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"plugins": ["` + r.TLS.PeerCertificates[0].Subject.CommonName + `"]}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	provider := SecretCredentials{
+		Name: "browserbro",
+		Fetcher: secretFetcherFunc(func(ctx context.Context, name string) ([]byte, error) {
+			return json.Marshal(map[string]string{"certificate": certPEM, "privateKey": keyPEM})
+		}),
+	}
+	c, err := New(server.URL, server.Client(), WithCredentialProvider(provider))
+	require.NoError(t, err)
+
+	plugins, err := c.Plugins(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"browserbro-client"}, plugins)
+}
+
+func TestSecretCredentials(t *testing.T) {
+	fetcher := func(secret string) SecretFetcher {
+		return secretFetcherFunc(func(ctx context.Context, name string) ([]byte, error) {
+			assert.Equal(t, "prod/browserbro", name)
+			return []byte(secret), nil
+		})
+	}
+
+	creds, err := SecretCredentials{Fetcher: fetcher("  key-123\n"), Name: "prod/browserbro"}.Credentials(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{APIKey: "key-123"}, creds)
+
+	creds, err = SecretCredentials{
+		Fetcher: fetcher(`{"apiKey": "key-456"}`),
+		Name:    "prod/browserbro",
+		TTL:     time.Hour,
+	}.Credentials(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "key-456", creds.APIKey)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), creds.Expiry, time.Minute)
+
+	_, err = SecretCredentials{Fetcher: fetcher(`{}`), Name: "prod/browserbro"}.Credentials(context.Background())
+	assert.EqualError(t, err, "secret holds no credentials")
+}
+
+func TestCachedCredentials(t *testing.T) {
+	var fetches atomic.Int32
+	expiry := time.Now().Add(time.Hour)
+	cached := NewCachedCredentials(credentialsFunc(func(ctx context.Context) (Credentials, error) {
+		fetches.Add(1)
+		return Credentials{APIKey: "key", Expiry: expiry}, nil
+	}), time.Minute)
+
+	for i := 0; i < 3; i++ {
+		_, err := cached.Credentials(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// Credentials about to expire are refreshed.
+	expiry = time.Now().Add(30 * time.Second)
+	cached.Invalidate()
+	_, _ = cached.Credentials(context.Background())
+	_, _ = cached.Credentials(context.Background())
+	assert.Equal(t, int32(3), fetches.Load())
+}
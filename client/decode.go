@@ -0,0 +1,153 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// WithOutputFields decodes only the given top-level fields of the plugin
+// output. Other fields are skipped while reading the response, so large
+// sections the caller does not need are never held in memory.
+func WithOutputFields(fields ...string) RunOption {
+	return func(cfg *runConfig) {
+		dec := cfg.outputDecoder()
+		if dec.fields == nil {
+			dec.fields = make(map[string]bool, len(fields))
+		}
+		for _, f := range fields {
+			dec.fields[f] = true
+		}
+	}
+}
+
+// WithOutputItems decodes the array in the top-level output field one
+// element at a time and passes each element to fn as it is read, instead
+// of collecting the array in the result output. It keeps peak memory to
+// a single item for crawler results with thousands of pages. An error
+// returned by fn stops the run. If a bot-detection retry happens, fn also
+// receives the items of the retried run.
+func WithOutputItems(field string, fn func(item map[string]any) error) RunOption {
+	return func(cfg *runConfig) {
+		if field == "" || fn == nil {
+			return
+		}
+		dec := cfg.outputDecoder()
+		dec.itemField = field
+		dec.itemFn = fn
+	}
+}
+
+// outputDecoder decodes plugin output incrementally, token by token.
+type outputDecoder struct {
+	// fields are the top-level fields to decode; nil means all.
+	fields    map[string]bool
+	itemField string
+	itemFn    func(map[string]any) error
+}
+
+func (cfg *runConfig) outputDecoder() *outputDecoder {
+	if cfg.output == nil {
+		cfg.output = &outputDecoder{}
+	}
+	return cfg.output
+}
+
+// decode reads a JSON object from r. Items of the item field are
+// scrubbed by s, if set, before they are passed to the item function.
+func (d *outputDecoder) decode(r io.Reader, s *Scrubber) (map[string]any, error) {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode plugin output: %w", err)
+	}
+	if tok == nil {
+		return nil, nil
+	}
+	if tok != json.Delim('{') {
+		return nil, fmt.Errorf("failed to decode plugin output: unexpected %v", tok)
+	}
+
+	output := make(map[string]any)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode plugin output: %w", err)
+		}
+		key := tok.(string)
+		switch {
+		case d.itemFn != nil && key == d.itemField:
+			if err := d.decodeItems(dec, s); err != nil {
+				return nil, err
+			}
+		case d.fields == nil || d.fields[key]:
+			var v any
+			if err := dec.Decode(&v); err != nil {
+				return nil, fmt.Errorf("failed to decode plugin output: %w", err)
+			}
+			output[key] = v
+		default:
+			if err := skipValue(dec); err != nil {
+				return nil, fmt.Errorf("failed to decode plugin output: %w", err)
+			}
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("failed to decode plugin output: %w", err)
+	}
+	return output, nil
+}
+
+func (d *outputDecoder) decodeItems(dec *json.Decoder, s *Scrubber) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("failed to decode plugin output: %w", err)
+	}
+	if tok == nil {
+		return nil
+	}
+	if tok != json.Delim('[') {
+		return fmt.Errorf("failed to decode plugin output: field %q is not an array", d.itemField)
+	}
+	for i := 0; dec.More(); i++ {
+		var item map[string]any
+		if err := dec.Decode(&item); err != nil {
+			return fmt.Errorf("failed to decode output item %d: %w", i, err)
+		}
+		if s != nil {
+			item = s.Scrub(item)
+		}
+		if err := safeCall(func() error { return d.itemFn(item) }); err != nil {
+			return fmt.Errorf("failed to handle output item %d: %w", i, err)
+		}
+	}
+	_, err = dec.Token()
+	if err != nil {
+		return fmt.Errorf("failed to decode plugin output: %w", err)
+	}
+	return nil
+}
+
+// skipValue reads the next value from dec without keeping it.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
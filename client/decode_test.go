@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const crawlOutput = `{
+	"site": "example.com",
+	"html": {"raw": "<html>...</html>", "nested": [1, [2, {"a": 3}]]},
+	"pages": [
+		{"url": "https://example.com/1", "email": "a@example.com"},
+		{"url": "https://example.com/2"}
+	],
+	"count": 2
+}`
+
+func outputServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestWithOutputFields(t *testing.T) {
+	server := outputServer(t, crawlOutput)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	output, err := c.RunPlugin(context.Background(), "crawler", nil, WithOutputFields("site", "count"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"site": "example.com", "count": 2.0}, output)
+
+	output, err = c.RunPlugin(context.Background(), "crawler", nil, WithOutputFields("missing"))
+	require.NoError(t, err)
+	assert.Empty(t, output)
+}
+
+func TestWithOutputItems(t *testing.T) {
+	server := outputServer(t, crawlOutput)
+	defer server.Close()
+
+	scrubber, err := NewScrubber(EmailRule())
+	require.NoError(t, err)
+	c, err := New(server.URL, nil, WithScrubber(scrubber))
+	require.NoError(t, err)
+
+	var items []map[string]any
+	output, err := c.RunPlugin(context.Background(), "crawler", nil,
+		WithOutputItems("pages", func(item map[string]any) error {
+			items = append(items, item)
+			return nil
+		}),
+		WithOutputFields("count"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"count": 2.0}, output)
+	assert.Equal(t, []map[string]any{
+		{"url": "https://example.com/1", "email": "[REDACTED:email]"},
+		{"url": "https://example.com/2"},
+	}, items)
+
+	t.Run("all other fields", func(t *testing.T) {
+		output, err := c.RunPlugin(context.Background(), "crawler", nil,
+			WithOutputItems("pages", func(map[string]any) error { return nil }))
+		require.NoError(t, err)
+		assert.NotContains(t, output, "pages")
+		assert.Contains(t, output, "html")
+	})
+
+	t.Run("item error", func(t *testing.T) {
+		errStop := errors.New("stop")
+		calls := 0
+		_, err := c.RunPlugin(context.Background(), "crawler", nil,
+			WithOutputItems("pages", func(map[string]any) error {
+				calls++
+				return errStop
+			}))
+		assert.ErrorIs(t, err, errStop)
+		assert.EqualError(t, err, "failed to handle output item 0: stop")
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestOutputDecoder(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name: "null",
+			body: `null`,
+		},
+		{
+			name: "null items",
+			body: `{"pages": null, "a": 1}`,
+			want: map[string]any{"a": 1.0},
+		},
+		{
+			name:    "not an object",
+			body:    `[1]`,
+			wantErr: "failed to decode plugin output: unexpected [",
+		},
+		{
+			name:    "items not an array",
+			body:    `{"pages": {}}`,
+			wantErr: `failed to decode plugin output: field "pages" is not an array`,
+		},
+		{
+			name:    "truncated skipped field",
+			body:    `{"skip": {"a": [1, 2`,
+			wantErr: "failed to decode plugin output: unexpected EOF",
+		},
+		{
+			name:    "invalid item",
+			body:    `{"pages": [1]}`,
+			wantErr: "failed to decode output item 0: json: cannot unmarshal number into Go value of type map[string]interface {}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := &outputDecoder{
+				fields:    map[string]bool{"a": true},
+				itemField: "pages",
+				itemFn:    func(map[string]any) error { return nil },
+			}
+			output, err := dec.decode(strings.NewReader(tt.body), nil)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, output)
+		})
+	}
+}
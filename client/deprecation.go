@@ -0,0 +1,97 @@
+package client
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Deprecation describes a deprecated endpoint or plugin, as announced by
+// the server with the Deprecation (RFC 9745) and Sunset (RFC 8594)
+// response headers.
+type Deprecation struct {
+	// Date is when the resource was or will be deprecated. It is zero
+	// when the server did not say.
+	Date time.Time
+	// Sunset is when the resource will stop responding, if announced.
+	Sunset time.Time
+	// Link points to documentation about the deprecation or sunset.
+	Link string
+	// Warnings are the Warning header texts sent with the announcement,
+	// which usually name the replacement.
+	Warnings []string
+}
+
+// WithLogger makes the client log noteworthy events that do not fail a
+// call, such as deprecation announcements, to logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// parseDeprecation returns the deprecation announced by h, or nil.
+func parseDeprecation(h http.Header) *Deprecation {
+	deprecation, sunset := h.Get("Deprecation"), h.Get("Sunset")
+	if deprecation == "" && sunset == "" {
+		return nil
+	}
+	d := &Deprecation{}
+	if ts, ok := strings.CutPrefix(deprecation, "@"); ok {
+		if sec, err := strconv.ParseInt(ts, 10, 64); err == nil {
+			d.Date = time.Unix(sec, 0).UTC()
+		}
+	} else if t, err := http.ParseTime(deprecation); err == nil {
+		// Drafts of RFC 9745 used an HTTP date.
+		d.Date = t
+	}
+	if t, err := http.ParseTime(sunset); err == nil {
+		d.Sunset = t
+	}
+	for _, v := range h.Values("Link") {
+		for _, link := range strings.Split(v, ",") {
+			target, params, ok := strings.Cut(link, ";")
+			if !ok {
+				continue
+			}
+			rel := strings.ToLower(params)
+			if strings.Contains(rel, `rel="deprecation"`) || strings.Contains(rel, `rel="sunset"`) ||
+				strings.Contains(rel, "rel=deprecation") || strings.Contains(rel, "rel=sunset") {
+				d.Link = strings.Trim(strings.TrimSpace(target), "<>")
+				break
+			}
+		}
+		if d.Link != "" {
+			break
+		}
+	}
+	for _, v := range h.Values("Warning") {
+		d.Warnings = append(d.Warnings, parseWarning(v))
+	}
+	return d
+}
+
+// logDeprecation logs the deprecation of the resource req was sent to,
+// once per method and path.
+func (c *Client) logDeprecation(req *http.Request, d *Deprecation) {
+	key := req.Method + " " + req.URL.Path
+	if _, logged := c.deprecationsLogged.LoadOrStore(key, true); logged {
+		return
+	}
+	attrs := []any{"method", req.Method, "url", req.URL.Redacted()}
+	if !d.Date.IsZero() {
+		attrs = append(attrs, "deprecation", d.Date)
+	}
+	if !d.Sunset.IsZero() {
+		attrs = append(attrs, "sunset", d.Sunset)
+	}
+	if d.Link != "" {
+		attrs = append(attrs, "link", d.Link)
+	}
+	if len(d.Warnings) > 0 {
+		attrs = append(attrs, "warnings", d.Warnings)
+	}
+	c.logger.WarnContext(req.Context(), "browserbro: deprecated API", attrs...)
+}
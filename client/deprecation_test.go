@@ -0,0 +1,70 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDeprecation(t *testing.T) {
+	assert.Nil(t, parseDeprecation(http.Header{"Warning": {`299 - "slow"`}}))
+
+	d := parseDeprecation(http.Header{
+		"Deprecation": {"@1688169599"},
+		"Sunset":      {"Sun, 30 Jun 2024 23:59:59 GMT"},
+		"Link": {
+			`<https://example.com/next>; rel="next"`,
+			`<https://docs.example.com/migrate>; rel="deprecation"; type="text/html"`,
+		},
+		"Warning": {`299 - "use screenshot2 instead"`},
+	})
+	require.NotNil(t, d)
+	assert.Equal(t, time.Date(2023, 6, 30, 23, 59, 59, 0, time.UTC), d.Date)
+	assert.Equal(t, time.Date(2024, 6, 30, 23, 59, 59, 0, time.UTC), d.Sunset)
+	assert.Equal(t, "https://docs.example.com/migrate", d.Link)
+	assert.Equal(t, []string{"use screenshot2 instead"}, d.Warnings)
+
+	d = parseDeprecation(http.Header{"Deprecation": {"Fri, 30 Jun 2023 23:59:59 GMT"}})
+	require.NotNil(t, d)
+	assert.Equal(t, time.Date(2023, 6, 30, 23, 59, 59, 0, time.UTC), d.Date)
+
+	d = parseDeprecation(http.Header{"Deprecation": {"true"}})
+	require.NotNil(t, d)
+	assert.True(t, d.Date.IsZero())
+}
+
+func TestWithLogger_Deprecation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "@1688169599")
+		w.Header().Set("Sunset", "Sun, 30 Jun 2024 23:59:59 GMT")
+		_, _ = w.Write([]byte(`{"screenshot": "ok"}`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	c, err := New(server.URL, nil, WithLogger(logger))
+	require.NoError(t, err)
+
+	result, err := c.RunPluginResult(context.Background(), "screenshot", nil)
+	require.NoError(t, err)
+	require.NotNil(t, result.Deprecation)
+	assert.Equal(t, time.Date(2024, 6, 30, 23, 59, 59, 0, time.UTC), result.Deprecation.Sunset)
+
+	_, err = c.RunPluginResult(context.Background(), "screenshot", nil)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	require.Len(t, lines, 1, "a deprecation is logged once")
+	assert.Contains(t, lines[0], `level=WARN msg="browserbro: deprecated API" method=POST`)
+	assert.Contains(t, lines[0], "/api/v1/plugins/screenshot")
+	assert.Contains(t, lines[0], "sunset=2024-06-30T23:59:59.000Z")
+}
@@ -0,0 +1,231 @@
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Description is the effective configuration of a Client, as returned by
+// Describe. It never contains secrets: credentials embedded in URLs are
+// masked and credential providers are described by kind only.
+type Description struct {
+	// Endpoint is the API base URL of the server.
+	Endpoint string
+	Region   string
+	// RegionalServers maps regions to the API base URLs of their servers.
+	RegionalServers map[string]string
+	// Timeout is the HTTP client timeout; zero means none.
+	Timeout   time.Duration
+	Transport TransportDescription
+	// Auth describes how requests are authenticated, e.g. "none" or
+	// "vault (https://vault.example.com, secret/browserbro)".
+	Auth string
+	// StatusPolicies describes the registered status policies, by status code.
+	StatusPolicies map[int]string
+	// Middleware lists the features applied to calls, in the order
+	// they apply to a run.
+	Middleware []string
+}
+
+// TransportDescription describes the HTTP transport of a Client.
+type TransportDescription struct {
+	// Type is the Go type of the transport.
+	Type string
+	// Proxy reports whether requests may be sent through a proxy.
+	Proxy           bool
+	TLSMinVersion   string
+	MaxConnsPerHost int
+	IdleConnTimeout time.Duration
+}
+
+// Describe returns the effective configuration of c, for example to
+// include the exact client setup in a support ticket.
+func (c *Client) Describe() Description {
+	d := Description{
+		Endpoint:       maskURL(c.addr),
+		Region:         c.region,
+		Timeout:        c.client.Timeout,
+		Transport:      describeTransport(c.client.Transport),
+		Auth:           describeAuth(c.credentials),
+		StatusPolicies: make(map[int]string, len(c.statusPolicies)),
+	}
+	if len(c.regionalServers) > 0 {
+		d.RegionalServers = make(map[string]string, len(c.regionalServers))
+		for _, rs := range c.regionalServers {
+			d.RegionalServers[rs.region] = maskURL(rs.addr)
+		}
+	}
+	for code, p := range c.statusPolicies {
+		desc := "permanent"
+		if p.Retryable {
+			desc = "retryable"
+		}
+		if p.Err != nil {
+			desc += ", matches " + strconv.Quote(p.Err.Error())
+		}
+		d.StatusPolicies[code] = desc
+	}
+
+	add := func(enabled bool, name string) {
+		if enabled {
+			d.Middleware = append(d.Middleware, name)
+		}
+	}
+	_, simulated := c.client.Transport.(*simulator)
+	add(simulated, "simulation")
+	add(c.policy != nil, "policy")
+	add(c.pacer != nil, "rate limit pacing")
+	if c.retry != nil {
+		add(true, fmt.Sprintf("retry (%d attempts)", c.retry.maxAttempts))
+	}
+	add(c.fingerprints != nil, "fingerprint rotation")
+	add(c.userAgents != nil, "user agent rotation")
+	if c.proxies != nil {
+		add(true, fmt.Sprintf("proxy rotation (%d proxies)", len(c.proxies.Proxies())))
+	}
+	add(c.kms != nil, "payload encryption")
+	add(c.noBufferPool, "no buffer pooling")
+	add(c.scrubber != nil, "scrubber")
+	add(c.sink != nil, fmt.Sprintf("result sink (%T)", c.sink))
+	add(c.publisher != nil, fmt.Sprintf("result publisher (%T)", c.publisher))
+	add(c.events != nil, fmt.Sprintf("event sink (%T)", c.events))
+	if c.downloadBudget != nil {
+		add(true, fmt.Sprintf("download budget (%d bytes)", c.downloadBudget.total))
+	}
+	if c.cache != nil {
+		add(true, fmt.Sprintf("response cache (%d bytes)", c.cache.size))
+	}
+	if c.prefetcher != nil {
+		add(true, fmt.Sprintf("prefetch (ttl %s)", c.prefetcher.ttl))
+	}
+	add(c.versionCheck.strict, "strict version check")
+	add(c.logger != nil, "logger")
+	if c.recorder != nil {
+		add(true, fmt.Sprintf("recorder (last %d calls)", c.recorder.size))
+	}
+	add(c.errorHook != nil, "error hook")
+	add(c.audit != nil, "audit log")
+	return d
+}
+
+// String formats the description as "key: value" lines.
+func (d Description) String() string {
+	var sb strings.Builder
+	line := func(key, value string) {
+		sb.WriteString(key + ": " + value + "\n")
+	}
+	line("endpoint", d.Endpoint)
+	if d.Region != "" {
+		line("region", d.Region)
+	}
+	regions := make([]string, 0, len(d.RegionalServers))
+	for region := range d.RegionalServers {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	for _, region := range regions {
+		line("regional server "+region, d.RegionalServers[region])
+	}
+	line("timeout", d.Timeout.String())
+	t := d.Transport
+	transport := t.Type
+	if t.Type != "" && t.Type != "default" {
+		transport += fmt.Sprintf(" (proxy %t, max conns per host %d, idle conn timeout %s",
+			t.Proxy, t.MaxConnsPerHost, t.IdleConnTimeout)
+		if t.TLSMinVersion != "" {
+			transport += ", min " + t.TLSMinVersion
+		}
+		transport += ")"
+	}
+	line("transport", transport)
+	line("auth", d.Auth)
+	codes := make([]int, 0, len(d.StatusPolicies))
+	for code := range d.StatusPolicies {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		line("status policy "+strconv.Itoa(code), d.StatusPolicies[code])
+	}
+	middleware := "none"
+	if len(d.Middleware) > 0 {
+		middleware = strings.Join(d.Middleware, ", ")
+	}
+	line("middleware", middleware)
+	return sb.String()
+}
+
+func describeTransport(rt http.RoundTripper) TransportDescription {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return TransportDescription{Type: fmt.Sprintf("%T", rt)}
+	}
+	d := TransportDescription{
+		Type:            "*http.Transport",
+		Proxy:           t.Proxy != nil,
+		MaxConnsPerHost: t.MaxConnsPerHost,
+		IdleConnTimeout: t.IdleConnTimeout,
+	}
+	if rt == http.DefaultTransport {
+		d.Type = "default"
+	}
+	if t.TLSClientConfig != nil && t.TLSClientConfig.MinVersion != 0 {
+		d.TLSMinVersion = tls.VersionName(t.TLSClientConfig.MinVersion)
+	}
+	return d
+}
+
+// describeAuth describes a credential provider without its secrets.
+func describeAuth(p CredentialProvider) string {
+	switch p := p.(type) {
+	case nil:
+		return "none"
+	case *CachedCredentials:
+		return "cached " + describeAuth(p.provider)
+	case VaultCredentials:
+		return fmt.Sprintf("vault (%s, %s/%s)", maskURL(p.Address), p.Mount, p.Path)
+	case SecretCredentials:
+		return fmt.Sprintf("secret manager (%T, %s)", p.Fetcher, p.Name)
+	case StaticCredentials:
+		var kinds []string
+		if p.APIKey != "" {
+			kinds = append(kinds, "api key")
+		}
+		if p.BearerToken != "" {
+			kinds = append(kinds, "bearer token")
+		} else if p.Username != "" || p.Password != "" {
+			kinds = append(kinds, fmt.Sprintf("basic auth (%s)", p.Username))
+		}
+		if len(kinds) == 0 {
+			return "none"
+		}
+		return strings.Join(kinds, ", ")
+	case tokenCredentials:
+		return fmt.Sprintf("token source (%T)", p.source)
+	default:
+		return fmt.Sprintf("credential provider (%T)", p)
+	}
+}
+
+// maskURL masks the password of a URL with user info, or the user name
+// when there is no password, as it then usually is a token.
+func maskURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	if _, ok := u.User.Password(); !ok {
+		u.User = url.User("xxxxx")
+		return u.String()
+	}
+	return u.Redacted()
+}
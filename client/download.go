@@ -0,0 +1,68 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DownloadFileStream opens a file with the given ID for reading, so large
+// files such as screen recordings are never held in memory in full. It
+// returns the file contents and size, or -1 if the server did not report
+// it. The caller must close the reader. The HTTP client timeout and ctx
+// also bound reading the contents. Unlike DownloadFile, it neither writes
+// the file to the result sink nor reserves download budget.
+func (c *Client) DownloadFileStream(ctx context.Context, fileID string) (_ io.ReadCloser, _ int64, err error) {
+	ctx, op := c.startOperation(ctx, "DownloadFileStream")
+	defer func() { c.finishOperation(ctx, op, err) }()
+	op.FileID = fileID
+
+	return c.downloadFileStream(ctx, fileID)
+}
+
+// DownloadFileTo downloads a file with the given ID to w, like
+// DownloadFileStream, and returns the number of bytes written.
+func (c *Client) DownloadFileTo(ctx context.Context, fileID string, w io.Writer) (_ int64, err error) {
+	ctx, op := c.startOperation(ctx, "DownloadFileTo")
+	defer func() { c.finishOperation(ctx, op, err) }()
+	op.FileID = fileID
+
+	body, _, err := c.downloadFileStream(ctx, fileID)
+	if err != nil {
+		return 0, err
+	}
+	defer closeBody(body)
+
+	n, err := io.Copy(w, body)
+	if err != nil {
+		return n, fmt.Errorf("failed to download file: %w", transportError(err))
+	}
+	return n, nil
+}
+
+func (c *Client) downloadFileStream(ctx context.Context, fileID string) (io.ReadCloser, int64, error) {
+	if c.prefetcher != nil {
+		if data, ok := c.prefetcher.take(ctx, fileID); ok {
+			return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+		}
+	}
+	u, err := c.fileURL(fileID)
+	if err != nil {
+		return nil, 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create download request: %w", err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to download file: %w", transportError(err))
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer closeBody(resp.Body)
+		return nil, 0, c.newAPIError(resp)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
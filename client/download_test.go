@@ -0,0 +1,70 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_DownloadFileStream(t *testing.T) {
+	content := strings.Repeat("recording", 100_000)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "missing.webm" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "file not found"}`))
+			return
+		}
+		_, _ = io.Copy(w, strings.NewReader(content))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	body, size, err := c.DownloadFileStream(context.Background(), "rec.webm")
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), size)
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.NoError(t, body.Close())
+	assert.Equal(t, content, string(data))
+
+	_, _, err = c.DownloadFileStream(context.Background(), "missing.webm")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, _, err = c.DownloadFileStream(context.Background(), "../secret")
+	assert.Error(t, err)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestClient_DownloadFileTo(t *testing.T) {
+	server := mockServer(t, http.StatusOK, "%PDF-1.7")
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	n, err := c.DownloadFileTo(context.Background(), "bundle.pdf", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), n)
+	assert.Equal(t, "%PDF-1.7", buf.String())
+
+	_, err = c.DownloadFileTo(context.Background(), "bundle.pdf", failingWriter{})
+	assert.ErrorContains(t, err, "disk full")
+}
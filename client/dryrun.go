@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// DryRunResult is the outcome of a successful RunPluginDryRun.
+type DryRunResult struct {
+	// Params are the params the run would submit, after run options
+	// and coercion.
+	Params map[string]any
+	// Local reports whether the params were validated by the client
+	// because the server has no validation endpoint.
+	Local bool
+	// Warnings are server-reported warnings about the run.
+	Warnings []string
+}
+
+// RunPluginDryRun checks that a run would be accepted, without running
+// the plugin or consuming a browser, e.g. to check generated params
+// before a deployment. The params are validated by the server's
+// validation endpoint; if the server has none, the client checks that
+// the plugin exists and that the params match the schema of the plugin
+// given to WithParamSchemas or in DefaultParamSchemas. Invalid params
+// return a *ValidationError or an *APIError, like RunPlugin would.
+func (c *Client) RunPluginDryRun(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	opts ...RunOption,
+) (_ *DryRunResult, err error) {
+	ctx, op := c.startOperation(ctx, "RunPluginDryRun")
+	defer func() { c.finishOperation(ctx, op, err) }()
+	op.Plugin = pluginName
+
+	cfg := newRunConfig(params, opts)
+	c.coerceParams(pluginName, cfg)
+	op.params = cfg.params
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if err := c.checkPolicy(pluginName, cfg.params); err != nil {
+		return nil, err
+	}
+	rc, err := c.resident(cfg.residency, cfg.prober)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := rc.validateRemote(ctx, pluginName, cfg.params)
+	if errors.Is(err, errNoValidationEndpoint) {
+		result, err = rc.validateLocal(ctx, pluginName, cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Params = cfg.params
+	return result, nil
+}
+
+var errNoValidationEndpoint = errors.New("no validation endpoint")
+
+func (c *Client) validateRemote(ctx context.Context, pluginName string, params map[string]any) (*DryRunResult, error) {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to JSON encode params: %w", err)
+	}
+	body, contentType, err := c.sealBody(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newBodyRequest(
+		ctx,
+		http.MethodPost,
+		c.addr+"/plugins/"+pluginName+"/validate",
+		contentType,
+		body,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create validation request: %w", err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate params: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented:
+		return nil, errNoValidationEndpoint
+	case resp.StatusCode == http.StatusNotFound:
+		// An unknown plugin is reported with an error code; a bare 404
+		// means the server predates the validation endpoint.
+		apiErr := c.newAPIError(resp)
+		if apiErr.Code == "" {
+			return nil, errNoValidationEndpoint
+		}
+		return nil, apiErr
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, c.newAPIError(resp)
+	}
+
+	result := &DryRunResult{}
+	for _, v := range resp.Header.Values("Warning") {
+		result.Warnings = append(result.Warnings, parseWarning(v))
+	}
+	return result, nil
+}
+
+func (c *Client) validateLocal(ctx context.Context, pluginName string, cfg *runConfig) (*DryRunResult, error) {
+	plugins, err := c.Plugins(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(plugins, pluginName) {
+		match, err := resolvePlugin(pluginName, plugins)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &UnknownPluginError{Name: pluginName, Suggestions: []string{match}}
+	}
+
+	schema, ok := c.paramSchemas[pluginName]
+	if !ok {
+		schema = DefaultParamSchemas[pluginName]
+	}
+	// Coerce a copy: the run only coerces with a client-wide schema.
+	check := &runConfig{params: make(map[string]any, len(cfg.params))}
+	for k, v := range cfg.params {
+		check.params[k] = v
+	}
+	schema.coerce(check)
+	if err := check.validate(); err != nil {
+		return nil, err
+	}
+	return &DryRunResult{Local: true}, nil
+}
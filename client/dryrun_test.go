@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPluginDryRun(t *testing.T) {
+	var params map[string]any
+	var runs int
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/plugins/screenshot/validate", func(w http.ResponseWriter, r *http.Request) {
+		params = nil
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		if _, ok := params["urls"]; !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message": "urls is required", "code": "invalid_param", "param": "urls"}`))
+			return
+		}
+		w.Header().Add("Warning", `199 - "viewport is deprecated"`)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /api/v1/plugins/screenshot", func(w http.ResponseWriter, r *http.Request) {
+		runs++
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := New(server.URL, nil, WithParamSchemas(DefaultParamSchemas))
+	require.NoError(t, err)
+
+	result, err := c.RunPluginDryRun(context.Background(), "screenshot", map[string]any{"urls": "https://example.com"})
+	require.NoError(t, err)
+	assert.False(t, result.Local)
+	assert.Equal(t, []string{"viewport is deprecated"}, result.Warnings)
+	assert.Equal(t, map[string]any{"urls": []string{"https://example.com"}}, result.Params)
+	assert.Equal(t, map[string]any{"urls": []any{"https://example.com"}}, params)
+
+	_, err = c.RunPluginDryRun(context.Background(), "screenshot", nil)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "urls", apiErr.Param)
+	assert.Zero(t, runs)
+}
+
+func TestRunPluginDryRun_Local(t *testing.T) {
+	var runs int
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/plugins", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"plugins": ["screenshot", "googlesearch"]}`))
+	})
+	mux.HandleFunc("POST /api/v1/plugins/{name}", func(w http.ResponseWriter, r *http.Request) {
+		runs++
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	result, err := c.RunPluginDryRun(context.Background(), "googlesearch", map[string]any{"query": 42})
+	require.NoError(t, err)
+	assert.True(t, result.Local)
+	// Without a client-wide schema, the submitted params are not coerced.
+	assert.Equal(t, map[string]any{"query": 42}, result.Params)
+
+	_, err = c.RunPluginDryRun(context.Background(), "screenshot", map[string]any{"urls": 42})
+	require.ErrorIs(t, err, ErrInvalidParams)
+	assert.EqualError(t, err, "invalid params: urls: cannot coerce int to list of strings")
+
+	_, err = c.RunPluginDryRun(context.Background(), "Screenshot", nil)
+	require.ErrorIs(t, err, ErrUnknownPlugin)
+	assert.EqualError(t, err, `unknown plugin "Screenshot"; did you mean "screenshot"?`)
+
+	_, err = c.RunPluginDryRun(context.Background(), "pdf", nil)
+	require.ErrorIs(t, err, ErrUnknownPlugin)
+	assert.Zero(t, runs)
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// EnvelopeContentType is the content type of envelope-encrypted
+// request and response bodies.
+const EnvelopeContentType = "application/vnd.browserbro.envelope+json"
+
+// EnvelopeAlgorithm is the payload cipher of an Envelope.
+const EnvelopeAlgorithm = "AES-256-GCM"
+
+// ErrUnencryptedResponse is returned when payload encryption is enabled
+// and the server answers a plugin run with a plaintext result.
+var ErrUnencryptedResponse = errors.New("server returned an unencrypted result")
+
+// ErrEncryptionUnsupported is returned by RunPluginStream and
+// DialControlChannel when payload encryption is enabled: streamed frames
+// cannot be sealed as a single envelope.
+var ErrEncryptionUnsupported = errors.New("payload encryption is not supported")
+
+// DataKey is a data encryption key generated by a KMS.
+type DataKey struct {
+	// KeyID identifies the KMS key that encrypted the data key.
+	KeyID string
+	// Plaintext is the 32-byte AES key. It is never sent.
+	Plaintext []byte
+	// Encrypted is the data key encrypted by the KMS key.
+	Encrypted []byte
+}
+
+// KMS issues and decrypts data keys. Implementations wrap a key
+// management service such as AWS KMS, Google Cloud KMS or Vault Transit.
+type KMS interface {
+	GenerateDataKey(ctx context.Context) (DataKey, error)
+	Decrypt(ctx context.Context, keyID string, encryptedKey []byte) ([]byte, error)
+}
+
+// WithPayloadEncryption encrypts plugin params and requires encrypted
+// plugin results, so a proxy terminating TLS between the client and the
+// server sees neither. Each request is sealed with a fresh data key from
+// kms; the server seals its result the same way with a key the client
+// decrypts through kms. Error responses and file content are not
+// encrypted. Streaming runs and control channels are refused with
+// ErrEncryptionUnsupported.
+func WithPayloadEncryption(kms KMS) Option {
+	return func(c *Client) {
+		c.kms = kms
+	}
+}
+
+// SealEnvelope encrypts plaintext with a new data key from kms.
+func SealEnvelope(ctx context.Context, kms KMS, plaintext []byte) (*Envelope, error) {
+	key, err := kms.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	aead, err := newAEAD(key.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return &Envelope{
+		Algorithm:    EnvelopeAlgorithm,
+		KeyID:        key.KeyID,
+		EncryptedKey: key.Encrypted,
+		Nonce:        nonce,
+		Ciphertext:   aead.Seal(nil, nonce, plaintext, nil),
+	}, nil
+}
+
+// OpenEnvelope decrypts env with its data key, decrypted by kms.
+func OpenEnvelope(ctx context.Context, kms KMS, env *Envelope) ([]byte, error) {
+	if env.Algorithm != EnvelopeAlgorithm {
+		return nil, fmt.Errorf("unsupported envelope algorithm %q", env.Algorithm)
+	}
+	key, err := kms.Decrypt(ctx, env.KeyID, env.EncryptedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid envelope nonce")
+	}
+	plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt envelope: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid data key length %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealBody encrypts a JSON request body when payload encryption is
+// enabled. It returns the body to send and its content type.
+func (c *Client) sealBody(ctx context.Context, body []byte) ([]byte, string, error) {
+	if c.kms == nil {
+		return body, "application/json", nil
+	}
+	env, err := SealEnvelope(ctx, c.kms, body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encrypt params: %w", err)
+	}
+	sealed, err := json.Marshal(env)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to JSON encode envelope: %w", err)
+	}
+	return sealed, EnvelopeContentType, nil
+}
+
+// decodeBody decodes a JSON response body into v, decrypting it first
+// when payload encryption is enabled.
+func (c *Client) decodeBody(ctx context.Context, resp *http.Response, v any) error {
+	r, err := c.bodyReader(ctx, resp)
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(r).Decode(v)
+}
+
+// bodyReader returns a reader of the JSON response body. When payload
+// encryption is enabled, the envelope is read and decrypted in full.
+func (c *Client) bodyReader(ctx context.Context, resp *http.Response) (io.Reader, error) {
+	if c.kms == nil {
+		return resp.Body, nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != EnvelopeContentType {
+		return nil, ErrUnencryptedResponse
+	}
+	var env Envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return nil, fmt.Errorf("failed to decode envelope: %w", err)
+	}
+	plaintext, err := OpenEnvelope(ctx, c.kms, &env)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(plaintext), nil
+}
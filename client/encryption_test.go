@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKMS wraps data keys with a local AES-GCM master key.
+type testKMS struct {
+	aead cipher.AEAD
+}
+
+func newTestKMS(t *testing.T) *testKMS {
+	t.Helper()
+
+	master := make([]byte, 32)
+	_, err := rand.Read(master)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(master)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return &testKMS{aead: aead}
+}
+
+func (k *testKMS) GenerateDataKey(ctx context.Context) (DataKey, error) {
+	key := make([]byte, 32)
+	nonce := make([]byte, k.aead.NonceSize())
+	_, _ = rand.Read(key)
+	_, _ = rand.Read(nonce)
+	return DataKey{
+		KeyID:     "test",
+		Plaintext: key,
+		Encrypted: k.aead.Seal(nonce, nonce, key, nil),
+	}, nil
+}
+
+func (k *testKMS) Decrypt(ctx context.Context, keyID string, encryptedKey []byte) ([]byte, error) {
+	if keyID != "test" {
+		return nil, errors.New("unknown key")
+	}
+	n := k.aead.NonceSize()
+	if len(encryptedKey) < n {
+		return nil, errors.New("short key")
+	}
+	return k.aead.Open(nil, encryptedKey[:n], encryptedKey[n:], nil)
+}
+
+func TestEnvelope(t *testing.T) {
+	kms := newTestKMS(t)
+	ctx := context.Background()
+
+	env, err := SealEnvelope(ctx, kms, []byte("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, EnvelopeAlgorithm, env.Algorithm)
+	assert.NotContains(t, string(env.Ciphertext), "secret")
+
+	plaintext, err := OpenEnvelope(ctx, kms, env)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+
+	env.Ciphertext[0] ^= 0xff
+	_, err = OpenEnvelope(ctx, kms, env)
+	assert.ErrorContains(t, err, "failed to decrypt envelope")
+}
+
+func TestWithPayloadEncryption(t *testing.T) {
+	kms := newTestKMS(t)
+
+	t.Run("round trip", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, EnvelopeContentType, r.Header.Get("Content-Type"))
+			assert.Equal(t, EnvelopeContentType, r.Header.Get("Accept"))
+			body, _ := io.ReadAll(r.Body)
+			assert.NotContains(t, string(body), "golang")
+
+			var env Envelope
+			require.NoError(t, json.Unmarshal(body, &env))
+			plaintext, err := OpenEnvelope(r.Context(), kms, &env)
+			require.NoError(t, err)
+			var params map[string]any
+			require.NoError(t, json.Unmarshal(plaintext, &params))
+
+			output, _ := json.Marshal(map[string]any{"googlesearch": params["query"]})
+			sealed, err := SealEnvelope(r.Context(), kms, output)
+			require.NoError(t, err)
+			w.Header().Set("Content-Type", EnvelopeContentType+"; charset=utf-8")
+			_ = json.NewEncoder(w).Encode(sealed)
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, nil, WithPayloadEncryption(kms))
+		require.NoError(t, err)
+
+		output, err := c.RunPlugin(context.Background(), "googlesearch", map[string]any{"query": "golang"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"googlesearch": "golang"}, output)
+	})
+
+	t.Run("plaintext result", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, `{"googlesearch": "leaked"}`)
+		defer server.Close()
+
+		c, err := New(server.URL, nil, WithPayloadEncryption(kms))
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "googlesearch", nil)
+		assert.ErrorIs(t, err, ErrUnencryptedResponse)
+	})
+
+	t.Run("other endpoints", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /api/v1/plugins/crawl/results/c1", sealedHandler(t, kms, func([]byte) any {
+			return map[string]any{"page": 2}
+		}))
+		mux.HandleFunc("POST /api/v1/graphql", sealedHandler(t, kms, func(body []byte) any {
+			assert.Contains(t, string(body), "plugins")
+			return map[string]any{"data": map[string]any{"plugins": []string{"crawl"}}}
+		}))
+		mux.HandleFunc("POST /api/v1/files/delete", sealedHandler(t, kms, func(body []byte) any {
+			assert.JSONEq(t, `{"ids": ["a"]}`, string(body))
+			return DeleteFilesResponse{Results: []DeleteFileResult{{ID: "a", Status: http.StatusOK}}}
+		}))
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		c, err := New(server.URL, nil, WithPayloadEncryption(kms))
+		require.NoError(t, err)
+		ctx := context.Background()
+
+		result := c.newRunResult("crawl", http.Header{"X-Next-Cursor": {"c1"}})
+		page, err := result.NextPage(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2.0, page.Output["page"])
+
+		var data struct{ Plugins []string }
+		require.NoError(t, c.GraphQL(ctx, "{ plugins }", nil, &data))
+		assert.Equal(t, []string{"crawl"}, data.Plugins)
+
+		require.NoError(t, c.DeleteFiles(ctx, []string{"a"}))
+	})
+
+	t.Run("plaintext result page", func(t *testing.T) {
+		server := paginatedServer(t)
+		defer server.Close()
+
+		c, err := New(server.URL, nil, WithPayloadEncryption(kms))
+		require.NoError(t, err)
+
+		result := c.newRunResult("crawl", http.Header{"X-Next-Cursor": {"c1"}})
+		_, err = result.NextPage(context.Background())
+		assert.ErrorIs(t, err, ErrUnencryptedResponse)
+	})
+
+	t.Run("streaming unsupported", func(t *testing.T) {
+		c, err := New("http://localhost", nil, WithPayloadEncryption(kms))
+		require.NoError(t, err)
+
+		_, err = c.RunPluginStream(context.Background(), "googlesearch", nil)
+		assert.ErrorIs(t, err, ErrEncryptionUnsupported)
+		_, err = c.DialControlChannel(context.Background())
+		assert.ErrorIs(t, err, ErrEncryptionUnsupported)
+	})
+}
+
+// sealedHandler opens the sealed request body, passes it to respond and
+// seals the JSON encoded response.
+func sealedHandler(t *testing.T, kms KMS, respond func([]byte) any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, EnvelopeContentType, r.Header.Get("Accept"))
+		var plaintext []byte
+		if r.Method == http.MethodPost {
+			assert.Equal(t, EnvelopeContentType, r.Header.Get("Content-Type"))
+			var env Envelope
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&env))
+			var err error
+			plaintext, err = OpenEnvelope(r.Context(), kms, &env)
+			require.NoError(t, err)
+		}
+		output, _ := json.Marshal(respond(plaintext))
+		sealed, err := SealEnvelope(r.Context(), kms, output)
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", EnvelopeContentType)
+		_ = json.NewEncoder(w).Encode(sealed)
+	}
+}
@@ -0,0 +1,288 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+var (
+	// ErrTimeout matches every timeout, whether the client gave up waiting
+	// (Client.Timeout or a context deadline) or the server timed out.
+	ErrTimeout = errors.New("timeout")
+	// ErrPluginTimeout matches server-side plugin timeouts only.
+	ErrPluginTimeout = errors.New("plugin timed out")
+	// ErrInsufficientScope matches 403 responses caused by a token
+	// missing required scopes; see APIError.RequiredScopes.
+	ErrInsufficientScope = errors.New("insufficient scope")
+	// ErrNotFound matches 404 responses, e.g. for a missing file.
+	ErrNotFound = errors.New("not found")
+	// ErrPluginNotFound matches 404 responses to plugin runs, responses
+	// with the "plugin_not_found" code, and UnknownPluginError.
+	ErrPluginNotFound = errors.New("plugin not found")
+	// ErrRateLimited matches 429 responses.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrUnauthorized matches 401 responses.
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// APIError is returned when the server responds with an unexpected status.
+type APIError struct {
+	StatusCode int
+	Status     string
+	// Method and URL identify the request, with any password of the
+	// URL masked.
+	Method string
+	URL    string
+	// RequestID is the ID the server answered with in RequestIDHeader,
+	// or else the ID the client sent, for correlating with server logs.
+	RequestID string
+	// Message is the server-provided error message, if any.
+	Message string
+	// Code, Param and Hint carry the structured error details
+	// sent by the server, if any.
+	Code  string
+	Param string
+	Hint  string
+	// Fields lists per-parameter failures.
+	Fields []FieldError
+	// RequiredScopes lists the token scopes the call needs when the
+	// server rejected it for missing scope. They are taken from the
+	// error payload or the scope attribute of a Bearer
+	// WWW-Authenticate challenge (RFC 6750).
+	RequiredScopes []string
+
+	// policy is the StatusPolicy registered for StatusCode, if any.
+	policy *StatusPolicy
+	// pluginRun reports whether the request ran a plugin.
+	pluginRun bool
+}
+
+func (e *APIError) Error() string {
+	msg := "unexpected response status: " + e.Status
+	if e.Message != "" {
+		msg += "; message: " + e.Message
+	}
+	if e.Code != "" {
+		msg += "; code: " + e.Code
+	}
+	if e.Param != "" {
+		msg += "; param: " + e.Param
+	}
+	if e.Hint != "" {
+		msg += "; hint: " + e.Hint
+	}
+	if len(e.RequiredScopes) > 0 {
+		msg += "; required scopes: " + strings.Join(e.RequiredScopes, ", ")
+	}
+	for _, f := range e.Fields {
+		msg += "; " + f.Param
+		if f.Message != "" {
+			msg += ": " + f.Message
+		} else if f.Code != "" {
+			msg += ": " + f.Code
+		}
+	}
+	return msg
+}
+
+// Retryable reports whether repeating the request may succeed.
+// Timeouts, rate limiting and temporary gateway or availability
+// failures are retryable; all other statuses are permanent.
+func (e *APIError) Retryable() bool {
+	if e.policy != nil {
+		return e.policy.Retryable
+	}
+	return retryableStatus(e.StatusCode)
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout,
+		http.StatusTooEarly,
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Is makes APIError match ErrTimeout and ErrPluginTimeout when the
+// server reports that the plugin ran out of time, and the Err
+// of the StatusPolicy registered for its status.
+func (e *APIError) Is(target error) bool {
+	if e.policy != nil && e.policy.Err != nil && e.policy.Err == target {
+		return true
+	}
+	switch target {
+	case ErrTimeout, ErrPluginTimeout:
+		return e.StatusCode == http.StatusGatewayTimeout
+	case ErrInsufficientScope:
+		return e.StatusCode == http.StatusForbidden &&
+			(len(e.RequiredScopes) > 0 || e.Code == "insufficient_scope")
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrPluginNotFound:
+		return e.Code == "plugin_not_found" || e.StatusCode == http.StatusNotFound && e.pluginRun
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	}
+	return false
+}
+
+// Temporary is an alias of Retryable for callers using the
+// interface{ Temporary() bool } convention.
+func (e *APIError) Temporary() bool {
+	return e.Retryable()
+}
+
+// newAPIError builds an APIError from a non-200 response,
+// decoding the server error details when present. The caller must
+// still close the body with closeBody so the connection is reused.
+func (c *Client) newAPIError(resp *http.Response) *APIError {
+	var msg ErrorResponse
+	_ = json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodySize)).Decode(&msg)
+	e := &APIError{
+		StatusCode:     resp.StatusCode,
+		Status:         resp.Status,
+		Message:        msg.Message,
+		Code:           msg.Code,
+		Param:          msg.Param,
+		Hint:           msg.Hint,
+		Fields:         msg.Errors,
+		RequiredScopes: msg.RequiredScopes,
+		RequestID:      resp.Header.Get(RequestIDHeader),
+		policy:         c.statusPolicies[resp.StatusCode],
+	}
+	if req := resp.Request; req != nil {
+		e.Method = req.Method
+		e.URL = maskURL(req.URL.String())
+		if e.RequestID == "" {
+			e.RequestID = req.Header.Get(RequestIDHeader)
+		}
+		e.pluginRun = req.Method == http.MethodPost && isPluginRunPath(strings.TrimPrefix(req.URL.Path, c.addrPath()))
+	}
+	if e.StatusCode == http.StatusForbidden && len(e.RequiredScopes) == 0 {
+		e.RequiredScopes = challengeScopes(resp.Header.Values("WWW-Authenticate"))
+	}
+	return e
+}
+
+// isPluginRunPath reports whether path, relative to the API base URL,
+// runs a plugin: /plugins/{name}, optionally followed by /stream or /jobs.
+func isPluginRunPath(path string) bool {
+	name, ok := strings.CutPrefix(path, "/plugins/")
+	if !ok {
+		return false
+	}
+	name = strings.TrimSuffix(name, "/stream")
+	name = strings.TrimSuffix(name, "/jobs")
+	return name != "" && !strings.Contains(name, "/")
+}
+
+// addrPath returns the path of the API base URL.
+func (c *Client) addrPath() string {
+	u, err := url.Parse(c.addr)
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
+
+// challengeScopes returns the scopes of the Bearer challenge with
+// error="insufficient_scope" among the WWW-Authenticate header values.
+func challengeScopes(challenges []string) []string {
+	for _, ch := range challenges {
+		scheme, attrs, _ := strings.Cut(strings.TrimSpace(ch), " ")
+		if !strings.EqualFold(scheme, "Bearer") {
+			continue
+		}
+		params := challengeParams(attrs)
+		if params["error"] == "insufficient_scope" && params["scope"] != "" {
+			return strings.Fields(params["scope"])
+		}
+	}
+	return nil
+}
+
+// challengeParams parses comma-separated key=value auth-params,
+// unquoting quoted values.
+func challengeParams(s string) map[string]string {
+	params := make(map[string]string)
+	for s != "" {
+		var key, value string
+		key, s, _ = strings.Cut(s, "=")
+		key = strings.ToLower(strings.TrimSpace(strings.TrimLeft(key, ", ")))
+		s = strings.TrimLeft(s, " ")
+		if strings.HasPrefix(s, `"`) {
+			end := strings.Index(s[1:], `"`)
+			if end < 0 {
+				value, s = s[1:], ""
+			} else {
+				value, s = s[1:end+1], s[end+2:]
+			}
+		} else {
+			value, s, _ = strings.Cut(s, ",")
+		}
+		if key != "" {
+			params[key] = strings.TrimSpace(value)
+		}
+	}
+	return params
+}
+
+// clientTimeoutError marks a request the client gave up on.
+// It matches ErrTimeout and context.DeadlineExceeded in addition
+// to the underlying transport error.
+type clientTimeoutError struct {
+	err error
+}
+
+func (e *clientTimeoutError) Error() string { return e.err.Error() }
+func (e *clientTimeoutError) Timeout() bool { return true }
+
+func (e *clientTimeoutError) Unwrap() []error {
+	return []error{e.err, ErrTimeout, context.DeadlineExceeded}
+}
+
+// transportError classifies an error returned by the HTTP client.
+func transportError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) ||
+		(errors.As(err, &netErr) && netErr.Timeout()) {
+		return &clientTimeoutError{err: err}
+	}
+	return err
+}
+
+// IsRetryable reports whether an error returned by the client is
+// transient, so that repeating the call may succeed. It honors
+// Retryable and Temporary methods anywhere in the error chain, and
+// treats network timeouts, connection resets and truncated responses
+// as retryable. Canceled contexts are never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var r interface{ Retryable() bool }
+	if errors.As(err, &r) {
+		return r.Retryable()
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
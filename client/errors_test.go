@@ -0,0 +1,275 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIError(t *testing.T) {
+	t.Run("message", func(t *testing.T) {
+		err := &APIError{StatusCode: 400, Status: "400 Bad Request", Message: "bad param"}
+		assert.EqualError(t, err, "unexpected response status: 400 Bad Request; message: bad param")
+	})
+
+	t.Run("details", func(t *testing.T) {
+		server := mockServer(t, http.StatusBadRequest, `{
+			"message": "invalid parameter",
+			"code": "invalid_param",
+			"param": "wait.maxWaitMs",
+			"hint": "must be a positive integer",
+			"errors": [{"param": "url", "code": "required"}]
+		}`)
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+		var apiErr *APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, "invalid_param", apiErr.Code)
+		assert.Equal(t, "wait.maxWaitMs", apiErr.Param)
+		assert.Equal(t, "must be a positive integer", apiErr.Hint)
+		assert.Equal(t, []FieldError{{Param: "url", Code: "required"}}, apiErr.Fields)
+		assert.EqualError(t, err, "unexpected response status: 400 Bad Request; message: invalid parameter"+
+			"; code: invalid_param; param: wait.maxWaitMs; hint: must be a positive integer; url: required")
+	})
+
+	t.Run("retryable", func(t *testing.T) {
+		for code, want := range map[int]bool{
+			http.StatusRequestTimeout:      true,
+			http.StatusTooManyRequests:     true,
+			http.StatusBadGateway:          true,
+			http.StatusServiceUnavailable:  true,
+			http.StatusGatewayTimeout:      true,
+			http.StatusBadRequest:          false,
+			http.StatusNotFound:            false,
+			http.StatusUnauthorized:        false,
+			http.StatusInternalServerError: false,
+		} {
+			err := &APIError{StatusCode: code}
+			assert.Equal(t, want, err.Retryable(), code)
+			assert.Equal(t, want, err.Temporary(), code)
+		}
+	})
+
+	t.Run("returned by client methods", func(t *testing.T) {
+		server := mockServer(t, http.StatusServiceUnavailable, `{"message": "overloaded"}`)
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		_, err = c.Plugins(context.Background())
+		var apiErr *APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+		assert.Equal(t, "overloaded", apiErr.Message)
+		assert.True(t, IsRetryable(err))
+	})
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"wrapped api error", fmt.Errorf("x: %w", &APIError{StatusCode: 429}), true},
+		{"permanent api error", &APIError{StatusCode: 404}, false},
+		{"network timeout", fmt.Errorf("failed: %w", timeoutError{}), true},
+		{"connection reset", fmt.Errorf("failed: %w", syscall.ECONNRESET), true},
+		{"connection refused", fmt.Errorf("failed: %w", syscall.ECONNREFUSED), true},
+		{"unexpected eof", io.ErrUnexpectedEOF, true},
+		{"canceled", fmt.Errorf("failed: %w", context.Canceled), false},
+		{"other", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsRetryable(tt.err))
+		})
+	}
+
+	t.Run("client timeout", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, "")
+		defer server.Close()
+
+		c, err := New(server.URL, &http.Client{Timeout: time.Nanosecond})
+		require.NoError(t, err)
+
+		_, err = c.Plugins(context.Background())
+		require.Error(t, err)
+		assert.True(t, IsRetryable(err))
+	})
+}
+
+func TestTimeoutErrors(t *testing.T) {
+	t.Run("client timeout", func(t *testing.T) {
+		block := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-block
+		}))
+		defer server.Close()
+		defer close(block)
+
+		c, err := New(server.URL, &http.Client{Timeout: 50 * time.Millisecond})
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "slow", nil)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrTimeout))
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.False(t, errors.Is(err, ErrPluginTimeout))
+		assert.True(t, IsRetryable(err))
+	})
+
+	t.Run("plugin timeout", func(t *testing.T) {
+		server := mockServer(t, http.StatusGatewayTimeout, `{"message": "plugin timed out"}`)
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "slow", nil)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrTimeout))
+		assert.True(t, errors.Is(err, ErrPluginTimeout))
+		assert.False(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("other failures", func(t *testing.T) {
+		server := mockServer(t, http.StatusBadRequest, `{}`)
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "broken", nil)
+		assert.False(t, errors.Is(err, ErrTimeout))
+	})
+}
+
+func TestAPIError_RequiredScopes(t *testing.T) {
+	t.Run("payload", func(t *testing.T) {
+		server := mockServer(t, http.StatusForbidden, `{"message": "missing scope", "requiredScopes": ["files:delete"]}`)
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		err = c.DeleteFile(context.Background(), "id")
+		var apiErr *APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, []string{"files:delete"}, apiErr.RequiredScopes)
+		assert.ErrorIs(t, err, ErrInsufficientScope)
+		assert.EqualError(t, err, "unexpected response status: 403 Forbidden; message: missing scope; "+
+			"required scopes: files:delete")
+	})
+
+	t.Run("challenge", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="browserbro", error="insufficient_scope", scope="plugins:run files:read"`)
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+		var apiErr *APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, []string{"plugins:run", "files:read"}, apiErr.RequiredScopes)
+		assert.ErrorIs(t, err, ErrInsufficientScope)
+	})
+
+	t.Run("other forbidden", func(t *testing.T) {
+		server := mockServer(t, http.StatusForbidden, `{"message": "ip blocked"}`)
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		err = c.Healthcheck(context.Background())
+		assert.False(t, errors.Is(err, ErrInsufficientScope))
+	})
+}
+
+func TestChallengeScopes(t *testing.T) {
+	for challenge, want := range map[string][]string{
+		`Bearer error="insufficient_scope", scope="a b"`: {"a", "b"},
+		`bearer scope=a,error=insufficient_scope`:        {"a"},
+		`Basic realm="x"`:                                            nil,
+		`Bearer error="invalid_token", scope="a"`:                    nil,
+		`Bearer realm="a, b", error="insufficient_scope", scope="c"`: {"c"},
+	} {
+		assert.Equal(t, want, challengeScopes([]string{challenge}), challenge)
+	}
+}
+
+func TestAPIError_Sentinels(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RequestIDHeader, "server-id")
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("POST /api/v1/plugins/{name}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("name") {
+		case "missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = c.DownloadFile(ctx, "f1")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrPluginNotFound)
+	assert.Equal(t, http.MethodGet, apiErr.Method)
+	assert.Equal(t, server.URL+"/api/v1/files/f1", apiErr.URL)
+	assert.Equal(t, "server-id", apiErr.RequestID)
+
+	_, err = c.RunPlugin(ctx, "missing", nil)
+	require.ErrorAs(t, err, &apiErr)
+	assert.ErrorIs(t, err, ErrPluginNotFound)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotEmpty(t, apiErr.RequestID)
+
+	_, err = c.RunPlugin(ctx, "limited", nil)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.True(t, IsRetryable(err))
+
+	_, err = c.RunPlugin(ctx, "screenshot", nil)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.False(t, IsRetryable(err))
+
+	assert.ErrorIs(t, &APIError{StatusCode: http.StatusBadRequest, Code: "plugin_not_found"}, ErrPluginNotFound)
+	assert.ErrorIs(t, &UnknownPluginError{Name: "x"}, ErrPluginNotFound)
+}
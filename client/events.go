@@ -0,0 +1,111 @@
+package client
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CloudEvents types emitted by the client.
+const (
+	EventJobSubmitted = "browserbro.job.submitted"
+	EventJobCompleted = "browserbro.job.completed"
+	EventJobFailed    = "browserbro.job.failed"
+	EventFileCreated  = "browserbro.file.created"
+)
+
+// CloudEvent is a CloudEvents 1.0 event in structured JSON format.
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject,omitempty"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype,omitempty"`
+	Data            any       `json:"data,omitempty"`
+}
+
+// EventSink receives job lifecycle events.
+type EventSink interface {
+	Emit(event CloudEvent) error
+}
+
+// WithEventSink emits job lifecycle events to sink, using source as the
+// CloudEvents source attribute. Emission is best-effort: sink errors
+// never fail a plugin run.
+func WithEventSink(sink EventSink, source string) Option {
+	return func(c *Client) {
+		c.events = sink
+		c.eventSource = source
+	}
+}
+
+// ChannelEventSink is an EventSink sending events to a channel.
+// Emit blocks until the event is received.
+type ChannelEventSink chan<- CloudEvent
+
+// Emit implements EventSink.
+func (s ChannelEventSink) Emit(event CloudEvent) error {
+	s <- event
+	return nil
+}
+
+// HTTPEventSink is an EventSink posting events to URL in
+// structured content mode.
+type HTTPEventSink struct {
+	URL string
+	// Client is used to post events. http.DefaultClient is used when nil.
+	Client *http.Client
+}
+
+// Emit implements EventSink.
+func (s HTTPEventSink) Emit(event CloudEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to JSON encode event: %w", err)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Post(s.URL, "application/cloudevents+json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to emit event: %w", err)
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf(
+			"unexpected response status: %s",
+			resp.Status,
+		)
+	}
+	return nil
+}
+
+func (c *Client) emit(eventType, subject string, data any) {
+	if c.events == nil {
+		return
+	}
+	_ = c.events.Emit(CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              newEventID(),
+		Source:          c.eventSource,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		Data:            data,
+	})
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEventSink(t *testing.T) {
+	t.Run("completed job", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, `{"screenshot": {"fileIds": ["f1", "f2"]}}`)
+		defer server.Close()
+
+		events := make(chan CloudEvent, 10)
+		c, err := New(server.URL, nil, WithEventSink(ChannelEventSink(events), "/scraper"))
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+		require.NoError(t, err)
+		close(events)
+
+		var types, subjects []string
+		for e := range events {
+			assert.Equal(t, "1.0", e.SpecVersion)
+			assert.Equal(t, "/scraper", e.Source)
+			assert.NotEmpty(t, e.ID)
+			types = append(types, e.Type)
+			subjects = append(subjects, e.Subject)
+		}
+		assert.Equal(t, []string{
+			EventJobSubmitted,
+			EventJobCompleted,
+			EventFileCreated,
+			EventFileCreated,
+		}, types)
+		assert.Equal(t, []string{"screenshot", "screenshot", "f1", "f2"}, subjects)
+	})
+
+	t.Run("failed job", func(t *testing.T) {
+		server := mockServer(t, http.StatusInternalServerError, `{"message": "boom"}`)
+		defer server.Close()
+
+		events := make(chan CloudEvent, 10)
+		c, err := New(server.URL, nil, WithEventSink(ChannelEventSink(events), "/scraper"))
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+		require.Error(t, err)
+		close(events)
+
+		var got []CloudEvent
+		for e := range events {
+			got = append(got, e)
+		}
+		require.Len(t, got, 2)
+		assert.Equal(t, EventJobFailed, got[1].Type)
+		assert.Equal(t, map[string]any{
+			"plugin": "screenshot",
+			"error":  "unexpected response status: 500 Internal Server Error; message: boom",
+		}, got[1].Data)
+	})
+}
+
+func TestHTTPEventSink(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var received CloudEvent
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "application/cloudevents+json", r.Header.Get("Content-Type"))
+			_ = json.NewDecoder(r.Body).Decode(&received)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer receiver.Close()
+
+		err := HTTPEventSink{URL: receiver.URL}.Emit(CloudEvent{
+			SpecVersion: "1.0",
+			ID:          "1",
+			Type:        EventJobSubmitted,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, EventJobSubmitted, received.Type)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		receiver := mockServer(t, http.StatusBadRequest, "")
+		defer receiver.Close()
+
+		err := HTTPEventSink{URL: receiver.URL}.Emit(CloudEvent{})
+		require.EqualError(t, err, "unexpected response status: 400 Bad Request")
+	})
+}
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"net/url"
+
+	"github.com/bazuker/browserbro-go-api/files"
+)
+
+// extractFileIDs returns the file IDs referenced by a plugin output,
+// as found by files.ExtractIDs.
+func extractFileIDs(v any) []string {
+	return files.Strings(files.ExtractIDs(v))
+}
+
+// FileIDs returns the file IDs referenced by a plugin output: the string
+// values of "fileId" keys and the elements of "fileIds" arrays at any depth.
+// Use files.ExtractIDs for typed IDs.
+func FileIDs(output map[string]any) []string {
+	return extractFileIDs(output)
+}
+
+// fileURL returns the URL of a file, rejecting IDs that would address
+// another resource. The ID is escaped, so a literal "%" stays one.
+func (c *Client) fileURL(fileID string) (string, error) {
+	if err := files.FileID(fileID).Validate(); err != nil {
+		return "", err
+	}
+	return c.addr + "/files/" + url.PathEscape(fileID), nil
+}
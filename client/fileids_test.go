@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bazuker/browserbro-go-api/files"
+)
+
+func TestExtractFileIDs(t *testing.T) {
+	output := map[string]any{
+		"screenshot": map[string]any{
+			"https://b.com": map[string]any{"fileId": "f2"},
+			"https://a.com": map[string]any{"fileId": "f1"},
+		},
+		"pdf": []any{
+			map[string]any{"fileIds": []any{"f3", "f1", 42}},
+		},
+		"typed": map[string]any{"fileIds": []string{"f4"}},
+		"other": map[string]any{"fileId": 1},
+	}
+	assert.Equal(t, []string{"f3", "f1", "f2", "f4"}, extractFileIDs(output))
+	assert.Empty(t, extractFileIDs(map[string]any{"query": "x"}))
+}
+
+func TestFileIDs(t *testing.T) {
+	assert.Equal(t, []string{"f1", "f2"}, FileIDs(map[string]any{
+		"screenshot": map[string]any{"fileIds": []any{"f1", "f2"}},
+	}))
+	assert.Empty(t, FileIDs(nil))
+}
+
+func TestClient_InvalidFileID(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = c.DownloadFile(context.Background(), "../health")
+	assert.ErrorIs(t, err, files.ErrInvalidID)
+	assert.ErrorIs(t, c.DeleteFile(context.Background(), ""), files.ErrInvalidID)
+	assert.Zero(t, requests)
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListFilesOptions filters the files listed by ListFiles and FilesMatching.
+// Zero fields match all files.
+type ListFilesOptions struct {
+	// Prefix matches files whose ID starts with it.
+	Prefix string
+	// ContentType matches files of the media type, e.g. "image/png".
+	ContentType string
+	// OlderThan matches files created longer ago than it.
+	OlderThan time.Duration
+	// NewerThan matches files created less long ago than it.
+	NewerThan time.Duration
+	// PageSize is the number of files requested per page; zero uses
+	// DefaultPageSize.
+	PageSize int
+}
+
+// fileFilter holds the filters of a listing, with ages resolved to
+// creation time bounds.
+type fileFilter struct {
+	prefix        string
+	contentType   string
+	createdBefore time.Time
+	createdAfter  time.Time
+}
+
+func (o ListFilesOptions) filter(now time.Time) fileFilter {
+	f := fileFilter{prefix: o.Prefix, contentType: o.ContentType}
+	if o.OlderThan > 0 {
+		f.createdBefore = now.Add(-o.OlderThan)
+	}
+	if o.NewerThan > 0 {
+		f.createdAfter = now.Add(-o.NewerThan)
+	}
+	return f
+}
+
+// match reports whether a file passes the filter. Files without a
+// creation time never match an age filter.
+func (f fileFilter) match(fi FileInfo) bool {
+	if !strings.HasPrefix(fi.ID, f.prefix) {
+		return false
+	}
+	if f.contentType != "" {
+		mediaType, _, _ := strings.Cut(fi.ContentType, ";")
+		if !strings.EqualFold(strings.TrimSpace(mediaType), f.contentType) {
+			return false
+		}
+	}
+	if !f.createdBefore.IsZero() && (fi.CreatedAt.IsZero() || !fi.CreatedAt.Before(f.createdBefore)) {
+		return false
+	}
+	if !f.createdAfter.IsZero() && (fi.CreatedAt.IsZero() || !fi.CreatedAt.After(f.createdAfter)) {
+		return false
+	}
+	return true
+}
+
+func (f fileFilter) query(query url.Values) {
+	if f.prefix != "" {
+		query.Set("prefix", f.prefix)
+	}
+	if f.contentType != "" {
+		query.Set("contentType", f.contentType)
+	}
+	if !f.createdBefore.IsZero() {
+		query.Set("createdBefore", f.createdBefore.UTC().Format(time.RFC3339))
+	}
+	if !f.createdAfter.IsZero() {
+		query.Set("createdAfter", f.createdAfter.UTC().Format(time.RFC3339))
+	}
+}
+
+// Files returns a Pager over the files stored on the server.
+func (c *Client) Files() *Pager[FileInfo] {
+	return c.FilesMatching(ListFilesOptions{})
+}
+
+// FilesMatching returns a Pager over the files stored on the server that
+// match opts. Ages are relative to the time of the call. The filters are
+// sent to the server and also applied to the files it returns, so
+// servers that don't support filtering list the same files.
+func (c *Client) FilesMatching(opts ListFilesOptions) *Pager[FileInfo] {
+	filter := opts.filter(time.Now())
+	p := NewPager(func(ctx context.Context, cursor string, pageSize int) ([]FileInfo, string, error) {
+		return c.filesPage(ctx, filter, cursor, pageSize)
+	})
+	if opts.PageSize > 0 {
+		p.PageSize(opts.PageSize)
+	}
+	return p
+}
+
+// ListFiles returns the files stored on the server that match opts,
+// fetching all pages, e.g. to find artifacts orphaned by failed runs.
+func (c *Client) ListFiles(ctx context.Context, opts ListFilesOptions) ([]FileInfo, error) {
+	return c.FilesMatching(opts).All(ctx)
+}
+
+func (c *Client) filesPage(
+	ctx context.Context,
+	filter fileFilter,
+	cursor string,
+	pageSize int,
+) (_ []FileInfo, _ string, err error) {
+	ctx, op := c.startOperation(ctx, "ListFiles")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	query := url.Values{}
+	filter.query(query)
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	if pageSize > 0 {
+		query.Set("limit", strconv.Itoa(pageSize))
+	}
+	u := c.addr + "/files"
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create list request: %w", err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list files: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", c.newAPIError(resp)
+	}
+
+	var page FilesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, "", fmt.Errorf("failed to decode files: %w", err)
+	}
+	files := page.Files[:0]
+	for _, f := range page.Files {
+		if filter.match(f) {
+			files = append(files, f)
+		}
+	}
+	op.result = files
+	return files, page.NextCursor, nil
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFiles(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/files", r.URL.Path)
+		queries = append(queries, r.URL.RawQuery)
+		if r.URL.Query().Get("cursor") == "" {
+			_, _ = w.Write([]byte(`{"files":[{"id":"a","name":"a.png","size":10,"contentType":"image/png","createdAt":"2024-05-01T10:00:00Z"}],"nextCursor":"c1"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"files":[{"id":"b","size":20,"createdAt":"2024-05-02T10:00:00Z"}]}`))
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	files, err := c.Files().PageSize(1).All(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []FileInfo{
+		{ID: "a", Name: "a.png", Size: 10, ContentType: "image/png", CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{ID: "b", Size: 20, CreatedAt: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)},
+	}, files)
+	assert.Equal(t, []string{"limit=1", "cursor=c1&limit=1"}, queries)
+}
+
+func TestFiles_Error(t *testing.T) {
+	server := mockServer(t, http.StatusForbidden, `{"message":"forbidden"}`)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	p := c.Files()
+	assert.False(t, p.Next(context.Background()))
+	var apiErr *APIError
+	require.ErrorAs(t, p.Err(), &apiErr)
+	assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+}
+
+func TestClient_ListFiles(t *testing.T) {
+	now := time.Now().UTC()
+	created := func(age time.Duration) string { return now.Add(-age).Format(time.RFC3339) }
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		if r.URL.Query().Get("cursor") == "" {
+			_, _ = fmt.Fprintf(w, `{"files": [
+				{"id": "run-1/a.png", "contentType": "image/png", "createdAt": %q},
+				{"id": "run-1/b.pdf", "contentType": "application/pdf", "createdAt": %q},
+				{"id": "other.png", "contentType": "image/png", "createdAt": %[1]q}
+			], "nextCursor": "c1"}`, created(48*time.Hour), created(72*time.Hour))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"files": [
+			{"id": "run-1/c.png", "contentType": "image/png; q=1", "createdAt": %q},
+			{"id": "run-1/d.png", "contentType": "image/png"}
+		]}`, created(time.Hour))
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	files, err := c.ListFiles(context.Background(), ListFilesOptions{Prefix: "run-1/", ContentType: "image/png"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"run-1/a.png", "run-1/c.png", "run-1/d.png"}, fileInfoIDs(files))
+	assert.Equal(t, "contentType=image%2Fpng&limit=100&prefix=run-1%2F", queries[0])
+
+	queries = nil
+	files, err = c.ListFiles(context.Background(), ListFilesOptions{Prefix: "run-1/", OlderThan: 24 * time.Hour, PageSize: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"run-1/a.png", "run-1/b.pdf"}, fileInfoIDs(files))
+	assert.Contains(t, queries[0], "createdBefore=")
+	assert.Contains(t, queries[0], "limit=10")
+
+	files, err = c.ListFiles(context.Background(), ListFilesOptions{NewerThan: 60 * time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"run-1/a.png", "other.png", "run-1/c.png"}, fileInfoIDs(files))
+}
+
+func fileInfoIDs(files []FileInfo) []string {
+	ids := make([]string, len(files))
+	for i, f := range files {
+		ids[i] = f.ID
+	}
+	return ids
+}
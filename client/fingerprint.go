@@ -0,0 +1,104 @@
+package client
+
+import (
+	"errors"
+	"sync"
+)
+
+// FingerprintProfile is a browser fingerprint applied to a plugin run.
+type FingerprintProfile struct {
+	UserAgent string
+	Platform  string
+	Languages []string
+	Screen    Screen
+	Fonts     []string
+}
+
+// Screen describes the screen reported by the browser.
+type Screen struct {
+	Width      int
+	Height     int
+	PixelRatio float64
+}
+
+func (p FingerprintProfile) params() map[string]any {
+	return map[string]any{
+		"userAgent": p.UserAgent,
+		"platform":  p.Platform,
+		"languages": p.Languages,
+		"screen": map[string]any{
+			"width":      p.Screen.Width,
+			"height":     p.Screen.Height,
+			"pixelRatio": p.Screen.PixelRatio,
+		},
+		"fonts": p.Fonts,
+	}
+}
+
+// FingerprintRotator hands out fingerprint profiles according to
+// a rotation strategy. It is safe for concurrent use.
+type FingerprintRotator struct {
+	strategy RotationStrategy
+	profiles []FingerprintProfile
+
+	mu       sync.Mutex
+	next     int
+	byDomain map[string]int
+}
+
+// NewFingerprintRotator creates a rotator over the given profiles.
+func NewFingerprintRotator(
+	strategy RotationStrategy,
+	profiles ...FingerprintProfile,
+) (*FingerprintRotator, error) {
+	if len(profiles) == 0 {
+		return nil, errors.New("at least one fingerprint profile is required")
+	}
+	return &FingerprintRotator{
+		strategy: strategy,
+		profiles: profiles,
+		byDomain: make(map[string]int),
+	}, nil
+}
+
+// WithFingerprints applies profiles from r to every plugin run
+// that does not set a "fingerprint" param explicitly.
+func WithFingerprints(r *FingerprintRotator) Option {
+	return func(c *Client) {
+		c.fingerprints = r
+	}
+}
+
+// Next returns the profile to use for a run against domain.
+func (r *FingerprintRotator) Next(domain string) FingerprintProfile {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.strategy == StickyPerDomain && domain != "" {
+		if i, ok := r.byDomain[domain]; ok {
+			return r.profiles[i]
+		}
+	}
+	return r.advance(domain)
+}
+
+// Rotate discards the profile assigned to domain, if any,
+// and returns a different one.
+func (r *FingerprintRotator) Rotate(domain string) FingerprintProfile {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if i, ok := r.byDomain[domain]; ok && r.next == i {
+		r.next = (r.next + 1) % len(r.profiles)
+	}
+	return r.advance(domain)
+}
+
+func (r *FingerprintRotator) advance(domain string) FingerprintProfile {
+	i := r.next
+	r.next = (r.next + 1) % len(r.profiles)
+	if r.strategy == StickyPerDomain && domain != "" {
+		r.byDomain[domain] = i
+	}
+	return r.profiles[i]
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testProfiles = []FingerprintProfile{
+	{UserAgent: "ua-1", Platform: "Win32", Languages: []string{"en-US"}},
+	{UserAgent: "ua-2", Platform: "MacIntel", Languages: []string{"de-DE"}},
+	{UserAgent: "ua-3", Platform: "Linux x86_64", Languages: []string{"fr-FR"}},
+}
+
+func TestNewFingerprintRotator(t *testing.T) {
+	r, err := NewFingerprintRotator(RoundRobin)
+	require.Error(t, err)
+	require.Nil(t, r)
+}
+
+func TestFingerprintRotator_Next(t *testing.T) {
+	t.Run("round robin", func(t *testing.T) {
+		r, err := NewFingerprintRotator(RoundRobin, testProfiles...)
+		require.NoError(t, err)
+
+		assert.Equal(t, "ua-1", r.Next("a.com").UserAgent)
+		assert.Equal(t, "ua-2", r.Next("a.com").UserAgent)
+		assert.Equal(t, "ua-3", r.Next("b.com").UserAgent)
+		assert.Equal(t, "ua-1", r.Next("a.com").UserAgent)
+	})
+
+	t.Run("sticky per domain", func(t *testing.T) {
+		r, err := NewFingerprintRotator(StickyPerDomain, testProfiles...)
+		require.NoError(t, err)
+
+		assert.Equal(t, "ua-1", r.Next("a.com").UserAgent)
+		assert.Equal(t, "ua-2", r.Next("b.com").UserAgent)
+		assert.Equal(t, "ua-1", r.Next("a.com").UserAgent)
+		assert.Equal(t, "ua-3", r.Next("").UserAgent)
+	})
+}
+
+func TestFingerprintRotator_Rotate(t *testing.T) {
+	r, err := NewFingerprintRotator(StickyPerDomain, testProfiles...)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ua-1", r.Next("a.com").UserAgent)
+	assert.Equal(t, "ua-2", r.Rotate("a.com").UserAgent)
+	assert.Equal(t, "ua-2", r.Next("a.com").UserAgent)
+}
+
+func TestWithFingerprints(t *testing.T) {
+	r, err := NewFingerprintRotator(StickyPerDomain, testProfiles...)
+	require.NoError(t, err)
+
+	var params map[string]any
+	server := captureServer(t, http.StatusOK, `{}`, &params)
+	defer server.Close()
+
+	c, err := New(server.URL, nil, WithFingerprints(r))
+	require.NoError(t, err)
+
+	t.Run("applied automatically", func(t *testing.T) {
+		_, err = c.RunPlugin(context.Background(), "screenshot", map[string]any{
+			"urls": []string{"https://Example.com/page"},
+		})
+		require.NoError(t, err)
+		fp := params["fingerprint"].(map[string]any)
+		assert.Equal(t, "ua-1", fp["userAgent"])
+		assert.Equal(t, "Win32", fp["platform"])
+	})
+
+	t.Run("explicit param wins", func(t *testing.T) {
+		_, err = c.RunPlugin(context.Background(), "screenshot", map[string]any{
+			"urls":        []string{"https://example.com"},
+			"fingerprint": "custom",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "custom", params["fingerprint"])
+	})
+}
+
+func TestTargetDomain(t *testing.T) {
+	assert.Equal(t, "example.com", targetDomain(map[string]any{"url": "https://example.com/x"}))
+	assert.Equal(t, "a.com", targetDomain(map[string]any{"urls": []string{"http://a.com", "http://b.com"}}))
+	assert.Equal(t, "a.com", targetDomain(map[string]any{"urls": []any{"http://A.com"}}))
+	assert.Equal(t, "", targetDomain(map[string]any{"query": "golang"}))
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GraphQLError is returned by GraphQL when the server reports errors
+// in the response. Data that could be resolved is still decoded.
+type GraphQLError struct {
+	Errors []GraphQLErrorItem
+}
+
+// GraphQLErrorItem is a single error reported by the GraphQL endpoint.
+type GraphQLErrorItem struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+func (e *GraphQLError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, item := range e.Errors {
+		msgs = append(msgs, item.Message)
+	}
+	return "graphql: " + strings.Join(msgs, "; ")
+}
+
+// GraphQL runs a query against the server's GraphQL endpoint and decodes
+// the "data" field of the response into out. It lets dashboards select
+// only the fields they need from plugin metadata and job or file listings.
+func (c *Client) GraphQL(
+	ctx context.Context,
+	query string,
+	variables map[string]any,
+	out any,
+) (err error) {
+	ctx, op := c.startOperation(ctx, "GraphQL")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	body, err := json.Marshal(GraphQLRequest{
+		Query:     query,
+		Variables: variables,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to JSON encode query: %w", err)
+	}
+	body, contentType, err := c.sealBody(ctx, body)
+	if err != nil {
+		return err
+	}
+	req, err := newBodyRequest(ctx, http.MethodPost, c.addr+"/graphql", contentType, body)
+	if err != nil {
+		return fmt.Errorf("failed to create graphql request: %w", err)
+	}
+	if c.kms != nil {
+		req.Header.Set("Accept", EnvelopeContentType)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to run graphql query: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return c.newAPIError(resp)
+	}
+
+	var payload struct {
+		Data   json.RawMessage    `json:"data"`
+		Errors []GraphQLErrorItem `json:"errors"`
+	}
+	if err := c.decodeBody(ctx, resp, &payload); err != nil {
+		return fmt.Errorf("failed to decode graphql response: %w", err)
+	}
+	if out != nil && len(payload.Data) > 0 && string(payload.Data) != "null" {
+		if err := json.Unmarshal(payload.Data, out); err != nil {
+			return fmt.Errorf("failed to decode graphql data: %w", err)
+		}
+	}
+	if len(payload.Errors) > 0 {
+		return &GraphQLError{Errors: payload.Errors}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GraphQL(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/graphql", r.URL.Path)
+			var req map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "query($n: Int) { plugins(first: $n) { name } }", req["query"])
+			assert.Equal(t, map[string]any{"n": 2.0}, req["variables"])
+			_, _ = w.Write([]byte(`{"data": {"plugins": [{"name": "screenshot"}, {"name": "googlesearch"}]}}`))
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		var out struct {
+			Plugins []struct {
+				Name string `json:"name"`
+			} `json:"plugins"`
+		}
+		err = c.GraphQL(
+			context.Background(),
+			"query($n: Int) { plugins(first: $n) { name } }",
+			map[string]any{"n": 2},
+			&out,
+		)
+		require.NoError(t, err)
+		require.Len(t, out.Plugins, 2)
+		assert.Equal(t, "screenshot", out.Plugins[0].Name)
+	})
+
+	t.Run("graphql errors with partial data", func(t *testing.T) {
+		server := mockServer(
+			t,
+			http.StatusOK,
+			`{"data": {"files": null, "plugins": []}, "errors": [{"message": "files: forbidden", "path": ["files"]}]}`,
+		)
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		var out map[string]any
+		err = c.GraphQL(context.Background(), "{ files { id } plugins { name } }", nil, &out)
+		var gqlErr *GraphQLError
+		require.True(t, errors.As(err, &gqlErr))
+		assert.EqualError(t, err, "graphql: files: forbidden")
+		assert.Equal(t, []any{"files"}, gqlErr.Errors[0].Path)
+		assert.Contains(t, out, "plugins")
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, "")
+		server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		err = c.GraphQL(context.Background(), "{}", nil, nil)
+		require.ErrorContains(t, err, "failed to run graphql query:")
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		server := mockServer(t, http.StatusNotFound, "")
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		err = c.GraphQL(context.Background(), "{}", nil, nil)
+		require.EqualError(t, err, "unexpected response status: 404 Not Found")
+	})
+
+	t.Run("invalid server response body", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, "")
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		err = c.GraphQL(context.Background(), "{}", nil, nil)
+		require.ErrorContains(t, err, "failed to decode graphql response:")
+	})
+}
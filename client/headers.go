@@ -0,0 +1,58 @@
+package client
+
+import (
+	"maps"
+	"net/http"
+	"strings"
+)
+
+// WithExtraHeaders sets HTTP headers the browser sends with every
+// navigation during the run. Multiple values of a header are joined
+// with ", ". The headers are merged with those of the params, given as
+// a map[string]string or a map[string]any of strings, and with those
+// of earlier calls.
+func WithExtraHeaders(h http.Header) RunOption {
+	return func(cfg *runConfig) {
+		// The headers may belong to the caller's params, so they are
+		// copied rather than modified.
+		headers := make(map[string]string, len(h))
+		switch existing := cfg.params["headers"].(type) {
+		case nil:
+		case map[string]string:
+			maps.Copy(headers, existing)
+		case map[string]any:
+			for name, v := range existing {
+				value, ok := v.(string)
+				if !ok {
+					cfg.invalid("headers."+name, CodeInvalid, "header value must be a string")
+					continue
+				}
+				headers[name] = value
+			}
+		default:
+			cfg.invalid("headers", CodeInvalid, "headers must be an object of strings")
+			return
+		}
+		cfg.params["headers"] = headers
+		for name, values := range h {
+			if !validHeaderName(name) {
+				cfg.invalid("headers."+name, CodeInvalid, "invalid header name")
+				continue
+			}
+			headers[http.CanonicalHeaderKey(name)] = strings.Join(values, ", ")
+		}
+	}
+}
+
+// validHeaderName reports whether name is a non-empty RFC 7230 token.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r >= 0x7f || r <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithExtraHeaders(t *testing.T) {
+	var params map[string]any
+	server := captureServer(t, http.StatusOK, `{}`, &params)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = c.RunPlugin(context.Background(),
+		"screenshot",
+		nil,
+		WithExtraHeaders(http.Header{"authorization": {"Bearer abc"}}),
+		WithExtraHeaders(http.Header{"X-Cohort": {"a", "b"}}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"Authorization": "Bearer abc",
+		"X-Cohort":      "a, b",
+	}, params["headers"])
+}
+
+func TestWithExtraHeaders_CallerParams(t *testing.T) {
+	var params map[string]any
+	server := captureServer(t, http.StatusOK, `{}`, &params)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	headers := map[string]string{"Accept-Language": "en"}
+	_, err = c.RunPlugin(context.Background(),
+		"screenshot",
+		map[string]any{"headers": headers},
+		WithExtraHeaders(http.Header{"X-Cohort": {"a"}}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Accept-Language": "en"}, headers)
+	assert.Equal(t, map[string]any{"Accept-Language": "en", "X-Cohort": "a"}, params["headers"])
+}
+
+func TestWithExtraHeaders_CallerParamsAny(t *testing.T) {
+	var params map[string]any
+	server := captureServer(t, http.StatusOK, `{}`, &params)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = c.RunPlugin(context.Background(),
+		"screenshot",
+		map[string]any{"headers": map[string]any{"Accept-Language": "en"}},
+		WithExtraHeaders(http.Header{"X-Cohort": {"a"}}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Accept-Language": "en", "X-Cohort": "a"}, params["headers"])
+
+	for _, headers := range []any{map[string]any{"X-Count": 1}, []string{"X-Cohort"}} {
+		_, err = c.RunPlugin(context.Background(),
+			"screenshot",
+			map[string]any{"headers": headers},
+			WithExtraHeaders(http.Header{"X-Cohort": {"a"}}),
+		)
+		var verr *ValidationError
+		require.ErrorAs(t, err, &verr)
+	}
+}
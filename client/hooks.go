@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// RequestIDHeader carries the ID of a client call on every request the
+// call sends. A server may answer with its own ID in the same header.
+const RequestIDHeader = "X-Request-ID"
+
+// Operation describes a single client call.
+type Operation struct {
+	// Name is the client method, e.g. "RunPlugin".
+	Name string
+	// Plugin is the plugin a run call executed.
+	Plugin string
+	// FileID is the file a file call accessed.
+	FileID string
+	// Attempts is the number of requests the call sent.
+	Attempts int
+	// RequestID is the ID sent in RequestIDHeader, or the ID the
+	// server answered with.
+	RequestID string
+	// Metadata is the metadata of the call's context.
+	Metadata Metadata
+}
+
+// ErrorHook is called once for every failed client call, after all of
+// its attempts, with the context of the call.
+type ErrorHook func(ctx context.Context, op Operation, err error)
+
+// WithErrorHook registers hook to be called for every failed call,
+// e.g. to report errors to a telemetry backend in one place.
+func WithErrorHook(hook ErrorHook) Option {
+	return func(c *Client) {
+		c.errorHook = hook
+	}
+}
+
+type operationKey struct{}
+
+// operation is an Operation in progress.
+type operation struct {
+	// mu guards Attempts and RequestID, which requests sent in parallel
+	// by a single call update concurrently.
+	mu sync.Mutex
+	Operation
+	// params and result are the call's payloads, hashed by the audit log.
+	params any
+	result any
+}
+
+// startOperation returns a context carrying a new operation for a call
+// of the named method. Requests sent with c.do under that context are
+// counted as attempts of the operation.
+func (c *Client) startOperation(ctx context.Context, name string) (context.Context, *operation) {
+	op := &operation{Operation: Operation{
+		Name:      name,
+		RequestID: newEventID(),
+		Metadata:  MetadataFromContext(ctx),
+	}}
+	return context.WithValue(ctx, operationKey{}, op), op
+}
+
+// operationFrom returns the operation in ctx, or a detached one
+// when ctx has none.
+func operationFrom(ctx context.Context) *operation {
+	if op, ok := ctx.Value(operationKey{}).(*operation); ok {
+		return op
+	}
+	return &operation{}
+}
+
+// finishOperation reports err to the error hook and records the call
+// in the audit log, if any.
+func (c *Client) finishOperation(ctx context.Context, op *operation, err error) {
+	if err != nil && c.errorHook != nil {
+		c.errorHook(ctx, op.Operation, err)
+	}
+	if c.audit != nil {
+		c.audit.record(op, err)
+	}
+}
+
+// do sends req as an attempt of the operation in its context.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if err := c.checkVersion(req, nil); err != nil {
+		return nil, err
+	}
+	if err := c.authorize(req.Context(), req.Header); err != nil {
+		return nil, err
+	}
+	MetadataFromContext(req.Context()).setHeaders(req.Header)
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	op, _ := req.Context().Value(operationKey{}).(*operation)
+	// The cache is consulted once per request: retries carry the
+	// validators added here and are answered from the same entry.
+	cached := c.cache != nil && cacheable(req)
+	var entry *cacheEntry
+	if cached {
+		entry = c.cache.prepare(req)
+	}
+	if c.retry != nil {
+		return c.retry.do(req, func(req *http.Request) (*http.Response, error) {
+			return c.send(req, op, cached, entry)
+		})
+	}
+	return c.send(req, op, cached, entry)
+}
+
+// send sends a single attempt of req. If cached is set, the response is
+// served from or stored in the cache; entry is the cached response
+// whose validators req carries, if any.
+func (c *Client) send(req *http.Request, op *operation, cached bool, entry *cacheEntry) (*http.Response, error) {
+	if c.pacer != nil {
+		if err := c.pacer.wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+	if op != nil {
+		op.mu.Lock()
+		op.Attempts++
+		req.Header.Set(RequestIDHeader, op.RequestID)
+		op.mu.Unlock()
+	}
+	var call *RecordedCall
+	if c.recorder != nil {
+		call = c.recorder.record(c, req, op)
+	}
+	resp, err := c.client.Do(req)
+	if call != nil {
+		c.recorder.finish(call, resp, err)
+	}
+	if resp != nil && c.pacer != nil {
+		c.pacer.update(resp)
+	}
+	if resp != nil && cached {
+		resp = c.cache.handle(req, resp, entry)
+	}
+	if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+		c.rejected()
+	}
+	if resp != nil && c.logger != nil {
+		if d := parseDeprecation(resp.Header); d != nil {
+			c.logDeprecation(req, d)
+		}
+	}
+	if resp != nil {
+		if err := c.checkVersion(req, resp.Header); err != nil {
+			closeBody(resp.Body)
+			return nil, err
+		}
+	}
+	if op != nil && resp != nil {
+		if id := resp.Header.Get(RequestIDHeader); id != "" {
+			op.mu.Lock()
+			op.RequestID = id
+			op.mu.Unlock()
+		}
+	}
+	return resp, err
+}
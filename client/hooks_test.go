@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hookCall struct {
+	op  Operation
+	err error
+}
+
+type hookRecorder struct {
+	mu    sync.Mutex
+	calls []hookCall
+}
+
+func (r *hookRecorder) hook(ctx context.Context, op Operation, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, hookCall{op: op, err: err})
+}
+
+func TestWithErrorHook(t *testing.T) {
+	t.Run("failed call", func(t *testing.T) {
+		var requestID string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID = r.Header.Get(RequestIDHeader)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		var rec hookRecorder
+		c, err := New(server.URL, nil, WithErrorHook(rec.hook))
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+		require.Error(t, err)
+
+		require.Len(t, rec.calls, 1)
+		assert.Equal(t, "RunPlugin", rec.calls[0].op.Name)
+		assert.Equal(t, 1, rec.calls[0].op.Attempts)
+		assert.NotEmpty(t, requestID)
+		assert.Equal(t, requestID, rec.calls[0].op.RequestID)
+		assert.Equal(t, err, rec.calls[0].err)
+	})
+
+	t.Run("server request id", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(RequestIDHeader, "srv-1")
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		var rec hookRecorder
+		c, err := New(server.URL, nil, WithErrorHook(rec.hook))
+		require.NoError(t, err)
+
+		require.Error(t, c.DeleteFile(context.Background(), "missing"))
+		require.Len(t, rec.calls, 1)
+		assert.Equal(t, Operation{Name: "DeleteFile", FileID: "missing", Attempts: 1, RequestID: "srv-1"}, rec.calls[0].op)
+	})
+
+	t.Run("attempts", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls > 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"blocked": true}`))
+		}))
+		defer server.Close()
+
+		var rec hookRecorder
+		c, err := New(server.URL, nil, WithErrorHook(rec.hook))
+		require.NoError(t, err)
+
+		_, err = c.RunPluginResult(context.Background(), "screenshot", nil, WithBotDetectionRetry(BotDetectionPolicy{
+			Detect: func(output map[string]any) bool { return output["blocked"] == true },
+		}))
+		require.Error(t, err)
+		require.Len(t, rec.calls, 1)
+		assert.Equal(t, "RunPluginResult", rec.calls[0].op.Name)
+		assert.Equal(t, 2, rec.calls[0].op.Attempts)
+	})
+
+	t.Run("successful call", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, `{"plugins": ["screenshot"]}`)
+		defer server.Close()
+
+		var rec hookRecorder
+		c, err := New(server.URL, nil, WithErrorHook(rec.hook))
+		require.NoError(t, err)
+
+		_, err = c.Plugins(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, rec.calls)
+	})
+}
@@ -0,0 +1,336 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultJobPollInterval is the interval WaitForJob polls the status of
+// a job at when no positive interval is given.
+const DefaultJobPollInterval = time.Second
+
+// JobID identifies an asynchronous plugin job on the server.
+type JobID string
+
+// JobState is the state of an asynchronous plugin job.
+type JobState string
+
+const (
+	JobQueued    JobState = "queued"
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+	JobCanceled  JobState = "canceled"
+)
+
+// Done reports whether the job of state s has finished, successfully or not.
+func (s JobState) Done() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCanceled
+}
+
+var (
+	// ErrJobNotFinished is returned by JobResult for jobs that are
+	// still queued or running.
+	ErrJobNotFinished = errors.New("job not finished")
+	// ErrJobFailed matches the *JobError of a failed job.
+	ErrJobFailed = errors.New("job failed")
+	// ErrJobCanceled matches the *JobError of a canceled job.
+	ErrJobCanceled = errors.New("job canceled")
+)
+
+// JobError is returned by WaitForJob when a job finished without success.
+type JobError struct {
+	Job *JobInfo
+}
+
+func (e *JobError) Error() string {
+	msg := fmt.Sprintf("job %s %s", e.Job.ID, e.Job.State)
+	if e.Job.Error != "" {
+		msg += ": " + e.Job.Error
+	}
+	return msg
+}
+
+func (e *JobError) Is(target error) bool {
+	switch target {
+	case ErrJobFailed:
+		return e.Job.State == JobFailed
+	case ErrJobCanceled:
+		return e.Job.State == JobCanceled
+	}
+	return false
+}
+
+// SubmitPlugin submits a run of a plugin as an asynchronous job and
+// returns its ID as soon as the server accepted it, so long-running
+// plugins don't tie up a connection. Params are checked like for
+// RunPlugin. Options that need the client to take part in the run, i.e.
+// bot detection policies, residency and output decoders, are rejected.
+// Use JobStatus and JobResult, or WaitForJob, to get the outcome, or
+// ArtifactManager.Submit to also collect the files of the job.
+func (c *Client) SubmitPlugin(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	opts ...RunOption,
+) (_ JobID, err error) {
+	ctx, op := c.startOperation(ctx, "SubmitPlugin")
+	defer func() { c.finishOperation(ctx, op, err) }()
+	op.Plugin = pluginName
+
+	cfg := newRunConfig(params, opts)
+	c.coerceParams(pluginName, cfg)
+	op.params = cfg.params
+	if err := cfg.validate(); err != nil {
+		return "", err
+	}
+	if err := cfg.checkJob(); err != nil {
+		return "", err
+	}
+	if err := c.checkPolicy(pluginName, cfg.params); err != nil {
+		return "", err
+	}
+	if err := c.applyRotation(cfg.params); err != nil {
+		return "", err
+	}
+
+	body, release, err := c.marshalParams(cfg.params)
+	if err != nil {
+		return "", fmt.Errorf("failed to JSON encode params: %w", err)
+	}
+	defer func() { release() }()
+	body, contentType, err := c.sealBody(ctx, body)
+	if err != nil {
+		return "", err
+	}
+	req, err := newBodyRequest(
+		ctx,
+		http.MethodPost,
+		c.addr+"/plugins/"+pluginName+"/jobs",
+		contentType,
+		body,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create job request: %w", err)
+	}
+	release = releaseAfterBody(req, release)
+	resp, err := c.do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to submit job: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return "", c.newAPIError(resp)
+	}
+
+	// Job metadata is never encrypted, only params and outputs are.
+	var info JobInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return "", fmt.Errorf("failed to decode job: %w", err)
+	}
+	if info.ID == "" {
+		return "", errors.New("server returned no job ID")
+	}
+	c.emit(EventJobSubmitted, pluginName, map[string]any{
+		"plugin": pluginName,
+		"jobId":  string(info.ID),
+	})
+	return info.ID, nil
+}
+
+// checkJob rejects the options of cfg that don't apply to jobs.
+func (cfg *runConfig) checkJob() error {
+	switch {
+	case cfg.botPolicy != nil:
+		return errors.New("bot detection policies are not supported for jobs")
+	case len(cfg.residency) > 0:
+		return errors.New("residency is not supported for jobs")
+	case cfg.output != nil:
+		return errors.New("output decoders are not supported for jobs")
+	}
+	return nil
+}
+
+// JobStatus fetches the state of a job.
+func (c *Client) JobStatus(ctx context.Context, jobID JobID) (_ *JobInfo, err error) {
+	ctx, op := c.startOperation(ctx, "JobStatus")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	return c.jobStatus(ctx, jobID)
+}
+
+func (c *Client) jobStatus(ctx context.Context, jobID JobID) (*JobInfo, error) {
+	u, err := c.jobURL(jobID)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job status request: %w", err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch job status: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.newAPIError(resp)
+	}
+
+	var info JobInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to decode job status: %w", err)
+	}
+	return &info, nil
+}
+
+// JobResult fetches the outcome of a finished job. It returns an error
+// matching ErrJobNotFinished while the job is queued or running, and
+// an *APIError if the job failed. Like for RunPlugin, the result is
+// written to the client's result sink and published.
+func (c *Client) JobResult(ctx context.Context, jobID JobID) (_ *RunResult, err error) {
+	ctx, op := c.startOperation(ctx, "JobResult")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	return c.jobResult(ctx, jobID, "")
+}
+
+// jobResult fetches the result of a job of the given plugin, if known,
+// as the server may not report it.
+func (c *Client) jobResult(ctx context.Context, jobID JobID, pluginName string) (*RunResult, error) {
+	u, err := c.jobURL(jobID)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u+"/result", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job result request: %w", err)
+	}
+	if c.kms != nil {
+		req.Header.Set("Accept", EnvelopeContentType)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch job result: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusAccepted:
+		var info JobInfo
+		if err := json.NewDecoder(resp.Body).Decode(&info); err != nil || info.State == "" {
+			return nil, fmt.Errorf("job %s: %w", jobID, ErrJobNotFinished)
+		}
+		return nil, fmt.Errorf("job %s is %s: %w", jobID, info.State, ErrJobNotFinished)
+	default:
+		return nil, c.newAPIError(resp)
+	}
+
+	var output map[string]any
+	if err := c.decodeBody(ctx, resp, &output); err != nil {
+		return nil, fmt.Errorf("failed to decode job result: %w", err)
+	}
+	if p := resp.Header.Get("X-Plugin"); p != "" {
+		pluginName = p
+	}
+	result := c.newRunResult(pluginName, resp.Header)
+	if result.JobID == "" {
+		result.JobID = string(jobID)
+	}
+	result.Output = output
+	c.scrubResult(result)
+	op := operationFrom(ctx)
+	op.Plugin = pluginName
+	op.result = result.Output
+	if err := c.deliverResult(ctx, pluginName, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// WaitForJob polls the status of a job every pollInterval, or every
+// DefaultJobPollInterval if it is not positive, until the job finished,
+// and returns its result like JobResult. A job that failed or was
+// canceled returns a *JobError. Canceling ctx stops the wait, not the job.
+func (c *Client) WaitForJob(ctx context.Context, jobID JobID, pollInterval time.Duration) (_ *RunResult, err error) {
+	ctx, op := c.startOperation(ctx, "WaitForJob")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	if pollInterval <= 0 {
+		pollInterval = DefaultJobPollInterval
+	}
+	for {
+		info, err := c.jobStatus(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+		op.Plugin = info.Plugin
+		switch {
+		case info.State == JobSucceeded:
+			return c.jobResult(ctx, jobID, info.Plugin)
+		case info.State.Done():
+			return nil, &JobError{Job: info}
+		}
+		if err := sleep(ctx, pollInterval); err != nil {
+			return nil, fmt.Errorf("failed to wait for job %s: %w", jobID, err)
+		}
+	}
+}
+
+// Jobs returns a Pager over the jobs on the server.
+func (c *Client) Jobs() *Pager[JobInfo] {
+	return NewPager(c.jobsPage)
+}
+
+func (c *Client) jobsPage(ctx context.Context, cursor string, pageSize int) (_ []JobInfo, _ string, err error) {
+	ctx, op := c.startOperation(ctx, "ListJobs")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	query := url.Values{}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	if pageSize > 0 {
+		query.Set("limit", strconv.Itoa(pageSize))
+	}
+	u := c.addr + "/jobs"
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create list request: %w", err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list jobs: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", c.newAPIError(resp)
+	}
+
+	var page JobsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, "", fmt.Errorf("failed to decode jobs: %w", err)
+	}
+	return page.Jobs, page.NextCursor, nil
+}
+
+// jobURL returns the URL of a job.
+func (c *Client) jobURL(jobID JobID) (string, error) {
+	if jobID == "" {
+		return "", errors.New("job ID is required")
+	}
+	return c.addr + "/jobs/" + url.PathEscape(string(jobID)), nil
+}
@@ -0,0 +1,239 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_SubmitPlugin(t *testing.T) {
+	var params map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/plugins/screenshot/jobs", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"id": "job-1", "plugin": "screenshot", "state": "queued"}`))
+	})
+	mux.HandleFunc("POST /api/v1/plugins/missing/jobs", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "plugin not found"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	id, err := c.SubmitPlugin(context.Background(), "screenshot", map[string]any{
+		"urls": []string{"https://example.com"},
+	}, WithWait(WaitCondition{NetworkIdle: true}))
+	require.NoError(t, err)
+	assert.Equal(t, JobID("job-1"), id)
+	assert.Equal(t, []any{"https://example.com"}, params["urls"])
+	assert.Equal(t, map[string]any{"networkIdle": true}, params["wait"])
+
+	_, err = c.SubmitPlugin(context.Background(), "missing", nil)
+	assert.ErrorIs(t, err, ErrPluginNotFound)
+
+	for msg, opt := range map[string]RunOption{
+		"bot detection policies are not supported for jobs": WithBotDetectionRetry(BotDetectionPolicy{
+			Detect: func(map[string]any) bool { return false },
+		}),
+		"residency is not supported for jobs":        WithResidency("eu"),
+		"output decoders are not supported for jobs": WithOutputFields("results"),
+	} {
+		_, err = c.SubmitPlugin(context.Background(), "screenshot", nil, opt)
+		assert.EqualError(t, err, msg)
+	}
+}
+
+func TestClient_JobResult(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/jobs/{id}/result", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("id") {
+		case "done":
+			w.Header().Set("X-Plugin", "googlesearch")
+			_, _ = w.Write([]byte(`{"results": ["a"]}`))
+		case "running":
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"id": "running", "state": "running"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"message": "navigation timeout"}`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	result, err := c.JobResult(context.Background(), "done")
+	require.NoError(t, err)
+	assert.Equal(t, "done", result.JobID)
+	assert.Equal(t, []any{"a"}, result.Output["results"])
+
+	_, err = c.JobResult(context.Background(), "running")
+	assert.ErrorIs(t, err, ErrJobNotFinished)
+	assert.ErrorContains(t, err, "is running")
+
+	_, err = c.JobResult(context.Background(), "failed")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "navigation timeout", apiErr.Message)
+
+	_, err = c.JobResult(context.Background(), "")
+	assert.Error(t, err)
+}
+
+func TestClient_JobResult_Publisher(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id": "job-1", "plugin": "screenshot", "state": "succeeded"}`))
+	})
+	mux.HandleFunc("GET /api/v1/jobs/{id}/result", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"screenshot": {"fileId": "f1"}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var events []ResultEvent
+	c, err := New(server.URL, nil, WithResultPublisher(publisherFunc(func(e ResultEvent) error {
+		events = append(events, e)
+		return nil
+	})))
+	require.NoError(t, err)
+
+	_, err = c.WaitForJob(context.Background(), "job-1", time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "screenshot", events[0].Plugin)
+	assert.Equal(t, "job-1", events[0].JobID)
+	assert.Equal(t, []string{"f1"}, events[0].FileIDs)
+}
+
+func TestClient_WaitForJob(t *testing.T) {
+	var polls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		state := JobRunning
+		switch {
+		case id == "canceled":
+			state = JobCanceled
+		case id == "slow":
+		case polls.Add(1) > 2:
+			state = JobSucceeded
+		}
+		_, _ = fmt.Fprintf(w, `{"id": %q, "plugin": "screenshot", "state": %q, "error": "by user"}`, id, state)
+	})
+	mux.HandleFunc("GET /api/v1/jobs/{id}/result", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"fileIds": ["a.png"]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	result, err := c.WaitForJob(context.Background(), "job-1", time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), polls.Load())
+	assert.Equal(t, []any{"a.png"}, result.Output["fileIds"])
+
+	_, err = c.WaitForJob(context.Background(), "canceled", time.Millisecond)
+	var jobErr *JobError
+	require.ErrorAs(t, err, &jobErr)
+	assert.ErrorIs(t, err, ErrJobCanceled)
+	assert.NotErrorIs(t, err, ErrJobFailed)
+	assert.Equal(t, "job canceled canceled: by user", err.Error())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = c.WaitForJob(ctx, "slow", time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClient_Jobs(t *testing.T) {
+	var queries []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/jobs", func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		if r.URL.Query().Get("cursor") == "" {
+			_, _ = w.Write([]byte(`{"jobs": [{"id": "job-1", "state": "running"}], "nextCursor": "c1"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"jobs": [{"id": "job-2", "state": "succeeded"}]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	jobs, err := c.Jobs().PageSize(1).All(context.Background())
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, JobID("job-1"), jobs[0].ID)
+	assert.Equal(t, JobSucceeded, jobs[1].State)
+	assert.Equal(t, []string{"limit=1", "cursor=c1&limit=1"}, queries)
+}
+
+func TestJobInfo_JSON(t *testing.T) {
+	var info JobInfo
+	require.NoError(t, json.Unmarshal([]byte(`{"id": "job-1", "state": "running", "startedAt": "2024-05-01T00:00:00Z"}`), &info))
+	require.NotNil(t, info.StartedAt)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), *info.StartedAt)
+	assert.Nil(t, info.FinishedAt)
+
+	data, err := json.Marshal(JobInfo{ID: "job-1", State: JobQueued})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": "job-1", "state": "queued"}`, string(data))
+}
+
+func TestJobState_Done(t *testing.T) {
+	assert.False(t, JobQueued.Done())
+	assert.False(t, JobRunning.Done())
+	assert.True(t, JobSucceeded.Done())
+	assert.True(t, JobFailed.Done())
+	assert.True(t, JobCanceled.Done())
+}
+
+func TestClient_Jobs_PayloadEncryption(t *testing.T) {
+	kms := newTestKMS(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/plugins/googlesearch/jobs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, EnvelopeContentType, r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"id": "job-1", "state": "queued"}`))
+	})
+	mux.HandleFunc("GET /api/v1/jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id": "job-1", "state": "succeeded"}`))
+	})
+	mux.HandleFunc("GET /api/v1/jobs/{id}/result", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, EnvelopeContentType, r.Header.Get("Accept"))
+		sealed, err := SealEnvelope(r.Context(), kms, []byte(`{"googlesearch": "golang"}`))
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", EnvelopeContentType)
+		_ = json.NewEncoder(w).Encode(sealed)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := New(server.URL, nil, WithPayloadEncryption(kms))
+	require.NoError(t, err)
+
+	id, err := c.SubmitPlugin(context.Background(), "googlesearch", map[string]any{"query": "golang"})
+	require.NoError(t, err)
+	result, err := c.WaitForJob(context.Background(), id, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"googlesearch": "golang"}, result.Output)
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PendingJob is a submitted job whose artifacts were not all collected.
+type PendingJob struct {
+	ID          string    `json:"id"`
+	Plugin      string    `json:"plugin,omitempty"`
+	SubmittedAt time.Time `json:"submittedAt"`
+	// Files are the IDs of the expected artifacts not yet deleted from
+	// the server. It is empty for a job still running.
+	Files []string `json:"files,omitempty"`
+	// Downloaded are the IDs of the files in Files already downloaded.
+	Downloaded []string `json:"downloaded,omitempty"`
+}
+
+// JobStore persists the pending jobs of an ArtifactManager, so a restarted
+// process can resume collecting their artifacts; see WithJobStore.
+type JobStore interface {
+	// Put creates or replaces a job.
+	Put(job PendingJob) error
+	Delete(jobID string) error
+	List() ([]PendingJob, error)
+}
+
+// FileJobStore is a JobStore keeping every job in a JSON file of its
+// directory. Files are replaced atomically, so a crash never leaves a
+// partially written job behind.
+type FileJobStore struct {
+	dir string
+}
+
+// NewFileJobStore creates a FileJobStore in dir, creating it if needed.
+func NewFileJobStore(dir string) (*FileJobStore, error) {
+	if dir == "" {
+		return nil, errors.New("directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create job store directory: %w", err)
+	}
+	return &FileJobStore{dir: dir}, nil
+}
+
+// Put implements JobStore.
+func (s *FileJobStore) Put(job PendingJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+	f, err := os.CreateTemp(s.dir, ".job-*")
+	if err != nil {
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.path(job.ID))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	return nil
+}
+
+// Delete implements JobStore. Deleting a missing job is not an error.
+func (s *FileJobStore) Delete(jobID string) error {
+	if err := os.Remove(s.path(jobID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+	return nil
+}
+
+// List implements JobStore. Jobs are sorted by submission time.
+func (s *FileJobStore) List() ([]PendingJob, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	jobs := make([]PendingJob, 0, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read job: %w", err)
+		}
+		var job PendingJob
+		if err := json.Unmarshal(data, &job); err != nil {
+			return nil, fmt.Errorf("failed to decode job %s: %w", filepath.Base(p), err)
+		}
+		jobs = append(jobs, job)
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].SubmittedAt.Before(jobs[j].SubmittedAt)
+	})
+	return jobs, nil
+}
+
+// path returns the file of a job; IDs are escaped to stay in the directory.
+func (s *FileJobStore) path(jobID string) string {
+	name := strings.ReplaceAll(url.PathEscape(jobID), ".", "%2E")
+	return filepath.Join(s.dir, name+".json")
+}
+
+// newJobID returns a random ID for jobs without a server-assigned one.
+func newJobID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "local-" + hex.EncodeToString(b)
+}
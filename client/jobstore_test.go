@@ -0,0 +1,46 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileJobStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileJobStore(dir)
+	require.NoError(t, err)
+
+	first := PendingJob{ID: "job/1", Plugin: "screenshot", SubmittedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Files: []string{"f1"}}
+	second := PendingJob{ID: "..", SubmittedAt: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)}
+	require.NoError(t, s.Put(second))
+	require.NoError(t, s.Put(first))
+	first.Downloaded = []string{"f1"}
+	require.NoError(t, s.Put(first))
+
+	jobs, err := s.List()
+	require.NoError(t, err)
+	assert.Equal(t, []PendingJob{first, second}, jobs)
+
+	// IDs never escape the directory.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	require.NoError(t, s.Delete("job/1"))
+	require.NoError(t, s.Delete("missing"))
+	jobs, err = s.List()
+	require.NoError(t, err)
+	assert.Equal(t, []PendingJob{second}, jobs)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o644))
+	_, err = s.List()
+	require.ErrorContains(t, err, "failed to decode job broken.json")
+
+	_, err = NewFileJobStore("")
+	require.EqualError(t, err, "directory is required")
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Headers carrying the Metadata of a call.
+const (
+	TenantHeader      = "X-Tenant-ID"
+	PriorityHeader    = "X-Priority"
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+// Priority is the scheduling priority of a call, as requested by the caller.
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityNormal Priority = "normal"
+	PriorityHigh   Priority = "high"
+)
+
+// Metadata is caller information carried by the context of a call. The
+// client forwards it to the server in request headers, to error hooks in
+// Operation.Metadata and to the audit log, so that cross-cutting policies
+// such as per-tenant quotas need no change to method signatures.
+type Metadata struct {
+	Tenant   string
+	Priority Priority
+	// TraceParent and TraceState are W3C Trace Context values.
+	TraceParent string
+	TraceState  string
+}
+
+type metadataKey struct{}
+
+// ContextWithTenant returns a copy of ctx whose calls are made on behalf of tenant.
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	md := MetadataFromContext(ctx)
+	md.Tenant = tenant
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// ContextWithPriority returns a copy of ctx whose calls have priority p.
+func ContextWithPriority(ctx context.Context, p Priority) context.Context {
+	md := MetadataFromContext(ctx)
+	md.Priority = p
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// ContextWithTrace returns a copy of ctx whose calls belong to the trace
+// identified by the W3C traceparent and tracestate values.
+func ContextWithTrace(ctx context.Context, traceParent, traceState string) context.Context {
+	md := MetadataFromContext(ctx)
+	md.TraceParent = traceParent
+	md.TraceState = traceState
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// MetadataFromContext returns the metadata set on ctx.
+func MetadataFromContext(ctx context.Context) Metadata {
+	md, _ := ctx.Value(metadataKey{}).(Metadata)
+	return md
+}
+
+// Labels returns the set tenant and priority, keyed by "tenant" and
+// "priority", for use as metrics labels. Trace values are left out as
+// they are unique per call.
+func (m Metadata) Labels() map[string]string {
+	labels := make(map[string]string, 2)
+	if m.Tenant != "" {
+		labels["tenant"] = m.Tenant
+	}
+	if m.Priority != "" {
+		labels["priority"] = string(m.Priority)
+	}
+	return labels
+}
+
+// setHeaders sets the headers of the set metadata values that h lacks.
+func (m Metadata) setHeaders(h http.Header) {
+	set := func(key, value string) {
+		if value != "" && h.Get(key) == "" {
+			h.Set(key, value)
+		}
+	}
+	set(TenantHeader, m.Tenant)
+	set(PriorityHeader, string(m.Priority))
+	set(TraceParentHeader, m.TraceParent)
+	set(TraceStateHeader, m.TraceState)
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataFromContext(t *testing.T) {
+	assert.Equal(t, Metadata{}, MetadataFromContext(context.Background()))
+
+	ctx := ContextWithTenant(context.Background(), "acme")
+	ctx = ContextWithPriority(ctx, PriorityHigh)
+	ctx = ContextWithTrace(ctx, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "vendor=1")
+	md := MetadataFromContext(ctx)
+	assert.Equal(t, Metadata{
+		Tenant:      "acme",
+		Priority:    PriorityHigh,
+		TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		TraceState:  "vendor=1",
+	}, md)
+	assert.Equal(t, map[string]string{"tenant": "acme", "priority": "high"}, md.Labels())
+	assert.Empty(t, Metadata{}.Labels())
+}
+
+func TestMetadataPropagation(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var audit bytes.Buffer
+	var hookOp Operation
+	c, err := New(server.URL, nil,
+		WithAuditLog(NewAuditLog(&audit, "tester")),
+		WithErrorHook(func(_ context.Context, op Operation, _ error) { hookOp = op }),
+	)
+	require.NoError(t, err)
+
+	ctx := ContextWithPriority(ContextWithTenant(context.Background(), "acme"), PriorityLow)
+	ctx = ContextWithTrace(ctx, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "")
+	err = c.GraphQL(ctx, "{ plugins }", nil, nil)
+	require.Error(t, err)
+
+	assert.Equal(t, "acme", headers.Get(TenantHeader))
+	assert.Equal(t, "low", headers.Get(PriorityHeader))
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", headers.Get(TraceParentHeader))
+	assert.Empty(t, headers.Get(TraceStateHeader))
+
+	assert.Equal(t, Metadata{
+		Tenant:      "acme",
+		Priority:    PriorityLow,
+		TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}, hookOp.Metadata)
+
+	var rec AuditRecord
+	require.NoError(t, json.Unmarshal(audit.Bytes(), &rec))
+	assert.Equal(t, "acme", rec.Tenant)
+}
@@ -0,0 +1,140 @@
+package client
+
+import "time"
+
+// Wire types of the BrowserBro HTTP API. They mirror the request and
+// response schemas of the server's API and are used for all encoding
+// and decoding in this package, so any contract change shows up here.
+
+// PluginsResponse is the response body of GET /plugins.
+type PluginsResponse struct {
+	Plugins []string `json:"plugins"`
+}
+
+// ErrorResponse is the response body the server sends with non-200 statuses.
+type ErrorResponse struct {
+	Message string `json:"message"`
+	// Code is a machine-readable error code, e.g. "invalid_param".
+	Code string `json:"code,omitempty"`
+	// Param names the offending parameter, if any.
+	Param string `json:"param,omitempty"`
+	// Hint suggests how to fix the request.
+	Hint string `json:"hint,omitempty"`
+	// Errors lists per-field failures when several params are invalid.
+	Errors []FieldError `json:"errors,omitempty"`
+	// RequiredScopes lists the token scopes a 403 response is missing.
+	RequiredScopes []string `json:"requiredScopes,omitempty"`
+}
+
+// FieldError describes a single invalid request parameter.
+type FieldError struct {
+	Param   string `json:"param"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// SessionCookie is a cookie as stored in a browser session.
+type SessionCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Domain   string `json:"domain,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Expires  int64  `json:"expires,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+}
+
+// SessionCookies is the request and response body of
+// GET and PUT /sessions/{id}/cookies.
+type SessionCookies struct {
+	Cookies []SessionCookie `json:"cookies"`
+}
+
+// GraphQLRequest is the request body of POST /graphql.
+type GraphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+// JobRequest is a plugin job submitted through a message-based frontend.
+type JobRequest struct {
+	ID     string         `json:"id"`
+	Plugin string         `json:"plugin"`
+	Params map[string]any `json:"params,omitempty"`
+}
+
+// JobResponse is the outcome of a JobRequest.
+// Error is set instead of Output when the job failed.
+type JobResponse struct {
+	ID     string         `json:"id"`
+	Output map[string]any `json:"output,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// Envelope is an envelope-encrypted payload. Ciphertext is the payload
+// sealed with a data key, which is itself encrypted by a KMS key.
+type Envelope struct {
+	Algorithm    string `json:"alg"`
+	KeyID        string `json:"keyId"`
+	EncryptedKey []byte `json:"encryptedKey"`
+	Nonce        []byte `json:"nonce"`
+	Ciphertext   []byte `json:"ciphertext"`
+}
+
+// FileInfo describes a file stored on the server.
+type FileInfo struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name,omitempty"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"contentType,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// FilesResponse is the response body of GET /files.
+type FilesResponse struct {
+	Files []FileInfo `json:"files"`
+	// NextCursor identifies the next page; it is empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// JobInfo is the response body of POST /plugins/{name}/jobs and
+// GET /jobs/{id}. It describes an asynchronous plugin job.
+type JobInfo struct {
+	ID     JobID    `json:"id"`
+	Plugin string   `json:"plugin,omitempty"`
+	State  JobState `json:"state"`
+	// Error describes why the job failed or was canceled.
+	Error string `json:"error,omitempty"`
+	// SubmittedAt, StartedAt and FinishedAt are nil until the job
+	// reaches the respective state.
+	SubmittedAt *time.Time `json:"submittedAt,omitempty"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+}
+
+// JobsResponse is the response body of GET /jobs.
+type JobsResponse struct {
+	Jobs []JobInfo `json:"jobs"`
+	// NextCursor identifies the next page; it is empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// FileIDsRequest is the request body of POST /files/delete and
+// POST /files/archive.
+type FileIDsRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// DeleteFilesResponse is the response body of POST /files/delete.
+type DeleteFilesResponse struct {
+	Results []DeleteFileResult `json:"results"`
+}
+
+// DeleteFileResult is the outcome of deleting one of the files of
+// POST /files/delete. Status is the HTTP status the delete would have
+// had on its own, e.g. 404 for an unknown file.
+type DeleteFileResult struct {
+	ID     string         `json:"id"`
+	Status int            `json:"status"`
+	Error  *ErrorResponse `json:"error,omitempty"`
+}
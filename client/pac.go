@@ -0,0 +1,529 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// WithProxyFunc sets how the client picks proxies for its own
+// connections to the server, e.g. http.ProxyFromEnvironment or
+// PAC.Proxy. It requires the HTTP client to use an *http.Transport.
+// The HTTP client passed to New is not modified.
+func WithProxyFunc(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(c *Client) {
+		rt := c.client.Transport
+		if rt == nil {
+			rt = http.DefaultTransport
+		}
+		base, ok := rt.(*http.Transport)
+		if !ok {
+			c.optionError(fmt.Errorf("failed to set proxy: unsupported transport %T", rt))
+			return
+		}
+		t := base.Clone()
+		t.Proxy = proxy
+		client := *c.client
+		client.Transport = t
+		c.client = &client
+	}
+}
+
+// WithSystemProxy uses the proxy settings of the environment
+// (HTTPS_PROXY, HTTP_PROXY and NO_PROXY) for the client's own
+// connections. Operating system proxy settings are not read and PAC
+// files are not discovered through WPAD; a known PAC file can be used
+// through LoadPAC and WithProxyFunc.
+func WithSystemProxy() Option {
+	return WithProxyFunc(http.ProxyFromEnvironment)
+}
+
+// ErrUnsupportedPAC is returned by ParsePAC for PAC files using
+// JavaScript outside of the subset supported by PAC.
+var ErrUnsupportedPAC = errors.New("unsupported PAC construct")
+
+// PAC is a parsed proxy auto-configuration file.
+//
+// PAC files are not run by a JavaScript engine. Only a subset is
+// supported: a FindProxyForURL function made of if/else statements and
+// return statements, with conditions combining shExpMatch, dnsDomainIs,
+// localHostOrDomainIs and isPlainHostName by !, && and ||. Files using
+// other constructs, such as var declarations, or other PAC functions,
+// such as isInNet, dnsResolve and myIpAddress, are rejected by ParsePAC
+// with ErrUnsupportedPAC.
+type PAC struct {
+	urlParam, hostParam string
+	body                []pacStmt
+}
+
+// LoadPAC fetches and parses the PAC file at pacURL.
+// http.DefaultClient is used if client is nil.
+func LoadPAC(ctx context.Context, pacURL string, client *http.Client) (*PAC, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pacURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PAC request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch PAC file: %w", err)
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(
+			"unexpected response status: %s",
+			resp.Status,
+		)
+	}
+	src, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PAC file: %w", err)
+	}
+	return ParsePAC(string(src))
+}
+
+// ParsePAC parses the source of a PAC file.
+func ParsePAC(src string) (*PAC, error) {
+	tokens, err := tokenizePAC(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PAC file: %w", err)
+	}
+	p := &pacParser{tokens: tokens}
+	pac, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PAC file: %w", err)
+	}
+	return pac, nil
+}
+
+// FindProxyForURL evaluates the PAC file for u and returns its result,
+// e.g. "PROXY proxy:8080; DIRECT".
+func (p *PAC) FindProxyForURL(u *url.URL) (string, error) {
+	env := map[string]string{p.urlParam: u.String(), p.hostParam: u.Hostname()}
+	result, ok, err := evalPACStmts(p.body, env)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", errors.New("FindProxyForURL returned no value")
+	}
+	return result, nil
+}
+
+// Proxy returns the first usable proxy the PAC file selects for req,
+// or nil for a direct connection. It can be passed to WithProxyFunc.
+func (p *PAC) Proxy(req *http.Request) (*url.URL, error) {
+	result, err := p.FindProxyForURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range strings.Split(result, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		var scheme string
+		switch strings.ToUpper(fields[0]) {
+		case "DIRECT":
+			return nil, nil
+		case "PROXY", "HTTP":
+			scheme = "http"
+		case "HTTPS":
+			scheme = "https"
+		case "SOCKS", "SOCKS5":
+			scheme = "socks5"
+		default:
+			continue
+		}
+		if len(fields) < 2 {
+			continue
+		}
+		return &url.URL{Scheme: scheme, Host: fields[1]}, nil
+	}
+	return nil, nil
+}
+
+type pacExpr any
+
+type (
+	pacString string
+	pacIdent  string
+	pacNot    struct{ x pacExpr }
+	pacBinary struct {
+		op   string
+		x, y pacExpr
+	}
+	pacCall struct {
+		fn   string
+		args []pacExpr
+		// re is the compiled pattern of a shExpMatch call with a
+		// string literal pattern.
+		re *regexp.Regexp
+	}
+)
+
+type pacStmt struct {
+	// cond, then and els are set for if statements,
+	// ret for return statements.
+	cond      pacExpr
+	then, els []pacStmt
+	ret       pacExpr
+}
+
+var pacFuncs = map[string]int{
+	"shExpMatch":          2,
+	"dnsDomainIs":         2,
+	"localHostOrDomainIs": 2,
+	"isPlainHostName":     1,
+}
+
+func tokenizePAC(src string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				i = len(src)
+			} else {
+				i += end + 4
+			}
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, src[i:j+1])
+			i = j + 1
+		case strings.HasPrefix(src[i:], "&&") || strings.HasPrefix(src[i:], "||"):
+			tokens = append(tokens, src[i:i+2])
+			i += 2
+		case isPACIdentChar(c):
+			j := i
+			for j < len(src) && isPACIdentChar(src[j]) {
+				j++
+			}
+			tokens = append(tokens, src[i:j])
+			i = j
+		default:
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isPACIdentChar(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+type pacParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *pacParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *pacParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *pacParser) expect(want string) error {
+	if got := p.next(); got != want {
+		return fmt.Errorf("expected %q, got %q", want, got)
+	}
+	return nil
+}
+
+func (p *pacParser) parse() (*PAC, error) {
+	for _, t := range []string{"function", "FindProxyForURL", "("} {
+		if err := p.expect(t); err != nil {
+			return nil, err
+		}
+	}
+	pac := &PAC{urlParam: p.next()}
+	if err := p.expect(","); err != nil {
+		return nil, err
+	}
+	pac.hostParam = p.next()
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	body, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unsupported code after FindProxyForURL: %q", p.peek())
+	}
+	pac.body = body
+	return pac, nil
+}
+
+func (p *pacParser) block() ([]pacStmt, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var stmts []pacStmt
+	for p.peek() != "}" {
+		if p.peek() == "" {
+			return nil, errors.New("unexpected end of file")
+		}
+		s, err := p.stmt()
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, s)
+	}
+	p.next()
+	return stmts, nil
+}
+
+// body parses a block or a single statement.
+func (p *pacParser) body() ([]pacStmt, error) {
+	if p.peek() == "{" {
+		return p.block()
+	}
+	s, err := p.stmt()
+	if err != nil {
+		return nil, err
+	}
+	return []pacStmt{s}, nil
+}
+
+func (p *pacParser) stmt() (pacStmt, error) {
+	switch t := p.next(); t {
+	case "if":
+		if err := p.expect("("); err != nil {
+			return pacStmt{}, err
+		}
+		cond, err := p.expr()
+		if err != nil {
+			return pacStmt{}, err
+		}
+		if err := p.expect(")"); err != nil {
+			return pacStmt{}, err
+		}
+		s := pacStmt{cond: cond}
+		if s.then, err = p.body(); err != nil {
+			return pacStmt{}, err
+		}
+		if p.peek() == "else" {
+			p.next()
+			if s.els, err = p.body(); err != nil {
+				return pacStmt{}, err
+			}
+		}
+		return s, nil
+	case "return":
+		ret, err := p.expr()
+		if err != nil {
+			return pacStmt{}, err
+		}
+		if p.peek() == ";" {
+			p.next()
+		}
+		return pacStmt{ret: ret}, nil
+	case "var", "let", "const":
+		return pacStmt{}, fmt.Errorf("%w: variable declaration", ErrUnsupportedPAC)
+	default:
+		return pacStmt{}, fmt.Errorf("%w: statement %q", ErrUnsupportedPAC, t)
+	}
+}
+
+func (p *pacParser) expr() (pacExpr, error) {
+	return p.binary("||", p.and)
+}
+
+func (p *pacParser) and() (pacExpr, error) {
+	return p.binary("&&", p.unary)
+}
+
+func (p *pacParser) binary(op string, operand func() (pacExpr, error)) (pacExpr, error) {
+	x, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == op {
+		p.next()
+		y, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		x = pacBinary{op: op, x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *pacParser) unary() (pacExpr, error) {
+	t := p.next()
+	switch {
+	case t == "!":
+		x, err := p.unary()
+		return pacNot{x: x}, err
+	case t == "(":
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expect(")")
+	case strings.HasPrefix(t, `"`) || strings.HasPrefix(t, "'"):
+		return pacString(strings.ReplaceAll(t[1:len(t)-1], `\`, "")), nil
+	case t != "" && isPACIdentChar(t[0]):
+		if p.peek() != "(" {
+			return pacIdent(t), nil
+		}
+		arity, ok := pacFuncs[t]
+		if !ok {
+			return nil, fmt.Errorf("%w: function %s", ErrUnsupportedPAC, t)
+		}
+		p.next()
+		call := pacCall{fn: t}
+		for p.peek() != ")" {
+			if len(call.args) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			arg, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+		}
+		p.next()
+		if len(call.args) != arity {
+			return nil, fmt.Errorf("%s takes %d arguments", t, arity)
+		}
+		if pattern, ok := call.args[len(call.args)-1].(pacString); ok && t == "shExpMatch" {
+			call.re = shExpRegexp(string(pattern))
+		}
+		return call, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", t)
+}
+
+func evalPACStmts(stmts []pacStmt, env map[string]string) (string, bool, error) {
+	for _, s := range stmts {
+		if s.ret != nil {
+			v, err := evalPACString(s.ret, env)
+			return v, err == nil, err
+		}
+		cond, err := evalPACBool(s.cond, env)
+		if err != nil {
+			return "", false, err
+		}
+		branch := s.els
+		if cond {
+			branch = s.then
+		}
+		if v, ok, err := evalPACStmts(branch, env); ok || err != nil {
+			return v, ok, err
+		}
+	}
+	return "", false, nil
+}
+
+func evalPACString(e pacExpr, env map[string]string) (string, error) {
+	switch e := e.(type) {
+	case pacString:
+		return string(e), nil
+	case pacIdent:
+		v, ok := env[string(e)]
+		if !ok {
+			return "", fmt.Errorf("undefined variable %s", e)
+		}
+		return v, nil
+	}
+	return "", errors.New("expected a string")
+}
+
+func evalPACBool(e pacExpr, env map[string]string) (bool, error) {
+	switch e := e.(type) {
+	case pacNot:
+		v, err := evalPACBool(e.x, env)
+		return !v, err
+	case pacBinary:
+		x, err := evalPACBool(e.x, env)
+		if err != nil {
+			return false, err
+		}
+		if e.op == "||" && x || e.op == "&&" && !x {
+			return x, nil
+		}
+		return evalPACBool(e.y, env)
+	case pacCall:
+		args := make([]string, len(e.args))
+		for i, a := range e.args {
+			v, err := evalPACString(a, env)
+			if err != nil {
+				return false, err
+			}
+			args[i] = v
+		}
+		switch e.fn {
+		case "shExpMatch":
+			re := e.re
+			if re == nil {
+				re = shExpRegexp(args[1])
+			}
+			return re.MatchString(args[0]), nil
+		case "dnsDomainIs":
+			return strings.HasSuffix(strings.ToLower(args[0]), strings.ToLower(args[1])), nil
+		case "localHostOrDomainIs":
+			host, full := strings.ToLower(args[0]), strings.ToLower(args[1])
+			return host == full || !strings.Contains(host, ".") && strings.HasPrefix(full, host+"."), nil
+		case "isPlainHostName":
+			return !strings.Contains(args[0], ".") && net.ParseIP(args[0]) == nil, nil
+		}
+	}
+	return false, errors.New("expected a condition")
+}
+
+// shExpRegexp compiles a shell expression in which "*" matches any
+// sequence of characters, including "/", and "?" any one character.
+func shExpRegexp(pattern string) *regexp.Regexp {
+	var re strings.Builder
+	re.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			re.WriteString(".*")
+		case '?':
+			re.WriteString(".")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	re.WriteString("$")
+	return regexp.MustCompile(re.String())
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPAC = `
+// Corporate proxy configuration.
+function FindProxyForURL(url, host) {
+	/* Intranet hosts are reached directly. */
+	if (isPlainHostName(host) || dnsDomainIs(host, ".corp.example.com"))
+		return "DIRECT";
+	if (shExpMatch(url, "*/staging/*") && !localHostOrDomainIs(host, "public.example.com")) {
+		return 'PROXY staging-proxy:3128; DIRECT';
+	} else if (shExpMatch(host, "*.socks.example.com")) {
+		return "SOCKS5 socks:1080";
+	}
+	return "PROXY proxy.corp.example.com:8080; DIRECT";
+}
+`
+
+func TestPAC(t *testing.T) {
+	pac, err := ParsePAC(testPAC)
+	require.NoError(t, err)
+
+	for raw, want := range map[string]string{
+		"http://intranet/":                    "",
+		"https://wiki.corp.example.com/page":  "",
+		"https://browserbro.io/staging/api":   "http://staging-proxy:3128",
+		"https://public.example.com/staging/": "http://proxy.corp.example.com:8080",
+		"https://a.socks.example.com/":        "socks5://socks:1080",
+		"https://golang.org/":                 "http://proxy.corp.example.com:8080",
+	} {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		proxy, err := pac.Proxy(&http.Request{URL: u})
+		require.NoError(t, err, raw)
+		if want == "" {
+			assert.Nil(t, proxy, raw)
+		} else {
+			require.NotNil(t, proxy, raw)
+			assert.Equal(t, want, proxy.String(), raw)
+		}
+	}
+}
+
+func TestParsePAC_Unsupported(t *testing.T) {
+	for src, want := range map[string]string{
+		`function FindProxyForURL(url, host) { var p = "DIRECT"; return p; }`:                                  "failed to parse PAC file: unsupported PAC construct: variable declaration",
+		`function FindProxyForURL(url, host) { if (isInNet(host, "10.0.0.0", "255.0.0.0")) return "DIRECT"; }`: "failed to parse PAC file: unsupported PAC construct: function isInNet",
+		`function FindProxyForURL(url, host) { return dnsResolve(host); }`:                                     "failed to parse PAC file: unsupported PAC construct: function dnsResolve",
+		`function FindProxyForURL(url, host) { while (true) { return "DIRECT"; } }`:                            `failed to parse PAC file: unsupported PAC construct: statement "while"`,
+		`function FindProxyForURL(url, host) { return "DIRECT";`:                                               "failed to parse PAC file: unexpected end of file",
+		`alert("hi")`: `failed to parse PAC file: expected "function", got "alert"`,
+		`function FindProxyForURL(u, h) { return "`:       "failed to parse PAC file: unterminated string",
+		`function FindProxyForURL(u, h) { return 'DIRECT`: "failed to parse PAC file: unterminated string",
+		`function FindProxyForURL(u, h) { return "a\`:     "failed to parse PAC file: unterminated string",
+	} {
+		_, err := ParsePAC(src)
+		assert.EqualError(t, err, want)
+	}
+
+	_, err := ParsePAC(`function FindProxyForURL(url, host) { return myIpAddress(); }`)
+	assert.ErrorIs(t, err, ErrUnsupportedPAC)
+}
+
+func TestPAC_ShExpMatch(t *testing.T) {
+	pac, err := ParsePAC(`function FindProxyForURL(url, host) {
+		if (shExpMatch(host, "*.example.com") || shExpMatch(url, host)) return "DIRECT";
+		return "PROXY proxy:8080";
+	}`)
+	require.NoError(t, err)
+
+	cond := pac.body[0].cond.(pacBinary)
+	assert.NotNil(t, cond.x.(pacCall).re)
+	assert.Nil(t, cond.y.(pacCall).re)
+
+	for raw, want := range map[string]string{
+		"https://a.example.com/": "DIRECT",
+		"https://golang.org/":    "PROXY proxy:8080",
+	} {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		result, err := pac.FindProxyForURL(u)
+		require.NoError(t, err)
+		assert.Equal(t, want, result, raw)
+	}
+}
+
+func TestLoadPAC(t *testing.T) {
+	var proxied bool
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute target URL.
+		proxied = r.URL.IsAbs()
+		_, _ = w.Write([]byte(`{"plugins": ["screenshot"]}`))
+	}))
+	defer proxy.Close()
+
+	pacServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ns-proxy-autoconfig")
+		_, _ = w.Write([]byte(`function FindProxyForURL(url, host) { return "PROXY ` + proxy.Listener.Addr().String() + `"; }`))
+	}))
+	defer pacServer.Close()
+
+	pac, err := LoadPAC(context.Background(), pacServer.URL+"/proxy.pac", nil)
+	require.NoError(t, err)
+
+	c, err := New("http://browserbro.staging.internal", nil, WithProxyFunc(pac.Proxy))
+	require.NoError(t, err)
+
+	plugins, err := c.Plugins(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"screenshot"}, plugins)
+	assert.True(t, proxied)
+}
+
+func TestWithProxyFunc_UnsupportedTransport(t *testing.T) {
+	_, err := New("http://localhost", &http.Client{Transport: roundTripperFunc(nil)}, WithSystemProxy())
+	assert.EqualError(t, err, "failed to set proxy: unsupported transport client.roundTripperFunc")
+}
@@ -0,0 +1,86 @@
+package client
+
+import "context"
+
+// DefaultPageSize is the number of items a Pager requests per page
+// unless PageSize is called.
+const DefaultPageSize = 100
+
+// PageFunc fetches the page of at most pageSize items identified by
+// cursor, which is empty for the first page. It returns the items and
+// the cursor of the next page, which is empty after the last page.
+type PageFunc[T any] func(ctx context.Context, cursor string, pageSize int) (items []T, next string, err error)
+
+// Pager iterates over the items of a paginated list, fetching pages as
+// needed. All paginated listings of the client return a Pager:
+//
+//	p := c.Files()
+//	for p.Next(ctx) {
+//		process(p.Item())
+//	}
+//	if err := p.Err(); err != nil {
+//		...
+//	}
+//
+// A Pager is not safe for concurrent use.
+type Pager[T any] struct {
+	fetch    PageFunc[T]
+	pageSize int
+
+	items  []T
+	item   T
+	cursor string
+	done   bool
+	err    error
+}
+
+// NewPager returns a Pager over the pages returned by fetch, for example
+// to page through an endpoint called with GetJSON.
+func NewPager[T any](fetch PageFunc[T]) *Pager[T] {
+	return &Pager[T]{fetch: fetch, pageSize: DefaultPageSize}
+}
+
+// PageSize sets the number of items requested per page; n <= 0 leaves
+// the size to the server. It must be called before the first Next.
+func (p *Pager[T]) PageSize(n int) *Pager[T] {
+	p.pageSize = max(n, 0)
+	return p
+}
+
+// Next advances to the next item, fetching the next page if needed.
+// It returns false when there are no more items or an error occurred.
+func (p *Pager[T]) Next(ctx context.Context) bool {
+	for len(p.items) == 0 {
+		if p.done || p.err != nil {
+			return false
+		}
+		items, next, err := p.fetch(ctx, p.cursor, p.pageSize)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.items, p.cursor = items, next
+		p.done = next == ""
+	}
+	p.item, p.items = p.items[0], p.items[1:]
+	return true
+}
+
+// Item returns the current item.
+func (p *Pager[T]) Item() T {
+	return p.item
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// All returns the remaining items of all pages.
+func (p *Pager[T]) All(ctx context.Context) ([]T, error) {
+	var all []T
+	for p.Next(ctx) {
+		all = append(all, p.Item())
+	}
+	return all, p.Err()
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// numberPages serves the numbers 0 through n-1 in pages.
+func numberPages(n int, sizes *[]int) PageFunc[int] {
+	return func(_ context.Context, cursor string, pageSize int) ([]int, string, error) {
+		*sizes = append(*sizes, pageSize)
+		start := 0
+		if cursor != "" {
+			start, _ = strconv.Atoi(cursor)
+		}
+		end := min(start+pageSize, n)
+		items := make([]int, 0, end-start)
+		for i := start; i < end; i++ {
+			items = append(items, i)
+		}
+		if end == n {
+			return items, "", nil
+		}
+		return items, strconv.Itoa(end), nil
+	}
+}
+
+func TestPager(t *testing.T) {
+	var sizes []int
+	p := NewPager(numberPages(5, &sizes)).PageSize(2)
+
+	var got []int
+	for p.Next(context.Background()) {
+		got = append(got, p.Item())
+	}
+	require.NoError(t, p.Err())
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, got)
+	assert.Equal(t, []int{2, 2, 2}, sizes)
+	assert.False(t, p.Next(context.Background()))
+}
+
+func TestPager_All(t *testing.T) {
+	var sizes []int
+	all, err := NewPager(numberPages(250, &sizes)).All(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, all, 250)
+	assert.Equal(t, []int{DefaultPageSize, DefaultPageSize, DefaultPageSize}, sizes)
+}
+
+func TestPager_Error(t *testing.T) {
+	calls := 0
+	p := NewPager(func(context.Context, string, int) ([]int, string, error) {
+		calls++
+		if calls > 1 {
+			return nil, "", errors.New("boom")
+		}
+		return []int{1}, "next", nil
+	})
+
+	all, err := p.All(context.Background())
+	require.EqualError(t, err, "boom")
+	assert.Equal(t, []int{1}, all)
+	assert.False(t, p.Next(context.Background()))
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ErrNoNextPage is returned by NextPage when the result is the last page.
+var ErrNoNextPage = errors.New("no next page")
+
+// HasNextPage reports whether the server split the plugin output and
+// more pages can be fetched with NextPage.
+func (r *RunResult) HasNextPage() bool {
+	return r.NextCursor != "" && r.client != nil
+}
+
+// NextPage fetches the next page of an oversized plugin output.
+func (r *RunResult) NextPage(ctx context.Context) (*RunResult, error) {
+	if !r.HasNextPage() {
+		return nil, ErrNoNextPage
+	}
+	return r.client.resultPage(ctx, r.pluginName, r.NextCursor)
+}
+
+// Pages returns an iterator over this page and all following pages.
+func (r *RunResult) Pages() *PageIterator {
+	return &PageIterator{next: r}
+}
+
+// PageIterator iterates over the pages of a plugin output.
+//
+//	it := result.Pages()
+//	for it.Next(ctx) {
+//		process(it.Page().Output)
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type PageIterator struct {
+	page *RunResult
+	next *RunResult
+	err  error
+}
+
+// Next advances to the next page, fetching it if needed.
+// It returns false when there are no more pages or an error occurred.
+func (it *PageIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if it.next != nil {
+		it.page, it.next = it.next, nil
+		return true
+	}
+	if it.page == nil || !it.page.HasNextPage() {
+		return false
+	}
+	page, err := it.page.NextPage(ctx)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.page = page
+	return true
+}
+
+// Page returns the current page.
+func (it *PageIterator) Page() *RunResult {
+	return it.page
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *PageIterator) Err() error {
+	return it.err
+}
+
+func (c *Client) resultPage(ctx context.Context, pluginName, cursor string) (_ *RunResult, err error) {
+	ctx, op := c.startOperation(ctx, "NextPage")
+	defer func() { c.finishOperation(ctx, op, err) }()
+	op.Plugin = pluginName
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		c.addr+"/plugins/"+pluginName+"/results/"+url.PathEscape(cursor),
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create page request: %w", err)
+	}
+	if c.kms != nil {
+		req.Header.Set("Accept", EnvelopeContentType)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch result page: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.newAPIError(resp)
+	}
+
+	var output map[string]any
+	if err := c.decodeBody(ctx, resp, &output); err != nil {
+		return nil, fmt.Errorf("failed to decode result page: %w", err)
+	}
+
+	result := c.newRunResult(pluginName, resp.Header)
+	result.Output = output
+	c.scrubResult(result)
+	op.result = result.Output
+	if err := c.deliverResult(ctx, pluginName, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
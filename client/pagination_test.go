@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func paginatedServer(t testing.TB) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/plugins/crawl", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Next-Cursor", "c1")
+		_, _ = w.Write([]byte(`{"page": 1}`))
+	})
+	mux.HandleFunc("GET /api/v1/plugins/crawl/results/c1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Next-Cursor", "c2")
+		_, _ = w.Write([]byte(`{"page": 2}`))
+	})
+	mux.HandleFunc("GET /api/v1/plugins/crawl/results/c2", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"page": 3}`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRunResult_NextPage(t *testing.T) {
+	server := paginatedServer(t)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	result, err := c.RunPluginResult(context.Background(), "crawl", nil)
+	require.NoError(t, err)
+	require.True(t, result.HasNextPage())
+
+	page, err := result.NextPage(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, page.Output["page"])
+	assert.Equal(t, "c2", page.NextCursor)
+
+	last, err := page.NextPage(context.Background())
+	require.NoError(t, err)
+	assert.False(t, last.HasNextPage())
+
+	_, err = last.NextPage(context.Background())
+	require.ErrorIs(t, err, ErrNoNextPage)
+}
+
+func TestRunResult_Pages(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := paginatedServer(t)
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		result, err := c.RunPluginResult(context.Background(), "crawl", nil)
+		require.NoError(t, err)
+
+		var pages []any
+		it := result.Pages()
+		for it.Next(context.Background()) {
+			pages = append(pages, it.Page().Output["page"])
+		}
+		require.NoError(t, it.Err())
+		assert.Equal(t, []any{1.0, 2.0, 3.0}, pages)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				w.Header().Set("X-Next-Cursor", "c1")
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		result, err := c.RunPluginResult(context.Background(), "crawl", nil)
+		require.NoError(t, err)
+
+		it := result.Pages()
+		assert.True(t, it.Next(context.Background()))
+		assert.False(t, it.Next(context.Background()))
+		require.EqualError(t, it.Err(), "unexpected response status: 410 Gone")
+	})
+}
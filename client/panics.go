@@ -0,0 +1,42 @@
+package client
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is reported in place of a panic recovered in a goroutine
+// started by the library, e.g. from a user-provided sink, checker or
+// detector, so that the panic never crashes the host process.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+// NewPanicError creates a PanicError for a recovered value.
+// It must be called from the deferred function that recovered.
+func NewPanicError(v any) *PanicError {
+	return &PanicError{Value: v, Stack: debug.Stack()}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("recovered panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// safeCall calls fn and converts a panic into a *PanicError.
+func safeCall(fn func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = NewPanicError(v)
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// panicSink panics on every write.
+type panicSink struct{}
+
+func (panicSink) WriteResult(string, map[string]any) error { panic("sink exploded") }
+func (panicSink) WriteFile(string, []byte) error           { panic("sink exploded") }
+
+func TestPanicError(t *testing.T) {
+	err := safeCall(func() error { panic(io.ErrUnexpectedEOF) })
+	var panicErr *PanicError
+	require.True(t, errors.As(err, &panicErr))
+	assert.EqualError(t, err, "recovered panic: unexpected EOF")
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Contains(t, string(panicErr.Stack), "TestPanicError")
+
+	assert.NoError(t, safeCall(func() error { return nil }))
+}
+
+func TestPanicRecovery(t *testing.T) {
+	t.Run("batch", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, `{"echo": true}`)
+		defer server.Close()
+
+		c, err := New(server.URL, nil, WithResultSink(panicSink{}))
+		require.NoError(t, err)
+
+		_, err = c.RunBatch(context.Background(), "echo", []map[string]any{{}, {}})
+		var multi *MultiError
+		require.True(t, errors.As(err, &multi))
+		require.Len(t, multi.Failed(), 2)
+		var panicErr *PanicError
+		require.True(t, errors.As(multi.Failed()[0].Err, &panicErr))
+		assert.Equal(t, "sink exploded", panicErr.Value)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, "{\"page\": 1}\n")
+		defer server.Close()
+
+		c, err := New(server.URL, nil, WithResultSink(panicSink{}))
+		require.NoError(t, err)
+
+		items, err := c.RunPluginStream(context.Background(), "crawl", nil)
+		require.NoError(t, err)
+
+		var errs []error
+		for item := range items {
+			errs = append(errs, item.Err)
+		}
+		require.Len(t, errs, 1)
+		var panicErr *PanicError
+		assert.True(t, errors.As(errs[0], &panicErr))
+	})
+
+	t.Run("proxy health checks", func(t *testing.T) {
+		p := NewProxyPool(RoundRobin, 0, "p1")
+		p.StartHealthChecks(time.Millisecond, func(ctx context.Context, proxy string) error {
+			panic("checker exploded")
+		})
+		defer p.Stop()
+
+		require.Eventually(t, func() bool {
+			_, err := p.Next("")
+			return errors.Is(err, ErrNoProxyAvailable)
+		}, time.Second, time.Millisecond)
+	})
+}
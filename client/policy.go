@@ -0,0 +1,92 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ErrPolicyDenied is returned, before any request is sent, for runs
+// the client's Policy does not allow.
+var ErrPolicyDenied = errors.New("denied by client policy")
+
+// Policy restricts what a client may run, e.g. when it is shared with
+// semi-trusted internal callers.
+type Policy struct {
+	// Plugins lists the plugins that may be run. Empty allows all.
+	Plugins []string
+	// Domains lists the domains that may be visited, matched against the
+	// hosts of the "url" and "urls" params. A "*." prefix also matches
+	// every subdomain. Empty allows all.
+	Domains []string
+}
+
+// WithPolicy makes the client refuse runs that p does not allow.
+func WithPolicy(p Policy) Option {
+	return func(c *Client) {
+		c.policy = &p
+	}
+}
+
+// Check returns an error matching ErrPolicyDenied if p does not allow
+// running the plugin with params.
+func (p Policy) Check(pluginName string, params map[string]any) error {
+	if len(p.Plugins) > 0 && !slices.Contains(p.Plugins, pluginName) {
+		return fmt.Errorf("%w: plugin %s is not allowed", ErrPolicyDenied, pluginName)
+	}
+	if len(p.Domains) == 0 {
+		return nil
+	}
+	for _, raw := range targetURLs(params) {
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			return fmt.Errorf("%w: invalid target URL %q", ErrPolicyDenied, raw)
+		}
+		if !p.allowsHost(strings.ToLower(u.Hostname())) {
+			return fmt.Errorf("%w: domain %s is not allowed", ErrPolicyDenied, u.Hostname())
+		}
+	}
+	return nil
+}
+
+func (c *Client) checkPolicy(pluginName string, params map[string]any) error {
+	if c.policy == nil {
+		return nil
+	}
+	return c.policy.Check(pluginName, params)
+}
+
+func (p Policy) allowsHost(host string) bool {
+	for _, d := range p.Domains {
+		d = strings.ToLower(d)
+		if parent, ok := strings.CutPrefix(d, "*."); ok {
+			if host == parent || strings.HasSuffix(host, "."+parent) {
+				return true
+			}
+		} else if host == d {
+			return true
+		}
+	}
+	return false
+}
+
+// targetURLs returns the URLs of the "url" and "urls" params.
+func targetURLs(params map[string]any) []string {
+	var urls []string
+	if v, ok := params["url"].(string); ok && v != "" {
+		urls = append(urls, v)
+	}
+	switch v := params["urls"].(type) {
+	case []string:
+		urls = append(urls, v...)
+	case []any:
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				urls = append(urls, s)
+			}
+		}
+	}
+	return urls
+}
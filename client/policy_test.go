@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	p := Policy{
+		Plugins: []string{"screenshot", "googlesearch"},
+		Domains: []string{"example.com", "*.golang.org"},
+	}
+
+	for name, tc := range map[string]struct {
+		plugin string
+		params map[string]any
+		err    string
+	}{
+		"allowed":          {plugin: "screenshot", params: map[string]any{"url": "https://example.com/a"}},
+		"subdomain":        {plugin: "screenshot", params: map[string]any{"urls": []any{"https://pkg.go.golang.org", "https://golang.org"}}},
+		"no target":        {plugin: "googlesearch", params: map[string]any{"query": "golang"}},
+		"case insensitive": {plugin: "screenshot", params: map[string]any{"urls": []string{"https://EXAMPLE.com"}}},
+		"plugin denied":    {plugin: "crawl", err: "denied by client policy: plugin crawl is not allowed"},
+		"domain denied":    {plugin: "screenshot", params: map[string]any{"urls": []string{"https://example.com", "https://evil.com"}}, err: "denied by client policy: domain evil.com is not allowed"},
+		"not a subdomain":  {plugin: "screenshot", params: map[string]any{"url": "https://sub.example.com"}, err: "denied by client policy: domain sub.example.com is not allowed"},
+		"suffix lookalike": {plugin: "screenshot", params: map[string]any{"url": "https://notgolang.org"}, err: "denied by client policy: domain notgolang.org is not allowed"},
+		"invalid target":   {plugin: "screenshot", params: map[string]any{"url": "not a url"}, err: `denied by client policy: invalid target URL "not a url"`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := p.Check(tc.plugin, tc.params)
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.err)
+			assert.ErrorIs(t, err, ErrPolicyDenied)
+		})
+	}
+
+	assert.NoError(t, Policy{}.Check("anything", map[string]any{"url": "https://evil.com"}))
+}
+
+func TestWithPolicy(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, nil, WithPolicy(Policy{Plugins: []string{"screenshot"}}))
+	require.NoError(t, err)
+
+	_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+	require.NoError(t, err)
+
+	_, err = c.RunPlugin(context.Background(), "crawl", nil)
+	assert.ErrorIs(t, err, ErrPolicyDenied)
+	_, err = c.RunPluginStream(context.Background(), "crawl", nil)
+	assert.ErrorIs(t, err, ErrPolicyDenied)
+	assert.Equal(t, int32(1), requests.Load())
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultPrefetchTTL is how long a prefetched file is kept unless
+// WithPrefetch is given another TTL.
+const DefaultPrefetchTTL = time.Minute
+
+// WithPrefetch makes runs start downloading the files referenced in their
+// output in the background, writing them to the result sink if one is
+// configured, so that DownloadFile returns them without waiting for the
+// server. A prefetched file is kept in memory until DownloadFile returns
+// it or for ttl after it was downloaded; zero means DefaultPrefetchTTL.
+// At most DefaultBatchConcurrency files are prefetched at a time. A
+// prefetched file holds its reservation of the download budget, if any,
+// until it is returned or evicted, so the budget also bounds the memory
+// of files kept in advance. A failed prefetch is retried by DownloadFile.
+func WithPrefetch(ttl time.Duration) Option {
+	return func(c *Client) {
+		if ttl <= 0 {
+			ttl = DefaultPrefetchTTL
+		}
+		c.prefetcher = &prefetcher{
+			ttl:   ttl,
+			sem:   make(chan struct{}, DefaultBatchConcurrency),
+			files: make(map[string]*prefetchedFile),
+		}
+	}
+}
+
+type prefetcher struct {
+	ttl time.Duration
+	sem chan struct{}
+
+	mu    sync.Mutex
+	files map[string]*prefetchedFile
+}
+
+type prefetchedFile struct {
+	// done is closed once data, release and err are set.
+	done chan struct{}
+	data []byte
+	// release releases the download budget reservation of data.
+	release func()
+	err     error
+}
+
+// prefetch starts downloading the files not already prefetched.
+func (c *Client) prefetch(fileIDs []string) {
+	p := c.prefetcher
+	for _, id := range fileIDs {
+		p.mu.Lock()
+		if _, ok := p.files[id]; ok {
+			p.mu.Unlock()
+			continue
+		}
+		f := &prefetchedFile{done: make(chan struct{})}
+		p.files[id] = f
+		p.mu.Unlock()
+
+		go func() {
+			p.sem <- struct{}{}
+			defer func() { <-p.sem }()
+
+			f.err = safeCall(func() (err error) {
+				ctx, op := c.startOperation(context.Background(), "PrefetchFile")
+				defer func() { c.finishOperation(ctx, op, err) }()
+				op.FileID = id
+				f.data, f.release, err = c.holdFile(ctx, id)
+				op.result = f.data
+				return err
+			})
+			close(f.done)
+			time.AfterFunc(p.ttl, func() { p.evict(id, f) })
+		}()
+	}
+}
+
+// take removes a prefetched file and returns its contents, waiting for a
+// prefetch in progress. It reports false if the file was not prefetched
+// or its prefetch failed.
+func (p *prefetcher) take(ctx context.Context, fileID string) ([]byte, bool) {
+	p.mu.Lock()
+	f, ok := p.files[fileID]
+	delete(p.files, fileID)
+	p.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		// The reservation is released when the file is evicted.
+		return nil, false
+	}
+	f.free()
+	return f.data, f.err == nil
+}
+
+func (p *prefetcher) evict(fileID string, f *prefetchedFile) {
+	p.mu.Lock()
+	if p.files[fileID] == f {
+		delete(p.files, fileID)
+	}
+	p.mu.Unlock()
+	f.free()
+}
+
+// free releases the download budget reservation of a finished prefetch.
+// It may be called more than once.
+func (f *prefetchedFile) free() {
+	if f.release != nil {
+		f.release()
+	}
+}
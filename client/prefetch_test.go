@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fileSink records the files written to it.
+type fileSink struct {
+	mu    sync.Mutex
+	files map[string]string
+}
+
+func (s *fileSink) WriteResult(string, map[string]any) error { return nil }
+
+func (s *fileSink) WriteFile(fileID string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.files == nil {
+		s.files = make(map[string]string)
+	}
+	s.files[fileID] = string(data)
+	return nil
+}
+
+func prefetchServer(t *testing.T, downloads *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/plugins/screenshot", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"fileIds": ["a", "b", "bad"]}`))
+	})
+	mux.HandleFunc("GET /api/v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		if r.PathValue("id") == "bad" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("data-" + r.PathValue("id")))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestWithPrefetch(t *testing.T) {
+	var downloads atomic.Int32
+	server := prefetchServer(t, &downloads)
+	defer server.Close()
+
+	sink := &fileSink{}
+	c, err := New(server.URL, nil, WithPrefetch(0), WithResultSink(sink))
+	require.NoError(t, err)
+
+	_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+	require.NoError(t, err)
+
+	data, err := c.DownloadFile(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, "data-a", string(data))
+	require.Eventually(t, func() bool { return downloads.Load() == 3 }, time.Second, time.Millisecond)
+
+	data, err = c.DownloadFile(context.Background(), "b")
+	require.NoError(t, err)
+	assert.Equal(t, "data-b", string(data))
+	assert.Equal(t, int32(3), downloads.Load())
+	assert.Equal(t, map[string]string{"a": "data-a", "b": "data-b"}, sink.files)
+
+	// A failed prefetch is retried.
+	_, err = c.DownloadFile(context.Background(), "bad")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, int32(4), downloads.Load())
+
+	// Prefetched files are returned once.
+	_, err = c.DownloadFile(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), downloads.Load())
+}
+
+func TestWithPrefetch_TTL(t *testing.T) {
+	var downloads atomic.Int32
+	server := prefetchServer(t, &downloads)
+	defer server.Close()
+
+	c, err := New(server.URL, nil, WithPrefetch(10*time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		c.prefetcher.mu.Lock()
+		defer c.prefetcher.mu.Unlock()
+		return len(c.prefetcher.files) == 0
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(3), downloads.Load())
+}
+
+func TestWithPrefetch_DownloadBudget(t *testing.T) {
+	var downloads atomic.Int32
+	server := prefetchServer(t, &downloads)
+	defer server.Close()
+
+	budget := NewDownloadBudget(1 << 20)
+	c, err := New(server.URL, nil, WithPrefetch(200*time.Millisecond), WithDownloadBudget(budget))
+	require.NoError(t, err)
+
+	_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+	require.NoError(t, err)
+	// Prefetched files keep their reservations until taken or evicted.
+	require.Eventually(t, func() bool {
+		return budget.InUse() == int64(len("data-a")+len("data-b"))
+	}, time.Second, time.Millisecond)
+	_, err = c.DownloadFile(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("data-b")), budget.InUse())
+	require.Eventually(t, func() bool { return budget.InUse() == 0 }, 2*time.Second, time.Millisecond)
+}
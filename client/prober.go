@@ -0,0 +1,229 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultProbeInterval is the interval between probes unless NewProber is
+// given another interval.
+const DefaultProbeInterval = 30 * time.Second
+
+// probeSmoothing is the weight of a new latency sample in the moving
+// average, so a single slow probe doesn't reorder the ranking.
+const probeSmoothing = 0.3
+
+// ErrNoReachableServer is returned when no probed server is reachable.
+var ErrNoReachableServer = errors.New("no reachable server")
+
+// RegionStatus is the probed state of the server of a region.
+type RegionStatus struct {
+	// Region is empty for a primary server without a region.
+	Region    string
+	Reachable bool
+	// Latency is the moving average of the health check latency.
+	Latency time.Duration
+	// Err is the error of the last probe, if it failed.
+	Err       error
+	CheckedAt time.Time
+}
+
+// Prober periodically health-checks the primary and regional servers of a
+// client and ranks them by reachability and latency. Runs given
+// WithProber are routed to the best-ranked server their residency
+// requirement allows. Close must be called to stop probing.
+type Prober struct {
+	c        *Client
+	interval time.Duration
+
+	mu       sync.Mutex
+	statuses map[string]*RegionStatus
+	// probed is closed after the first round of probes.
+	probed chan struct{}
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewProber creates a Prober that probes every interval, zero meaning
+// DefaultProbeInterval, starting immediately. A probe times out after
+// half the interval.
+func (c *Client) NewProber(interval time.Duration) *Prober {
+	if interval <= 0 {
+		interval = DefaultProbeInterval
+	}
+	p := &Prober{
+		c:        c,
+		interval: interval,
+		statuses: make(map[string]*RegionStatus),
+		probed:   make(chan struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go p.loop()
+	return p
+}
+
+// WithProber routes the run to the reachable server with the lowest
+// latency among the servers allowed by WithResidency, or among all
+// servers without a residency requirement. Until the first probes
+// complete, or if no allowed server is reachable, the run is routed
+// as without a prober.
+func WithProber(p *Prober) RunOption {
+	return func(cfg *runConfig) {
+		cfg.prober = p
+	}
+}
+
+// Close stops probing.
+func (p *Prober) Close() {
+	select {
+	case <-p.stop:
+	default:
+		close(p.stop)
+	}
+	<-p.done
+}
+
+// Ready returns a channel closed once every server was probed once.
+func (p *Prober) Ready() <-chan struct{} {
+	return p.probed
+}
+
+// Ranking returns the status of every probed server, reachable servers
+// first, each group ordered by latency.
+func (p *Prober) Ranking() []RegionStatus {
+	p.mu.Lock()
+	ranking := make([]RegionStatus, 0, len(p.statuses))
+	for _, s := range p.statuses {
+		ranking = append(ranking, *s)
+	}
+	p.mu.Unlock()
+
+	sort.Slice(ranking, func(i, j int) bool {
+		a, b := ranking[i], ranking[j]
+		if a.Reachable != b.Reachable {
+			return a.Reachable
+		}
+		if a.Latency != b.Latency {
+			return a.Latency < b.Latency
+		}
+		return a.Region < b.Region
+	})
+	return ranking
+}
+
+// Best returns a client for the best-ranked reachable server.
+func (p *Prober) Best() (*Client, error) {
+	for _, s := range p.Ranking() {
+		if s.Reachable {
+			return p.c.InRegion(s.Region)
+		}
+	}
+	return nil, ErrNoReachableServer
+}
+
+// best returns the best-ranked reachable region among regions.
+func (p *Prober) best(regions []string) (string, bool) {
+	allowed := make(map[string]bool, len(regions))
+	for _, r := range regions {
+		allowed[r] = true
+	}
+	for _, s := range p.Ranking() {
+		if s.Reachable && allowed[s.Region] {
+			return s.Region, true
+		}
+	}
+	return "", false
+}
+
+func (p *Prober) loop() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	p.probeAll()
+	close(p.probed)
+	for {
+		select {
+		case <-ticker.C:
+			p.probeAll()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// probeAll probes every server in parallel.
+func (p *Prober) probeAll() {
+	ctx, cancel := context.WithTimeout(context.Background(), p.interval/2)
+	defer cancel()
+	go func() {
+		select {
+		case <-p.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	servers := append([]regionalServer{{region: p.c.region, addr: p.c.addr}}, p.c.regionalServers...)
+	var wg sync.WaitGroup
+	for _, s := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := safeCall(func() error { return p.c.probe(ctx, s.addr) })
+			p.record(s.region, time.Since(start), err)
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *Prober) record(region string, latency time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, ok := p.statuses[region]
+	if !ok {
+		s = &RegionStatus{Region: region}
+		p.statuses[region] = s
+	}
+	s.CheckedAt = time.Now()
+	s.Err = err
+	s.Reachable = err == nil
+	if err != nil {
+		return
+	}
+	if s.Latency == 0 {
+		s.Latency = latency
+	} else {
+		s.Latency = time.Duration(probeSmoothing*float64(latency) + (1-probeSmoothing)*float64(s.Latency))
+	}
+}
+
+// probe health-checks the server at addr.
+func (c *Client) probe(ctx context.Context, addr string) (err error) {
+	ctx, op := c.startOperation(ctx, "Probe")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create probe request: %w", err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to probe server: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return c.newAPIError(resp)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// probedServer answers health checks after delay, or with 503 if down,
+// and plugin runs with its region.
+func probedServer(t *testing.T, region string, delay time.Duration, down bool) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/health" {
+			time.Sleep(delay)
+			if down {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+			return
+		}
+		_, _ = w.Write([]byte(`{"region": "` + region + `"}`))
+	}))
+}
+
+func TestProber(t *testing.T) {
+	us := probedServer(t, "us", 60*time.Millisecond, false)
+	defer us.Close()
+	eu := probedServer(t, "eu", 0, false)
+	defer eu.Close()
+	ap := probedServer(t, "ap", 0, true)
+	defer ap.Close()
+
+	c, err := New(us.URL, nil,
+		WithRegion("us"),
+		WithRegionalServer("eu", eu.URL),
+		WithRegionalServer("ap", ap.URL),
+	)
+	require.NoError(t, err)
+
+	p := c.NewProber(time.Hour)
+	defer p.Close()
+	<-p.Ready()
+
+	ranking := p.Ranking()
+	require.Len(t, ranking, 3)
+	assert.Equal(t, "eu", ranking[0].Region)
+	assert.Equal(t, "us", ranking[1].Region)
+	assert.GreaterOrEqual(t, ranking[1].Latency, 60*time.Millisecond)
+	assert.Equal(t, "ap", ranking[2].Region)
+	assert.False(t, ranking[2].Reachable)
+	var apiErr *APIError
+	require.ErrorAs(t, ranking[2].Err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+
+	best, err := p.Best()
+	require.NoError(t, err)
+	assert.Equal(t, "eu", best.Region())
+
+	t.Run("routing", func(t *testing.T) {
+		output, err := c.RunPlugin(context.Background(), "screenshot", nil, WithProber(p))
+		require.NoError(t, err)
+		assert.Equal(t, "eu", output["region"])
+
+		// Residency still restricts the candidates.
+		output, err = c.RunPlugin(context.Background(), "screenshot", nil, WithProber(p), WithResidency("us", "ap"))
+		require.NoError(t, err)
+		assert.Equal(t, "us", output["region"])
+
+		// Unreachable approved servers fall back to the static order.
+		output, err = c.RunPlugin(context.Background(), "screenshot", nil, WithProber(p), WithResidency("ap"))
+		require.NoError(t, err)
+		assert.Equal(t, "ap", output["region"])
+	})
+}
+
+func TestProber_NoReachableServer(t *testing.T) {
+	server := probedServer(t, "", 0, true)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	p := c.NewProber(0)
+	defer p.Close()
+	<-p.Ready()
+
+	_, err = p.Best()
+	require.ErrorIs(t, err, ErrNoReachableServer)
+	assert.Equal(t, "", p.Ranking()[0].Region)
+}
+
+func TestProber_Smoothing(t *testing.T) {
+	p := &Prober{statuses: make(map[string]*RegionStatus)}
+	p.record("us", 100*time.Millisecond, nil)
+	p.record("us", 200*time.Millisecond, nil)
+	assert.Equal(t, 130*time.Millisecond, p.Ranking()[0].Latency)
+}
@@ -0,0 +1,291 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrNoProxyAvailable is returned by RunPlugin when a proxy pool is
+// configured but has no healthy, non-banned proxy for the target domain.
+var ErrNoProxyAvailable = errors.New("no proxy available")
+
+// DefaultProxyBanDuration is how long a proxy stays banned for a domain
+// when NewProxyPool is given a non-positive ban duration.
+const DefaultProxyBanDuration = 30 * time.Minute
+
+// ProxyChecker reports whether a proxy is usable. A nil error means healthy.
+type ProxyChecker func(ctx context.Context, proxy string) error
+
+// ProxyPool assigns exit proxies to plugin runs. Proxies failing health
+// checks are skipped until they recover, and proxies banned by a domain
+// are skipped for that domain until the ban expires.
+// It is safe for concurrent use.
+type ProxyPool struct {
+	strategy    RotationStrategy
+	banDuration time.Duration
+
+	mu       sync.Mutex
+	proxies  []*proxyState
+	next     int
+	byDomain map[string]string
+	stop     chan struct{}
+}
+
+type proxyState struct {
+	addr    string
+	healthy bool
+	bans    map[string]time.Time
+}
+
+// NewProxyPool creates a pool over the given proxy URLs.
+func NewProxyPool(
+	strategy RotationStrategy,
+	banDuration time.Duration,
+	proxies ...string,
+) *ProxyPool {
+	if banDuration <= 0 {
+		banDuration = DefaultProxyBanDuration
+	}
+	p := &ProxyPool{
+		strategy:    strategy,
+		banDuration: banDuration,
+		byDomain:    make(map[string]string),
+	}
+	for _, proxy := range proxies {
+		p.Add(proxy)
+	}
+	return p
+}
+
+// WithProxyPool assigns a proxy from p to every plugin run
+// that does not set a "proxy" param explicitly.
+func WithProxyPool(p *ProxyPool) Option {
+	return func(c *Client) {
+		c.proxies = p
+	}
+}
+
+// Add adds a proxy to the pool. Adding a known proxy is a no-op.
+func (p *ProxyPool) Add(proxy string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, s := range p.proxies {
+		if s.addr == proxy {
+			return
+		}
+	}
+	p.proxies = append(p.proxies, &proxyState{
+		addr:    proxy,
+		healthy: true,
+		bans:    make(map[string]time.Time),
+	})
+}
+
+// Remove removes a proxy from the pool.
+func (p *ProxyPool) Remove(proxy string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, s := range p.proxies {
+		if s.addr == proxy {
+			p.proxies = append(p.proxies[:i], p.proxies[i+1:]...)
+			break
+		}
+	}
+	for domain, addr := range p.byDomain {
+		if addr == proxy {
+			delete(p.byDomain, domain)
+		}
+	}
+}
+
+// Proxies returns all proxies in the pool.
+func (p *ProxyPool) Proxies() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := make([]string, 0, len(p.proxies))
+	for _, s := range p.proxies {
+		out = append(out, s.addr)
+	}
+	return out
+}
+
+// Next returns the proxy to use for a run against domain.
+func (p *ProxyPool) Next(domain string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if addr, ok := p.byDomain[domain]; ok {
+		if s := p.find(addr); s != nil && p.usable(s, domain, time.Now()) {
+			return addr, nil
+		}
+	}
+	return p.pick(domain, "")
+}
+
+// Rotate discards the proxy assigned to domain, if any,
+// and returns a different usable one.
+func (p *ProxyPool) Rotate(domain string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.pick(domain, p.byDomain[domain])
+}
+
+// ReportBan marks proxy as banned by domain.
+func (p *ProxyPool) ReportBan(proxy, domain string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if s := p.find(proxy); s != nil {
+		s.bans[domain] = time.Now().Add(p.banDuration)
+	}
+	if p.byDomain[domain] == proxy {
+		delete(p.byDomain, domain)
+	}
+}
+
+// CheckHealth runs check against every proxy once and updates their health.
+// A proxy whose check panics is marked unhealthy.
+func (p *ProxyPool) CheckHealth(ctx context.Context, check ProxyChecker) {
+	for _, proxy := range p.Proxies() {
+		err := safeCall(func() error { return check(ctx, proxy) })
+		p.mu.Lock()
+		if s := p.find(proxy); s != nil {
+			s.healthy = err == nil
+		}
+		p.mu.Unlock()
+	}
+}
+
+// StartHealthChecks runs CheckHealth every interval in the background
+// until Stop is called.
+func (p *ProxyPool) StartHealthChecks(interval time.Duration, check ProxyChecker) {
+	p.mu.Lock()
+	if p.stop != nil {
+		p.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	p.stop = stop
+	p.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			<-stop
+			cancel()
+		}()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				p.CheckHealth(ctx, check)
+			}
+		}
+	}()
+}
+
+// Stop stops background health checks.
+func (p *ProxyPool) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+}
+
+func (p *ProxyPool) find(addr string) *proxyState {
+	for _, s := range p.proxies {
+		if s.addr == addr {
+			return s
+		}
+	}
+	return nil
+}
+
+func (p *ProxyPool) usable(s *proxyState, domain string, now time.Time) bool {
+	if !s.healthy {
+		return false
+	}
+	until, banned := s.bans[domain]
+	return !banned || now.After(until)
+}
+
+func (p *ProxyPool) pick(domain, exclude string) (string, error) {
+	now := time.Now()
+	var fallback *proxyState
+	for i := 0; i < len(p.proxies); i++ {
+		idx := (p.next + i) % len(p.proxies)
+		s := p.proxies[idx]
+		if !p.usable(s, domain, now) {
+			continue
+		}
+		if s.addr == exclude {
+			fallback = s
+			continue
+		}
+		p.next = (idx + 1) % len(p.proxies)
+		p.assign(domain, s.addr)
+		return s.addr, nil
+	}
+	if fallback != nil {
+		p.assign(domain, fallback.addr)
+		return fallback.addr, nil
+	}
+	return "", ErrNoProxyAvailable
+}
+
+func (p *ProxyPool) assign(domain, addr string) {
+	if p.strategy == StickyPerDomain && domain != "" {
+		p.byDomain[domain] = addr
+	}
+}
+
+// HTTPProxyChecker returns a ProxyChecker that fetches target through
+// the proxy and treats any response below 500 as healthy.
+func HTTPProxyChecker(target string, timeout time.Duration) ProxyChecker {
+	return func(ctx context.Context, proxy string) error {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		client := &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)},
+		}
+		defer client.CloseIdleConnections()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer closeBody(resp.Body)
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf(
+				"unexpected response status: %s",
+				resp.Status,
+			)
+		}
+		return nil
+	}
+}
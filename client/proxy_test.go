@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyPool_AddRemove(t *testing.T) {
+	p := NewProxyPool(RoundRobin, 0, "http://p1:8080", "http://p2:8080")
+	p.Add("http://p1:8080")
+	p.Add("http://p3:8080")
+	assert.Equal(t, []string{"http://p1:8080", "http://p2:8080", "http://p3:8080"}, p.Proxies())
+
+	p.Remove("http://p2:8080")
+	assert.Equal(t, []string{"http://p1:8080", "http://p3:8080"}, p.Proxies())
+}
+
+func TestProxyPool_Next(t *testing.T) {
+	t.Run("round robin", func(t *testing.T) {
+		p := NewProxyPool(RoundRobin, 0, "p1", "p2")
+		for _, want := range []string{"p1", "p2", "p1"} {
+			got, err := p.Next("a.com")
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		}
+	})
+
+	t.Run("sticky per domain", func(t *testing.T) {
+		p := NewProxyPool(StickyPerDomain, 0, "p1", "p2")
+		a, err := p.Next("a.com")
+		require.NoError(t, err)
+		b, err := p.Next("b.com")
+		require.NoError(t, err)
+		assert.NotEqual(t, a, b)
+
+		again, err := p.Next("a.com")
+		require.NoError(t, err)
+		assert.Equal(t, a, again)
+	})
+
+	t.Run("empty pool", func(t *testing.T) {
+		p := NewProxyPool(RoundRobin, 0)
+		_, err := p.Next("a.com")
+		require.ErrorIs(t, err, ErrNoProxyAvailable)
+	})
+}
+
+func TestProxyPool_ReportBan(t *testing.T) {
+	p := NewProxyPool(StickyPerDomain, time.Hour, "p1", "p2")
+	a, err := p.Next("a.com")
+	require.NoError(t, err)
+	assert.Equal(t, "p1", a)
+
+	p.ReportBan("p1", "a.com")
+	for i := 0; i < 3; i++ {
+		got, err := p.Next("a.com")
+		require.NoError(t, err)
+		assert.Equal(t, "p2", got)
+	}
+
+	p.ReportBan("p2", "a.com")
+	_, err = p.Next("a.com")
+	require.ErrorIs(t, err, ErrNoProxyAvailable)
+
+	got, err := p.Next("b.com")
+	require.NoError(t, err, "bans are per domain")
+	assert.NotEmpty(t, got)
+}
+
+func TestProxyPool_Rotate(t *testing.T) {
+	p := NewProxyPool(StickyPerDomain, 0, "p1", "p2")
+	a, err := p.Next("a.com")
+	require.NoError(t, err)
+	b, err := p.Rotate("a.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, a, b)
+
+	single := NewProxyPool(StickyPerDomain, 0, "p1")
+	_, err = single.Next("a.com")
+	require.NoError(t, err)
+	got, err := single.Rotate("a.com")
+	require.NoError(t, err)
+	assert.Equal(t, "p1", got, "falls back to the only usable proxy")
+}
+
+func TestProxyPool_CheckHealth(t *testing.T) {
+	p := NewProxyPool(RoundRobin, 0, "good", "bad")
+	p.CheckHealth(context.Background(), func(ctx context.Context, proxy string) error {
+		if proxy == "bad" {
+			return errors.New("unreachable")
+		}
+		return nil
+	})
+
+	for i := 0; i < 3; i++ {
+		got, err := p.Next("")
+		require.NoError(t, err)
+		assert.Equal(t, "good", got)
+	}
+}
+
+func TestProxyPool_StartHealthChecks(t *testing.T) {
+	p := NewProxyPool(RoundRobin, 0, "p1")
+	var checks atomic.Int32
+	p.StartHealthChecks(time.Millisecond, func(ctx context.Context, proxy string) error {
+		checks.Add(1)
+		return errors.New("down")
+	})
+	defer p.Stop()
+
+	require.Eventually(t, func() bool {
+		_, err := p.Next("")
+		return checks.Load() > 0 && errors.Is(err, ErrNoProxyAvailable)
+	}, time.Second, time.Millisecond)
+}
+
+func TestHTTPProxyChecker(t *testing.T) {
+	t.Run("healthy", func(t *testing.T) {
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer proxy.Close()
+
+		err := HTTPProxyChecker("http://example.invalid/", time.Second)(context.Background(), proxy.URL)
+		require.NoError(t, err)
+	})
+
+	t.Run("unhealthy", func(t *testing.T) {
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer proxy.Close()
+
+		err := HTTPProxyChecker("http://example.invalid/", time.Second)(context.Background(), proxy.URL)
+		require.EqualError(t, err, "unexpected response status: 502 Bad Gateway")
+	})
+}
+
+func TestWithProxyPool(t *testing.T) {
+	t.Run("assigned automatically", func(t *testing.T) {
+		var params map[string]any
+		server := captureServer(t, http.StatusOK, `{}`, &params)
+		defer server.Close()
+
+		c, err := New(server.URL, nil, WithProxyPool(NewProxyPool(RoundRobin, 0, "http://p1:8080")))
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "http://p1:8080", params["proxy"])
+	})
+
+	t.Run("no proxy available", func(t *testing.T) {
+		c, err := New("http://localhost:10001", nil, WithProxyPool(NewProxyPool(RoundRobin, 0)))
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+		require.ErrorIs(t, err, ErrNoProxyAvailable)
+	})
+
+	t.Run("banned on bot detection", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				_, _ = w.Write([]byte(`{"blocked": true}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+
+		pool := NewProxyPool(StickyPerDomain, time.Hour, "p1", "p2")
+		c, err := New(server.URL, nil, WithProxyPool(pool))
+		require.NoError(t, err)
+
+		output, err := c.RunPlugin(context.Background(),
+			"screenshot",
+			map[string]any{"urls": []string{"https://a.com"}},
+			WithBotDetectionRetry(BotDetectionPolicy{
+				Detect: func(output map[string]any) bool { return output["blocked"] == true },
+			}),
+		)
+		require.NoError(t, err)
+		attempts := output[AttemptsKey].([]Attempt)
+		assert.Equal(t, "p1", attempts[0].Params["proxy"])
+		assert.Equal(t, "p2", attempts[1].Params["proxy"])
+
+		got, err := pool.Next("a.com")
+		require.NoError(t, err)
+		assert.Equal(t, "p2", got)
+	})
+}
@@ -0,0 +1,49 @@
+package client
+
+import "time"
+
+// ResultEventSchemaVersion is the version of the ResultEvent JSON schema.
+// It is incremented only for incompatible changes.
+const ResultEventSchemaVersion = 1
+
+// ResultPublisher forwards completed plugin runs to downstream consumers.
+type ResultPublisher interface {
+	PublishResult(event ResultEvent) error
+}
+
+// WithResultPublisher publishes every successful plugin run to p.
+func WithResultPublisher(p ResultPublisher) Option {
+	return func(c *Client) {
+		c.publisher = p
+	}
+}
+
+// ResultEvent is the schema-stable representation of a completed plugin run.
+type ResultEvent struct {
+	SchemaVersion int            `json:"schemaVersion"`
+	Plugin        string         `json:"plugin"`
+	JobID         string         `json:"jobId,omitempty"`
+	Browser       string         `json:"browser,omitempty"`
+	CompletedAt   time.Time      `json:"completedAt"`
+	DurationMs    int64          `json:"durationMs"`
+	Retries       int            `json:"retries"`
+	Warnings      []string       `json:"warnings,omitempty"`
+	FileIDs       []string       `json:"fileIds,omitempty"`
+	Output        map[string]any `json:"output"`
+}
+
+// NewResultEvent builds the ResultEvent of a plugin run.
+func NewResultEvent(pluginName string, r *RunResult) ResultEvent {
+	return ResultEvent{
+		SchemaVersion: ResultEventSchemaVersion,
+		Plugin:        pluginName,
+		JobID:         r.JobID,
+		Browser:       r.Browser,
+		CompletedAt:   time.Now().UTC(),
+		DurationMs:    r.Duration.Milliseconds(),
+		Retries:       r.Retries,
+		Warnings:      r.Warnings,
+		FileIDs:       extractFileIDs(r.Output),
+		Output:        r.Output,
+	}
+}
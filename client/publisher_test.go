@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type publisherFunc func(ResultEvent) error
+
+func (f publisherFunc) PublishResult(event ResultEvent) error { return f(event) }
+
+func TestWithResultPublisher(t *testing.T) {
+	server := mockServer(t, http.StatusOK, `{"screenshot": {"fileId": "f1"}}`)
+	defer server.Close()
+
+	t.Run("success", func(t *testing.T) {
+		var events []ResultEvent
+		c, err := New(server.URL, nil, WithResultPublisher(publisherFunc(func(e ResultEvent) error {
+			events = append(events, e)
+			return nil
+		})))
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, ResultEventSchemaVersion, events[0].SchemaVersion)
+		assert.Equal(t, []string{"f1"}, events[0].FileIDs)
+	})
+
+	t.Run("publisher error", func(t *testing.T) {
+		c, err := New(server.URL, nil, WithResultPublisher(publisherFunc(func(ResultEvent) error {
+			return errors.New("unavailable")
+		})))
+		require.NoError(t, err)
+
+		output, err := c.RunPlugin(context.Background(), "screenshot", nil)
+		require.EqualError(t, err, "failed to publish result: unavailable")
+		require.Nil(t, output)
+	})
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rangeServer serves content with range support and the given digest
+// header, counting ranged requests.
+func rangeServer(t *testing.T, content []byte, digest http.Header, ranged *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			ranged.Add(1)
+		}
+		for k, v := range digest {
+			w.Header()[k] = v
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+}
+
+func TestClient_DownloadFileParallel(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	sum := sha256.Sum256(content)
+	encoded := base64.StdEncoding.EncodeToString(sum[:])
+
+	tests := []struct {
+		name       string
+		digest     http.Header
+		wantRanged int32
+		wantErr    error
+	}{
+		{
+			name:       "repr-digest",
+			digest:     http.Header{"Repr-Digest": {"sha-512=:eA==:, sha-256=:" + encoded + ":"}},
+			wantRanged: 10,
+		},
+		{
+			name:       "legacy digest",
+			digest:     http.Header{"Digest": {"SHA-256=" + encoded}},
+			wantRanged: 10,
+		},
+		{
+			name:       "no digest",
+			wantRanged: 10,
+		},
+		{
+			name:       "mismatch",
+			digest:     http.Header{"Digest": {"SHA-256=" + base64.StdEncoding.EncodeToString(make([]byte, 32))}},
+			wantRanged: 10,
+			wantErr:    ErrChecksumMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ranged atomic.Int32
+			server := rangeServer(t, content, tt.digest, &ranged)
+			defer server.Close()
+
+			c, err := New(server.URL, nil)
+			require.NoError(t, err)
+
+			data, err := c.DownloadFileParallel(context.Background(), "video",
+				WithSegmentSize(1000), WithDownloadConcurrency(3))
+			assert.Equal(t, tt.wantRanged, ranged.Load())
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, content, data)
+		})
+	}
+}
+
+func TestClient_DownloadFileParallel_Fallback(t *testing.T) {
+	content := []byte("small file")
+
+	t.Run("small file", func(t *testing.T) {
+		var ranged atomic.Int32
+		server := rangeServer(t, content, nil, &ranged)
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+		data, err := c.DownloadFileParallel(context.Background(), "file")
+		require.NoError(t, err)
+		assert.Equal(t, content, data)
+		assert.Zero(t, ranged.Load())
+	})
+
+	t.Run("no range support", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, string(content))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+		data, err := c.DownloadFileParallel(context.Background(), "file", WithSegmentSize(2))
+		require.NoError(t, err)
+		assert.Equal(t, content, data)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		server := mockServer(t, http.StatusNotFound, "")
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+		_, err = c.DownloadFileParallel(context.Background(), "file")
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	})
+}
+
+func TestClient_DownloadFileParallel_Changed(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 100)
+	var version atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every request sees a new version of the file.
+		w.Header().Set("ETag", `"`+string(rune('a'+version.Add(1)))+`"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+	_, err = c.DownloadFileParallel(context.Background(), "file", WithSegmentSize(10))
+	assert.ErrorContains(t, err, "file changed")
+}
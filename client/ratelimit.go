@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate limit headers sent by the server with every response.
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// RateLimit is the request budget of the client in the current rate
+// limit window, as last reported by the server.
+type RateLimit struct {
+	// Limit is the number of requests allowed per window, or 0 if the
+	// server did not report it.
+	Limit int
+	// Remaining is the number of requests left in the window, less the
+	// requests sent since it was reported.
+	Remaining int
+	// Reset is when the window ends and the budget is restored.
+	Reset time.Time
+}
+
+// RateLimitHook is called with the budget every time the server
+// reports it, e.g. to export it as a metric.
+type RateLimitHook func(RateLimit)
+
+// WithRateLimitPacing paces requests by the rate limit headers of the
+// server: the remaining requests of a window are spread evenly until
+// its reset, and once the budget is spent, requests wait for the reset
+// instead of being answered with 429. A 429 response pauses requests
+// for its Retry-After duration. hook, if not nil, is called with every
+// budget update.
+func WithRateLimitPacing(hook RateLimitHook) Option {
+	return func(c *Client) {
+		c.pacer = &pacer{hook: hook}
+	}
+}
+
+// RateLimit returns the current request budget. It reports false if
+// rate limit pacing is disabled or the server has not reported a budget
+// yet.
+func (c *Client) RateLimit() (RateLimit, bool) {
+	if c.pacer == nil {
+		return RateLimit{}, false
+	}
+	return c.pacer.budget()
+}
+
+// pacer schedules requests within the rate limit budget.
+type pacer struct {
+	hook RateLimitHook
+
+	mu    sync.Mutex
+	limit RateLimit
+	known bool
+	// next is the earliest time the next request may be sent.
+	next time.Time
+}
+
+func (p *pacer) budget() (RateLimit, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.limit, p.known
+}
+
+// wait blocks until a request may be sent and takes it from the budget.
+func (p *pacer) wait(ctx context.Context) error {
+	p.mu.Lock()
+	now := time.Now()
+	if !p.known || !now.Before(p.limit.Reset) {
+		p.mu.Unlock()
+		return nil
+	}
+	var at time.Time
+	if p.limit.Remaining <= 0 {
+		at = p.limit.Reset
+	} else {
+		at = now
+		if p.next.After(at) {
+			at = p.next
+		}
+		p.next = at.Add(p.limit.Reset.Sub(now) / time.Duration(p.limit.Remaining))
+		p.limit.Remaining--
+	}
+	p.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// update records the budget reported by a response.
+func (p *pacer) update(resp *http.Response) {
+	now := time.Now()
+	limit, ok := parseRateLimit(resp.Header, now)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if d, found := parseRetryAfter(resp.Header.Get("Retry-After"), now); found {
+			limit.Reset = now.Add(d)
+			ok = true
+		}
+		limit.Remaining = 0
+	}
+	if !ok {
+		return
+	}
+
+	p.mu.Lock()
+	if limit.Limit == 0 {
+		limit.Limit = p.limit.Limit
+	}
+	p.limit = limit
+	p.known = true
+	p.mu.Unlock()
+
+	if p.hook != nil {
+		p.hook(limit)
+	}
+}
+
+// parseRateLimit parses the rate limit headers. The reset is either a
+// Unix time or a number of seconds from now.
+func parseRateLimit(h http.Header, now time.Time) (RateLimit, bool) {
+	remaining, err := strconv.Atoi(strings.TrimSpace(h.Get(RateLimitRemainingHeader)))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	reset, err := strconv.ParseInt(strings.TrimSpace(h.Get(RateLimitResetHeader)), 10, 64)
+	if err != nil {
+		return RateLimit{}, false
+	}
+	limit := RateLimit{Remaining: max(remaining, 0)}
+	limit.Limit, _ = strconv.Atoi(strings.TrimSpace(h.Get(RateLimitLimitHeader)))
+	// Deltas are at most a day; anything larger is a Unix time.
+	if reset > 24*60*60 {
+		limit.Reset = time.Unix(reset, 0)
+	} else {
+		limit.Reset = now.Add(time.Duration(reset) * time.Second)
+	}
+	return limit, true
+}
+
+// parseRetryAfter parses a Retry-After value in seconds or as an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
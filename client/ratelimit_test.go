@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRateLimitPacing(t *testing.T) {
+	var mu sync.Mutex
+	var sent []time.Time
+	remaining := 3
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, time.Now())
+		remaining--
+		w.Header().Set(RateLimitLimitHeader, "3")
+		w.Header().Set(RateLimitRemainingHeader, strconv.Itoa(remaining))
+		w.Header().Set(RateLimitResetHeader, "1")
+	}))
+	defer server.Close()
+
+	var budgets []RateLimit
+	c, err := New(server.URL, nil, WithRateLimitPacing(func(l RateLimit) {
+		budgets = append(budgets, l)
+	}))
+	require.NoError(t, err)
+	_, ok := c.RateLimit()
+	assert.False(t, ok)
+
+	for range 3 {
+		require.NoError(t, c.Healthcheck(context.Background()))
+	}
+	require.Len(t, sent, 3)
+	// The first response leaves 2 requests for the next second.
+	assert.GreaterOrEqual(t, sent[2].Sub(sent[0]), 400*time.Millisecond)
+	require.Len(t, budgets, 3)
+	assert.Equal(t, RateLimit{Limit: 3, Remaining: 0, Reset: budgets[2].Reset}, budgets[2])
+
+	limit, ok := c.RateLimit()
+	require.True(t, ok)
+	assert.Zero(t, limit.Remaining)
+
+	// The budget is spent, so the next request waits for the reset.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, c.pacer.wait(ctx), context.DeadlineExceeded)
+}
+
+func TestWithRateLimitPacing_TooManyRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, nil, WithRateLimitPacing(nil))
+	require.NoError(t, err)
+	require.Error(t, c.Healthcheck(context.Background()))
+
+	limit, ok := c.RateLimit()
+	require.True(t, ok)
+	assert.Zero(t, limit.Remaining)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), limit.Reset, time.Second)
+}
+
+func TestParseRateLimit(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   RateLimit
+		ok     bool
+	}{
+		{
+			name:   "delta seconds",
+			header: http.Header{"X-Ratelimit-Remaining": {"5"}, "X-Ratelimit-Reset": {"60"}},
+			want:   RateLimit{Remaining: 5, Reset: now.Add(time.Minute)},
+			ok:     true,
+		},
+		{
+			name: "unix time",
+			header: http.Header{
+				"X-Ratelimit-Limit":     {"100"},
+				"X-Ratelimit-Remaining": {"0"},
+				"X-Ratelimit-Reset":     {"1700000120"},
+			},
+			want: RateLimit{Limit: 100, Reset: now.Add(2 * time.Minute)},
+			ok:   true,
+		},
+		{
+			name:   "missing reset",
+			header: http.Header{"X-Ratelimit-Remaining": {"5"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRateLimit(tt.header, now)
+			assert.Equal(t, tt.ok, ok)
+			assert.True(t, tt.want.Reset.Equal(got.Reset))
+			tt.want.Reset, got.Reset = time.Time{}, time.Time{}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRecorderBodyLimit is the number of bytes of each request and
+// response body a Recorder keeps.
+const DefaultRecorderBodyLimit = 64 << 10
+
+// redactedHeaders are the headers whose values a Recorder never keeps.
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// RecordedCall is a request sent by the client and its response.
+type RecordedCall struct {
+	// ID numbers the calls of a Recorder from 1.
+	ID uint64 `json:"id"`
+	// Operation is the client method that sent the request.
+	Operation string `json:"operation,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	Method    string `json:"method"`
+	// Path is the request path relative to the API base URL, with the
+	// query, e.g. "/plugins/screenshot".
+	Path string `json:"path"`
+	// RequestHeader and ResponseHeader hold the headers, with the values
+	// of credentials and cookies redacted.
+	RequestHeader  http.Header `json:"requestHeader,omitempty"`
+	RequestBody    []byte      `json:"requestBody,omitempty"`
+	StatusCode     int         `json:"statusCode,omitempty"`
+	ResponseHeader http.Header `json:"responseHeader,omitempty"`
+	// ResponseBody holds the part of the response body the caller read.
+	ResponseBody []byte `json:"responseBody,omitempty"`
+	// RequestTruncated reports whether the request body was longer than
+	// the body limit or could not be read; such calls cannot be replayed.
+	RequestTruncated bool `json:"requestTruncated,omitempty"`
+	// ResponseTruncated reports whether the response body was longer
+	// than the body limit.
+	ResponseTruncated bool `json:"responseTruncated,omitempty"`
+	// Error is the transport error of a call without a response.
+	Error string    `json:"error,omitempty"`
+	Start time.Time `json:"start"`
+	// Duration is the time until the response headers were received.
+	Duration time.Duration `json:"duration"`
+}
+
+// Recorder keeps the last requests sent by a client and their responses,
+// for reproducing failures: export a call with Export and send it again
+// with Client.Replay or the replay subcommand of the browserbro-go-api
+// command. It is safe for concurrent use.
+type Recorder struct {
+	size      int
+	bodyLimit int
+
+	mu     sync.Mutex
+	calls  []*RecordedCall
+	nextID uint64
+}
+
+// NewRecorder creates a Recorder keeping the last size calls.
+func NewRecorder(size int) *Recorder {
+	return &Recorder{size: max(size, 1), bodyLimit: DefaultRecorderBodyLimit}
+}
+
+// WithRecorder records every request sent by the client in r.
+// Encrypted payloads are recorded as sent, so replaying them requires
+// a client with the same KMS.
+func WithRecorder(r *Recorder) Option {
+	return func(c *Client) {
+		c.recorder = r
+	}
+}
+
+// Calls returns the recorded calls, oldest first.
+func (r *Recorder) Calls() []RecordedCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls := make([]RecordedCall, len(r.calls))
+	for i, call := range r.calls {
+		calls[i] = *call
+	}
+	return calls
+}
+
+// Call returns the recorded call with the given ID, if still kept.
+func (r *Recorder) Call(id uint64) (RecordedCall, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, call := range r.calls {
+		if call.ID == id {
+			return *call, true
+		}
+	}
+	return RecordedCall{}, false
+}
+
+// Export writes the calls with the given IDs, or all calls if none are
+// given, to w as JSON lines, the format read by ReadRecordedCalls.
+func (r *Recorder) Export(w io.Writer, ids ...uint64) error {
+	enc := json.NewEncoder(w)
+	for _, call := range r.Calls() {
+		if len(ids) > 0 && !containsID(ids, call.ID) {
+			continue
+		}
+		if err := enc.Encode(call); err != nil {
+			return fmt.Errorf("failed to export call %d: %w", call.ID, err)
+		}
+	}
+	return nil
+}
+
+func containsID(ids []uint64, id uint64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadRecordedCalls reads calls written by Recorder.Export.
+func ReadRecordedCalls(rd io.Reader) ([]RecordedCall, error) {
+	var calls []RecordedCall
+	sc := bufio.NewScanner(rd)
+	sc.Buffer(nil, 16<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var call RecordedCall
+		if err := json.Unmarshal(sc.Bytes(), &call); err != nil {
+			return nil, fmt.Errorf("failed to decode call on line %d: %w", line, err)
+		}
+		calls = append(calls, call)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calls: %w", err)
+	}
+	return calls, nil
+}
+
+// record starts recording req, sent by c under op, if any.
+func (r *Recorder) record(c *Client, req *http.Request, op *operation) *RecordedCall {
+	call := &RecordedCall{
+		Method:        req.Method,
+		Path:          strings.TrimPrefix(req.URL.String(), c.addr),
+		RequestHeader: redact(req.Header),
+		Start:         time.Now(),
+	}
+	if op != nil {
+		call.Operation = op.Name
+		op.mu.Lock()
+		call.RequestID = op.RequestID
+		op.mu.Unlock()
+	}
+	switch {
+	case req.Body == nil || req.Body == http.NoBody:
+	case req.GetBody != nil:
+		if body, err := req.GetBody(); err == nil {
+			call.RequestBody, call.RequestTruncated = readLimited(body, r.bodyLimit)
+			_ = body.Close()
+		} else {
+			call.RequestTruncated = true
+		}
+	default:
+		// The body can be read only once, by the transport.
+		call.RequestTruncated = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	call.ID = r.nextID
+	r.calls = append(r.calls, call)
+	if len(r.calls) > r.size {
+		r.calls[0] = nil
+		r.calls = r.calls[1:]
+	}
+	return call
+}
+
+// finish records the outcome of call. The response body is recorded as
+// the caller reads it.
+func (r *Recorder) finish(call *RecordedCall, resp *http.Response, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	call.Duration = time.Since(call.Start)
+	if err != nil {
+		call.Error = err.Error()
+		return
+	}
+	call.StatusCode = resp.StatusCode
+	call.ResponseHeader = redact(resp.Header)
+	if id := resp.Header.Get(RequestIDHeader); id != "" {
+		call.RequestID = id
+	}
+	resp.Body = &recordingBody{ReadCloser: resp.Body, r: r, call: call}
+}
+
+// recordingBody copies up to the body limit of a response body into its call.
+type recordingBody struct {
+	io.ReadCloser
+	r    *Recorder
+	call *RecordedCall
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.r.mu.Lock()
+		if room := b.r.bodyLimit - len(b.call.ResponseBody); room < n {
+			b.call.ResponseBody = append(b.call.ResponseBody, p[:max(room, 0)]...)
+			b.call.ResponseTruncated = true
+		} else {
+			b.call.ResponseBody = append(b.call.ResponseBody, p[:n]...)
+		}
+		b.r.mu.Unlock()
+	}
+	return n, err
+}
+
+func readLimited(r io.Reader, limit int) ([]byte, bool) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return data, true
+	}
+	if len(data) > limit {
+		return data[:limit], true
+	}
+	return data, false
+}
+
+func redact(h http.Header) http.Header {
+	h = h.Clone()
+	for _, name := range redactedHeaders {
+		if _, ok := h[name]; ok {
+			h[name] = []string{"REDACTED"}
+		}
+	}
+	return h
+}
+
+// ErrNotReplayable is returned by Replay for calls whose request was not
+// fully recorded.
+var ErrNotReplayable = errors.New("call is not replayable")
+
+// Replay sends the request of a recorded call to the server of c, with
+// the credentials of c instead of the redacted ones, and returns the new
+// call, e.g. to reproduce a production failure against staging. The
+// response body is read in full.
+func (c *Client) Replay(ctx context.Context, call RecordedCall) (_ *RecordedCall, err error) {
+	ctx, op := c.startOperation(ctx, "Replay")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	if call.RequestTruncated || call.Method == "" {
+		return nil, fmt.Errorf("%w: request %d was not fully recorded", ErrNotReplayable, call.ID)
+	}
+	req, err := newBodyRequest(ctx, call.Method, c.addr+call.Path, "", call.RequestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create replay request: %w", err)
+	}
+	for name, values := range call.RequestHeader {
+		if len(values) == 1 && values[0] == "REDACTED" || name == RequestIDHeader {
+			continue
+		}
+		req.Header[name] = values
+	}
+	if len(call.RequestBody) == 0 {
+		req.Body, req.GetBody, req.ContentLength = http.NoBody, nil, 0
+	}
+
+	replayed := &RecordedCall{
+		ID:            call.ID,
+		Operation:     call.Operation,
+		Method:        call.Method,
+		Path:          call.Path,
+		RequestHeader: redact(req.Header),
+		RequestBody:   call.RequestBody,
+		Start:         time.Now(),
+	}
+	resp, err := c.do(req)
+	replayed.Duration = time.Since(replayed.Start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay call: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
+
+	replayed.StatusCode = resp.StatusCode
+	replayed.ResponseHeader = redact(resp.Header)
+	replayed.RequestID = resp.Header.Get(RequestIDHeader)
+	if replayed.ResponseBody, err = io.ReadAll(resp.Body); err != nil {
+		return nil, fmt.Errorf("failed to read replay response: %w", err)
+	}
+	return replayed, nil
+}
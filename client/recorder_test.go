@@ -0,0 +1,98 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	var keys []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/plugins/screenshot", func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(APIKeyHeader))
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "session=secret")
+		if strings.Contains(string(body), "broken") {
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(`{"message": "navigation failed"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"screenshot": {"fileIds": ["f1"]}}`))
+	})
+	mux.HandleFunc("GET /api/v1/health", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	rec := NewRecorder(2)
+	c, err := New(server.URL, nil, WithRecorder(rec), WithCredentialProvider(credentialsFunc(
+		func(ctx context.Context) (Credentials, error) {
+			return Credentials{APIKey: "key"}, nil
+		},
+	)))
+	require.NoError(t, err)
+
+	require.NoError(t, c.Healthcheck(context.Background()))
+	_, err = c.RunPlugin(context.Background(), "screenshot", map[string]any{"urls": []string{"https://example.com"}})
+	require.NoError(t, err)
+	_, err = c.RunPlugin(context.Background(), "screenshot", map[string]any{"urls": []string{"https://broken.example.com"}})
+	require.Error(t, err)
+
+	calls := rec.Calls()
+	require.Len(t, calls, 2)
+	assert.Equal(t, uint64(2), calls[0].ID)
+	assert.Equal(t, "RunPlugin", calls[0].Operation)
+	assert.Equal(t, "/plugins/screenshot", calls[0].Path)
+	assert.JSONEq(t, `{"urls": ["https://example.com"]}`, string(calls[0].RequestBody))
+	assert.Equal(t, http.StatusOK, calls[0].StatusCode)
+	assert.JSONEq(t, `{"screenshot": {"fileIds": ["f1"]}}`, string(calls[0].ResponseBody))
+	assert.Equal(t, "REDACTED", calls[0].RequestHeader.Get(APIKeyHeader))
+	assert.Equal(t, "REDACTED", calls[0].ResponseHeader.Get("Set-Cookie"))
+	assert.NotEmpty(t, calls[0].RequestID)
+	assert.Equal(t, http.StatusBadGateway, calls[1].StatusCode)
+	_, ok := rec.Call(1)
+	assert.False(t, ok)
+
+	var buf bytes.Buffer
+	require.NoError(t, rec.Export(&buf, 3))
+	exported, err := ReadRecordedCalls(&buf)
+	require.NoError(t, err)
+	require.Len(t, exported, 1)
+	assert.Equal(t, calls[1].RequestBody, exported[0].RequestBody)
+
+	replayed, err := c.Replay(context.Background(), exported[0])
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, replayed.StatusCode)
+	assert.JSONEq(t, `{"message": "navigation failed"}`, string(replayed.ResponseBody))
+	assert.Equal(t, []string{"key", "key", "key"}, keys)
+	assert.Len(t, rec.Calls(), 2)
+}
+
+func TestRecorder_Truncated(t *testing.T) {
+	server := mockServer(t, http.StatusOK, `{"screenshot": {"fileIds": ["f1"]}}`)
+	defer server.Close()
+
+	rec := NewRecorder(10)
+	rec.bodyLimit = 8
+	c, err := New(server.URL, nil, WithRecorder(rec))
+	require.NoError(t, err)
+
+	_, err = c.RunPlugin(context.Background(), "screenshot", map[string]any{"urls": []string{"https://example.com"}})
+	require.NoError(t, err)
+
+	call, ok := rec.Call(1)
+	require.True(t, ok)
+	assert.True(t, call.RequestTruncated)
+	assert.True(t, call.ResponseTruncated)
+	assert.Len(t, call.ResponseBody, 8)
+
+	_, err = c.Replay(context.Background(), call)
+	assert.ErrorIs(t, err, ErrNotReplayable)
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// NewRequest creates a request to an endpoint of the server's API that
+// this package does not wrap, for use with Do. path is relative to the
+// API base URL, e.g. "/jobs/123". A non-nil body is sent as is if it is
+// an io.Reader and JSON-encoded otherwise, encrypted when payload
+// encryption is enabled.
+func (c *Client) NewRequest(ctx context.Context, method, path string, body any) (*http.Request, error) {
+	url := c.addr + "/" + strings.TrimPrefix(path, "/")
+
+	switch b := body.(type) {
+	case nil:
+		return c.newRequest(ctx, method, url, nil)
+	case io.Reader:
+		return c.newRequest(ctx, method, url, b)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to JSON encode body: %w", err)
+	}
+	data, contentType, err := c.sealBody(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newBodyRequest(ctx, method, url, contentType, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.kms != nil {
+		req.Header.Set("Accept", EnvelopeContentType)
+	}
+	return req, nil
+}
+
+func (c *Client) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.kms != nil {
+		req.Header.Set("Accept", EnvelopeContentType)
+	}
+	return req, nil
+}
+
+// Do sends req with the client's authentication, metadata, hooks and
+// error mapping: a non-2xx response is returned as an *APIError.
+// The response body is decoded into out unless out is nil or the
+// response has no content. If out is a *[]byte, it receives the raw body.
+func (c *Client) Do(req *http.Request, out any) (err error) {
+	ctx, op := c.startOperation(req.Context(), "Do")
+	defer func() { c.finishOperation(ctx, op, err) }()
+	req = req.WithContext(ctx)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return c.newAPIError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if raw, ok := out.(*[]byte); ok {
+		if *raw, err = readBody(resp); err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		op.result = *raw
+		return nil
+	}
+	if err := c.decodeBody(ctx, resp, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	op.result = out
+	return nil
+}
+
+// GetJSON sends a GET request to path, relative to the API base URL,
+// and decodes the JSON response into out. See Do.
+func (c *Client) GetJSON(ctx context.Context, path string, out any) error {
+	req, err := c.NewRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	return c.Do(req, out)
+}
+
+// PostJSON sends in, JSON-encoded, in a POST request to path, relative to
+// the API base URL, and decodes the JSON response into out. See Do.
+func (c *Client) PostJSON(ctx context.Context, path string, in, out any) error {
+	req, err := c.NewRequest(ctx, http.MethodPost, path, in)
+	if err != nil {
+		return err
+	}
+	return c.Do(req, out)
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDo(t *testing.T) {
+	var method, path string
+	var params map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		_, _ = w.Write([]byte(`{"id":"job-1","status":"queued"}`))
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	req, err := c.NewRequest(context.Background(), http.MethodPost, "/jobs", map[string]any{"plugin": "screenshot"})
+	require.NoError(t, err)
+	var job struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	require.NoError(t, c.Do(req, &job))
+	assert.Equal(t, http.MethodPost, method)
+	assert.Equal(t, "/api/v1/jobs", path)
+	assert.Equal(t, map[string]any{"plugin": "screenshot"}, params)
+	assert.Equal(t, "job-1", job.ID)
+	assert.Equal(t, "queued", job.Status)
+
+	req, err = c.NewRequest(context.Background(), http.MethodGet, "jobs/job-1", nil)
+	require.NoError(t, err)
+	var raw []byte
+	require.NoError(t, c.Do(req, &raw))
+	assert.Equal(t, "/api/v1/jobs/job-1", path)
+	assert.JSONEq(t, `{"id":"job-1","status":"queued"}`, string(raw))
+
+	req, err = c.NewRequest(context.Background(), http.MethodPut, "/jobs/job-1", strings.NewReader("raw"))
+	require.NoError(t, err)
+	require.NoError(t, c.Do(req, nil))
+	assert.Equal(t, http.MethodPut, method)
+}
+
+func TestDoError(t *testing.T) {
+	server := mockServer(t, http.StatusNotFound, `{"message":"no such job"}`)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	req, err := c.NewRequest(context.Background(), http.MethodGet, "/jobs/missing", nil)
+	require.NoError(t, err)
+	err = c.Do(req, nil)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "no such job", apiErr.Message)
+}
+
+func TestGetPostJSON(t *testing.T) {
+	var params map[string]any
+	server := captureServer(t, http.StatusOK, `{"id":"job-1"}`, &params)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	var out map[string]any
+	require.NoError(t, c.PostJSON(context.Background(), "/jobs", map[string]any{"plugin": "crawler"}, &out))
+	assert.Equal(t, map[string]any{"plugin": "crawler"}, params)
+	assert.Equal(t, map[string]any{"id": "job-1"}, out)
+
+	out = nil
+	require.NoError(t, c.GetJSON(context.Background(), "/jobs/job-1", &out))
+	assert.Equal(t, map[string]any{"id": "job-1"}, out)
+
+	failing := mockServer(t, http.StatusBadRequest, `{"message":"bad job"}`)
+	defer failing.Close()
+	c, err = New(failing.URL, nil)
+	require.NoError(t, err)
+	err = c.GetJSON(context.Background(), "/jobs/job-1", &out)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "bad job", apiErr.Message)
+}
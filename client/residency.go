@@ -0,0 +1,104 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrResidencyUnsatisfied is returned when no configured server is in
+// a region approved by WithResidency. No request is sent in that case.
+var ErrResidencyUnsatisfied = errors.New("no server in an approved region")
+
+type regionalServer struct {
+	region string
+	addr   string
+}
+
+// WithRegion sets the region of the server passed to New.
+func WithRegion(region string) Option {
+	return func(c *Client) {
+		c.region = region
+	}
+}
+
+// WithRegionalServer adds a server in another region. Runs that set
+// WithResidency are routed to it when the primary server's region is
+// not approved. Servers are tried in the order they were added.
+func WithRegionalServer(region, serverAddress string) Option {
+	return func(c *Client) {
+		if region == "" || serverAddress == "" {
+			c.optionError(errors.New("region and server address are required"))
+			return
+		}
+		c.regionalServers = append(c.regionalServers, regionalServer{
+			region: region,
+			addr:   apiAddress(serverAddress),
+		})
+	}
+}
+
+// WithResidency restricts the run to servers in the given regions, so the
+// job and the artifacts it creates stay in them. The run fails with
+// ErrResidencyUnsatisfied if no server is in an approved region.
+// Download the run's files with the client returned by InRegion for the
+// region reported in RunResult.Region.
+func WithResidency(regions ...string) RunOption {
+	return func(cfg *runConfig) {
+		cfg.residency = regions
+	}
+}
+
+// Region returns the region of the client's server, if set.
+func (c *Client) Region() string {
+	return c.region
+}
+
+// InRegion returns a client that sends every call to the server of
+// the given region. It shares the options of c.
+func (c *Client) InRegion(region string) (*Client, error) {
+	if c.region == region {
+		return c, nil
+	}
+	for _, s := range c.regionalServers {
+		if s.region == region {
+			rc := *c
+			rc.addr = s.addr
+			rc.region = s.region
+			return &rc, nil
+		}
+	}
+	return nil, errors.New("no server in region " + region)
+}
+
+// resident returns the client to run a job on under the given residency
+// requirement: the best-ranked reachable approved server if p is set,
+// otherwise c itself if its region is approved or there is no
+// requirement, otherwise the first regional server in an approved region.
+func (c *Client) resident(regions []string, p *Prober) (*Client, error) {
+	if p != nil {
+		candidates := regions
+		if len(candidates) == 0 {
+			candidates = []string{c.region}
+			for _, s := range c.regionalServers {
+				candidates = append(candidates, s.region)
+			}
+		}
+		if region, ok := p.best(candidates); ok {
+			return c.InRegion(region)
+		}
+	}
+	if len(regions) == 0 {
+		return c, nil
+	}
+	if slices.Contains(regions, c.region) && c.region != "" {
+		return c, nil
+	}
+	for _, s := range c.regionalServers {
+		if slices.Contains(regions, s.region) {
+			return c.InRegion(s.region)
+		}
+	}
+	return nil, fmt.Errorf("%w: approved regions %s", ErrResidencyUnsatisfied, strings.Join(regions, ", "))
+}
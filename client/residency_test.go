@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func regionServer(t *testing.T, region string, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`{"region": "` + region + `"}`))
+	}))
+}
+
+func TestWithResidency(t *testing.T) {
+	var usRequests, euRequests atomic.Int32
+	us := regionServer(t, "us", &usRequests)
+	defer us.Close()
+	eu := regionServer(t, "eu", &euRequests)
+	defer eu.Close()
+
+	c, err := New(us.URL, nil, WithRegion("us"), WithRegionalServer("eu", eu.URL))
+	require.NoError(t, err)
+	assert.Equal(t, "us", c.Region())
+
+	t.Run("no requirement", func(t *testing.T) {
+		result, err := c.RunPluginResult(context.Background(), "screenshot", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "us", result.Output["region"])
+		assert.Equal(t, "us", result.Region)
+	})
+
+	t.Run("primary approved", func(t *testing.T) {
+		output, err := c.RunPlugin(context.Background(), "screenshot", nil, WithResidency("eu", "us"))
+		require.NoError(t, err)
+		assert.Equal(t, "us", output["region"])
+	})
+
+	t.Run("routed", func(t *testing.T) {
+		result, err := c.RunPluginResult(context.Background(), "screenshot", nil, WithResidency("eu"))
+		require.NoError(t, err)
+		assert.Equal(t, "eu", result.Output["region"])
+		assert.Equal(t, "eu", result.Region)
+
+		items, err := c.RunPluginStream(context.Background(), "crawl", nil, WithResidency("eu"))
+		require.NoError(t, err)
+		item := <-items
+		require.NoError(t, item.Err)
+		assert.Equal(t, "eu", item.Output["region"])
+
+		rc, err := c.InRegion(result.Region)
+		require.NoError(t, err)
+		data, err := rc.DownloadFile(context.Background(), "file1")
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "eu")
+	})
+
+	t.Run("unsatisfied", func(t *testing.T) {
+		before := usRequests.Load() + euRequests.Load()
+		_, err := c.RunPlugin(context.Background(), "screenshot", nil, WithResidency("ap"))
+		assert.ErrorIs(t, err, ErrResidencyUnsatisfied)
+		assert.EqualError(t, err, "no server in an approved region: approved regions ap")
+		assert.Equal(t, before, usRequests.Load()+euRequests.Load())
+	})
+}
+
+func TestClient_InRegion(t *testing.T) {
+	c, err := New("http://us.example.com", nil, WithRegion("us"), WithRegionalServer("eu", "http://eu.example.com"))
+	require.NoError(t, err)
+
+	same, err := c.InRegion("us")
+	require.NoError(t, err)
+	assert.Same(t, c, same)
+
+	eu, err := c.InRegion("eu")
+	require.NoError(t, err)
+	assert.Equal(t, "http://eu.example.com/api/v1", eu.addr)
+	assert.Equal(t, "eu", eu.Region())
+
+	_, err = c.InRegion("ap")
+	assert.EqualError(t, err, "no server in region ap")
+
+	_, err = New("http://us.example.com", nil, WithRegionalServer("eu", ""))
+	assert.EqualError(t, err, "region and server address are required")
+}
@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrUnknownPlugin matches errors of plugin names that do not resolve
+// to a plugin of the server.
+var ErrUnknownPlugin = errors.New("unknown plugin")
+
+// UnknownPluginError is returned by ResolvePlugin when a name matches
+// no plugin. Suggestions lists the closest plugin names, if any.
+type UnknownPluginError struct {
+	Name        string
+	Suggestions []string
+}
+
+func (e *UnknownPluginError) Error() string {
+	msg := "unknown plugin " + strconv.Quote(e.Name)
+	if len(e.Suggestions) > 0 {
+		quoted := make([]string, len(e.Suggestions))
+		for i, s := range e.Suggestions {
+			quoted[i] = strconv.Quote(s)
+		}
+		msg += "; did you mean " + strings.Join(quoted, " or ") + "?"
+	}
+	return msg
+}
+
+func (e *UnknownPluginError) Is(target error) bool {
+	return target == ErrUnknownPlugin || target == ErrPluginNotFound
+}
+
+// ResolvePlugin returns the name of the server plugin that name refers to.
+// Names match exactly or after normalization, which ignores case and
+// separators, so "Google-Search" resolves to "googlesearch". Otherwise an
+// *UnknownPluginError suggests the plugins within a small edit distance.
+// The plugin list is fetched from the server on every call.
+func (c *Client) ResolvePlugin(ctx context.Context, name string) (string, error) {
+	plugins, err := c.Plugins(ctx)
+	if err != nil {
+		return "", err
+	}
+	return resolvePlugin(name, plugins)
+}
+
+// RunResolved runs the plugin name resolves to, like RunPluginResult.
+// It eases migrations when plugin names change on the server, at the
+// cost of fetching the plugin list before every run.
+func (c *Client) RunResolved(
+	ctx context.Context,
+	name string,
+	params map[string]any,
+	opts ...RunOption,
+) (*RunResult, error) {
+	pluginName, err := c.ResolvePlugin(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return c.RunPluginResult(ctx, pluginName, params, opts...)
+}
+
+func resolvePlugin(name string, plugins []string) (string, error) {
+	for _, p := range plugins {
+		if p == name {
+			return p, nil
+		}
+	}
+	norm := normalizePluginName(name)
+	var matches []string
+	for _, p := range plugins {
+		if normalizePluginName(p) == norm {
+			matches = append(matches, p)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	if len(matches) > 1 {
+		sort.Strings(matches)
+		return "", &UnknownPluginError{Name: name, Suggestions: matches}
+	}
+
+	// Allow about one typo per three characters.
+	maxDist := max(1, len(norm)/3)
+	type candidate struct {
+		name string
+		dist int
+	}
+	var candidates []candidate
+	for _, p := range plugins {
+		if d := editDistance(norm, normalizePluginName(p)); d <= maxDist {
+			candidates = append(candidates, candidate{p, d})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].dist != candidates[j].dist {
+			return candidates[i].dist < candidates[j].dist
+		}
+		return candidates[i].name < candidates[j].name
+	})
+	err := &UnknownPluginError{Name: name}
+	for _, cand := range candidates {
+		err.Suggestions = append(err.Suggestions, cand.name)
+	}
+	return "", err
+}
+
+// normalizePluginName lowercases name and drops everything
+// but letters and digits.
+func normalizePluginName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
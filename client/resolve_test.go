@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePlugin(t *testing.T) {
+	plugins := []string{"screenshot", "googlesearch", "google_search", "pdf", "Crawler"}
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		{name: "screenshot", want: "screenshot"},
+		{name: "google_search", want: "google_search"},
+		{name: "Screen-Shot", want: "screenshot"},
+		{name: "crawler", want: "Crawler"},
+		{name: "Google Search", wantErr: `unknown plugin "Google Search"; did you mean "google_search" or "googlesearch"?`},
+		{name: "screnshot", wantErr: `unknown plugin "screnshot"; did you mean "screenshot"?`},
+		{name: "pfd", wantErr: `unknown plugin "pfd"`},
+		{name: "video", wantErr: `unknown plugin "video"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolvePlugin(tt.name, plugins)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.ErrorIs(t, err, ErrUnknownPlugin)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("", ""))
+	assert.Equal(t, 3, editDistance("", "abc"))
+	assert.Equal(t, 1, editDistance("screnshot", "screenshot"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+}
+
+func TestClient_RunResolved(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/plugins", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"plugins": ["googlesearch"]}`))
+	})
+	mux.HandleFunc("POST /api/v1/plugins/googlesearch", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"googlesearch": "ok"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	result, err := c.RunResolved(context.Background(), "Google-Search", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result.Output["googlesearch"])
+
+	_, err = c.RunResolved(context.Background(), "bing", nil)
+	assert.ErrorIs(t, err, ErrUnknownPlugin)
+}
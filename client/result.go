@@ -0,0 +1,96 @@
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RunResult is the output of a plugin run together with its execution metadata.
+type RunResult struct {
+	// Output is the plugin output, as returned by RunPlugin.
+	Output map[string]any
+	// JobID is the server-assigned identifier of the run, if reported.
+	JobID string
+	// Browser is the browser the server used for the run, if reported.
+	Browser string
+	// Timings are the server-reported phase durations, keyed by phase name.
+	Timings map[string]time.Duration
+	// Duration is the total client-side time spent on the run.
+	Duration time.Duration
+	// Retries is the number of additional runs the client performed.
+	Retries int
+	// Attempts lists every run made under a BotDetectionPolicy.
+	// It is empty when the plugin ran only once.
+	Attempts []Attempt
+	// Warnings are server-reported warnings about the run.
+	Warnings []string
+	// NextCursor identifies the next page of an oversized output.
+	// It is empty for the last page.
+	NextCursor string
+	// Region is the region of the server that ran the plugin, if set.
+	Region string
+	// Deprecation is set when the server announced that the plugin
+	// or endpoint is deprecated.
+	Deprecation *Deprecation
+
+	client     *Client
+	pluginName string
+}
+
+func (c *Client) newRunResult(pluginName string, h http.Header) *RunResult {
+	r := &RunResult{
+		client:      c,
+		pluginName:  pluginName,
+		Region:      c.region,
+		NextCursor:  h.Get("X-Next-Cursor"),
+		JobID:       h.Get("X-Job-ID"),
+		Browser:     h.Get("X-Browser"),
+		Timings:     parseServerTiming(h.Values("Server-Timing")),
+		Deprecation: parseDeprecation(h),
+	}
+	for _, v := range h.Values("Warning") {
+		r.Warnings = append(r.Warnings, parseWarning(v))
+	}
+	return r
+}
+
+// parseServerTiming parses Server-Timing header values such as
+// `launch;dur=120.5, navigate;desc="Navigation";dur=830`.
+func parseServerTiming(values []string) map[string]time.Duration {
+	timings := make(map[string]time.Duration)
+	for _, value := range values {
+		for _, metric := range strings.Split(value, ",") {
+			parts := strings.Split(metric, ";")
+			name := strings.TrimSpace(parts[0])
+			if name == "" {
+				continue
+			}
+			for _, param := range parts[1:] {
+				k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || k != "dur" {
+					continue
+				}
+				ms, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					continue
+				}
+				timings[name] = time.Duration(ms * float64(time.Millisecond))
+			}
+		}
+	}
+	return timings
+}
+
+// parseWarning extracts the text of a Warning header value such as
+// `299 - "plugin is deprecated"`, returning the raw value if it is
+// not in that form.
+func parseWarning(value string) string {
+	start := strings.Index(value, `"`)
+	end := strings.LastIndex(value, `"`)
+	if start >= 0 && end > start {
+		return value[start+1 : end]
+	}
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RunPluginResult(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Job-ID", "job-1")
+			w.Header().Set("X-Browser", "chromium/124")
+			w.Header().Add("Server-Timing", `launch;dur=120.5, navigate;desc="Navigation";dur=830`)
+			w.Header().Add("Warning", `299 - "viewport was clamped"`)
+			_, _ = w.Write([]byte(`{"screenshot": {"key": "value"}}`))
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		result, err := c.RunPluginResult(context.Background(), "screenshot", nil)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"screenshot": map[string]any{"key": "value"}}, result.Output)
+		assert.Equal(t, "job-1", result.JobID)
+		assert.Equal(t, "chromium/124", result.Browser)
+		assert.Equal(t, map[string]time.Duration{
+			"launch":   120500 * time.Microsecond,
+			"navigate": 830 * time.Millisecond,
+		}, result.Timings)
+		assert.Equal(t, []string{"viewport was clamped"}, result.Warnings)
+		assert.Zero(t, result.Retries)
+		assert.Positive(t, result.Duration)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		server := mockServer(t, http.StatusInternalServerError, `{"message": "something went wrong"}`)
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		result, err := c.RunPluginResult(context.Background(), "screenshot", nil)
+		require.EqualError(
+			t,
+			err,
+			"unexpected response status: 500 Internal Server Error; message: something went wrong",
+		)
+		require.Nil(t, result)
+	})
+
+	t.Run("bot detection retries", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				_, _ = w.Write([]byte(`{"blocked": true}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		result, err := c.RunPluginResult(context.Background(), "screenshot", nil, WithBotDetectionRetry(BotDetectionPolicy{
+			Detect: func(output map[string]any) bool { return output["blocked"] == true },
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Retries)
+		assert.Len(t, result.Attempts, 2)
+	})
+}
+
+func TestParseWarning(t *testing.T) {
+	assert.Equal(t, "deprecated", parseWarning(`299 - "deprecated"`))
+	assert.Equal(t, "plain text", parseWarning(" plain text "))
+}
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// DefaultMaxRetryAfter is the longest Retry-After delay WithRetry waits
+// for. Responses asking for a longer delay are returned as they are.
+const DefaultMaxRetryAfter = time.Minute
+
+// BackoffPolicy returns the delay before retry number attempt,
+// starting at 1.
+type BackoffPolicy interface {
+	Backoff(attempt int) time.Duration
+}
+
+// ExponentialBackoff waits Initial before the first retry and
+// Multiplier times longer before every further one, up to Max. Each
+// delay is shortened by a random fraction of up to Jitter, so clients
+// failing together do not retry together.
+type ExponentialBackoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	// Jitter is between 0 and 1.
+	Jitter float64
+}
+
+// DefaultBackoff is the BackoffPolicy used by WithRetry unless another
+// is given.
+var DefaultBackoff = ExponentialBackoff{
+	Initial:    200 * time.Millisecond,
+	Max:        10 * time.Second,
+	Multiplier: 2,
+	Jitter:     0.5,
+}
+
+// Backoff implements BackoffPolicy.
+func (b ExponentialBackoff) Backoff(attempt int) time.Duration {
+	d := float64(b.Initial)
+	for i := 1; i < attempt && (b.Max <= 0 || d < float64(b.Max)); i++ {
+		d *= max(b.Multiplier, 1)
+	}
+	if b.Max > 0 {
+		d = min(d, float64(b.Max))
+	}
+	d -= d * min(max(b.Jitter, 0), 1) * rand.Float64()
+	return time.Duration(d)
+}
+
+// WithRetry sends every request up to maxAttempts times while it fails
+// transiently: with a connection error, a timeout of the HTTP client,
+// or a 408, 425, 429, 502, 503 or 504 response, or another status
+// registered as retryable with WithStatusPolicy. Retries wait for
+// backoff, or DefaultBackoff if nil, or for the Retry-After delay of
+// the response, if longer. Requests with a body that cannot be sent
+// again, and requests whose context is done, are not retried. Every
+// attempt is counted in Operation.Attempts.
+func WithRetry(maxAttempts int, backoff BackoffPolicy) Option {
+	return func(c *Client) {
+		if backoff == nil {
+			backoff = DefaultBackoff
+		}
+		c.retry = &retrier{
+			maxAttempts:   max(maxAttempts, 1),
+			backoff:       backoff,
+			maxRetryAfter: DefaultMaxRetryAfter,
+			client:        c,
+		}
+	}
+}
+
+type retrier struct {
+	maxAttempts   int
+	backoff       BackoffPolicy
+	maxRetryAfter time.Duration
+	client        *Client
+}
+
+// do sends req with send until an attempt succeeds, fails permanently
+// or the attempts are used up.
+func (r *retrier) do(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		resp, err := send(req)
+		if attempt >= r.maxAttempts || ctx.Err() != nil {
+			return resp, err
+		}
+		delay, ok := r.delay(attempt, resp, err)
+		if !ok {
+			return resp, err
+		}
+		next, rewound := rewind(req)
+		if !rewound {
+			return resp, err
+		}
+		if resp != nil {
+			closeBody(resp.Body)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		req = next
+	}
+}
+
+// delay reports whether the outcome of an attempt is transient and how
+// long to wait before retrying it.
+func (r *retrier) delay(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	backoff := r.backoff.Backoff(attempt)
+	if err != nil {
+		return backoff, IsRetryable(transportError(err))
+	}
+	retryable := retryableStatus(resp.StatusCode)
+	if p, ok := r.client.statusPolicies[resp.StatusCode]; ok {
+		retryable = p.Retryable
+	}
+	if !retryable {
+		return 0, false
+	}
+	if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		if d > r.maxRetryAfter {
+			return 0, false
+		}
+		return max(d, backoff), true
+	}
+	return backoff, true
+}
+
+// rewind returns a copy of req to send again, if its body can be
+// replayed.
+func rewind(req *http.Request) (*http.Request, bool) {
+	next := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return next, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	next.Body = body
+	return next, true
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testBackoff = ExponentialBackoff{Initial: time.Millisecond, Max: time.Millisecond}
+
+func TestWithRetry(t *testing.T) {
+	var attempts atomic.Int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		switch attempts.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = w.Write([]byte(`{"screenshot": {}}`))
+		}
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, nil, WithRetry(3, testBackoff))
+	require.NoError(t, err)
+	_, err = c.RunPlugin(context.Background(), "screenshot", map[string]any{"urls": []string{"https://example.com"}})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), attempts.Load())
+	assert.Equal(t, bodies[0], bodies[2])
+	assert.NotEmpty(t, bodies[0])
+}
+
+func TestWithRetry_GivesUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		header   http.Header
+		options  []Option
+		attempts int32
+	}{
+		{name: "attempts used up", status: http.StatusServiceUnavailable, attempts: 3},
+		{name: "permanent status", status: http.StatusBadRequest, attempts: 1},
+		{
+			name:     "long retry after",
+			status:   http.StatusTooManyRequests,
+			header:   http.Header{"Retry-After": {"3600"}},
+			attempts: 1,
+		},
+		{
+			name:     "status policy",
+			status:   http.StatusConflict,
+			options:  []Option{WithStatusPolicy(http.StatusConflict, StatusPolicy{Retryable: true})},
+			attempts: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				for k, v := range tt.header {
+					w.Header()[k] = v
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			var failed Operation
+			opts := append([]Option{
+				WithRetry(3, testBackoff),
+				WithErrorHook(func(ctx context.Context, op Operation, err error) { failed = op }),
+			}, tt.options...)
+			c, err := New(server.URL, nil, opts...)
+			require.NoError(t, err)
+
+			var apiErr *APIError
+			require.ErrorAs(t, c.Healthcheck(context.Background()), &apiErr)
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, tt.attempts, attempts.Load())
+			assert.Equal(t, int(tt.attempts), failed.Attempts)
+		})
+	}
+}
+
+func TestWithRetry_Context(t *testing.T) {
+	server := mockServer(t, http.StatusServiceUnavailable, "")
+	defer server.Close()
+
+	c, err := New(server.URL, nil, WithRetry(5, ExponentialBackoff{Initial: time.Hour}))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = c.Healthcheck(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWithRetry_ConnectionError(t *testing.T) {
+	server := mockServer(t, http.StatusOK, "")
+	server.Close()
+
+	var failed Operation
+	c, err := New(server.URL, nil, WithRetry(2, testBackoff),
+		WithErrorHook(func(ctx context.Context, op Operation, err error) { failed = op }))
+	require.NoError(t, err)
+	require.Error(t, c.Healthcheck(context.Background()))
+	assert.Equal(t, 2, failed.Attempts)
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := ExponentialBackoff{Initial: 100 * time.Millisecond, Max: time.Second, Multiplier: 2}
+	assert.Equal(t, 100*time.Millisecond, b.Backoff(1))
+	assert.Equal(t, 200*time.Millisecond, b.Backoff(2))
+	assert.Equal(t, 800*time.Millisecond, b.Backoff(4))
+	assert.Equal(t, time.Second, b.Backoff(50))
+
+	b.Jitter = 0.5
+	for range 20 {
+		d := b.Backoff(2)
+		assert.GreaterOrEqual(t, d, 100*time.Millisecond)
+		assert.LessOrEqual(t, d, 200*time.Millisecond)
+	}
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"net/url"
+	"strings"
+)
+
+// RotationStrategy selects how client-managed pools pick an entry for a run.
+type RotationStrategy int
+
+const (
+	// RoundRobin picks the next entry for every run.
+	RoundRobin RotationStrategy = iota
+	// StickyPerDomain keeps using the same entry for all runs against
+	// the same target domain.
+	StickyPerDomain
+)
+
+// applyRotation fills in params managed by the client's rotation pools.
+// Values already present in params take precedence.
+func (c *Client) applyRotation(params map[string]any) error {
+	domain := targetDomain(params)
+	if c.fingerprints != nil {
+		if _, ok := params["fingerprint"]; !ok {
+			params["fingerprint"] = c.fingerprints.Next(domain).params()
+		}
+	}
+	if c.userAgents != nil {
+		if _, ok := params["userAgent"]; !ok {
+			params["userAgent"] = c.userAgents.Next(domain)
+		}
+	}
+	if c.proxies != nil {
+		if _, ok := params["proxy"]; !ok {
+			proxy, err := c.proxies.Next(domain)
+			if err != nil {
+				return err
+			}
+			params["proxy"] = proxy
+		}
+	}
+	return nil
+}
+
+// rotate replaces params managed by the client's rotation pools with
+// fresh entries, for example after the previous run was blocked.
+// A proxy assigned by the pool is reported as banned by the target domain.
+func (c *Client) rotate(params map[string]any) error {
+	domain := targetDomain(params)
+	if c.fingerprints != nil {
+		params["fingerprint"] = c.fingerprints.Rotate(domain).params()
+	}
+	if c.userAgents != nil {
+		params["userAgent"] = c.userAgents.Rotate(domain)
+	}
+	if c.proxies != nil {
+		if old, ok := params["proxy"].(string); ok {
+			c.proxies.ReportBan(old, domain)
+		}
+		proxy, err := c.proxies.Rotate(domain)
+		if err != nil {
+			return err
+		}
+		params["proxy"] = proxy
+	}
+	return nil
+}
+
+// targetDomain returns the host of the first URL found in the
+// "url" or "urls" param, or an empty string.
+func targetDomain(params map[string]any) string {
+	urls := targetURLs(params)
+	if len(urls) == 0 || urls[0] == "" {
+		return ""
+	}
+	u, err := url.Parse(urls[0])
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
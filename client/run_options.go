@@ -0,0 +1,45 @@
+package client
+
+// RunOption configures a single plugin run.
+type RunOption func(*runConfig)
+
+type runConfig struct {
+	params    map[string]any
+	botPolicy *BotDetectionPolicy
+	residency []string
+	prober    *Prober
+	// output decodes the plugin output incrementally, if set.
+	output *outputDecoder
+	// violations are the validation failures found by the options.
+	violations []FieldError
+}
+
+// newRunConfig applies opts on top of a shallow copy of params,
+// so the caller's map is never modified.
+func newRunConfig(params map[string]any, opts []RunOption) *runConfig {
+	cfg := &runConfig{params: copyParams(params)}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// ApplyRunOptions returns a copy of params with the param-level effects
+// of opts applied. Alternative API implementations use it to honor run
+// options; client-side behavior such as bot-detection re-runs is not applied.
+// If an option is given invalid values, a *ValidationError is returned.
+func ApplyRunOptions(params map[string]any, opts ...RunOption) (map[string]any, error) {
+	cfg := newRunConfig(params, opts)
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg.params, nil
+}
+
+func copyParams(params map[string]any) map[string]any {
+	cp := make(map[string]any, len(params))
+	for k, v := range params {
+		cp[k] = v
+	}
+	return cp
+}
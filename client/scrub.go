@@ -0,0 +1,176 @@
+package client
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ScrubRule redacts personal data from plugin outputs.
+// Exactly one of Pattern and Path is set.
+type ScrubRule struct {
+	// Name labels the redaction, e.g. "email" in "[REDACTED:email]".
+	Name string
+	// Pattern redacts every match in every string value of the output.
+	Pattern *regexp.Regexp
+	// Path redacts the whole value at a dotted field path, e.g.
+	// "googlesearch.results.*.author". A "*" segment matches any key
+	// or list index.
+	Path string
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	phonePattern = regexp.MustCompile(`(?:\+\d{1,3}[-.\s]?)?(?:\(\d{3}\)|\b\d{3})[-.\s]?\d{3}[-.\s]?\d{4}\b`)
+	ssnPattern   = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+)
+
+// EmailRule redacts email addresses.
+func EmailRule() ScrubRule {
+	return ScrubRule{Name: "email", Pattern: emailPattern}
+}
+
+// PhoneRule redacts North American style phone numbers, optionally
+// with a country code, e.g. "+1 (555) 123-4567".
+func PhoneRule() ScrubRule {
+	return ScrubRule{Name: "phone", Pattern: phonePattern}
+}
+
+// SSNRule redacts US social security numbers written as 123-45-6789.
+func SSNRule() ScrubRule {
+	return ScrubRule{Name: "ssn", Pattern: ssnPattern}
+}
+
+// Scrubber redacts plugin outputs according to its rules.
+// It is safe for concurrent use.
+type Scrubber struct {
+	patterns []ScrubRule
+	paths    []scrubPath
+}
+
+type scrubPath struct {
+	name     string
+	segments []string
+}
+
+// NewScrubber creates a Scrubber. Rules are applied in order.
+func NewScrubber(rules ...ScrubRule) (*Scrubber, error) {
+	s := &Scrubber{}
+	for i, r := range rules {
+		if r.Name == "" {
+			return nil, errors.New("scrub rule " + strconv.Itoa(i) + ": name is required")
+		}
+		switch {
+		case r.Pattern != nil && r.Path == "":
+			s.patterns = append(s.patterns, r)
+		case r.Pattern == nil && r.Path != "":
+			s.paths = append(s.paths, scrubPath{name: r.Name, segments: strings.Split(r.Path, ".")})
+		default:
+			return nil, errors.New("scrub rule " + r.Name + ": exactly one of pattern and path is required")
+		}
+	}
+	return s, nil
+}
+
+// WithScrubber redacts every plugin output with s before it is returned,
+// written to the result sink or published, including the outputs of
+// bot-detection attempts and stream items.
+func WithScrubber(s *Scrubber) Option {
+	return func(c *Client) {
+		c.scrubber = s
+	}
+}
+
+// Scrub returns a redacted copy of output. The output is not modified.
+func (s *Scrubber) Scrub(output map[string]any) map[string]any {
+	if output == nil {
+		return nil
+	}
+	scrubbed, _ := s.scrubValue(output).(map[string]any)
+	for _, p := range s.paths {
+		redactPath(scrubbed, p.segments, redacted(p.name))
+	}
+	return scrubbed
+}
+
+func (s *Scrubber) scrubValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		cp := make(map[string]any, len(v))
+		for k, e := range v {
+			cp[k] = s.scrubValue(e)
+		}
+		return cp
+	case []any:
+		cp := make([]any, len(v))
+		for i, e := range v {
+			cp[i] = s.scrubValue(e)
+		}
+		return cp
+	case string:
+		for _, r := range s.patterns {
+			v = r.Pattern.ReplaceAllLiteralString(v, redacted(r.Name))
+		}
+		return v
+	}
+	return v
+}
+
+// redactPath replaces the values at path in v, which must be a copy
+// owned by the scrubber.
+func redactPath(v any, path []string, replacement string) {
+	if len(path) == 0 {
+		return
+	}
+	seg, rest := path[0], path[1:]
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			if seg != "*" && seg != k {
+				continue
+			}
+			if len(rest) == 0 {
+				v[k] = replacement
+			} else {
+				redactPath(e, rest, replacement)
+			}
+		}
+	case []any:
+		for i, e := range v {
+			if seg != "*" && seg != strconv.Itoa(i) {
+				continue
+			}
+			if len(rest) == 0 {
+				v[i] = replacement
+			} else {
+				redactPath(e, rest, replacement)
+			}
+		}
+	}
+}
+
+func redacted(name string) string {
+	return "[REDACTED:" + name + "]"
+}
+
+// scrubResult redacts the output of result and of its attempts.
+func (c *Client) scrubResult(result *RunResult) {
+	if c.scrubber == nil {
+		return
+	}
+	result.Output = c.scrubber.Scrub(result.Output)
+	if len(result.Attempts) == 0 {
+		return
+	}
+	attempts := make([]Attempt, len(result.Attempts))
+	for i, a := range result.Attempts {
+		a.Output = c.scrubber.Scrub(a.Output)
+		attempts[i] = a
+	}
+	result.Attempts = attempts
+	if result.Output == nil {
+		result.Output = map[string]any{}
+	}
+	result.Output[AttemptsKey] = attempts
+}
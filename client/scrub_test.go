@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySink keeps results in memory.
+type memorySink struct {
+	results map[string]map[string]any
+}
+
+func (s *memorySink) WriteResult(pluginName string, output map[string]any) error {
+	if s.results == nil {
+		s.results = make(map[string]map[string]any)
+	}
+	s.results[pluginName] = output
+	return nil
+}
+
+func (s *memorySink) WriteFile(string, []byte) error { return nil }
+
+func TestNewScrubber(t *testing.T) {
+	_, err := NewScrubber(ScrubRule{Pattern: emailPattern})
+	assert.EqualError(t, err, "scrub rule 0: name is required")
+
+	_, err = NewScrubber(ScrubRule{Name: "both", Pattern: emailPattern, Path: "a"})
+	assert.EqualError(t, err, "scrub rule both: exactly one of pattern and path is required")
+
+	_, err = NewScrubber(ScrubRule{Name: "none"})
+	assert.Error(t, err)
+}
+
+func TestScrubber_Scrub(t *testing.T) {
+	s, err := NewScrubber(
+		EmailRule(),
+		PhoneRule(),
+		SSNRule(),
+		ScrubRule{Name: "author", Path: "posts.*.author"},
+		ScrubRule{Name: "token", Pattern: regexp.MustCompile(`tok_[a-z0-9]+`)},
+	)
+	require.NoError(t, err)
+
+	output := map[string]any{
+		"contact": "mail jane.doe@example.com or call +1 (555) 123-4567",
+		"ssn":     "SSN 123-45-6789 on file",
+		"posts": []any{
+			map[string]any{"author": "Jane", "text": "key tok_abc123"},
+			map[string]any{"author": "John", "likes": 3.0},
+		},
+		"count": 2.0,
+	}
+	scrubbed := s.Scrub(output)
+
+	assert.Equal(t, map[string]any{
+		"contact": "mail [REDACTED:email] or call [REDACTED:phone]",
+		"ssn":     "SSN [REDACTED:ssn] on file",
+		"posts": []any{
+			map[string]any{"author": "[REDACTED:author]", "text": "key [REDACTED:token]"},
+			map[string]any{"author": "[REDACTED:author]", "likes": 3.0},
+		},
+		"count": 2.0,
+	}, scrubbed)
+	assert.Equal(t, "Jane", output["posts"].([]any)[0].(map[string]any)["author"])
+	assert.Nil(t, s.Scrub(nil))
+}
+
+func TestWithScrubber(t *testing.T) {
+	s, err := NewScrubber(EmailRule())
+	require.NoError(t, err)
+
+	t.Run("run", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, `{"scrape": {"text": "write to a@b.io"}}`)
+		defer server.Close()
+
+		sink := &memorySink{}
+		c, err := New(server.URL, nil, WithScrubber(s), WithResultSink(sink))
+		require.NoError(t, err)
+
+		output, err := c.RunPlugin(context.Background(), "scrape", nil)
+		require.NoError(t, err)
+		want := map[string]any{"scrape": map[string]any{"text": "write to [REDACTED:email]"}}
+		assert.Equal(t, want, output)
+		assert.Equal(t, want, sink.results["scrape"])
+	})
+
+	t.Run("attempts", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, `{"blocked": true, "text": "a@b.io"}`)
+		defer server.Close()
+
+		c, err := New(server.URL, nil, WithScrubber(s))
+		require.NoError(t, err)
+
+		result, err := c.RunPluginResult(context.Background(), "scrape", nil, WithBotDetectionRetry(BotDetectionPolicy{
+			Detect: func(output map[string]any) bool { return output["blocked"] == true },
+		}))
+		require.NoError(t, err)
+		require.Len(t, result.Attempts, 2)
+		for _, a := range result.Attempts {
+			assert.Equal(t, "[REDACTED:email]", a.Output["text"])
+		}
+		assert.Equal(t, result.Attempts, result.Output[AttemptsKey])
+	})
+
+	t.Run("attempts without output", func(t *testing.T) {
+		c, err := New("http://localhost", nil, WithScrubber(s))
+		require.NoError(t, err)
+
+		result := &RunResult{Attempts: []Attempt{{Output: map[string]any{"text": "a@b.io"}}}}
+		c.scrubResult(result)
+		assert.Equal(t, "[REDACTED:email]", result.Attempts[0].Output["text"])
+		assert.Equal(t, result.Attempts, result.Output[AttemptsKey])
+	})
+
+	t.Run("result page", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, `{"text": "a@b.io"}`)
+		defer server.Close()
+
+		sink := &memorySink{}
+		c, err := New(server.URL, nil, WithScrubber(s), WithResultSink(sink))
+		require.NoError(t, err)
+
+		result := c.newRunResult("crawl", http.Header{"X-Next-Cursor": {"c1"}})
+		page, err := result.NextPage(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "[REDACTED:email]", page.Output["text"])
+		assert.Equal(t, page.Output, sink.results["crawl"])
+	})
+
+	t.Run("control channel", func(t *testing.T) {
+		server := controlServer(t, nil)
+		defer server.Close()
+
+		sink := &memorySink{}
+		c, err := New(server.URL, nil, WithScrubber(s), WithResultSink(sink))
+		require.NoError(t, err)
+		ch, err := c.DialControlChannel(context.Background())
+		require.NoError(t, err)
+		defer ch.Close()
+
+		output, err := ch.Run(context.Background(), "text", map[string]any{"query": "a@b.io"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"text": "[REDACTED:email]"}, output)
+		assert.Equal(t, output, sink.results["text"])
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, "{\"text\": \"a@b.io\"}\n")
+		defer server.Close()
+
+		c, err := New(server.URL, nil, WithScrubber(s))
+		require.NoError(t, err)
+
+		items, err := c.RunPluginStream(context.Background(), "crawl", nil)
+		require.NoError(t, err)
+		item := <-items
+		require.NoError(t, item.Err)
+		assert.Equal(t, "[REDACTED:email]", item.Output["text"])
+	})
+}
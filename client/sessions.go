@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// SessionCookies fetches the cookies stored in a browser session.
+func (c *Client) SessionCookies(ctx context.Context, sessionID string) (_ []*http.Cookie, err error) {
+	ctx, op := c.startOperation(ctx, "SessionCookies")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	u, err := c.sessionURL(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u+"/cookies", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session cookies request: %w", err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch session cookies: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.newAPIError(resp)
+	}
+
+	var payload SessionCookies
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode session cookies: %w", err)
+	}
+
+	cookies := make([]*http.Cookie, 0, len(payload.Cookies))
+	for _, sc := range payload.Cookies {
+		cookie := &http.Cookie{
+			Name:     sc.Name,
+			Value:    sc.Value,
+			Domain:   sc.Domain,
+			Path:     sc.Path,
+			Secure:   sc.Secure,
+			HttpOnly: sc.HTTPOnly,
+		}
+		if sc.Expires > 0 {
+			cookie.Expires = time.Unix(sc.Expires, 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies, nil
+}
+
+// SetSessionCookies adds cookies to a browser session,
+// replacing cookies with the same name, domain and path.
+func (c *Client) SetSessionCookies(ctx context.Context, sessionID string, cookies []*http.Cookie) (err error) {
+	ctx, op := c.startOperation(ctx, "SetSessionCookies")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	u, err := c.sessionURL(sessionID)
+	if err != nil {
+		return err
+	}
+
+	payload := SessionCookies{
+		Cookies: make([]SessionCookie, 0, len(cookies)),
+	}
+	for _, cookie := range cookies {
+		sc := SessionCookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Secure:   cookie.Secure,
+			HTTPOnly: cookie.HttpOnly,
+		}
+		if !cookie.Expires.IsZero() {
+			sc.Expires = cookie.Expires.Unix()
+		}
+		payload.Cookies = append(payload.Cookies, sc)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to JSON encode cookies: %w", err)
+	}
+
+	req, err := newBodyRequest(
+		ctx,
+		http.MethodPut,
+		u+"/cookies",
+		"application/json",
+		body,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create set cookies request: %w", err)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to set session cookies: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return c.newAPIError(resp)
+	}
+
+	return nil
+}
+
+// sessionURL returns the URL of a browser session.
+func (c *Client) sessionURL(sessionID string) (string, error) {
+	if sessionID == "" {
+		return "", errors.New("session ID is required")
+	}
+	return c.addr + "/sessions/" + url.PathEscape(sessionID), nil
+}
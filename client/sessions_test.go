@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sessionServer is a fake BrowserBro server holding cookies of one session.
+type sessionServer struct {
+	mu      sync.Mutex
+	cookies []SessionCookie
+}
+
+func (s *sessionServer) start(t testing.TB) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/sessions/s1/cookies", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]any{"cookies": s.cookies})
+	})
+	mux.HandleFunc("PUT /api/v1/sessions/s1/cookies", func(w http.ResponseWriter, r *http.Request) {
+		var payload SessionCookies
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.cookies = append(s.cookies, payload.Cookies...)
+		s.mu.Unlock()
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestClient_SessionCookies(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := mockServer(
+			t,
+			http.StatusOK,
+			`{"cookies":[{"name":"sid","value":"abc","domain":"example.com","path":"/","expires":1700000000,"secure":true}]}`,
+		)
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		cookies, err := c.SessionCookies(context.Background(), "s1")
+		require.NoError(t, err)
+		require.Len(t, cookies, 1)
+		assert.Equal(t, "sid", cookies[0].Name)
+		assert.Equal(t, "abc", cookies[0].Value)
+		assert.True(t, cookies[0].Secure)
+		assert.Equal(t, time.Unix(1700000000, 0), cookies[0].Expires)
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, "")
+		server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		_, err = c.SessionCookies(context.Background(), "s1")
+		require.ErrorContains(t, err, "failed to fetch session cookies:")
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		server := mockServer(t, http.StatusNotFound, "")
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		cookies, err := c.SessionCookies(context.Background(), "s1")
+		require.EqualError(t, err, "unexpected response status: 404 Not Found")
+		require.Nil(t, cookies)
+	})
+
+	t.Run("invalid server response body", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, "")
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		_, err = c.SessionCookies(context.Background(), "s1")
+		require.ErrorContains(t, err, "failed to decode session cookies:")
+	})
+
+	t.Run("session ID", func(t *testing.T) {
+		var path string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.EscapedPath()
+			_, _ = w.Write([]byte(`{"cookies": []}`))
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		_, err = c.SessionCookies(context.Background(), "a/b?c")
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/sessions/a%2Fb%3Fc/cookies", path)
+
+		_, err = c.SessionCookies(context.Background(), "")
+		assert.EqualError(t, err, "session ID is required")
+		assert.EqualError(t, c.SetSessionCookies(context.Background(), "", nil), "session ID is required")
+	})
+}
+
+func TestClient_SetSessionCookies(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var params map[string]any
+		server := captureServer(t, http.StatusOK, "", &params)
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		err = c.SetSessionCookies(context.Background(), "s1", []*http.Cookie{
+			{Name: "sid", Value: "abc", Domain: "example.com", HttpOnly: true},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"cookies": []any{
+				map[string]any{
+					"name":     "sid",
+					"value":    "abc",
+					"domain":   "example.com",
+					"httpOnly": true,
+				},
+			},
+		}, params)
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, "")
+		server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		err = c.SetSessionCookies(context.Background(), "s1", nil)
+		require.ErrorContains(t, err, "failed to set session cookies:")
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		server := mockServer(t, http.StatusInternalServerError, "")
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		err = c.SetSessionCookies(context.Background(), "s1", nil)
+		require.EqualError(t, err, "unexpected response status: 500 Internal Server Error")
+	})
+}
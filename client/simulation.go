@@ -0,0 +1,183 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSimulatedLatency is the response time of simulated calls
+// unless Fixtures or a Fixture set another latency.
+const DefaultSimulatedLatency = 300 * time.Millisecond
+
+// Fixture is the canned response of a simulated plugin.
+type Fixture struct {
+	// Output is the plugin output returned by every run.
+	Output map[string]any
+	// Status is the response status; zero means 200. Error statuses
+	// return Message as the server error message.
+	Status  int
+	Message string
+	// Latency overrides Fixtures.Latency for the plugin.
+	Latency time.Duration
+}
+
+// Fixtures are the canned responses of a simulated server.
+type Fixtures struct {
+	// Plugins are the simulated plugins, by name.
+	Plugins map[string]Fixture
+	// Files are the contents of the simulated files, by file ID.
+	Files map[string][]byte
+	// Latency is the response time of every call; zero means
+	// DefaultSimulatedLatency. Responses are delayed by up to 20%
+	// more or less for realism.
+	Latency time.Duration
+}
+
+// DefaultFixtures returns fixtures simulating the googlesearch and
+// screenshot plugins, with a placeholder image as the screenshot file.
+func DefaultFixtures() Fixtures {
+	var img bytes.Buffer
+	placeholder := image.NewGray(image.Rect(0, 0, 16, 9))
+	for i := range placeholder.Pix {
+		placeholder.Pix[i] = color.Gray{Y: 0xcc}.Y
+	}
+	_ = png.Encode(&img, placeholder)
+
+	return Fixtures{
+		Plugins: map[string]Fixture{
+			"googlesearch": {Output: map[string]any{
+				"googlesearch": map[string]any{"results": []any{
+					map[string]any{"title": "Simulated result", "url": "https://example.com/", "snippet": "A simulated search result."},
+					map[string]any{"title": "Another simulated result", "url": "https://example.org/", "snippet": "Another simulated search result."},
+				}},
+			}},
+			"screenshot": {Output: map[string]any{
+				"screenshot": map[string]any{"fileIds": []any{"simulated-screenshot.png"}},
+			}},
+		},
+		Files: map[string][]byte{"simulated-screenshot.png": img.Bytes()},
+	}
+}
+
+// WithSimulation makes the client serve every call from fixtures instead
+// of contacting the server, for development and CI environments without
+// a BrowserBro instance. It replaces the transport of the HTTP client,
+// so it must be given after options that configure the transport.
+// The HTTP client passed to New is not modified.
+func WithSimulation(fixtures Fixtures) Option {
+	return func(c *Client) {
+		if fixtures.Latency <= 0 {
+			fixtures.Latency = DefaultSimulatedLatency
+		}
+		client := *c.client
+		client.Transport = &simulator{fixtures: fixtures}
+		c.client = &client
+	}
+}
+
+// simulator is an http.RoundTripper serving the BrowserBro API from fixtures.
+type simulator struct {
+	fixtures Fixtures
+}
+
+func (s *simulator) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+		_ = req.Body.Close()
+	}
+	path := req.URL.Path
+	if i := strings.Index(path, "/api/v1/"); i >= 0 {
+		path = path[i+len("/api/v1"):]
+	}
+
+	latency := s.fixtures.Latency
+	status, body := http.StatusNotImplemented, errorBody("endpoint is not simulated")
+	switch resource, name, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/"); {
+	case resource == "health" && req.Method == http.MethodGet:
+		status, body = http.StatusOK, nil
+	case resource == "plugins" && name == "" && req.Method == http.MethodGet:
+		names := make([]string, 0, len(s.fixtures.Plugins))
+		for n := range s.fixtures.Plugins {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		status, body = jsonBody(PluginsResponse{Plugins: names})
+	case resource == "plugins" && req.Method == http.MethodPost:
+		// Streaming runs yield the output as a single item.
+		name = strings.TrimSuffix(name, "/stream")
+		f, ok := s.fixtures.Plugins[name]
+		switch {
+		case !ok:
+			status, body = http.StatusNotFound, errorBody("plugin "+name+" not found")
+		case f.Status != 0 && f.Status != http.StatusOK:
+			status, body = f.Status, errorBody(f.Message)
+		default:
+			status, body = jsonBody(f.Output)
+		}
+		if f.Latency > 0 {
+			latency = f.Latency
+		}
+	case resource == "files" && name != "":
+		data, ok := s.fixtures.Files[name]
+		switch {
+		case !ok:
+			status, body = http.StatusNotFound, errorBody("file "+name+" not found")
+		case req.Method == http.MethodDelete:
+			// Files are kept so that repeated runs can download them again.
+			status, body = http.StatusOK, nil
+		case req.Method == http.MethodGet || req.Method == http.MethodHead:
+			status, body = http.StatusOK, data
+		}
+	}
+
+	jitter := time.Duration((rand.Float64()*0.4 - 0.2) * float64(latency))
+	timer := time.NewTimer(latency + jitter)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	resp := &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		ContentLength: int64(len(body)),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		Request:       req,
+	}
+	if req.Method == http.MethodHead {
+		resp.Body = http.NoBody
+	}
+	if len(body) > 0 && (status >= 400 || !strings.HasPrefix(path, "/files/")) {
+		resp.Header.Set("Content-Type", "application/json")
+	}
+	return resp, nil
+}
+
+func jsonBody(v any) (int, []byte) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return http.StatusInternalServerError, errorBody(err.Error())
+	}
+	return http.StatusOK, data
+}
+
+func errorBody(message string) []byte {
+	data, _ := json.Marshal(ErrorResponse{Message: message})
+	return data
+}
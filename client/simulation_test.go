@@ -0,0 +1,77 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSimulation(t *testing.T) {
+	fixtures := DefaultFixtures()
+	fixtures.Latency = 10 * time.Millisecond
+	fixtures.Plugins["broken"] = Fixture{Status: http.StatusBadGateway, Message: "browser crashed"}
+
+	// The address is never contacted.
+	c, err := New("http://browserbro.invalid", nil, WithSimulation(fixtures))
+	require.NoError(t, err)
+
+	require.NoError(t, c.Healthcheck(context.Background()))
+	plugins, err := c.Plugins(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"broken", "googlesearch", "screenshot"}, plugins)
+
+	start := time.Now()
+	output, err := c.RunPlugin(context.Background(), "screenshot", map[string]any{"urls": []string{"https://example.com"}})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 8*time.Millisecond)
+	ids := FileIDs(output)
+	require.Equal(t, []string{"simulated-screenshot.png"}, ids)
+
+	data, err := c.DownloadFile(context.Background(), ids[0])
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 16, img.Bounds().Dx())
+	require.NoError(t, c.DeleteFile(context.Background(), ids[0]))
+
+	items, err := c.RunPluginStream(context.Background(), "googlesearch", nil)
+	require.NoError(t, err)
+	item := <-items
+	require.NoError(t, item.Err)
+	assert.Contains(t, item.Output, "googlesearch")
+
+	_, err = c.RunPlugin(context.Background(), "broken", nil)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, "browser crashed", apiErr.Message)
+
+	_, err = c.RunPlugin(context.Background(), "missing", nil)
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+
+	_, err = c.DownloadFile(context.Background(), "missing.png")
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+
+	assert.Contains(t, c.Describe().Middleware, "simulation")
+}
+
+func TestWithSimulation_Context(t *testing.T) {
+	fixtures := DefaultFixtures()
+	fixtures.Plugins["slow"] = Fixture{Output: map[string]any{}, Latency: time.Hour}
+
+	c, err := New("http://browserbro.invalid", nil, WithSimulation(fixtures))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = c.RunPluginStream(ctx, "slow", nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ResultSink persists plugin outputs and downloaded files.
+// A sink configured with WithResultSink is invoked automatically by
+// RunPlugin and DownloadFile, and therefore by every helper built on them.
+type ResultSink interface {
+	WriteResult(pluginName string, output map[string]any) error
+	WriteFile(fileID string, data []byte) error
+}
+
+// ContextSink is an optional ResultSink extension for sinks doing I/O
+// that should end with the run or download, such as uploads. When
+// implemented, its methods are called with the context of the call
+// instead of those of ResultSink.
+type ContextSink interface {
+	WriteResultContext(ctx context.Context, pluginName string, output map[string]any) error
+	WriteFileContext(ctx context.Context, fileID string, r io.Reader) error
+}
+
+// WithResultSink persists every successful plugin output and downloaded file to s.
+func WithResultSink(s ResultSink) Option {
+	return func(c *Client) {
+		c.sink = s
+	}
+}
+
+// NopSink is a ResultSink that discards everything.
+type NopSink struct{}
+
+// WriteResult implements ResultSink.
+func (NopSink) WriteResult(string, map[string]any) error { return nil }
+
+// WriteFile implements ResultSink.
+func (NopSink) WriteFile(string, []byte) error { return nil }
+
+// writeResult writes output to the result sink.
+func (c *Client) writeResult(ctx context.Context, pluginName string, output map[string]any) error {
+	if s, ok := c.sink.(ContextSink); ok {
+		return s.WriteResultContext(ctx, pluginName, output)
+	}
+	return c.sink.WriteResult(pluginName, output)
+}
+
+// writeFile writes a downloaded file to the result sink.
+func (c *Client) writeFile(ctx context.Context, fileID string, data []byte) error {
+	if s, ok := c.sink.(ContextSink); ok {
+		return s.WriteFileContext(ctx, fileID, bytes.NewReader(data))
+	}
+	return c.sink.WriteFile(fileID, data)
+}
+
+// DiskSink is a ResultSink writing plugin outputs as JSON files to the
+// "results" subdirectory and downloaded files to the "files" subdirectory
+// of its root directory.
+type DiskSink struct {
+	dir      string
+	dedupe   bool
+	hardLink bool
+	seq      atomic.Uint64
+
+	mu     sync.Mutex
+	byHash map[string]string
+	paths  map[string]string
+}
+
+// DiskSinkOption configures a DiskSink.
+type DiskSinkOption func(*DiskSink)
+
+// WithDeduplication makes the sink skip writing files whose content it has
+// already stored. If hardLink is true, a hard link to the stored copy is
+// created under the new file ID instead. Use Path to locate a file's content.
+func WithDeduplication(hardLink bool) DiskSinkOption {
+	return func(s *DiskSink) {
+		s.dedupe = true
+		s.hardLink = hardLink
+	}
+}
+
+// NewDiskSink creates a DiskSink rooted at dir, creating it if needed.
+func NewDiskSink(dir string, opts ...DiskSinkOption) (*DiskSink, error) {
+	if dir == "" {
+		return nil, errors.New("directory is required")
+	}
+	for _, sub := range []string{"results", "files"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create sink directory: %w", err)
+		}
+	}
+	s := &DiskSink{
+		dir:    dir,
+		byHash: make(map[string]string),
+		paths:  make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// WriteResult implements ResultSink. Each output is written to its own
+// file named after the plugin, the current time and a sequence number.
+func (s *DiskSink) WriteResult(pluginName string, output map[string]any) error {
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to JSON encode result: %w", err)
+	}
+	name := filepath.Base(pluginName) + "-" +
+		strconv.FormatInt(time.Now().UnixNano(), 10) + "-" +
+		strconv.FormatUint(s.seq.Add(1), 10) + ".json"
+	return os.WriteFile(filepath.Join(s.dir, "results", name), data, 0o644)
+}
+
+// WriteFile implements ResultSink.
+func (s *DiskSink) WriteFile(fileID string, data []byte) error {
+	p := s.filePath(fileID)
+	if !s.dedupe {
+		return os.WriteFile(p, data, 0o644)
+	}
+
+	hash := contentHash(data)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.byHash[hash]; ok {
+		if !s.hardLink || existing == p {
+			s.paths[fileID] = existing
+			return nil
+		}
+		if err := os.Link(existing, p); err != nil && !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to link duplicate file: %w", err)
+		}
+		s.paths[fileID] = p
+		return nil
+	}
+	if err := os.WriteFile(p, data, 0o644); err != nil {
+		return err
+	}
+	s.byHash[hash] = p
+	s.paths[fileID] = p
+	return nil
+}
+
+// Path returns the path holding the content of the given file.
+// With deduplication, it may be the path of an earlier identical file.
+func (s *DiskSink) Path(fileID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.paths[fileID]; ok {
+		return p
+	}
+	return s.filePath(fileID)
+}
+
+func (s *DiskSink) filePath(fileID string) string {
+	return filepath.Join(s.dir, "files", filepath.Base(fileID))
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingSink struct{}
+
+func (failingSink) WriteResult(string, map[string]any) error { return errors.New("disk full") }
+func (failingSink) WriteFile(string, []byte) error           { return errors.New("disk full") }
+
+type blobStoreFunc func(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+func (f blobStoreFunc) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	return f(ctx, key, r, size, contentType)
+}
+
+func TestNewDiskSink(t *testing.T) {
+	s, err := NewDiskSink("")
+	require.Error(t, err)
+	require.Nil(t, s)
+}
+
+func TestDiskSink(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewDiskSink(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, s.WriteResult("screenshot", map[string]any{"a": 1}))
+	require.NoError(t, s.WriteResult("screenshot", map[string]any{"a": 2}))
+	entries, err := os.ReadDir(filepath.Join(dir, "results"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	require.NoError(t, s.WriteFile("../file1", []byte("content")))
+	data, err := os.ReadFile(filepath.Join(dir, "files", "file1"))
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(data))
+}
+
+func TestWithResultSink(t *testing.T) {
+	t.Run("results and files are persisted", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, `{"screenshot": "ok"}`)
+		defer server.Close()
+
+		dir := t.TempDir()
+		sink, err := NewDiskSink(dir)
+		require.NoError(t, err)
+
+		c, err := New(server.URL, nil, WithResultSink(sink))
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+		require.NoError(t, err)
+		_, err = c.DownloadFile(context.Background(), "file1")
+		require.NoError(t, err)
+
+		entries, err := os.ReadDir(filepath.Join(dir, "results"))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.FileExists(t, filepath.Join(dir, "files", "file1"))
+	})
+
+	t.Run("nop sink", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, `{}`)
+		defer server.Close()
+
+		c, err := New(server.URL, nil, WithResultSink(NopSink{}))
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("context sink", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, `{"screenshot": "ok"}`)
+		defer server.Close()
+
+		type ctxKey struct{}
+		var values []any
+		store := blobStoreFunc(func(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+			values = append(values, ctx.Value(ctxKey{}))
+			return nil
+		})
+		sink, err := NewBlobSink(store, "")
+		require.NoError(t, err)
+		c, err := New(server.URL, nil, WithResultSink(sink))
+		require.NoError(t, err)
+
+		ctx := context.WithValue(context.Background(), ctxKey{}, "run")
+		_, err = c.RunPlugin(ctx, "screenshot", nil)
+		require.NoError(t, err)
+		_, err = c.DownloadFile(ctx, "file1")
+		require.NoError(t, err)
+		assert.Equal(t, []any{"run", "run"}, values)
+	})
+
+	t.Run("sink errors", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, `{}`)
+		defer server.Close()
+
+		c, err := New(server.URL, nil, WithResultSink(failingSink{}))
+		require.NoError(t, err)
+
+		output, err := c.RunPlugin(context.Background(), "screenshot", nil)
+		require.EqualError(t, err, "failed to write result: disk full")
+		require.Nil(t, output)
+
+		content, err := c.DownloadFile(context.Background(), "file1")
+		require.EqualError(t, err, "failed to write file: disk full")
+		require.Nil(t, content)
+	})
+}
+
+func TestDiskSink_Deduplication(t *testing.T) {
+	t.Run("skip duplicates", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := NewDiskSink(dir, WithDeduplication(false))
+		require.NoError(t, err)
+
+		require.NoError(t, s.WriteFile("file1", []byte("same")))
+		require.NoError(t, s.WriteFile("file2", []byte("same")))
+		require.NoError(t, s.WriteFile("file3", []byte("other")))
+
+		assert.FileExists(t, filepath.Join(dir, "files", "file1"))
+		assert.NoFileExists(t, filepath.Join(dir, "files", "file2"))
+		assert.Equal(t, s.Path("file1"), s.Path("file2"))
+		assert.Equal(t, filepath.Join(dir, "files", "file3"), s.Path("file3"))
+	})
+
+	t.Run("hard links", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := NewDiskSink(dir, WithDeduplication(true))
+		require.NoError(t, err)
+
+		require.NoError(t, s.WriteFile("file1", []byte("same")))
+		require.NoError(t, s.WriteFile("file2", []byte("same")))
+
+		first, err := os.Stat(filepath.Join(dir, "files", "file1"))
+		require.NoError(t, err)
+		second, err := os.Stat(filepath.Join(dir, "files", "file2"))
+		require.NoError(t, err)
+		assert.True(t, os.SameFile(first, second))
+		assert.Equal(t, filepath.Join(dir, "files", "file2"), s.Path("file2"))
+	})
+}
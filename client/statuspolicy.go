@@ -0,0 +1,23 @@
+package client
+
+// StatusPolicy overrides how a response status is reported as an error.
+type StatusPolicy struct {
+	// Retryable replaces the default retry classification of the status
+	// reported by APIError.Retryable and IsRetryable.
+	Retryable bool
+	// Err, if set, is matched by errors.Is on the returned APIError,
+	// e.g. to map a gateway's 409 onto an application sentinel.
+	Err error
+}
+
+// WithStatusPolicy registers policy for responses with the given status
+// code. It applies to every endpoint of the client. Registering a status
+// again replaces its policy.
+func WithStatusPolicy(statusCode int, policy StatusPolicy) Option {
+	return func(c *Client) {
+		if c.statusPolicies == nil {
+			c.statusPolicies = make(map[int]*StatusPolicy)
+		}
+		c.statusPolicies[statusCode] = &policy
+	}
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithStatusPolicy(t *testing.T) {
+	errBusy := errors.New("gateway busy")
+
+	t.Run("overrides classification", func(t *testing.T) {
+		server := mockServer(t, http.StatusConflict, `{"message": "locked"}`)
+		defer server.Close()
+
+		c, err := New(server.URL, nil, WithStatusPolicy(http.StatusConflict, StatusPolicy{
+			Retryable: true,
+			Err:       errBusy,
+		}))
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+		assert.ErrorIs(t, err, errBusy)
+		assert.True(t, IsRetryable(err))
+		assert.EqualError(t, err, "unexpected response status: 409 Conflict; message: locked")
+
+		err = c.DeleteFile(context.Background(), "id")
+		assert.ErrorIs(t, err, errBusy)
+		assert.True(t, IsRetryable(err))
+	})
+
+	t.Run("disables default retry", func(t *testing.T) {
+		server := mockServer(t, http.StatusServiceUnavailable, `{}`)
+		defer server.Close()
+
+		c, err := New(server.URL, nil, WithStatusPolicy(http.StatusServiceUnavailable, StatusPolicy{}))
+		require.NoError(t, err)
+
+		_, err = c.Plugins(context.Background())
+		require.Error(t, err)
+		assert.False(t, IsRetryable(err))
+	})
+
+	t.Run("other statuses unaffected", func(t *testing.T) {
+		server := mockServer(t, http.StatusTooManyRequests, `{}`)
+		defer server.Close()
+
+		c, err := New(server.URL, nil, WithStatusPolicy(http.StatusConflict, StatusPolicy{Err: errBusy}))
+		require.NoError(t, err)
+
+		err = c.Healthcheck(context.Background())
+		assert.True(t, IsRetryable(err))
+		assert.False(t, errors.Is(err, errBusy))
+	})
+}
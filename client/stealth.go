@@ -0,0 +1,91 @@
+package client
+
+import "strconv"
+
+// Stealth describes anti-detection features the browser should enable
+// for a plugin run.
+type Stealth struct {
+	// MaskWebdriver hides navigator.webdriver and related automation flags.
+	MaskWebdriver bool
+	// CanvasNoise adds subtle noise to canvas and WebGL readouts.
+	CanvasNoise bool
+	// UserAgentHints overrides the User-Agent Client Hints reported by the browser.
+	UserAgentHints *UserAgentHints
+}
+
+// UserAgentHints is a set of User-Agent Client Hints (Sec-CH-UA-*) overrides.
+type UserAgentHints struct {
+	Brands          []Brand
+	Platform        string
+	PlatformVersion string
+	Architecture    string
+	Model           string
+	Mobile          bool
+}
+
+// Brand is a single entry of the Sec-CH-UA brand list.
+type Brand struct {
+	Brand   string
+	Version string
+}
+
+// StealthPreset returns stealth settings that work well for most sites.
+func StealthPreset() Stealth {
+	return Stealth{
+		MaskWebdriver: true,
+		CanvasNoise:   true,
+		UserAgentHints: &UserAgentHints{
+			Brands: []Brand{
+				{Brand: "Chromium", Version: "124"},
+				{Brand: "Google Chrome", Version: "124"},
+				{Brand: "Not-A.Brand", Version: "99"},
+			},
+			Platform:        "Windows",
+			PlatformVersion: "15.0.0",
+			Architecture:    "x86",
+		},
+	}
+}
+
+// WithStealth enables the given stealth features for the run.
+func WithStealth(s Stealth) RunOption {
+	return func(cfg *runConfig) {
+		if h := s.UserAgentHints; h != nil {
+			for i, b := range h.Brands {
+				if b.Brand == "" {
+					cfg.invalid(
+						"stealth.userAgentHints.brands["+strconv.Itoa(i)+"].brand",
+						CodeRequired,
+						"must not be empty",
+					)
+				}
+			}
+		}
+		cfg.params["stealth"] = s.params()
+	}
+}
+
+func (s Stealth) params() map[string]any {
+	p := map[string]any{
+		"maskWebdriver": s.MaskWebdriver,
+		"canvasNoise":   s.CanvasNoise,
+	}
+	if h := s.UserAgentHints; h != nil {
+		brands := make([]map[string]any, 0, len(h.Brands))
+		for _, b := range h.Brands {
+			brands = append(brands, map[string]any{
+				"brand":   b.Brand,
+				"version": b.Version,
+			})
+		}
+		p["userAgentHints"] = map[string]any{
+			"brands":          brands,
+			"platform":        h.Platform,
+			"platformVersion": h.PlatformVersion,
+			"architecture":    h.Architecture,
+			"model":           h.Model,
+			"mobile":          h.Mobile,
+		}
+	}
+	return p
+}
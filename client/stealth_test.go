@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithStealth(t *testing.T) {
+	t.Run("preset", func(t *testing.T) {
+		var params map[string]any
+		server := captureServer(t, http.StatusOK, `{}`, &params)
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		input := map[string]any{"urls": []string{"https://example.com"}}
+		_, err = c.RunPlugin(context.Background(), "screenshot", input, WithStealth(StealthPreset()))
+		require.NoError(t, err)
+
+		require.Contains(t, params, "stealth")
+		stealth := params["stealth"].(map[string]any)
+		assert.Equal(t, true, stealth["maskWebdriver"])
+		assert.Equal(t, true, stealth["canvasNoise"])
+		hints := stealth["userAgentHints"].(map[string]any)
+		assert.Equal(t, "Windows", hints["platform"])
+		assert.Len(t, hints["brands"], 3)
+		assert.NotContains(t, input, "stealth", "caller params must not be modified")
+	})
+
+	t.Run("without hints", func(t *testing.T) {
+		p := Stealth{MaskWebdriver: true}.params()
+		assert.Equal(t, map[string]any{
+			"maskWebdriver": true,
+			"canvasNoise":   false,
+		}, p)
+	})
+}
@@ -0,0 +1,70 @@
+package client
+
+import "maps"
+
+// Storage holds Web Storage contents of the page a plugin navigated to.
+type Storage struct {
+	Local   map[string]string
+	Session map[string]string
+}
+
+// WithLocalStorage injects key/values into localStorage before navigation.
+func WithLocalStorage(values map[string]string) RunOption {
+	return func(cfg *runConfig) {
+		storageParams(cfg)["local"] = values
+	}
+}
+
+// WithSessionStorage injects key/values into sessionStorage before navigation.
+func WithSessionStorage(values map[string]string) RunOption {
+	return func(cfg *runConfig) {
+		storageParams(cfg)["session"] = values
+	}
+}
+
+// WithStorageReadback asks the plugin to include the page's Web Storage
+// in its output. Use StorageFromOutput to read it.
+func WithStorageReadback() RunOption {
+	return func(cfg *runConfig) {
+		storageParams(cfg)["readBack"] = true
+	}
+}
+
+// storageParams returns a copy of the storage params of cfg for writing,
+// as they may belong to the caller's params.
+func storageParams(cfg *runConfig) map[string]any {
+	p, _ := cfg.params["storage"].(map[string]any)
+	p = maps.Clone(p)
+	if p == nil {
+		p = map[string]any{}
+	}
+	cfg.params["storage"] = p
+	return p
+}
+
+// StorageFromOutput extracts Web Storage contents reported by a plugin run
+// made with WithStorageReadback. It returns false if the output has none.
+func StorageFromOutput(output map[string]any) (Storage, bool) {
+	raw, ok := output["storage"].(map[string]any)
+	if !ok {
+		return Storage{}, false
+	}
+	return Storage{
+		Local:   stringMap(raw["local"]),
+		Session: stringMap(raw["session"]),
+	}, true
+}
+
+func stringMap(v any) map[string]string {
+	raw, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	m := make(map[string]string, len(raw))
+	for k, v := range raw {
+		if s, ok := v.(string); ok {
+			m[k] = s
+		}
+	}
+	return m
+}
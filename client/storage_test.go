@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageOptions(t *testing.T) {
+	var params map[string]any
+	server := captureServer(
+		t,
+		http.StatusOK,
+		`{"storage": {"local": {"token": "abc"}, "session": {"tab": "2"}}}`,
+		&params,
+	)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	output, err := c.RunPlugin(context.Background(),
+		"screenshot",
+		nil,
+		WithLocalStorage(map[string]string{"token": "abc"}),
+		WithSessionStorage(map[string]string{"tab": "1"}),
+		WithStorageReadback(),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"local":    map[string]any{"token": "abc"},
+		"session":  map[string]any{"tab": "1"},
+		"readBack": true,
+	}, params["storage"])
+
+	storage, ok := StorageFromOutput(output)
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"token": "abc"}, storage.Local)
+	assert.Equal(t, map[string]string{"tab": "2"}, storage.Session)
+}
+
+func TestStorageOptions_CallerParams(t *testing.T) {
+	server := mockServer(t, http.StatusOK, `{"screenshot": "ok"}`)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	storage := map[string]any{"local": map[string]string{"token": "abc"}}
+	params := map[string]any{"storage": storage}
+	_, err = c.RunPlugin(context.Background(), "screenshot", params, WithStorageReadback())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"local": map[string]string{"token": "abc"}}, storage)
+}
+
+func TestStorageFromOutput(t *testing.T) {
+	_, ok := StorageFromOutput(map[string]any{"screenshot": "x"})
+	assert.False(t, ok)
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// StreamItem is a single incremental result of a streaming plugin run.
+// Exactly one of Output and Err is set.
+type StreamItem struct {
+	Output map[string]any
+	Err    error
+}
+
+// RunPluginStream runs a streaming-capable plugin and yields its results
+// as they are produced, for example one item per processed page.
+// The channel is closed when the plugin finishes, ctx is done, or an error
+// occurs; an error is delivered as the last item. The operation ends,
+// for the error hook and the audit log, when the channel is closed.
+func (c *Client) RunPluginStream(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	opts ...RunOption,
+) (_ <-chan StreamItem, err error) {
+	ctx, op := c.startOperation(ctx, "RunPluginStream")
+	defer func() {
+		// A started stream finishes the operation when it ends.
+		if err != nil {
+			c.finishOperation(ctx, op, err)
+		}
+	}()
+	op.Plugin = pluginName
+	if c.kms != nil {
+		return nil, fmt.Errorf("failed to stream plugin: %w", ErrEncryptionUnsupported)
+	}
+
+	cfg := newRunConfig(params, opts)
+	c.coerceParams(pluginName, cfg)
+	op.params = cfg.params
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if err := c.checkPolicy(pluginName, cfg.params); err != nil {
+		return nil, err
+	}
+	if err := c.applyRotation(cfg.params); err != nil {
+		return nil, err
+	}
+	rc, err := c.resident(cfg.residency, cfg.prober)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(cfg.params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to JSON encode params: %w", err)
+	}
+
+	req, err := newBodyRequest(
+		ctx,
+		http.MethodPost,
+		rc.addr+"/plugins/"+pluginName+"/stream",
+		"application/json",
+		body,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stream request: %w", err)
+	}
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run plugin: %w", transportError(err))
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer closeBody(resp.Body)
+		return nil, c.newAPIError(resp)
+	}
+
+	items := make(chan StreamItem)
+	go func() {
+		var streamErr error
+		defer close(items)
+		defer func() { c.finishOperation(ctx, op, streamErr) }()
+		defer resp.Body.Close()
+
+		send := func(item StreamItem) bool {
+			select {
+			case items <- item:
+				return true
+			case <-ctx.Done():
+				streamErr = ctx.Err()
+				return false
+			}
+		}
+		fail := func(err error) {
+			send(StreamItem{Err: err})
+			streamErr = err
+		}
+		defer func() {
+			if v := recover(); v != nil {
+				fail(NewPanicError(v))
+			}
+		}()
+
+		dec := json.NewDecoder(resp.Body)
+		for {
+			var output map[string]any
+			if err := dec.Decode(&output); err != nil {
+				if err == io.EOF {
+					return
+				}
+				if ctx.Err() != nil {
+					err = ctx.Err()
+				}
+				fail(fmt.Errorf("failed to decode stream item: %w", err))
+				return
+			}
+			if c.scrubber != nil {
+				output = c.scrubber.Scrub(output)
+			}
+			if c.sink != nil {
+				if err := c.writeResult(ctx, pluginName, output); err != nil {
+					fail(fmt.Errorf("failed to write result: %w", err))
+					return
+				}
+			}
+			if !send(StreamItem{Output: output}) {
+				return
+			}
+		}
+	}()
+	return items, nil
+}
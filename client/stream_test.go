@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RunPluginStream(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/plugins/crawl/stream", r.URL.Path)
+			assert.Equal(t, "application/x-ndjson", r.Header.Get("Accept"))
+			flusher := w.(http.Flusher)
+			for i := 1; i <= 3; i++ {
+				_, _ = fmt.Fprintf(w, "{\"page\": %d}\n", i)
+				flusher.Flush()
+			}
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		items, err := c.RunPluginStream(context.Background(), "crawl", nil)
+		require.NoError(t, err)
+
+		var pages []any
+		for item := range items {
+			require.NoError(t, item.Err)
+			pages = append(pages, item.Output["page"])
+		}
+		assert.Equal(t, []any{1.0, 2.0, 3.0}, pages)
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, "")
+		server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		_, err = c.RunPluginStream(context.Background(), "crawl", nil)
+		require.ErrorContains(t, err, "failed to run plugin:")
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		server := mockServer(t, http.StatusBadRequest, `{"message": "not streamable"}`)
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		items, err := c.RunPluginStream(context.Background(), "crawl", nil)
+		require.EqualError(t, err, "unexpected response status: 400 Bad Request; message: not streamable")
+		require.Nil(t, items)
+	})
+
+	t.Run("invalid item", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, "{\"page\": 1}\nnot json\n")
+		defer server.Close()
+
+		var hookErrs []error
+		c, err := New(server.URL, nil, WithErrorHook(func(_ context.Context, op Operation, err error) {
+			assert.Equal(t, "RunPluginStream", op.Name)
+			hookErrs = append(hookErrs, err)
+		}))
+		require.NoError(t, err)
+
+		items, err := c.RunPluginStream(context.Background(), "crawl", nil)
+		require.NoError(t, err)
+
+		first := <-items
+		require.NoError(t, first.Err)
+		second := <-items
+		require.ErrorContains(t, second.Err, "failed to decode stream item:")
+		_, open := <-items
+		assert.False(t, open)
+		// The error ends the operation, not the call starting the stream.
+		require.Len(t, hookErrs, 1)
+		assert.Equal(t, second.Err, hookErrs[0])
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("{\"page\": 1}\n"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		items, err := c.RunPluginStream(ctx, "crawl", nil)
+		require.NoError(t, err)
+
+		<-items
+		cancel()
+		for range items {
+		}
+	})
+}
@@ -0,0 +1,226 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrOverloaded is returned by SubmitQueue.Submit when the queue is
+	// full and its overflow policy rejects new runs.
+	ErrOverloaded = errors.New("submission queue overloaded")
+	// ErrDropped is returned for a queued run evicted by a newer one
+	// under the OverflowDropOldest policy.
+	ErrDropped = errors.New("run dropped from full submission queue")
+	// ErrQueueClosed is returned for runs submitted to or still waiting
+	// in a closed SubmitQueue.
+	ErrQueueClosed = errors.New("submission queue closed")
+)
+
+// OverflowPolicy selects what SubmitQueue.Submit does when the queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits until the queue has room or ctx is done.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest evicts the oldest waiting run, which fails with
+	// ErrDropped, to make room for the new one.
+	OverflowDropOldest
+	// OverflowReject fails the new run with ErrOverloaded.
+	OverflowReject
+)
+
+// SubmitQueue is a bounded queue of plugin runs in front of a Client.
+// A fixed number of workers take runs from the queue, which limits the
+// load put on the server, while the overflow policy limits the runs held
+// by the client process during traffic spikes. It is safe for concurrent use.
+type SubmitQueue struct {
+	c        *Client
+	capacity int
+	policy   OverflowPolicy
+
+	mu     sync.Mutex
+	jobs   []*submitJob
+	closed bool
+	// changed is closed and replaced whenever jobs or closed change.
+	changed chan struct{}
+	wg      sync.WaitGroup
+}
+
+type submitJob struct {
+	ctx        context.Context
+	pluginName string
+	params     map[string]any
+	opts       []RunOption
+	done       chan submitResult
+}
+
+type submitResult struct {
+	result *RunResult
+	err    error
+}
+
+// NewSubmitQueue creates a SubmitQueue holding up to capacity waiting
+// runs and executing up to workers runs at a time. Close must be called
+// to stop the workers.
+func (c *Client) NewSubmitQueue(capacity, workers int, policy OverflowPolicy) *SubmitQueue {
+	if capacity < 1 {
+		capacity = 1
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	q := &SubmitQueue{
+		c:        c,
+		capacity: capacity,
+		policy:   policy,
+		changed:  make(chan struct{}),
+	}
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// Submit queues a plugin run and waits for its result. If ctx is done
+// before a worker takes the run, it is removed from the queue.
+func (q *SubmitQueue) Submit(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	opts ...RunOption,
+) (*RunResult, error) {
+	job := &submitJob{
+		ctx:        ctx,
+		pluginName: pluginName,
+		params:     params,
+		opts:       opts,
+		done:       make(chan submitResult, 1),
+	}
+	if err := q.push(job); err != nil {
+		return nil, err
+	}
+	select {
+	case res := <-job.done:
+		return res.result, res.err
+	case <-ctx.Done():
+		if q.remove(job) {
+			return nil, ctx.Err()
+		}
+		// A worker already took the run and follows ctx.
+		res := <-job.done
+		return res.result, res.err
+	}
+}
+
+// Len returns the number of runs waiting for a worker.
+func (q *SubmitQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.jobs)
+}
+
+// Close stops accepting runs, fails waiting runs with ErrQueueClosed
+// and waits for the runs in progress to finish.
+func (q *SubmitQueue) Close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		for _, job := range q.jobs {
+			job.done <- submitResult{err: ErrQueueClosed}
+		}
+		q.jobs = nil
+		q.notifyLocked()
+	}
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+func (q *SubmitQueue) push(job *submitJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		if q.closed {
+			return ErrQueueClosed
+		}
+		if len(q.jobs) < q.capacity {
+			break
+		}
+		switch q.policy {
+		case OverflowReject:
+			return ErrOverloaded
+		case OverflowDropOldest:
+			q.jobs[0].done <- submitResult{err: ErrDropped}
+			q.jobs = q.jobs[1:]
+			continue
+		}
+
+		changed := q.changed
+		q.mu.Unlock()
+		select {
+		case <-job.ctx.Done():
+			q.mu.Lock()
+			return job.ctx.Err()
+		case <-changed:
+		}
+		q.mu.Lock()
+	}
+	q.jobs = append(q.jobs, job)
+	q.notifyLocked()
+	return nil
+}
+
+// remove removes a job that was not yet taken by a worker.
+// It reports whether the job was found.
+func (q *SubmitQueue) remove(job *submitJob) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, j := range q.jobs {
+		if j == job {
+			q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+			q.notifyLocked()
+			return true
+		}
+	}
+	return false
+}
+
+// pop waits for the next job. It returns nil once the queue is closed.
+func (q *SubmitQueue) pop() *submitJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.jobs) == 0 {
+		if q.closed {
+			return nil
+		}
+		changed := q.changed
+		q.mu.Unlock()
+		<-changed
+		q.mu.Lock()
+	}
+	job := q.jobs[0]
+	q.jobs = q.jobs[1:]
+	q.notifyLocked()
+	return job
+}
+
+func (q *SubmitQueue) work() {
+	defer q.wg.Done()
+	for job := q.pop(); job != nil; job = q.pop() {
+		var res submitResult
+		res.err = safeCall(func() (err error) {
+			ctx, op := q.c.startOperation(job.ctx, "Submit")
+			defer func() { q.c.finishOperation(ctx, op, err) }()
+			res.result, err = q.c.run(ctx, job.pluginName, job.params, job.opts)
+			return err
+		})
+		job.done <- res
+	}
+}
+
+func (q *SubmitQueue) notifyLocked() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
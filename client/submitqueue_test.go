@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gateServer holds every run until release is closed.
+func gateServer(t *testing.T) (server *httptest.Server, started chan struct{}, release chan struct{}) {
+	t.Helper()
+
+	started = make(chan struct{}, 16)
+	release = make(chan struct{})
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	return server, started, release
+}
+
+func submitAsync(ctx context.Context, q *SubmitQueue) chan error {
+	errs := make(chan error, 1)
+	go func() {
+		_, err := q.Submit(ctx, "echo", map[string]any{})
+		errs <- err
+	}()
+	return errs
+}
+
+func waitLen(t *testing.T, q *SubmitQueue, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool { return q.Len() == n }, time.Second, time.Millisecond)
+}
+
+func TestSubmitQueue(t *testing.T) {
+	server, started, release := gateServer(t)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	q := c.NewSubmitQueue(1, 1, OverflowBlock)
+	result, err := func() (*RunResult, error) {
+		go func() { <-started; release <- struct{}{} }()
+		return q.Submit(context.Background(), "echo", map[string]any{})
+	}()
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Output["ok"])
+	q.Close()
+
+	_, err = q.Submit(context.Background(), "echo", map[string]any{})
+	assert.ErrorIs(t, err, ErrQueueClosed)
+}
+
+func TestSubmitQueue_Overflow(t *testing.T) {
+	t.Run("reject", func(t *testing.T) {
+		server, started, release := gateServer(t)
+		defer server.Close()
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+		q := c.NewSubmitQueue(1, 1, OverflowReject)
+
+		running := submitAsync(context.Background(), q)
+		<-started
+		waiting := submitAsync(context.Background(), q)
+		waitLen(t, q, 1)
+
+		_, err = q.Submit(context.Background(), "echo", map[string]any{})
+		assert.ErrorIs(t, err, ErrOverloaded)
+
+		close(release)
+		assert.NoError(t, <-running)
+		assert.NoError(t, <-waiting)
+		q.Close()
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		server, started, release := gateServer(t)
+		defer server.Close()
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+		q := c.NewSubmitQueue(1, 1, OverflowDropOldest)
+
+		running := submitAsync(context.Background(), q)
+		<-started
+		oldest := submitAsync(context.Background(), q)
+		waitLen(t, q, 1)
+		newest := submitAsync(context.Background(), q)
+
+		assert.ErrorIs(t, <-oldest, ErrDropped)
+		close(release)
+		assert.NoError(t, <-running)
+		assert.NoError(t, <-newest)
+		q.Close()
+	})
+
+	t.Run("block", func(t *testing.T) {
+		server, started, release := gateServer(t)
+		defer server.Close()
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+		q := c.NewSubmitQueue(1, 1, OverflowBlock)
+
+		running := submitAsync(context.Background(), q)
+		<-started
+		waiting := submitAsync(context.Background(), q)
+		waitLen(t, q, 1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = q.Submit(ctx, "echo", map[string]any{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		blocked := submitAsync(context.Background(), q)
+		close(release)
+		assert.NoError(t, <-running)
+		assert.NoError(t, <-waiting)
+		assert.NoError(t, <-blocked)
+		q.Close()
+	})
+}
+
+func TestSubmitQueue_Cancel(t *testing.T) {
+	server, started, release := gateServer(t)
+	defer server.Close()
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+	q := c.NewSubmitQueue(2, 1, OverflowReject)
+
+	running := submitAsync(context.Background(), q)
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	waiting := submitAsync(ctx, q)
+	waitLen(t, q, 1)
+	cancel()
+	assert.ErrorIs(t, <-waiting, context.Canceled)
+	assert.Equal(t, 0, q.Len())
+
+	closed := submitAsync(context.Background(), q)
+	waitLen(t, q, 1)
+	go q.Close()
+	assert.ErrorIs(t, <-closed, ErrQueueClosed)
+	close(release)
+	assert.NoError(t, <-running)
+}
@@ -0,0 +1,85 @@
+package client
+
+// TargetErrorKind classifies why a single target of a multi-target run failed.
+type TargetErrorKind string
+
+const (
+	TargetTimeout  TargetErrorKind = "timeout"
+	TargetNotFound TargetErrorKind = "not_found"
+	TargetBlocked  TargetErrorKind = "blocked"
+	TargetFailed   TargetErrorKind = "failed"
+	// TargetMissing means the plugin output has no entry for the target.
+	TargetMissing TargetErrorKind = "missing"
+)
+
+// TargetError is the failure of a single target of a multi-target run.
+type TargetError struct {
+	URL     string
+	Kind    TargetErrorKind
+	Message string
+}
+
+func (e *TargetError) Error() string {
+	msg := "target " + e.URL + " " + string(e.Kind)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// TargetResult is the outcome of a single target of a multi-target run.
+// Exactly one of Output and Err is set.
+type TargetResult struct {
+	URL    string
+	Output any
+	Err    error
+}
+
+// TargetResults maps each target of a multi-target plugin run, such as
+// the "urls" param of the screenshot plugin, to its output or error.
+// The plugin section of output is expected to be keyed by target URL,
+// with failed targets reported as {"error": {"code": ..., "message": ...}}.
+// Results are returned in the order of targets.
+func TargetResults(output map[string]any, pluginName string, targets []string) []TargetResult {
+	section, _ := output[pluginName].(map[string]any)
+	results := make([]TargetResult, 0, len(targets))
+	for _, target := range targets {
+		v, ok := section[target]
+		if !ok {
+			results = append(results, TargetResult{
+				URL: target,
+				Err: &TargetError{URL: target, Kind: TargetMissing},
+			})
+			continue
+		}
+		if err := targetError(target, v); err != nil {
+			results = append(results, TargetResult{URL: target, Err: err})
+			continue
+		}
+		results = append(results, TargetResult{URL: target, Output: v})
+	}
+	return results
+}
+
+func targetError(target string, v any) *TargetError {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	raw, ok := m["error"]
+	if !ok {
+		return nil
+	}
+	e := &TargetError{URL: target, Kind: TargetFailed}
+	switch detail := raw.(type) {
+	case string:
+		e.Message = detail
+	case map[string]any:
+		e.Message, _ = detail["message"].(string)
+		switch code, _ := detail["code"].(string); TargetErrorKind(code) {
+		case TargetTimeout, TargetNotFound, TargetBlocked:
+			e.Kind = TargetErrorKind(code)
+		}
+	}
+	return e
+}
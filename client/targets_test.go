@@ -0,0 +1,47 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetResults(t *testing.T) {
+	output := map[string]any{
+		"screenshot": map[string]any{
+			"https://a.com": map[string]any{"fileId": "f1"},
+			"https://b.com": map[string]any{"error": map[string]any{"code": "timeout", "message": "30s elapsed"}},
+			"https://c.com": map[string]any{"error": map[string]any{"code": "not_found"}},
+			"https://d.com": map[string]any{"error": map[string]any{"code": "blocked"}},
+			"https://e.com": map[string]any{"error": "browser crashed"},
+		},
+	}
+	targets := []string{
+		"https://a.com",
+		"https://b.com",
+		"https://c.com",
+		"https://d.com",
+		"https://e.com",
+		"https://f.com",
+	}
+
+	results := TargetResults(output, "screenshot", targets)
+	require.Len(t, results, len(targets))
+
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, map[string]any{"fileId": "f1"}, results[0].Output)
+
+	kinds := []TargetErrorKind{TargetTimeout, TargetNotFound, TargetBlocked, TargetFailed, TargetMissing}
+	for i, kind := range kinds {
+		r := results[i+1]
+		assert.Equal(t, targets[i+1], r.URL)
+		assert.Nil(t, r.Output)
+		var targetErr *TargetError
+		require.True(t, errors.As(r.Err, &targetErr))
+		assert.Equal(t, kind, targetErr.Kind)
+	}
+	assert.EqualError(t, results[1].Err, "target https://b.com timeout: 30s elapsed")
+	assert.EqualError(t, results[4].Err, "target https://e.com failed: browser crashed")
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// fipsCipherSuites are the TLS 1.2 cipher suites approved by FIPS 140-3
+// (NIST SP 800-52r2): ECDHE key exchange with AES-GCM.
+var fipsCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+// fipsCurves are the FIPS-approved key exchange curves.
+var fipsCurves = []tls.CurveID{tls.CurveP256, tls.CurveP384}
+
+// WithFIPSTLS restricts TLS to versions 1.2 and 1.3, FIPS-approved TLS 1.2
+// cipher suites and the P-256 and P-384 curves. New fails if the restriction
+// cannot be applied: when the HTTP client uses a transport other than
+// *http.Transport, or its TLS config skips verification, caps the version
+// below TLS 1.2 or allows no approved cipher suite or curve.
+//
+// The TLS 1.3 cipher suites are fixed by crypto/tls; build and run with
+// the Go FIPS 140-3 module (GOFIPS140) to restrict them as well.
+// The HTTP client passed to New is not modified.
+func WithFIPSTLS() Option {
+	return func(c *Client) {
+		t, err := fipsTransport(c.client.Transport)
+		if err != nil {
+			c.optionError(fmt.Errorf("failed to apply FIPS TLS: %w", err))
+			return
+		}
+		client := *c.client
+		client.Transport = t
+		c.client = &client
+	}
+}
+
+func fipsTransport(rt http.RoundTripper) (*http.Transport, error) {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	base, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unsupported transport %T", rt)
+	}
+	t := base.Clone()
+
+	cfg := &tls.Config{}
+	if t.TLSClientConfig != nil {
+		cfg = t.TLSClientConfig.Clone()
+	}
+	if cfg.InsecureSkipVerify {
+		return nil, errors.New("certificate verification is disabled")
+	}
+	if cfg.MaxVersion != 0 && cfg.MaxVersion < tls.VersionTLS12 {
+		return nil, errors.New("maximum TLS version is below 1.2")
+	}
+	if cfg.MinVersion < tls.VersionTLS12 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+
+	cfg.CipherSuites = restrict(cfg.CipherSuites, fipsCipherSuites)
+	if len(cfg.CipherSuites) == 0 {
+		return nil, errors.New("no FIPS-approved cipher suite is allowed")
+	}
+	cfg.CurvePreferences = restrict(cfg.CurvePreferences, fipsCurves)
+	if len(cfg.CurvePreferences) == 0 {
+		return nil, errors.New("no FIPS-approved curve is allowed")
+	}
+
+	t.TLSClientConfig = cfg
+	return t, nil
+}
+
+// restrict returns the entries of configured that are allowed, keeping
+// their order, or all of allowed if nothing was configured.
+func restrict[T comparable](configured, allowed []T) []T {
+	if len(configured) == 0 {
+		return slices.Clone(allowed)
+	}
+	var out []T
+	for _, v := range configured {
+		if slices.Contains(allowed, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
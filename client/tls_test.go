@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestWithFIPSTLS(t *testing.T) {
+	t.Run("default client", func(t *testing.T) {
+		c, err := New("http://localhost", nil, WithFIPSTLS())
+		require.NoError(t, err)
+
+		cfg := c.client.Transport.(*http.Transport).TLSClientConfig
+		assert.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+		assert.Equal(t, fipsCipherSuites, cfg.CipherSuites)
+		assert.Equal(t, fipsCurves, cfg.CurvePreferences)
+	})
+
+	t.Run("does not modify the given client", func(t *testing.T) {
+		transport := &http.Transport{TLSClientConfig: &tls.Config{
+			CipherSuites: []uint16{
+				tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			},
+		}}
+		hc := &http.Client{Transport: transport}
+		c, err := New("http://localhost", hc, WithFIPSTLS())
+		require.NoError(t, err)
+
+		cfg := c.client.Transport.(*http.Transport).TLSClientConfig
+		assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}, cfg.CipherSuites)
+		assert.Same(t, transport, hc.Transport)
+		assert.Len(t, transport.TLSClientConfig.CipherSuites, 2)
+	})
+
+	t.Run("refuses", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			client *http.Client
+			err    string
+		}{
+			"custom transport": {
+				client: &http.Client{Transport: roundTripperFunc(nil)},
+				err:    "failed to apply FIPS TLS: unsupported transport client.roundTripperFunc",
+			},
+			"insecure": {
+				client: &http.Client{Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+				}},
+				err: "failed to apply FIPS TLS: certificate verification is disabled",
+			},
+			"old version": {
+				client: &http.Client{Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{MaxVersion: tls.VersionTLS11},
+				}},
+				err: "failed to apply FIPS TLS: maximum TLS version is below 1.2",
+			},
+			"no approved suite": {
+				client: &http.Client{Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{CipherSuites: []uint16{tls.TLS_RSA_WITH_AES_128_CBC_SHA}},
+				}},
+				err: "failed to apply FIPS TLS: no FIPS-approved cipher suite is allowed",
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				c, err := New("http://localhost", tc.client, WithFIPSTLS())
+				assert.EqualError(t, err, tc.err)
+				assert.Nil(t, c)
+			})
+		}
+	})
+
+	t.Run("handshake", func(t *testing.T) {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"plugins": ["screenshot"]}`))
+		}))
+		server.TLS = &tls.Config{
+			MaxVersion:   tls.VersionTLS12,
+			CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+		}
+		server.StartTLS()
+		defer server.Close()
+
+		c, err := New(server.URL, server.Client(), WithFIPSTLS())
+		require.NoError(t, err)
+		_, err = c.Plugins(context.Background())
+		require.NoError(t, err)
+	})
+
+	t.Run("non-compliant server", func(t *testing.T) {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.TLS = &tls.Config{
+			MaxVersion:   tls.VersionTLS12,
+			CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305},
+		}
+		server.Config.ErrorLog = log.New(io.Discard, "", 0)
+		server.StartTLS()
+		defer server.Close()
+
+		c, err := New(server.URL, server.Client(), WithFIPSTLS())
+		require.NoError(t, err)
+		_, err = c.Plugins(context.Background())
+		assert.ErrorContains(t, err, "failed to fetch plugins")
+	})
+}
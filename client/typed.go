@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// ErrNoOutputSection matches the *OutputError of an output without
+// a section for the plugin.
+var ErrNoOutputSection = errors.New("no output section")
+
+// OutputError is returned when a plugin output does not match the shape
+// of the value it is decoded into.
+type OutputError struct {
+	Plugin string
+	// Field is the dotted path of the mismatched field within the output
+	// section, e.g. "results.0.url"; it is empty for the section itself.
+	Field string
+	// Value is the kind of JSON value found, e.g. "string" or "array".
+	Value string
+	// Type is the Go type the value could not be decoded into.
+	Type reflect.Type
+	Err  error
+}
+
+func (e *OutputError) Error() string {
+	if errors.Is(e.Err, ErrNoOutputSection) {
+		return "plugin output has no " + strconv.Quote(e.Plugin) + " section"
+	}
+	msg := "failed to decode " + e.Plugin + " output: "
+	if e.Type == nil {
+		return msg + e.Err.Error()
+	}
+	if e.Field != "" {
+		msg += "field " + strconv.Quote(e.Field) + ": "
+	}
+	return msg + "cannot decode " + e.Value + " into " + e.Type.String()
+}
+
+func (e *OutputError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeOutput decodes the output section of a plugin, output[pluginName],
+// into out, which must be a non-nil pointer. A section that is missing or
+// does not match the shape of out returns an *OutputError.
+func DecodeOutput(output map[string]any, pluginName string, out any) error {
+	section, ok := output[pluginName]
+	if !ok {
+		return &OutputError{Plugin: pluginName, Err: ErrNoOutputSection}
+	}
+	data, err := json.Marshal(section)
+	if err != nil {
+		return &OutputError{Plugin: pluginName, Err: err}
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		outErr := &OutputError{Plugin: pluginName, Err: err}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			outErr.Field = typeErr.Field
+			outErr.Value = typeErr.Value
+			outErr.Type = typeErr.Type
+		}
+		return outErr
+	}
+	return nil
+}
+
+// RunPluginInto runs a plugin like RunPlugin and decodes its output
+// section into out, as DecodeOutput does.
+func (c *Client) RunPluginInto(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	out any,
+	opts ...RunOption,
+) error {
+	output, err := c.RunPlugin(ctx, pluginName, params, opts...)
+	if err != nil {
+		return err
+	}
+	return DecodeOutput(output, pluginName, out)
+}
+
+// RunPluginTyped runs a plugin with api and returns its output section
+// decoded into a T, as DecodeOutput does.
+func RunPluginTyped[T any](
+	ctx context.Context,
+	api API,
+	pluginName string,
+	params map[string]any,
+	opts ...RunOption,
+) (T, error) {
+	var out T
+	output, err := api.RunPlugin(ctx, pluginName, params, opts...)
+	if err != nil {
+		return out, err
+	}
+	err = DecodeOutput(output, pluginName, &out)
+	return out, err
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type searchOutput struct {
+	Results []struct {
+		URL   string `json:"url"`
+		Title string `json:"title"`
+	} `json:"results"`
+}
+
+func TestClient_RunPluginInto(t *testing.T) {
+	server := mockServer(t, http.StatusOK, `{"googlesearch": {"results": [{"url": "https://go.dev", "title": "Go"}]}}`)
+	defer server.Close()
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	var out searchOutput
+	require.NoError(t, c.RunPluginInto(context.Background(), "googlesearch", nil, &out))
+	require.Len(t, out.Results, 1)
+	assert.Equal(t, "https://go.dev", out.Results[0].URL)
+
+	err = c.RunPluginInto(context.Background(), "screenshot", nil, &out)
+	assert.ErrorIs(t, err, ErrNoOutputSection)
+	assert.EqualError(t, err, `plugin output has no "screenshot" section`)
+}
+
+func TestRunPluginTyped(t *testing.T) {
+	server := mockServer(t, http.StatusOK, `{"googlesearch": {"results": [{"url": 42}]}}`)
+	defer server.Close()
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = RunPluginTyped[searchOutput](context.Background(), c, "googlesearch", nil)
+	var outErr *OutputError
+	require.ErrorAs(t, err, &outErr)
+	assert.Equal(t, "googlesearch", outErr.Plugin)
+	assert.Equal(t, "results.0.url", outErr.Field)
+	assert.Equal(t, "number", outErr.Value)
+	assert.EqualError(t, err, `failed to decode googlesearch output: field "results.0.url": cannot decode number into string`)
+
+	server = mockServer(t, http.StatusOK, `{"googlesearch": {"results": []}}`)
+	defer server.Close()
+	c, err = New(server.URL, nil)
+	require.NoError(t, err)
+	out, err := RunPluginTyped[*searchOutput](context.Background(), c, "googlesearch", nil)
+	require.NoError(t, err)
+	assert.Empty(t, out.Results)
+}
+
+func TestDecodeOutput(t *testing.T) {
+	var out []string
+	err := DecodeOutput(map[string]any{"crawl": map[string]any{}}, "crawl", &out)
+	assert.EqualError(t, err, "failed to decode crawl output: cannot decode object into []string")
+
+	err = DecodeOutput(map[string]any{"crawl": []any{}}, "crawl", out)
+	assert.ErrorContains(t, err, "failed to decode crawl output: json: Unmarshal(non-pointer []string)")
+}
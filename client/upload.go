@@ -0,0 +1,173 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+)
+
+// UploadOption configures a file upload.
+type UploadOption func(*uploadConfig)
+
+type uploadConfig struct {
+	contentType string
+	progress    func(sent, total int64)
+}
+
+// WithContentType sets the content type of an uploaded file instead of
+// detecting it from the file name or contents.
+func WithContentType(contentType string) UploadOption {
+	return func(cfg *uploadConfig) {
+		cfg.contentType = contentType
+	}
+}
+
+// WithUploadProgress calls fn with the number of bytes of the file sent
+// so far and its total size, or -1 if unknown, as the upload proceeds.
+// fn is called from the goroutine writing the request body.
+func WithUploadProgress(fn func(sent, total int64)) UploadOption {
+	return func(cfg *uploadConfig) {
+		cfg.progress = fn
+	}
+}
+
+// UploadFile uploads the contents of r as a file with the given name and
+// returns its ID, to pass to plugins that consume input files. The file
+// is streamed as multipart/form-data, so it is never held in memory in
+// full; as the body cannot be replayed, uploads are never retried. The
+// content type is detected from the extension of name or, failing that,
+// from the first bytes of the contents, unless WithContentType is given.
+func (c *Client) UploadFile(
+	ctx context.Context,
+	name string,
+	r io.Reader,
+	opts ...UploadOption,
+) (_ string, err error) {
+	ctx, op := c.startOperation(ctx, "UploadFile")
+	defer func() { c.finishOperation(ctx, op, err) }()
+
+	if name == "" {
+		return "", errors.New("file name is required")
+	}
+	var cfg uploadConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	total := readerSize(r)
+	br := bufio.NewReader(r)
+	if cfg.contentType == "" {
+		cfg.contentType = detectContentType(name, br)
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(writeUpload(mw, name, br, &cfg, total))
+	}()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.addr+"/files", pr)
+	if err != nil {
+		_ = pr.Close()
+		return "", fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := c.do(req)
+	if err != nil {
+		_ = pr.Close()
+		return "", fmt.Errorf("failed to upload file: %w", transportError(err))
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", c.newAPIError(resp)
+	}
+
+	// File metadata is never encrypted, only params and outputs are.
+	var info FileInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return "", fmt.Errorf("failed to decode uploaded file: %w", err)
+	}
+	if info.ID == "" {
+		return "", errors.New("server returned no file ID")
+	}
+	op.FileID = info.ID
+	c.emit(EventFileCreated, info.ID, map[string]any{
+		"fileId": info.ID,
+		"name":   name,
+	})
+	return info.ID, nil
+}
+
+// writeUpload writes the multipart body of an upload.
+func writeUpload(mw *multipart.Writer, name string, r io.Reader, cfg *uploadConfig, total int64) error {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+		"name":     "file",
+		"filename": name,
+	}))
+	h.Set("Content-Type", cfg.contentType)
+	part, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	if cfg.progress != nil {
+		part = &progressWriter{w: part, total: total, fn: cfg.progress}
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	return mw.Close()
+}
+
+type progressWriter struct {
+	w     io.Writer
+	sent  int64
+	total int64
+	fn    func(sent, total int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.sent += int64(n)
+	p.fn(p.sent, p.total)
+	return n, err
+}
+
+// detectContentType returns the content type of a file from the
+// extension of its name or, failing that, from its first bytes.
+func detectContentType(name string, r *bufio.Reader) string {
+	if ct := mime.TypeByExtension(filepath.Ext(name)); ct != "" {
+		return ct
+	}
+	// DetectContentType considers at most the first 512 bytes.
+	head, _ := r.Peek(512)
+	return http.DetectContentType(head)
+}
+
+// readerSize returns the number of bytes left in r, or -1 if unknown.
+func readerSize(r io.Reader) int64 {
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case *os.File:
+		fi, err := r.Stat()
+		if err != nil || !fi.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return fi.Size() - offset
+	}
+	return -1
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_UploadFile(t *testing.T) {
+	type upload struct {
+		filename    string
+		contentType string
+		data        string
+	}
+	var mu sync.Mutex
+	var uploads []upload
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		f, h, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer f.Close()
+		data, _ := io.ReadAll(f)
+		mu.Lock()
+		uploads = append(uploads, upload{h.Filename, h.Header.Get("Content-Type"), string(data)})
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": "up-` + h.Filename + `", "size": 1}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	var progress [][2]int64
+	id, err := c.UploadFile(context.Background(), "form.pdf", strings.NewReader("%PDF-1.7"),
+		WithUploadProgress(func(sent, total int64) { progress = append(progress, [2]int64{sent, total}) }))
+	require.NoError(t, err)
+	assert.Equal(t, "up-form.pdf", id)
+	assert.Equal(t, [][2]int64{{8, 8}}, progress)
+
+	_, err = c.UploadFile(context.Background(), "data", strings.NewReader("<html><body>hi</body></html>"))
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "input.bin")
+	require.NoError(t, os.WriteFile(path, []byte("raw bytes"), 0o600))
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, int64(9), readerSize(f))
+	_, err = c.UploadFile(context.Background(), "input.bin", f, WithContentType("application/x-custom"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []upload{
+		{"form.pdf", "application/pdf", "%PDF-1.7"},
+		{"data", "text/html; charset=utf-8", "<html><body>hi</body></html>"},
+		{"input.bin", "application/x-custom", "raw bytes"},
+	}, uploads)
+
+	_, err = c.UploadFile(context.Background(), "", strings.NewReader("x"))
+	assert.Error(t, err)
+}
+
+func TestClient_UploadFile_Error(t *testing.T) {
+	server := mockServer(t, http.StatusRequestEntityTooLarge, `{"message": "file too large"}`)
+	defer server.Close()
+
+	c, err := New(server.URL, nil, WithRetry(3, ExponentialBackoff{}))
+	require.NoError(t, err)
+
+	_, err = c.UploadFile(context.Background(), "big.mp4", strings.NewReader(strings.Repeat("x", 1<<20)))
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, apiErr.StatusCode)
+	assert.Equal(t, "file too large", apiErr.Message)
+}
+
+func TestClient_UploadFile_PayloadEncryption(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": "up-1", "size": 8}`))
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, nil, WithPayloadEncryption(newTestKMS(t)))
+	require.NoError(t, err)
+
+	id, err := c.UploadFile(context.Background(), "form.pdf", strings.NewReader("%PDF-1.7"))
+	require.NoError(t, err)
+	assert.Equal(t, "up-1", id)
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+)
+
+// WeightedUserAgent is a user agent string with a relative selection weight.
+type WeightedUserAgent struct {
+	UserAgent string `json:"userAgent"`
+	Weight    int    `json:"weight"`
+}
+
+// UserAgentSource provides a curated list of user agents for a UserAgentPool.
+type UserAgentSource interface {
+	UserAgents() ([]WeightedUserAgent, error)
+}
+
+// UserAgentPool hands out user agents for plugin runs, picking them at
+// random proportionally to their weights. It is safe for concurrent use.
+type UserAgentPool struct {
+	strategy RotationStrategy
+
+	mu       sync.Mutex
+	agents   []WeightedUserAgent
+	total    int
+	byDomain map[string]string
+}
+
+// NewUserAgentPool creates a pool over the given user agents.
+// With StickyPerDomain, each domain keeps the first user agent picked for it.
+func NewUserAgentPool(
+	strategy RotationStrategy,
+	agents ...WeightedUserAgent,
+) (*UserAgentPool, error) {
+	p := &UserAgentPool{strategy: strategy}
+	if err := p.set(agents); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// WithUserAgentPool applies user agents from p to every plugin run
+// that does not set a "userAgent" param explicitly.
+func WithUserAgentPool(p *UserAgentPool) Option {
+	return func(c *Client) {
+		c.userAgents = p
+	}
+}
+
+// Next returns the user agent to use for a run against domain.
+func (p *UserAgentPool) Next(domain string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ua, ok := p.byDomain[domain]; ok {
+		return ua
+	}
+	return p.pick(domain, "")
+}
+
+// Rotate discards the user agent assigned to domain, if any,
+// and returns a different one when the pool allows it.
+func (p *UserAgentPool) Rotate(domain string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.pick(domain, p.byDomain[domain])
+}
+
+// Refresh replaces the pool contents with the user agents provided by src.
+// Sticky domain assignments are reset.
+func (p *UserAgentPool) Refresh(src UserAgentSource) error {
+	agents, err := src.UserAgents()
+	if err != nil {
+		return fmt.Errorf("failed to fetch user agents: %w", err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.set(agents)
+}
+
+func (p *UserAgentPool) set(agents []WeightedUserAgent) error {
+	total := 0
+	valid := make([]WeightedUserAgent, 0, len(agents))
+	for _, a := range agents {
+		if a.UserAgent == "" || a.Weight <= 0 {
+			continue
+		}
+		valid = append(valid, a)
+		total += a.Weight
+	}
+	if len(valid) == 0 {
+		return errors.New("at least one user agent with a positive weight is required")
+	}
+	p.agents = valid
+	p.total = total
+	p.byDomain = make(map[string]string)
+	return nil
+}
+
+// pick selects a weighted random user agent, avoiding exclude when
+// another one is available.
+func (p *UserAgentPool) pick(domain, exclude string) string {
+	total := p.total
+	candidates := p.agents
+	if exclude != "" && len(p.agents) > 1 {
+		candidates = make([]WeightedUserAgent, 0, len(p.agents))
+		total = 0
+		for _, a := range p.agents {
+			if a.UserAgent != exclude {
+				candidates = append(candidates, a)
+				total += a.Weight
+			}
+		}
+	}
+
+	n := rand.IntN(total)
+	ua := candidates[len(candidates)-1].UserAgent
+	for _, a := range candidates {
+		if n < a.Weight {
+			ua = a.UserAgent
+			break
+		}
+		n -= a.Weight
+	}
+	if p.strategy == StickyPerDomain && domain != "" {
+		p.byDomain[domain] = ua
+	}
+	return ua
+}
+
+// URLUserAgentSource fetches a JSON array of WeightedUserAgent from URL.
+type URLUserAgentSource struct {
+	URL string
+	// Client is used to fetch the list. http.DefaultClient is used when nil.
+	Client *http.Client
+}
+
+// UserAgents implements UserAgentSource.
+func (s URLUserAgentSource) UserAgents() ([]WeightedUserAgent, error) {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(s.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(
+			"unexpected response status: %s",
+			resp.Status,
+		)
+	}
+
+	var agents []WeightedUserAgent
+	if err := json.NewDecoder(resp.Body).Decode(&agents); err != nil {
+		return nil, fmt.Errorf("failed to decode user agents: %w", err)
+	}
+	return agents, nil
+}
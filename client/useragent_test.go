@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticUserAgentSource struct {
+	agents []WeightedUserAgent
+	err    error
+}
+
+func (s staticUserAgentSource) UserAgents() ([]WeightedUserAgent, error) {
+	return s.agents, s.err
+}
+
+func TestNewUserAgentPool(t *testing.T) {
+	t.Run("no valid agents", func(t *testing.T) {
+		p, err := NewUserAgentPool(RoundRobin, WeightedUserAgent{UserAgent: "ua", Weight: 0})
+		require.Error(t, err)
+		require.Nil(t, p)
+	})
+}
+
+func TestUserAgentPool_Next(t *testing.T) {
+	t.Run("weighted", func(t *testing.T) {
+		p, err := NewUserAgentPool(
+			RoundRobin,
+			WeightedUserAgent{UserAgent: "common", Weight: 99},
+			WeightedUserAgent{UserAgent: "rare", Weight: 1},
+			WeightedUserAgent{UserAgent: "disabled", Weight: 0},
+		)
+		require.NoError(t, err)
+
+		counts := map[string]int{}
+		for i := 0; i < 1000; i++ {
+			counts[p.Next("")]++
+		}
+		assert.Greater(t, counts["common"], counts["rare"])
+		assert.Zero(t, counts["disabled"])
+	})
+
+	t.Run("sticky per domain", func(t *testing.T) {
+		p, err := NewUserAgentPool(
+			StickyPerDomain,
+			WeightedUserAgent{UserAgent: "ua-1", Weight: 1},
+			WeightedUserAgent{UserAgent: "ua-2", Weight: 1},
+		)
+		require.NoError(t, err)
+
+		first := p.Next("a.com")
+		for i := 0; i < 20; i++ {
+			assert.Equal(t, first, p.Next("a.com"))
+		}
+		rotated := p.Rotate("a.com")
+		assert.NotEqual(t, first, rotated)
+		assert.Equal(t, rotated, p.Next("a.com"))
+	})
+}
+
+func TestUserAgentPool_Refresh(t *testing.T) {
+	p, err := NewUserAgentPool(StickyPerDomain, WeightedUserAgent{UserAgent: "old", Weight: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "old", p.Next("a.com"))
+
+	t.Run("success", func(t *testing.T) {
+		err := p.Refresh(staticUserAgentSource{
+			agents: []WeightedUserAgent{{UserAgent: "new", Weight: 1}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "new", p.Next("a.com"))
+	})
+
+	t.Run("source error", func(t *testing.T) {
+		err := p.Refresh(staticUserAgentSource{err: errors.New("boom")})
+		require.EqualError(t, err, "failed to fetch user agents: boom")
+		assert.Equal(t, "new", p.Next("a.com"))
+	})
+
+	t.Run("from url", func(t *testing.T) {
+		server := mockServer(t, http.StatusOK, `[{"userAgent": "remote", "weight": 3}]`)
+		defer server.Close()
+
+		err := p.Refresh(URLUserAgentSource{URL: server.URL})
+		require.NoError(t, err)
+		assert.Equal(t, "remote", p.Next("a.com"))
+	})
+
+	t.Run("from url server error", func(t *testing.T) {
+		server := mockServer(t, http.StatusInternalServerError, "")
+		defer server.Close()
+
+		err := p.Refresh(URLUserAgentSource{URL: server.URL})
+		require.EqualError(
+			t,
+			err,
+			"failed to fetch user agents: unexpected response status: 500 Internal Server Error",
+		)
+	})
+}
+
+func TestWithUserAgentPool(t *testing.T) {
+	p, err := NewUserAgentPool(RoundRobin, WeightedUserAgent{UserAgent: "pooled", Weight: 1})
+	require.NoError(t, err)
+
+	var params map[string]any
+	server := captureServer(t, http.StatusOK, `{}`, &params)
+	defer server.Close()
+
+	c, err := New(server.URL, nil, WithUserAgentPool(p))
+	require.NoError(t, err)
+
+	_, err = c.RunPlugin(context.Background(), "googlesearch", map[string]any{"query": "golang"})
+	require.NoError(t, err)
+	assert.Equal(t, "pooled", params["userAgent"])
+}
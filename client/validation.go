@@ -0,0 +1,63 @@
+package client
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidParams is matched by errors.Is on every ValidationError.
+var ErrInvalidParams = errors.New("invalid params")
+
+// ValidationError is returned before a request is sent when its params
+// fail client-side validation. Each entry of Fields names the offending
+// param by its path, e.g. "wait.maxWaitMs" or
+// "stealth.userAgentHints.brands[0].brand", so callers can map errors
+// back onto their own forms. The entries use the same FieldError type
+// the server reports field-level errors with.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString(ErrInvalidParams.Error())
+	for i, f := range e.Fields {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		b.WriteString(f.Param)
+		b.WriteString(": ")
+		b.WriteString(f.Message)
+	}
+	return b.String()
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidParams
+}
+
+// Field validation codes.
+const (
+	CodeRequired = "required"
+	CodeInvalid  = "invalid"
+)
+
+// invalid records a validation failure of the param at path.
+func (cfg *runConfig) invalid(path, code, message string) {
+	cfg.violations = append(cfg.violations, FieldError{
+		Param:   path,
+		Code:    code,
+		Message: message,
+	})
+}
+
+// validate returns a ValidationError listing every failure recorded
+// by the run options, if any.
+func (cfg *runConfig) validate() error {
+	if len(cfg.violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: cfg.violations}
+}
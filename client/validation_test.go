@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationError(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, nil)
+	require.NoError(t, err)
+
+	opts := []RunOption{
+		WithWait(WaitCondition{Selector: "#main", MaxWait: -time.Second}),
+		WithStealth(Stealth{UserAgentHints: &UserAgentHints{
+			Brands: []Brand{{Brand: "Chromium", Version: "124"}, {Version: "99"}},
+		}}),
+		WithExtraHeaders(http.Header{"Bad Header": {"x"}}),
+	}
+	want := []FieldError{
+		{Param: "wait.maxWaitMs", Code: CodeInvalid, Message: "must not be negative"},
+		{Param: "stealth.userAgentHints.brands[1].brand", Code: CodeRequired, Message: "must not be empty"},
+		{Param: "headers.Bad Header", Code: CodeInvalid, Message: "invalid header name"},
+	}
+
+	t.Run("run", func(t *testing.T) {
+		_, err := c.RunPlugin(context.Background(), "screenshot", nil, opts...)
+		var validationErr *ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		assert.Equal(t, want, validationErr.Fields)
+		assert.ErrorIs(t, err, ErrInvalidParams)
+		assert.EqualError(t, err, "invalid params: wait.maxWaitMs: must not be negative; "+
+			"stealth.userAgentHints.brands[1].brand: must not be empty; "+
+			"headers.Bad Header: invalid header name")
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		_, err := c.RunPluginStream(context.Background(), "crawl", nil, opts...)
+		assert.ErrorIs(t, err, ErrInvalidParams)
+	})
+
+	t.Run("apply run options", func(t *testing.T) {
+		_, err := ApplyRunOptions(nil, opts...)
+		assert.ErrorIs(t, err, ErrInvalidParams)
+
+		params, err := ApplyRunOptions(map[string]any{"query": "golang"}, WithWait(WaitCondition{NetworkIdle: true}))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"query": "golang",
+			"wait":  map[string]any{"networkIdle": true},
+		}, params)
+	})
+
+	assert.Zero(t, requests.Load())
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultCredentials read credentials from a HashiCorp Vault KV version 2
+// secret. The secret holds the fields of SecretCredentials: "apiKey",
+// and optionally "certificate" and "privateKey".
+type VaultCredentials struct {
+	// Address is the Vault server address, e.g. "https://vault:8200".
+	Address string
+	// Token authenticates to Vault.
+	Token string
+	// Mount is the KV engine mount path. It defaults to "secret".
+	Mount string
+	// Path is the secret path within the mount, e.g. "browserbro/prod".
+	Path string
+	// TTL is how long the fetched credentials are valid.
+	// Zero means they do not expire.
+	TTL time.Duration
+	// Client is the HTTP client used to reach Vault.
+	// http.DefaultClient is used if nil.
+	Client *http.Client
+}
+
+type vaultKVResponse struct {
+	Data struct {
+		Data secretPayload `json:"data"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// Credentials reads the latest version of the secret.
+func (v VaultCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	mount := v.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+	url := strings.TrimSuffix(v.Address, "/") + "/v1/" +
+		strings.Trim(mount, "/") + "/data/" + strings.TrimPrefix(v.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to create vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read vault secret: %w", err)
+	}
+	defer closeBody(resp.Body)
+
+	var payload vaultKVResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil && resp.StatusCode == http.StatusOK {
+		return Credentials{}, fmt.Errorf("failed to decode vault secret: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg := fmt.Sprintf("unexpected vault response status: %s", resp.Status)
+		if len(payload.Errors) > 0 {
+			msg += "; errors: " + strings.Join(payload.Errors, ", ")
+		}
+		return Credentials{}, errors.New(msg)
+	}
+	return newCredentials(payload.Data.Data, v.TTL)
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors": ["permission denied"]}`))
+			return
+		}
+		assert.Equal(t, "/v1/kv/data/browserbro/prod", r.URL.Path)
+		_, _ = w.Write([]byte(`{"data": {"data": {"apiKey": "key-123"}, "metadata": {"version": 3}}}`))
+	}))
+	defer server.Close()
+
+	v := VaultCredentials{Address: server.URL + "/", Token: "s.token", Mount: "kv", Path: "browserbro/prod"}
+	creds, err := v.Credentials(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "key-123", creds.APIKey)
+
+	v.Token = "wrong"
+	_, err = v.Credentials(context.Background())
+	assert.EqualError(t, err, "unexpected vault response status: 403 Forbidden; errors: permission denied")
+}
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Version is the version of this client library. It is sent in the
+// User-Agent header of every request.
+const Version = "0.1.0"
+
+var userAgent = "browserbro-go-api/" + Version
+
+const (
+	// ServerVersionHeader carries the version of the server.
+	ServerVersionHeader = "X-BrowserBro-Version"
+	// SupportedClientsHeader carries the client versions the server
+	// supports, as a range such as ">=0.1.0 <2.0.0 || 2.1.x".
+	SupportedClientsHeader = "X-BrowserBro-Supported-Clients"
+)
+
+// ErrVersionSkew matches errors of a client version the server does not support.
+var ErrVersionSkew = errors.New("unsupported client version")
+
+// VersionSkewError reports that the server does not support this
+// version of the client library.
+type VersionSkewError struct {
+	ClientVersion string
+	ServerVersion string
+	// Supported is the range of client versions the server supports.
+	Supported string
+}
+
+func (e *VersionSkewError) Error() string {
+	msg := "client version " + e.ClientVersion + " is not supported by the server"
+	if e.ServerVersion != "" {
+		msg += " " + e.ServerVersion
+	}
+	return msg + "; supported client versions: " + e.Supported + "; upgrade the client library"
+}
+
+func (e *VersionSkewError) Is(target error) bool {
+	return target == ErrVersionSkew
+}
+
+// WithStrictVersionCheck makes every call fail with a *VersionSkewError
+// once the server reports that it does not support this client version.
+// Without it, the skew is only logged to the logger set by WithLogger.
+func WithStrictVersionCheck() Option {
+	return func(c *Client) {
+		c.versionCheck.strict = true
+	}
+}
+
+// versionCheck compares the client version with the first supported
+// range advertised by the server.
+type versionCheck struct {
+	strict bool
+
+	mu      sync.Mutex
+	checked bool
+	err     *VersionSkewError
+}
+
+// checkVersion checks the version once a response header h advertises
+// the supported client versions; h may be nil before a request is sent.
+// It returns the skew error in strict mode.
+func (c *Client) checkVersion(req *http.Request, h http.Header) error {
+	vc := c.versionCheck
+	vc.mu.Lock()
+	if !vc.checked {
+		if supported := h.Get(SupportedClientsHeader); supported != "" {
+			vc.checked = true
+			if ok, err := versionInRange(Version, supported); err == nil && !ok {
+				vc.err = &VersionSkewError{
+					ClientVersion: Version,
+					ServerVersion: h.Get(ServerVersionHeader),
+					Supported:     supported,
+				}
+				if c.logger != nil {
+					c.logger.WarnContext(req.Context(), "browserbro: unsupported client version",
+						"clientVersion", vc.err.ClientVersion,
+						"serverVersion", vc.err.ServerVersion,
+						"supported", vc.err.Supported,
+					)
+				}
+			}
+		}
+	}
+	skew := vc.err
+	vc.mu.Unlock()
+
+	if vc.strict && skew != nil {
+		return skew
+	}
+	return nil
+}
+
+// versionInRange reports whether version satisfies a range of
+// comparators (=, >, >=, <, <=, and wildcard versions such as 1.2.x)
+// joined by spaces or commas, with alternatives separated by "||".
+func versionInRange(version, ranges string) (bool, error) {
+	v, err := parseVersion(version)
+	if err != nil {
+		return false, err
+	}
+	for _, alt := range strings.Split(ranges, "||") {
+		ok := true
+		comparators := strings.FieldsFunc(alt, func(r rune) bool { return r == ' ' || r == ',' })
+		if len(comparators) == 0 {
+			continue
+		}
+		for _, cmp := range comparators {
+			match, err := matchComparator(v, cmp)
+			if err != nil {
+				return false, err
+			}
+			ok = ok && match
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func matchComparator(v [3]int, cmp string) (bool, error) {
+	op := strings.TrimRight(cmp, "0123456789.xX*v")
+	target := cmp[len(op):]
+	if target == "" {
+		return false, fmt.Errorf("invalid version comparator %q", cmp)
+	}
+	if op == "" || op == "=" {
+		// A bare version may use wildcards: 1.2.x matches 1.2.*.
+		parts := strings.Split(strings.TrimPrefix(target, "v"), ".")
+		for i, p := range parts {
+			if p == "x" || p == "X" || p == "*" {
+				return true, nil
+			}
+			n, err := strconv.Atoi(p)
+			if err != nil || i > 2 {
+				return false, fmt.Errorf("invalid version %q", target)
+			}
+			if v[i] != n {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	t, err := parseVersion(target)
+	if err != nil {
+		return false, err
+	}
+	c := compareVersions(v, t)
+	switch op {
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	default:
+		return false, fmt.Errorf("invalid version comparator %q", cmp)
+	}
+}
+
+// parseVersion parses MAJOR[.MINOR[.PATCH]] with an optional "v"
+// prefix, ignoring pre-release and build suffixes.
+func parseVersion(s string) ([3]int, error) {
+	var v [3]int
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, fmt.Errorf("invalid version %q", s)
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version %q", s)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionInRange(t *testing.T) {
+	tests := []struct {
+		version string
+		ranges  string
+		want    bool
+		wantErr bool
+	}{
+		{"0.1.0", ">=0.1.0 <2.0.0", true, false},
+		{"0.1.0", ">=0.1.0, <2.0.0", true, false},
+		{"2.0.0", ">=0.1.0 <2.0.0", false, false},
+		{"2.1.5", ">=0.1.0 <2.0.0 || 2.1.x", true, false},
+		{"2.2.0", ">=0.1.0 <2.0.0 || 2.1.x", false, false},
+		{"1.0.0", "1", true, false},
+		{"1.0.0", ">v0.9", true, false},
+		{"1.0.0-rc.1", "<=1.0.0", true, false},
+		{"1.0.0", "*", true, false},
+		{"1.0.0", "~1.0", false, true},
+		{"1.0.0", ">=", false, true},
+		{"1.0.0", ">=1.a", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.ranges, func(t *testing.T) {
+			got, err := versionInRange(tt.version, tt.ranges)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// versionServer advertises the supported client range and counts requests.
+func versionServer(t *testing.T, supported string, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "browserbro-go-api/"+Version, r.Header.Get("User-Agent"))
+		w.Header().Set(ServerVersionHeader, "3.0.0")
+		w.Header().Set(SupportedClientsHeader, supported)
+		_, _ = w.Write([]byte(`{"plugins": []}`))
+	}))
+}
+
+func TestVersionSkew(t *testing.T) {
+	t.Run("warning", func(t *testing.T) {
+		var requests atomic.Int32
+		server := versionServer(t, ">=9.0.0", &requests)
+		defer server.Close()
+
+		var logs bytes.Buffer
+		c, err := New(server.URL, nil, WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+		require.NoError(t, err)
+
+		require.NoError(t, c.Healthcheck(context.Background()))
+		require.NoError(t, c.Healthcheck(context.Background()))
+		lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+		require.Len(t, lines, 1)
+		assert.Contains(t, lines[0], `msg="browserbro: unsupported client version" clientVersion=`+Version+
+			` serverVersion=3.0.0 supported=">=9.0.0"`)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		var requests atomic.Int32
+		server := versionServer(t, ">=9.0.0", &requests)
+		defer server.Close()
+
+		c, err := New(server.URL, nil, WithStrictVersionCheck())
+		require.NoError(t, err)
+
+		err = c.Healthcheck(context.Background())
+		assert.ErrorIs(t, err, ErrVersionSkew)
+		assert.EqualError(t, err, "failed to perform health check: client version "+Version+
+			" is not supported by the server 3.0.0; supported client versions: >=9.0.0; upgrade the client library")
+
+		_, err = c.Plugins(context.Background())
+		assert.ErrorIs(t, err, ErrVersionSkew)
+		assert.Equal(t, int32(1), requests.Load(), "no request is sent once the skew is known")
+	})
+
+	t.Run("supported", func(t *testing.T) {
+		var requests atomic.Int32
+		server := versionServer(t, ">="+Version, &requests)
+		defer server.Close()
+
+		c, err := New(server.URL, nil, WithStrictVersionCheck())
+		require.NoError(t, err)
+		require.NoError(t, c.Healthcheck(context.Background()))
+		require.NoError(t, c.Healthcheck(context.Background()))
+	})
+}
@@ -0,0 +1,43 @@
+package client
+
+import "time"
+
+// WaitCondition describes what a plugin should wait for after navigating
+// to a page and before collecting its output. Zero fields are omitted.
+type WaitCondition struct {
+	// Selector waits until an element matching the CSS selector is present.
+	Selector string
+	// NetworkIdle waits until there are no in-flight network requests.
+	NetworkIdle bool
+	// Predicate waits until the JavaScript expression evaluates to true.
+	Predicate string
+	// MaxWait caps the total time spent waiting.
+	MaxWait time.Duration
+}
+
+// WithWait makes the plugin wait for the given condition after navigation.
+func WithWait(w WaitCondition) RunOption {
+	return func(cfg *runConfig) {
+		if w.MaxWait < 0 {
+			cfg.invalid("wait.maxWaitMs", CodeInvalid, "must not be negative")
+		}
+		cfg.params["wait"] = w.params()
+	}
+}
+
+func (w WaitCondition) params() map[string]any {
+	p := map[string]any{}
+	if w.Selector != "" {
+		p["selector"] = w.Selector
+	}
+	if w.NetworkIdle {
+		p["networkIdle"] = true
+	}
+	if w.Predicate != "" {
+		p["predicate"] = w.Predicate
+	}
+	if w.MaxWait > 0 {
+		p["maxWaitMs"] = w.MaxWait.Milliseconds()
+	}
+	return p
+}
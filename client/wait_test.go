@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithWait(t *testing.T) {
+	t.Run("all conditions", func(t *testing.T) {
+		var params map[string]any
+		server := captureServer(t, http.StatusOK, `{}`, &params)
+		defer server.Close()
+
+		c, err := New(server.URL, nil)
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "screenshot", nil, WithWait(WaitCondition{
+			Selector:    "#content",
+			NetworkIdle: true,
+			Predicate:   "window.ready === true",
+			MaxWait:     5 * time.Second,
+		}))
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]any{
+			"selector":    "#content",
+			"networkIdle": true,
+			"predicate":   "window.ready === true",
+			"maxWaitMs":   5000.0,
+		}, params["wait"])
+	})
+
+	t.Run("zero fields are omitted", func(t *testing.T) {
+		p := WaitCondition{Selector: "body"}.params()
+		assert.Equal(t, map[string]any{"selector": "body"}, p)
+	})
+}
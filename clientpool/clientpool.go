@@ -0,0 +1,98 @@
+// Package clientpool spreads calls across several BrowserBro clients,
+// each with its own HTTP transport, for workloads where the connection
+// limits of a single transport become the bottleneck.
+package clientpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+// Pool is a client.API implementation that round-robins calls across
+// its clients. It is safe for concurrent use.
+type Pool struct {
+	clients []*client.Client
+	next    atomic.Uint64
+}
+
+var _ client.API = (*Pool)(nil)
+
+// New creates a pool of size clients of the server. newHTTPClient is
+// called once per client and must return a client with its own
+// transport; if nil, each client gets a clone of http.DefaultTransport
+// and a 30 second timeout. Every client is created with opts.
+func New(
+	serverAddress string,
+	size int,
+	newHTTPClient func() *http.Client,
+	opts ...client.Option,
+) (*Pool, error) {
+	if size <= 0 {
+		return nil, errors.New("pool size must be positive")
+	}
+	if newHTTPClient == nil {
+		newHTTPClient = func() *http.Client {
+			return &http.Client{
+				Transport: http.DefaultTransport.(*http.Transport).Clone(),
+				Timeout:   30 * time.Second,
+			}
+		}
+	}
+	p := &Pool{clients: make([]*client.Client, size)}
+	for i := range p.clients {
+		c, err := client.New(serverAddress, newHTTPClient(), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client %d: %w", i, err)
+		}
+		p.clients[i] = c
+	}
+	return p, nil
+}
+
+// Next returns the next client in round-robin order, for calls
+// outside of client.API.
+func (p *Pool) Next() *client.Client {
+	n := p.next.Add(1) - 1
+	return p.clients[n%uint64(len(p.clients))]
+}
+
+// Clients returns the clients of the pool.
+func (p *Pool) Clients() []*client.Client {
+	return append([]*client.Client(nil), p.clients...)
+}
+
+// Plugins fetches a list of available plugins.
+func (p *Pool) Plugins(ctx context.Context) ([]string, error) {
+	return p.Next().Plugins(ctx)
+}
+
+// RunPlugin runs a plugin with the given name and parameters.
+func (p *Pool) RunPlugin(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	opts ...client.RunOption,
+) (map[string]any, error) {
+	return p.Next().RunPlugin(ctx, pluginName, params, opts...)
+}
+
+// DownloadFile downloads a file with the given ID.
+func (p *Pool) DownloadFile(ctx context.Context, fileID string) ([]byte, error) {
+	return p.Next().DownloadFile(ctx, fileID)
+}
+
+// DeleteFile deletes a file with the given ID.
+func (p *Pool) DeleteFile(ctx context.Context, fileID string) error {
+	return p.Next().DeleteFile(ctx, fileID)
+}
+
+// Healthcheck performs a health check on the server.
+func (p *Pool) Healthcheck(ctx context.Context) error {
+	return p.Next().Healthcheck(ctx)
+}
@@ -0,0 +1,67 @@
+package clientpool
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New("http://localhost", 0, nil)
+	assert.EqualError(t, err, "pool size must be positive")
+
+	_, err = New("", 2, nil)
+	assert.EqualError(t, err, "failed to create client 0: server address is required")
+
+	p, err := New("http://localhost", 3, nil)
+	require.NoError(t, err)
+	assert.Len(t, p.Clients(), 3)
+}
+
+func TestPool(t *testing.T) {
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"plugins": ["screenshot"]}`))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	p, err := New(server.URL, 3, nil)
+	require.NoError(t, err)
+
+	seen := map[any]bool{}
+	for i := 0; i < 6; i++ {
+		seen[p.Next()] = true
+	}
+	assert.Len(t, seen, 3)
+
+	// Every client keeps its own connection.
+	for i := 0; i < 9; i++ {
+		plugins, err := p.Plugins(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"screenshot"}, plugins)
+	}
+	assert.Equal(t, int32(3), conns.Load())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, p.Healthcheck(context.Background()))
+		}()
+	}
+	wg.Wait()
+}
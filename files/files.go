@@ -0,0 +1,154 @@
+// Package files provides the FileID type for files stored on a
+// BrowserBro server and helpers to find them in plugin outputs.
+package files
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MaxIDLength is the length limit of a file ID.
+const MaxIDLength = 255
+
+// ErrInvalidID is returned for malformed file IDs.
+var ErrInvalidID = errors.New("invalid file ID")
+
+// FileID identifies a file stored on the server, e.g. a screenshot
+// created by a plugin run.
+type FileID string
+
+// Parse returns s as a FileID if it is valid.
+func Parse(s string) (FileID, error) {
+	id := FileID(s)
+	if err := id.Validate(); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Validate checks that id is a single, non-empty path segment of
+// printable characters, so it cannot address another resource.
+func (id FileID) Validate() error {
+	s := string(id)
+	switch {
+	case s == "":
+		return fmt.Errorf("%w: empty", ErrInvalidID)
+	case len(s) > MaxIDLength:
+		return fmt.Errorf("%w: longer than %d bytes", ErrInvalidID, MaxIDLength)
+	case s == "." || s == "..":
+		return fmt.Errorf("%w: %q", ErrInvalidID, s)
+	case strings.ContainsAny(s, `/\?#`):
+		return fmt.Errorf("%w: %q contains a reserved character", ErrInvalidID, s)
+	case strings.ContainsFunc(s, func(r rune) bool { return !unicode.IsPrint(r) }):
+		return fmt.Errorf("%w: %q contains a control character", ErrInvalidID, s)
+	}
+	return nil
+}
+
+func (id FileID) String() string {
+	return string(id)
+}
+
+// MarshalJSON encodes id as a JSON string.
+func (id FileID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(id))
+}
+
+// UnmarshalJSON decodes a JSON string and validates it. An empty string
+// decodes to the empty FileID, for optional fields.
+func (id *FileID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidID, data)
+	}
+	if s != "" {
+		if err := FileID(s).Validate(); err != nil {
+			return err
+		}
+	}
+	*id = FileID(s)
+	return nil
+}
+
+// Strings converts ids to strings.
+func Strings(ids []FileID) []string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = string(id)
+	}
+	return s
+}
+
+// ExtractIDs returns the file IDs referenced by a plugin output,
+// without duplicates: the string values of "fileId" keys and the elements
+// of "fileIds" arrays at any depth, visiting object keys in sorted order.
+func ExtractIDs(output any) []FileID {
+	var ids []FileID
+	seen := make(map[FileID]bool)
+	add := func(id FileID) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	var walk func(v any)
+	walk = func(v any) {
+		switch t := v.(type) {
+		case map[string]any:
+			keys := make([]string, 0, len(t))
+			for k := range t {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				child := t[k]
+				switch k {
+				case "fileId":
+					switch id := child.(type) {
+					case string:
+						add(FileID(id))
+						continue
+					case FileID:
+						add(id)
+						continue
+					}
+				case "fileIds":
+					switch list := child.(type) {
+					case []any:
+						for _, item := range list {
+							switch id := item.(type) {
+							case string:
+								add(FileID(id))
+							case FileID:
+								add(id)
+							}
+						}
+						continue
+					case []string:
+						for _, id := range list {
+							add(FileID(id))
+						}
+						continue
+					case []FileID:
+						for _, id := range list {
+							add(id)
+						}
+						continue
+					}
+				}
+				walk(child)
+			}
+		case []any:
+			for _, child := range t {
+				walk(child)
+			}
+		}
+	}
+	walk(output)
+	return ids
+}
@@ -0,0 +1,64 @@
+package files
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileID_Validate(t *testing.T) {
+	for _, s := range []string{"f1", "screenshot-1.png", "a b"} {
+		assert.NoError(t, FileID(s).Validate(), s)
+	}
+	for _, s := range []string{"", ".", "..", "../health", "a/b", `a\b`, "a?b", "a#b", "a\nb", strings.Repeat("a", MaxIDLength+1)} {
+		assert.ErrorIs(t, FileID(s).Validate(), ErrInvalidID, s)
+	}
+}
+
+func TestParse(t *testing.T) {
+	id, err := Parse("f1")
+	require.NoError(t, err)
+	assert.Equal(t, "f1", id.String())
+
+	_, err = Parse("../f1")
+	assert.ErrorIs(t, err, ErrInvalidID)
+}
+
+func TestFileID_JSON(t *testing.T) {
+	var v struct {
+		ID    FileID   `json:"fileId"`
+		IDs   []FileID `json:"fileIds"`
+		Empty FileID   `json:"empty"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"fileId": "f1", "fileIds": ["f2"], "empty": ""}`), &v))
+	assert.Equal(t, FileID("f1"), v.ID)
+	assert.Equal(t, []FileID{"f2"}, v.IDs)
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"fileId": "f1", "fileIds": ["f2"], "empty": ""}`, string(data))
+
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"fileId": "a/b"}`), &v), ErrInvalidID)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"fileId": 1}`), &v), ErrInvalidID)
+}
+
+func TestExtractIDs(t *testing.T) {
+	output := map[string]any{
+		"screenshot": map[string]any{
+			"https://b.com": map[string]any{"fileId": "f2"},
+			"https://a.com": map[string]any{"fileId": "f1"},
+		},
+		"pdf": []any{
+			map[string]any{"fileIds": []any{"f3", "f1", 42}},
+		},
+		"typed": map[string]any{"fileIds": []FileID{"f4"}, "fileId": FileID("f5")},
+		"other": map[string]any{"fileId": 1},
+	}
+	ids := ExtractIDs(output)
+	assert.Equal(t, []FileID{"f3", "f1", "f2", "f5", "f4"}, ids)
+	assert.Equal(t, []string{"f3", "f1", "f2", "f5", "f4"}, Strings(ids))
+	assert.Empty(t, ExtractIDs(map[string]any{"query": "x"}))
+}
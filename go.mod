@@ -2,7 +2,12 @@ module github.com/bazuker/browserbro-go-api
 
 go 1.22
 
-require github.com/stretchr/testify v1.9.0
+require (
+	github.com/gorilla/websocket v1.5.3
+	github.com/klauspost/compress v1.17.11
+	github.com/stretchr/testify v1.9.0
+	google.golang.org/protobuf v1.34.2
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
@@ -0,0 +1,71 @@
+// Package kafka publishes completed BrowserBro plugin runs to a Kafka topic.
+//
+// The package does not depend on a specific Kafka driver. Any producer,
+// such as a segmentio/kafka-go Writer or a franz-go client, is adapted
+// by implementing Producer.
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+// Producer writes a single record to a Kafka topic.
+type Producer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) error
+}
+
+// Publisher is a client.ResultPublisher writing client.ResultEvent records
+// as JSON to a topic. Records are keyed by job ID, falling back to the
+// plugin name, so events of the same job land in the same partition.
+type Publisher struct {
+	producer Producer
+	topic    string
+	timeout  time.Duration
+}
+
+var _ client.ResultPublisher = (*Publisher)(nil)
+
+// NewPublisher creates a Publisher. Each record is produced with
+// the given timeout; zero means no timeout.
+func NewPublisher(producer Producer, topic string, timeout time.Duration) (*Publisher, error) {
+	if producer == nil {
+		return nil, errors.New("producer is required")
+	}
+	if topic == "" {
+		return nil, errors.New("topic is required")
+	}
+	return &Publisher{
+		producer: producer,
+		topic:    topic,
+		timeout:  timeout,
+	}, nil
+}
+
+// PublishResult implements client.ResultPublisher.
+func (p *Publisher) PublishResult(event client.ResultEvent) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to JSON encode event: %w", err)
+	}
+	key := event.JobID
+	if key == "" {
+		key = event.Plugin
+	}
+
+	ctx := context.Background()
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	if err := p.producer.Produce(ctx, p.topic, []byte(key), value); err != nil {
+		return fmt.Errorf("failed to produce record: %w", err)
+	}
+	return nil
+}
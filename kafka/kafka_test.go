@@ -0,0 +1,90 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bazuker/browserbro-go-api/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	topic      string
+	key, value []byte
+}
+
+type memoryProducer struct {
+	records []record
+	err     error
+}
+
+func (m *memoryProducer) Produce(ctx context.Context, topic string, key, value []byte) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.records = append(m.records, record{topic: topic, key: key, value: value})
+	return nil
+}
+
+func TestNewPublisher(t *testing.T) {
+	_, err := NewPublisher(nil, "results", 0)
+	require.Error(t, err)
+	_, err = NewPublisher(&memoryProducer{}, "", 0)
+	require.Error(t, err)
+}
+
+func TestPublisher(t *testing.T) {
+	t.Run("publishes client results", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Job-ID", "job-1")
+			_, _ = io.Copy(w, bytes.NewBufferString(`{"screenshot": {"fileIds": ["f1"]}}`))
+		}))
+		defer server.Close()
+
+		producer := &memoryProducer{}
+		p, err := NewPublisher(producer, "browserbro.results", time.Second)
+		require.NoError(t, err)
+
+		c, err := client.New(server.URL, nil, client.WithResultPublisher(p))
+		require.NoError(t, err)
+
+		_, err = c.RunPlugin(context.Background(), "screenshot", nil)
+		require.NoError(t, err)
+
+		require.Len(t, producer.records, 1)
+		rec := producer.records[0]
+		assert.Equal(t, "browserbro.results", rec.topic)
+		assert.Equal(t, "job-1", string(rec.key))
+
+		var event map[string]any
+		require.NoError(t, json.Unmarshal(rec.value, &event))
+		assert.Equal(t, 1.0, event["schemaVersion"])
+		assert.Equal(t, "screenshot", event["plugin"])
+		assert.Equal(t, []any{"f1"}, event["fileIds"])
+	})
+
+	t.Run("key falls back to plugin", func(t *testing.T) {
+		producer := &memoryProducer{}
+		p, err := NewPublisher(producer, "results", 0)
+		require.NoError(t, err)
+
+		require.NoError(t, p.PublishResult(client.ResultEvent{Plugin: "googlesearch"}))
+		assert.Equal(t, "googlesearch", string(producer.records[0].key))
+	})
+
+	t.Run("producer error", func(t *testing.T) {
+		p, err := NewPublisher(&memoryProducer{err: errors.New("broker down")}, "results", 0)
+		require.NoError(t, err)
+
+		err = p.PublishResult(client.ResultEvent{Plugin: "googlesearch"})
+		require.EqualError(t, err, "failed to produce record: broker down")
+	})
+}
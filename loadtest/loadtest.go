@@ -0,0 +1,250 @@
+// Package loadtest drives a BrowserBro server with a configurable mix of
+// plugin runs and reports throughput, latency percentiles and errors, so
+// capacity planning runs are reproducible.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+// Job is an entry of the plugin mix.
+type Job struct {
+	Plugin string `json:"plugin"`
+	// Params lists parameter sets; every run picks one at random.
+	Params []map[string]any `json:"params"`
+	// Weight is the relative share of runs of the job; zero means 1.
+	Weight int `json:"weight,omitempty"`
+}
+
+// Config configures a load test.
+type Config struct {
+	// Mix lists the jobs to run.
+	Mix []Job
+	// Concurrency is the number of concurrent workers once ramped up.
+	Concurrency int
+	// RampUp is the time over which workers are started, evenly spaced.
+	// Zero starts all workers at once.
+	RampUp time.Duration
+	// Duration is how long new runs are started, including the ramp-up.
+	Duration time.Duration
+}
+
+// Latency summarizes run latencies.
+type Latency struct {
+	Min, Mean, Max time.Duration
+	P50, P90, P99  time.Duration
+}
+
+// Report is the outcome of a load test.
+type Report struct {
+	Runs      int
+	Succeeded int
+	Failed    int
+	// Elapsed is the time from the first run started to the last one finished.
+	Elapsed time.Duration
+	// Throughput is the number of runs per second.
+	Throughput float64
+	Latency    Latency
+	// Errors counts failed runs by kind: "status <code>", "timeout",
+	// "connection" or "other".
+	Errors map[string]int
+	// Plugins counts runs by plugin.
+	Plugins map[string]int
+}
+
+// Run runs the load test against api until cfg.Duration elapses or ctx
+// is done, then waits for runs in progress and returns the report.
+// Runs in progress when cfg.Duration elapses complete, while canceling
+// ctx aborts them.
+func Run(ctx context.Context, api client.API, cfg Config) (*Report, error) {
+	if len(cfg.Mix) == 0 {
+		return nil, errors.New("plugin mix is required")
+	}
+	for i, job := range cfg.Mix {
+		if job.Plugin == "" {
+			return nil, fmt.Errorf("job %d: plugin is required", i)
+		}
+		if job.Weight < 0 {
+			return nil, fmt.Errorf("job %d: weight must not be negative", i)
+		}
+	}
+	if cfg.Duration <= 0 {
+		return nil, errors.New("duration must be positive")
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+
+	runCtx := ctx
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	rec := &recorder{errors: map[string]int{}, plugins: map[string]int{}}
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.Concurrency; i++ {
+		if i > 0 && cfg.RampUp > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(cfg.RampUp / time.Duration(cfg.Concurrency)):
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				job := pick(cfg.Mix)
+				var params map[string]any
+				if len(job.Params) > 0 {
+					params = job.Params[rand.IntN(len(job.Params))]
+				}
+				runStart := time.Now()
+				_, err := api.RunPlugin(runCtx, job.Plugin, params)
+				rec.add(job.Plugin, time.Since(runStart), err)
+			}
+		}()
+	}
+	wg.Wait()
+	return rec.report(time.Since(start)), nil
+}
+
+// pick picks a job at random according to the job weights.
+func pick(mix []Job) Job {
+	total := 0
+	for _, job := range mix {
+		total += weight(job)
+	}
+	n := rand.IntN(total)
+	for _, job := range mix {
+		if n -= weight(job); n < 0 {
+			return job
+		}
+	}
+	return mix[len(mix)-1]
+}
+
+func weight(job Job) int {
+	if job.Weight == 0 {
+		return 1
+	}
+	return job.Weight
+}
+
+type recorder struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	failed    int
+	errors    map[string]int
+	plugins   map[string]int
+}
+
+func (r *recorder) add(plugin string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies = append(r.latencies, latency)
+	r.plugins[plugin]++
+	if err != nil {
+		r.failed++
+		r.errors[errorKind(err)]++
+	}
+}
+
+func (r *recorder) report(elapsed time.Duration) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := &Report{
+		Runs:      len(r.latencies),
+		Succeeded: len(r.latencies) - r.failed,
+		Failed:    r.failed,
+		Elapsed:   elapsed,
+		Latency:   summarize(r.latencies),
+		Errors:    r.errors,
+		Plugins:   r.plugins,
+	}
+	if elapsed > 0 {
+		report.Throughput = float64(report.Runs) / elapsed.Seconds()
+	}
+	return report
+}
+
+// errorKind classifies a run error for the error breakdown.
+func errorKind(err error) string {
+	var apiErr *client.APIError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, client.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.As(err, &apiErr):
+		return "status " + strconv.Itoa(apiErr.StatusCode)
+	case errors.As(err, &netErr):
+		return "connection"
+	default:
+		return "other"
+	}
+}
+
+// summarize computes latency statistics with nearest-rank percentiles.
+func summarize(latencies []time.Duration) Latency {
+	if len(latencies) == 0 {
+		return Latency{}
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, l := range sorted {
+		total += l
+	}
+	percentile := func(p int) time.Duration {
+		rank := (p*len(sorted) + 99) / 100
+		return sorted[max(rank, 1)-1]
+	}
+	return Latency{
+		Min:  sorted[0],
+		Mean: total / time.Duration(len(sorted)),
+		Max:  sorted[len(sorted)-1],
+		P50:  percentile(50),
+		P90:  percentile(90),
+		P99:  percentile(99),
+	}
+}
+
+// Write writes a human-readable summary of the report to w.
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "runs\t%d (%d succeeded, %d failed)\n", r.Runs, r.Succeeded, r.Failed)
+	fmt.Fprintf(tw, "elapsed\t%s\n", r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(tw, "throughput\t%.2f runs/s\n", r.Throughput)
+	fmt.Fprintf(tw, "latency\tmin %s, mean %s, p50 %s, p90 %s, p99 %s, max %s\n",
+		r.Latency.Min, r.Latency.Mean, r.Latency.P50, r.Latency.P90, r.Latency.P99, r.Latency.Max)
+	for _, plugin := range sortedKeys(r.Plugins) {
+		fmt.Fprintf(tw, "plugin %s\t%d\n", plugin, r.Plugins[plugin])
+	}
+	for _, kind := range sortedKeys(r.Errors) {
+		fmt.Fprintf(tw, "error %s\t%d\n", kind, r.Errors[kind])
+	}
+	return tw.Flush()
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
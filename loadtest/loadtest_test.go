@@ -0,0 +1,129 @@
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+// fakeAPI fails every third run with a 503 and records the peak
+// number of concurrent runs.
+type fakeAPI struct {
+	client.API
+	calls, inflight, peak atomic.Int32
+}
+
+func (f *fakeAPI) RunPlugin(_ context.Context, pluginName string, _ map[string]any, _ ...client.RunOption) (map[string]any, error) {
+	n := f.inflight.Add(1)
+	defer f.inflight.Add(-1)
+	for {
+		p := f.peak.Load()
+		if n <= p || f.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	if f.calls.Add(1)%3 == 0 {
+		return nil, &client.APIError{StatusCode: http.StatusServiceUnavailable}
+	}
+	return map[string]any{pluginName: true}, nil
+}
+
+func TestRun(t *testing.T) {
+	api := &fakeAPI{}
+	report, err := Run(context.Background(), api, Config{
+		Mix: []Job{
+			{Plugin: "screenshot", Params: []map[string]any{{"url": "a"}}, Weight: 3},
+			{Plugin: "googlesearch"},
+		},
+		Concurrency: 4,
+		RampUp:      20 * time.Millisecond,
+		Duration:    100 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int(api.calls.Load()), report.Runs)
+	assert.Equal(t, report.Runs, report.Succeeded+report.Failed)
+	assert.Equal(t, report.Failed, report.Errors["status 503"])
+	assert.Equal(t, report.Runs, report.Plugins["screenshot"]+report.Plugins["googlesearch"])
+	assert.Greater(t, report.Plugins["screenshot"], report.Plugins["googlesearch"])
+	assert.LessOrEqual(t, api.peak.Load(), int32(4))
+	assert.Greater(t, report.Throughput, 0.0)
+	assert.GreaterOrEqual(t, report.Elapsed, 100*time.Millisecond)
+	assert.LessOrEqual(t, report.Latency.Min, report.Latency.P50)
+	assert.LessOrEqual(t, report.Latency.P99, report.Latency.Max)
+}
+
+func TestRun_Config(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{"no mix", Config{Duration: time.Second}, "plugin mix is required"},
+		{"no plugin", Config{Mix: []Job{{}}, Duration: time.Second}, "job 0: plugin is required"},
+		{"negative weight", Config{Mix: []Job{{Plugin: "a", Weight: -1}}, Duration: time.Second}, "job 0: weight must not be negative"},
+		{"no duration", Config{Mix: []Job{{Plugin: "a"}}}, "duration must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Run(context.Background(), &fakeAPI{}, tt.cfg)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	assert.Equal(t, "status 429", errorKind(&client.APIError{StatusCode: http.StatusTooManyRequests}))
+	assert.Equal(t, "timeout", errorKind(&client.APIError{StatusCode: http.StatusGatewayTimeout}))
+	assert.Equal(t, "timeout", errorKind(context.DeadlineExceeded))
+	assert.Equal(t, "other", errorKind(errors.New("boom")))
+}
+
+func TestSummarize(t *testing.T) {
+	assert.Equal(t, Latency{}, summarize(nil))
+
+	var latencies []time.Duration
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, Latency{
+		Min:  time.Millisecond,
+		Mean: 50500 * time.Microsecond,
+		Max:  100 * time.Millisecond,
+		P50:  50 * time.Millisecond,
+		P90:  90 * time.Millisecond,
+		P99:  99 * time.Millisecond,
+	}, summarize(latencies))
+}
+
+func TestReport_Write(t *testing.T) {
+	report := &Report{
+		Runs:       3,
+		Succeeded:  2,
+		Failed:     1,
+		Elapsed:    time.Second,
+		Throughput: 3,
+		Latency:    summarize([]time.Duration{time.Millisecond}),
+		Errors:     map[string]int{"status 503": 1},
+		Plugins:    map[string]int{"screenshot": 3},
+	}
+	var sb strings.Builder
+	require.NoError(t, report.Write(&sb))
+	assert.Equal(t, `runs               3 (2 succeeded, 1 failed)
+elapsed            1s
+throughput         3.00 runs/s
+latency            min 1ms, mean 1ms, p50 1ms, p90 1ms, p99 1ms, max 1ms
+plugin screenshot  3
+error status 503   1
+`, sb.String())
+}
@@ -1,85 +1,114 @@
+// Command browserbro-go-api load tests a BrowserBro server with a mix of
+// plugin runs and prints a report:
+//
+//	go run . -server http://localhost:10001 -concurrency 20 -ramp 10s -duration 1m -mix mix.json
+//
+// The mix file holds a JSON array of loadtest.Job. Without it, the
+// screenshot and googlesearch plugins are run with sample params.
+//
+// The replay subcommand lists the calls exported by a client.Recorder,
+// or sends one of them again, e.g. against a staging server:
+//
+//	go run . replay -file calls.jsonl
+//	go run . replay -server http://staging:10001 -file calls.jsonl -id 42
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
-	"math/rand/v2"
-	"sync"
+	"os"
+	"os/signal"
+	"time"
 
 	"github.com/bazuker/browserbro-go-api/client"
+	"github.com/bazuker/browserbro-go-api/loadtest"
 )
 
+var defaultMix = []loadtest.Job{
+	{
+		Plugin: "screenshot",
+		Params: []map[string]any{
+			{"urls": []string{"https://nowsecure.nl/"}},
+			{"urls": []string{"https://bot.sannysoft.com"}},
+		},
+	},
+	{
+		Plugin: "googlesearch",
+		Params: []map[string]any{
+			{"query": "golang"},
+			{"query": "javascript"},
+			{"query": "python"},
+			{"query": "java"},
+			{"query": "c++"},
+			{"query": "rust"},
+		},
+	},
+}
+
 func main() {
-	c, err := client.New("http://localhost:10001", nil)
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		replay(os.Args[2:])
+		return
+	}
+	loadTest(os.Args[1:])
+}
+
+func loadTest(args []string) {
+	fs := flag.NewFlagSet("load test", flag.ExitOnError)
+	server := fs.String("server", "http://localhost:10001", "BrowserBro server address")
+	concurrency := fs.Int("concurrency", 10, "number of concurrent workers")
+	rampUp := fs.Duration("ramp", 0, "time over which workers are started")
+	duration := fs.Duration("duration", 30*time.Second, "how long to start new runs")
+	mixFile := fs.String("mix", "", "path to a JSON file with the plugin mix")
+	attempts := fs.Int("attempts", 1, "attempts per request, retrying transient failures")
+	_ = fs.Parse(args)
+
+	mix := defaultMix
+	if *mixFile != "" {
+		data, err := os.ReadFile(*mixFile)
+		if err != nil {
+			fmt.Println("failed to read plugin mix:", err)
+			os.Exit(1)
+		}
+		if err := json.Unmarshal(data, &mix); err != nil {
+			fmt.Println("failed to decode plugin mix:", err)
+			os.Exit(1)
+		}
+	}
+
+	var opts []client.Option
+	if *attempts > 1 {
+		opts = append(opts, client.WithRetry(*attempts, nil))
+	}
+	c, err := client.New(*server, nil, opts...)
 	if err != nil {
 		fmt.Println("failed to create client:", err)
-		return
+		os.Exit(1)
 	}
-	plugins, err := c.Plugins()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	plugins, err := c.Plugins(ctx)
 	if err != nil {
 		fmt.Println("failed to fetch plugins:", err)
-		return
+		os.Exit(1)
 	}
 	fmt.Println("available plugins:", plugins)
 
-	runPluginsConcurrently(c, 10, "screenshot", []map[string]any{
-		{
-			"urls": []string{"https://nowsecure.nl/"},
-		},
-		{
-			"urls": []string{"https://bot.sannysoft.com"},
-		},
+	report, err := loadtest.Run(ctx, c, loadtest.Config{
+		Mix:         mix,
+		Concurrency: *concurrency,
+		RampUp:      *rampUp,
+		Duration:    *duration,
 	})
-
-	runPluginsConcurrently(c, 10, "googlesearch", []map[string]any{
-		{
-			"query": "golang",
-		},
-		{
-			"query": "javascript",
-		},
-		{
-			"query": "python",
-		},
-		{
-			"query": "java",
-		},
-		{
-			"query": "c++",
-		},
-		{
-			"query": "rust",
-		},
-	})
-}
-
-func runPluginsConcurrently(
-	client *client.Client,
-	numOfJobs int,
-	pluginName string,
-	params []map[string]any,
-) {
-	fmt.Println("running", numOfJobs, "jobs concurrently, plugin:", pluginName)
-	success := 0
-	var wg sync.WaitGroup
-	wg.Add(numOfJobs)
-	for i := 0; i < numOfJobs; i++ {
-		go func() {
-			fmt.Println("running plugin job", i)
-			defer wg.Done()
-			// pick params at random from the array
-			output, err := client.RunPlugin(pluginName, params[rand.IntN(len(params))])
-			if err != nil {
-				fmt.Println("failed to run plugin:", err)
-				return
-			}
-			fmt.Printf("plugin %d output: %v\n", i, output[pluginName])
-			if len(output) > 0 && output[pluginName] != nil {
-				success++
-			}
-		}()
+	if err != nil {
+		fmt.Println("failed to run load test:", err)
+		os.Exit(1)
+	}
+	if err := report.Write(os.Stdout); err != nil {
+		fmt.Println("failed to write report:", err)
+		os.Exit(1)
 	}
-
-	wg.Wait()
-	fmt.Printf("successfully ran %d out of %d jobs\n", success, numOfJobs)
-	fmt.Printf("success rate %.2f%%\n", float64(success)/float64(numOfJobs)*100)
 }
@@ -0,0 +1,169 @@
+// Package nats implements client.API over NATS request/reply, for
+// deployments where BrowserBro workers subscribe to NATS subjects instead
+// of serving HTTP.
+//
+// The package does not depend on the NATS driver. A *nats.Conn is adapted
+// by implementing Requester with Conn.RequestWithContext, mapping
+// nats.ErrNoResponders to ErrNoResponders.
+//
+// Workers are expected to subscribe to the subjects below within a queue
+// group, so that each request is handled by exactly one worker:
+//
+//	<prefix>.plugins          list plugins
+//	<prefix>.plugins.run      run a plugin (client.JobRequest → client.JobResponse)
+//	<prefix>.files.get        download a file
+//	<prefix>.files.delete     delete a file
+//	<prefix>.health           health check
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+// ErrNoResponders is returned when no worker of the queue group
+// is subscribed to the requested subject.
+var ErrNoResponders = errors.New("no responders available")
+
+// Requester sends a request and waits for a single reply.
+type Requester interface {
+	Request(ctx context.Context, subject string, data []byte) ([]byte, error)
+}
+
+// Client is a client.API implementation over NATS.
+type Client struct {
+	conn    Requester
+	prefix  string
+	timeout time.Duration
+	nextID  atomic.Uint64
+}
+
+var _ client.API = (*Client)(nil)
+
+// New creates a Client sending requests under the subject prefix.
+// Each request is bounded by timeout, in addition to the deadline of
+// its context; zero means 30 seconds.
+func New(conn Requester, prefix string, timeout time.Duration) (*Client, error) {
+	if conn == nil {
+		return nil, errors.New("requester is required")
+	}
+	if prefix == "" {
+		prefix = "browserbro"
+	}
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &Client{
+		conn:    conn,
+		prefix:  prefix,
+		timeout: timeout,
+	}, nil
+}
+
+type fileRequest struct {
+	ID string `json:"id"`
+}
+
+type fileReply struct {
+	Data  []byte `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Plugins fetches a list of available plugins.
+func (c *Client) Plugins(ctx context.Context) ([]string, error) {
+	var resp client.PluginsResponse
+	if err := c.request(ctx, "plugins", nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch plugins: %w", err)
+	}
+	return resp.Plugins, nil
+}
+
+// RunPlugin runs a plugin with the given name and parameters.
+func (c *Client) RunPlugin(
+	ctx context.Context,
+	pluginName string,
+	params map[string]any,
+	opts ...client.RunOption,
+) (map[string]any, error) {
+	params, err := client.ApplyRunOptions(params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	req := client.JobRequest{
+		ID:     strconv.FormatUint(c.nextID.Add(1), 10),
+		Plugin: pluginName,
+		Params: params,
+	}
+	var resp client.JobResponse
+	if err := c.request(ctx, "plugins.run", req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to run plugin: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("plugin failed: %s", resp.Error)
+	}
+	return resp.Output, nil
+}
+
+// DownloadFile downloads a file with the given ID.
+func (c *Client) DownloadFile(ctx context.Context, fileID string) ([]byte, error) {
+	var resp fileReply
+	if err := c.request(ctx, "files.get", fileRequest{ID: fileID}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("failed to download file: %s", resp.Error)
+	}
+	return resp.Data, nil
+}
+
+// DeleteFile deletes a file with the given ID.
+func (c *Client) DeleteFile(ctx context.Context, fileID string) error {
+	var resp fileReply
+	if err := c.request(ctx, "files.delete", fileRequest{ID: fileID}, &resp); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("failed to delete file: %s", resp.Error)
+	}
+	return nil
+}
+
+// Healthcheck checks that at least one worker responds.
+func (c *Client) Healthcheck(ctx context.Context) error {
+	if err := c.request(ctx, "health", nil, nil); err != nil {
+		return fmt.Errorf("failed to perform health check: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) request(ctx context.Context, subject string, in, out any) error {
+	var data []byte
+	if in != nil {
+		var err error
+		if data, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("failed to JSON encode request: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	reply, err := c.conn.Request(ctx, c.prefix+"."+subject, data)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(reply, out); err != nil {
+		return fmt.Errorf("failed to decode reply: %w", err)
+	}
+	return nil
+}
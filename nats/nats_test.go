@@ -0,0 +1,107 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bazuker/browserbro-go-api/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWorker answers requests like a BrowserBro worker behind NATS.
+type fakeWorker struct {
+	subjects []string
+}
+
+func (w *fakeWorker) Request(ctx context.Context, subject string, data []byte) ([]byte, error) {
+	w.subjects = append(w.subjects, subject)
+	switch subject {
+	case "bb.plugins":
+		return []byte(`{"plugins": ["screenshot"]}`), nil
+	case "bb.plugins.run":
+		var req client.JobRequest
+		_ = json.Unmarshal(data, &req)
+		if req.Plugin == "broken" {
+			return json.Marshal(client.JobResponse{ID: req.ID, Error: "plugin crashed"})
+		}
+		return json.Marshal(client.JobResponse{ID: req.ID, Output: req.Params})
+	case "bb.files.get":
+		var req fileRequest
+		_ = json.Unmarshal(data, &req)
+		if req.ID != "f1" {
+			return []byte(`{"error": "not found"}`), nil
+		}
+		return json.Marshal(fileReply{Data: []byte("content")})
+	case "bb.files.delete":
+		return []byte(`{}`), nil
+	case "bb.health":
+		return nil, nil
+	case "bb.slow":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return nil, ErrNoResponders
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(nil, "", 0)
+	require.Error(t, err)
+
+	c, err := New(&fakeWorker{}, "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "browserbro", c.prefix)
+	assert.Equal(t, 30*time.Second, c.timeout)
+}
+
+func TestClient(t *testing.T) {
+	w := &fakeWorker{}
+	c, err := New(w, "bb", time.Second)
+	require.NoError(t, err)
+
+	plugins, err := c.Plugins(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"screenshot"}, plugins)
+
+	output, err := c.RunPlugin(context.Background(),
+		"screenshot",
+		map[string]any{"urls": []any{"https://a.com"}},
+		client.WithExtraHeaders(map[string][]string{"X-Cohort": {"a"}}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"https://a.com"}, output["urls"])
+	assert.Equal(t, map[string]any{"X-Cohort": "a"}, output["headers"])
+
+	_, err = c.RunPlugin(context.Background(), "broken", nil)
+	require.EqualError(t, err, "plugin failed: plugin crashed")
+
+	data, err := c.DownloadFile(context.Background(), "f1")
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(data))
+
+	_, err = c.DownloadFile(context.Background(), "f2")
+	require.EqualError(t, err, "failed to download file: not found")
+
+	require.NoError(t, c.DeleteFile(context.Background(), "f1"))
+	require.NoError(t, c.Healthcheck(context.Background()))
+}
+
+func TestClient_Errors(t *testing.T) {
+	t.Run("no responders", func(t *testing.T) {
+		c, err := New(&fakeWorker{}, "other", time.Second)
+		require.NoError(t, err)
+
+		err = c.Healthcheck(context.Background())
+		require.ErrorIs(t, err, ErrNoResponders)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		c, err := New(&fakeWorker{}, "bb", 10*time.Millisecond)
+		require.NoError(t, err)
+
+		err = c.request(context.Background(), "slow", nil, nil)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,121 @@
+// Package accessibility wraps the accessibility plugin, which runs an
+// axe-style accessibility audit on a rendered page.
+package accessibility
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bazuker/browserbro-go-api/client"
+	"github.com/bazuker/browserbro-go-api/plugins/internal/pluginio"
+)
+
+// Plugin is the name of the wrapped plugin.
+const Plugin = "accessibility"
+
+// Impact is the severity of a violation.
+type Impact string
+
+const (
+	Minor    Impact = "minor"
+	Moderate Impact = "moderate"
+	Serious  Impact = "serious"
+	Critical Impact = "critical"
+)
+
+// rank orders impacts from least to most severe; unknown impacts rank 0.
+func (i Impact) rank() int {
+	switch i {
+	case Minor:
+		return 1
+	case Moderate:
+		return 2
+	case Serious:
+		return 3
+	case Critical:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// Request audits URL.
+type Request struct {
+	URL string `json:"url"`
+	// Tags restricts the audit to rules with any of the tags, e.g.
+	// "wcag2a" or "best-practice"; empty runs all rules.
+	Tags []string `json:"tags,omitempty"`
+	// DisableRules skips the rules with the given IDs.
+	DisableRules []string `json:"disableRules,omitempty"`
+	// Include restricts the audit to the elements matching the CSS selector.
+	Include string `json:"include,omitempty"`
+}
+
+// Violation is a rule violated by one or more elements.
+type Violation struct {
+	// Rule is the ID of the rule, e.g. "color-contrast".
+	Rule        string `json:"rule"`
+	Impact      Impact `json:"impact"`
+	Description string `json:"description,omitempty"`
+	HelpURL     string `json:"helpUrl,omitempty"`
+	// Selectors are the CSS selectors of the violating elements.
+	Selectors []string `json:"selectors"`
+}
+
+// Result is the outcome of an audit.
+type Result struct {
+	URL        string      `json:"url"`
+	Violations []Violation `json:"violations"`
+	// Passes is the number of rules that passed.
+	Passes int `json:"passes,omitempty"`
+}
+
+// AtLeast returns the violations with an impact of at least min.
+func (r *Result) AtLeast(min Impact) []Violation {
+	var violations []Violation
+	for _, v := range r.Violations {
+		if v.Impact.rank() >= min.rank() {
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+// Err returns an error listing the violations with an impact of at least
+// min, or nil if there are none, for failing CI checks.
+func (r *Result) Err(min Impact) error {
+	violations := r.AtLeast(min)
+	if len(violations) == 0 {
+		return nil
+	}
+	lines := make([]string, len(violations))
+	for i, v := range violations {
+		lines[i] = fmt.Sprintf("%s (%s): %s", v.Rule, v.Impact, strings.Join(v.Selectors, ", "))
+	}
+	return fmt.Errorf("%d accessibility violations on %s:\n%s", len(violations), r.URL, strings.Join(lines, "\n"))
+}
+
+// Audit runs the plugin with req.
+func Audit(ctx context.Context, api client.API, req Request) (*Result, error) {
+	if req.URL == "" {
+		return nil, errors.New("URL is required")
+	}
+	params, err := pluginio.Params(req)
+	if err != nil {
+		return nil, err
+	}
+	output, err := api.RunPlugin(ctx, Plugin, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run accessibility audit: %w", err)
+	}
+	var result Result
+	if err := pluginio.Decode(output, Plugin, &result); err != nil {
+		return nil, err
+	}
+	if result.URL == "" {
+		result.URL = req.URL
+	}
+	return &result, nil
+}
@@ -0,0 +1,57 @@
+package accessibility
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+func TestAudit(t *testing.T) {
+	var params map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/plugins/accessibility", r.URL.Path)
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		_, _ = w.Write([]byte(`{"accessibility": {
+			"violations": [
+				{"rule": "color-contrast", "impact": "serious", "selectors": [".btn", "#footer a"]},
+				{"rule": "region", "impact": "moderate", "selectors": ["main"]},
+				{"rule": "image-alt", "impact": "critical", "helpUrl": "https://example.com/image-alt", "selectors": ["img.logo"]}
+			],
+			"passes": 40
+		}}`))
+	}))
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	result, err := Audit(context.Background(), c, Request{URL: "https://example.com", Tags: []string{"wcag2a"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"url": "https://example.com", "tags": []any{"wcag2a"}}, params)
+	assert.Equal(t, "https://example.com", result.URL)
+	assert.Equal(t, 40, result.Passes)
+	require.Len(t, result.Violations, 3)
+	assert.Equal(t, Violation{
+		Rule:      "color-contrast",
+		Impact:    Serious,
+		Selectors: []string{".btn", "#footer a"},
+	}, result.Violations[0])
+
+	assert.Len(t, result.AtLeast(Minor), 3)
+	assert.Len(t, result.AtLeast(Serious), 2)
+	assert.NoError(t, (&Result{}).Err(Minor))
+	assert.EqualError(t, result.Err(Critical),
+		"1 accessibility violations on https://example.com:\nimage-alt (critical): img.logo")
+}
+
+func TestAudit_Validation(t *testing.T) {
+	_, err := Audit(context.Background(), nil, Request{})
+	require.EqualError(t, err, "URL is required")
+}
@@ -0,0 +1,112 @@
+// Package feeds wraps the feeds plugin, which renders a page, discovers
+// the RSS and Atom feeds it links to, including links only added by
+// scripts, and parses their entries.
+package feeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bazuker/browserbro-go-api/client"
+	"github.com/bazuker/browserbro-go-api/plugins/internal/pluginio"
+)
+
+// Plugin is the name of the wrapped plugin.
+const Plugin = "feeds"
+
+// Format is a feed format.
+type Format string
+
+const (
+	RSS  Format = "rss"
+	Atom Format = "atom"
+)
+
+// Request discovers the feeds of URL.
+type Request struct {
+	URL string `json:"url"`
+	// MaxEntries caps the entries parsed per feed; zero uses the plugin
+	// default.
+	MaxEntries int `json:"maxEntries,omitempty"`
+}
+
+// Feed is a discovered feed.
+type Feed struct {
+	URL     string  `json:"url"`
+	Title   string  `json:"title,omitempty"`
+	Format  Format  `json:"format"`
+	Entries []Entry `json:"entries"`
+}
+
+// Entry is a feed entry. ID is the RSS guid or Atom id, if any.
+type Entry struct {
+	ID        string    `json:"id,omitempty"`
+	Title     string    `json:"title"`
+	Link      string    `json:"link"`
+	Author    string    `json:"author,omitempty"`
+	Summary   string    `json:"summary,omitempty"`
+	Published time.Time `json:"published"`
+	Updated   time.Time `json:"updated"`
+}
+
+// key identifies an entry across feeds.
+func (e Entry) key() string {
+	if e.ID != "" {
+		return e.ID
+	}
+	return e.Link
+}
+
+// Result is the outcome of a feeds run.
+type Result struct {
+	Feeds []Feed `json:"feeds"`
+}
+
+// Entries returns the entries of all feeds, newest first. Entries
+// published in several feeds, such as the RSS and Atom feed of a site,
+// are returned once.
+func (r *Result) Entries() []Entry {
+	var entries []Entry
+	seen := make(map[string]bool)
+	for _, f := range r.Feeds {
+		for _, e := range f.Entries {
+			if k := e.key(); k != "" {
+				if seen[k] {
+					continue
+				}
+				seen[k] = true
+			}
+			entries = append(entries, e)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Published.After(entries[j].Published)
+	})
+	return entries
+}
+
+// Discover runs the plugin with req.
+func Discover(ctx context.Context, api client.API, req Request) (*Result, error) {
+	if req.URL == "" {
+		return nil, errors.New("URL is required")
+	}
+	if req.MaxEntries < 0 {
+		return nil, fmt.Errorf("max entries %d must not be negative", req.MaxEntries)
+	}
+	params, err := pluginio.Params(req)
+	if err != nil {
+		return nil, err
+	}
+	output, err := api.RunPlugin(ctx, Plugin, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover feeds: %w", err)
+	}
+	var result Result
+	if err := pluginio.Decode(output, Plugin, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
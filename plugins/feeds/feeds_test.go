@@ -0,0 +1,58 @@
+package feeds
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+func TestDiscover(t *testing.T) {
+	var params map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/plugins/feeds", r.URL.Path)
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		_, _ = w.Write([]byte(`{"feeds": {"feeds": [
+			{"url": "https://example.com/rss.xml", "title": "Blog", "format": "rss", "entries": [
+				{"id": "p1", "title": "First", "link": "https://example.com/p1", "published": "2024-05-01T10:00:00Z"},
+				{"id": "p2", "title": "Second", "link": "https://example.com/p2", "published": "2024-05-03T10:00:00Z"}
+			]},
+			{"url": "https://example.com/atom.xml", "format": "atom", "entries": [
+				{"id": "p2", "title": "Second", "link": "https://example.com/p2", "published": "2024-05-03T10:00:00Z"},
+				{"title": "Note", "link": "https://example.com/n1", "published": "2024-05-02T10:00:00Z"}
+			]}
+		]}}`))
+	}))
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	result, err := Discover(context.Background(), c, Request{URL: "https://example.com", MaxEntries: 20})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"url": "https://example.com", "maxEntries": float64(20)}, params)
+	require.Len(t, result.Feeds, 2)
+	assert.Equal(t, RSS, result.Feeds[0].Format)
+	assert.Equal(t, "Blog", result.Feeds[0].Title)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), result.Feeds[0].Entries[0].Published)
+
+	var titles []string
+	for _, e := range result.Entries() {
+		titles = append(titles, e.Title)
+	}
+	assert.Equal(t, []string{"Second", "Note", "First"}, titles)
+}
+
+func TestDiscover_Validation(t *testing.T) {
+	_, err := Discover(context.Background(), nil, Request{})
+	require.EqualError(t, err, "URL is required")
+	_, err = Discover(context.Background(), nil, Request{URL: "https://example.com", MaxEntries: -1})
+	require.EqualError(t, err, "max entries -1 must not be negative")
+}
@@ -0,0 +1,82 @@
+// Package googlesearch wraps the googlesearch plugin, which returns the
+// results of a Google search.
+package googlesearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bazuker/browserbro-go-api/client"
+	"github.com/bazuker/browserbro-go-api/plugins/internal/pluginio"
+)
+
+// Plugin is the name of the wrapped plugin.
+const Plugin = "googlesearch"
+
+// Request searches Google for a query.
+type Request struct {
+	Query string `json:"query"`
+	// Limit caps the number of results; zero uses the plugin default.
+	Limit int `json:"limit,omitempty"`
+	// Locale is the language and region of the results, e.g. "en-US".
+	Locale     string `json:"locale,omitempty"`
+	SafeSearch bool   `json:"safeSearch,omitempty"`
+}
+
+// Validate reports whether the request is valid.
+func (r Request) Validate() error {
+	if r.Query == "" {
+		return errors.New("query is required")
+	}
+	if r.Limit < 0 {
+		return fmt.Errorf("limit %d must not be negative", r.Limit)
+	}
+	return nil
+}
+
+// SearchResult is a search result.
+type SearchResult struct {
+	Title   string
+	URL     string
+	Snippet string
+	// Rank is the 1-based position of the result on the results page.
+	Rank int
+}
+
+// Search runs the plugin with req and returns the results sorted by rank.
+// Results without a URL are skipped.
+func Search(ctx context.Context, api client.API, req Request) ([]SearchResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	params, err := pluginio.Params(req)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := api.RunPlugin(ctx, Plugin, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	found, err := pluginio.SearchResults(raw, Plugin)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, len(found))
+	for i, r := range found {
+		results[i] = SearchResult(r)
+	}
+	if req.Limit > 0 && len(results) > req.Limit {
+		results = results[:req.Limit]
+	}
+	return results, nil
+}
+
+// URLs returns the URLs of results, in order.
+func URLs(results []SearchResult) []string {
+	urls := make([]string, len(results))
+	for i, r := range results {
+		urls[i] = r.URL
+	}
+	return urls
+}
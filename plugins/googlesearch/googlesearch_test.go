@@ -0,0 +1,78 @@
+package googlesearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+// fakeServer serves the plugin with results in both field spellings and
+// stores the params of the last search in params.
+func fakeServer(t *testing.T, params *map[string]any) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/plugins/googlesearch", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(params)
+		_, _ = w.Write([]byte(`{"googlesearch": {"results": [
+			{"title": "Go", "url": "https://go.dev", "snippet": "The Go language"},
+			{"title": "No URL"},
+			{"title": "Blog", "link": "https://go.dev/blog", "description": "The Go blog", "rank": 3},
+			{"title": "Tour", "url": "https://go.dev/tour"}
+		]}}`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestSearch(t *testing.T) {
+	var params map[string]any
+	server := fakeServer(t, &params)
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	results, err := Search(context.Background(), c, Request{Query: "golang", Limit: 2, Locale: "en-US", SafeSearch: true})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"query": "golang", "limit": float64(2), "locale": "en-US", "safeSearch": true}, params)
+	assert.Equal(t, []SearchResult{
+		{Title: "Go", URL: "https://go.dev", Snippet: "The Go language", Rank: 1},
+		{Title: "Blog", URL: "https://go.dev/blog", Snippet: "The Go blog", Rank: 3},
+	}, results)
+	assert.Equal(t, []string{"https://go.dev", "https://go.dev/blog"}, URLs(results))
+
+	params = nil
+	results, err = Search(context.Background(), c, Request{Query: "golang"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"query": "golang"}, params)
+	require.Len(t, results, 3)
+	assert.Equal(t, 3, results[2].Rank)
+}
+
+func TestSearch_OutputMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"googlesearch": {"results": {"title": "Go"}}}`))
+	}))
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = Search(context.Background(), c, Request{Query: "golang"})
+	var outErr *client.OutputError
+	require.ErrorAs(t, err, &outErr)
+	assert.Equal(t, "results", outErr.Field)
+}
+
+func TestRequest_Validate(t *testing.T) {
+	assert.EqualError(t, Request{}.Validate(), "query is required")
+	assert.EqualError(t, Request{Query: "golang", Limit: -1}.Validate(), "limit -1 must not be negative")
+	assert.NoError(t, Request{Query: "golang", Limit: 10}.Validate())
+}
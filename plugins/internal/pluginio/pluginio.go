@@ -0,0 +1,29 @@
+// Package pluginio converts between the typed requests and results of the
+// plugin wrappers and the untyped params and outputs of the client.
+package pluginio
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+// Params returns the JSON object encoding of a request struct as plugin params.
+func Params(req any) (map[string]any, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode params: %w", err)
+	}
+	var params map[string]any
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, fmt.Errorf("failed to encode params: %w", err)
+	}
+	return params, nil
+}
+
+// Decode decodes the output section of plugin, output[plugin], into out.
+// Mismatches return a *client.OutputError.
+func Decode(output map[string]any, plugin string, out any) error {
+	return client.DecodeOutput(output, plugin, out)
+}
@@ -0,0 +1,28 @@
+package pluginio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParams(t *testing.T) {
+	params, err := Params(struct {
+		URL   string `json:"url"`
+		Limit int    `json:"limit,omitempty"`
+	}{URL: "https://example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"url": "https://example.com"}, params)
+}
+
+func TestDecode(t *testing.T) {
+	var out struct {
+		Score float64 `json:"score"`
+	}
+	require.NoError(t, Decode(map[string]any{"compare": map[string]any{"score": 0.5}}, "compare", &out))
+	assert.Equal(t, 0.5, out.Score)
+
+	require.EqualError(t, Decode(map[string]any{}, "compare", &out), `plugin output has no "compare" section`)
+	require.ErrorContains(t, Decode(map[string]any{"compare": "nope"}, "compare", &out), "failed to decode compare output")
+}
@@ -0,0 +1,64 @@
+package pluginio
+
+import "sort"
+
+// SearchResult is a result of a search plugin.
+type SearchResult struct {
+	Title   string
+	URL     string
+	Snippet string
+	Rank    int
+}
+
+// searchOutput is the output section of the bundled search plugins.
+// Depending on the plugin version, results carry their URL in "url" or
+// "link" and their snippet in "snippet" or "description".
+type searchOutput struct {
+	Results []struct {
+		Title       string `json:"title"`
+		URL         string `json:"url"`
+		Link        string `json:"link"`
+		Snippet     string `json:"snippet"`
+		Description string `json:"description"`
+		Rank        int    `json:"rank"`
+	} `json:"results"`
+}
+
+// SearchResults decodes the results of a search plugin from its output
+// section, output[plugin], sorted by rank. Results without a URL are
+// skipped; results without a rank are ranked by their position.
+func SearchResults(output map[string]any, plugin string) ([]SearchResult, error) {
+	var out searchOutput
+	if err := Decode(output, plugin, &out); err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, 0, len(out.Results))
+	for _, r := range out.Results {
+		res := SearchResult{
+			Title:   r.Title,
+			URL:     firstNonEmpty(r.URL, r.Link),
+			Snippet: firstNonEmpty(r.Snippet, r.Description),
+			Rank:    r.Rank,
+		}
+		if res.URL == "" {
+			continue
+		}
+		if res.Rank == 0 {
+			res.Rank = len(results) + 1
+		}
+		results = append(results, res)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rank < results[j].Rank
+	})
+	return results, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
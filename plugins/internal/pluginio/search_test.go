@@ -0,0 +1,30 @@
+package pluginio
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchResults(t *testing.T) {
+	var output map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{"googlesearch": {"results": [
+		{"title": "Tour", "url": "https://go.dev/tour", "rank": 3},
+		{"title": "No URL", "rank": 1},
+		{"title": "Blog", "link": "https://go.dev/blog", "description": "The Go blog", "rank": 2},
+		{"title": "Go", "url": "https://go.dev", "snippet": "The Go language", "rank": 1}
+	]}}`), &output))
+
+	results, err := SearchResults(output, "googlesearch")
+	require.NoError(t, err)
+	assert.Equal(t, []SearchResult{
+		{Title: "Go", URL: "https://go.dev", Snippet: "The Go language", Rank: 1},
+		{Title: "Blog", URL: "https://go.dev/blog", Snippet: "The Go blog", Rank: 2},
+		{Title: "Tour", URL: "https://go.dev/tour", Rank: 3},
+	}, results)
+
+	_, err = SearchResults(map[string]any{}, "googlesearch")
+	require.EqualError(t, err, `plugin output has no "googlesearch" section`)
+}
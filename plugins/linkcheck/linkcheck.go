@@ -0,0 +1,139 @@
+// Package linkcheck wraps the linkcheck plugin, which crawls a page or
+// site and checks every link it finds. Results are streamed link by link,
+// so sites with many thousands of links are checked in constant memory.
+package linkcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+// Plugin is the name of the wrapped plugin.
+const Plugin = "linkcheck"
+
+// Streamer runs streaming plugins; *client.Client implements it.
+type Streamer interface {
+	RunPluginStream(
+		ctx context.Context,
+		pluginName string,
+		params map[string]any,
+		opts ...client.RunOption,
+	) (<-chan client.StreamItem, error)
+}
+
+// Request checks the links of URL and, up to MaxDepth, of the pages it
+// links to.
+type Request struct {
+	URL string `json:"url"`
+	// MaxDepth is the number of link hops crawled from URL; zero checks
+	// the links of URL only.
+	MaxDepth int `json:"maxDepth,omitempty"`
+	// MaxPages caps the number of crawled pages; zero uses the plugin
+	// default.
+	MaxPages int `json:"maxPages,omitempty"`
+	// External also checks links to other hosts, without crawling them.
+	External bool `json:"external,omitempty"`
+}
+
+// Link is the check result of a link.
+type Link struct {
+	URL string `json:"url"`
+	// Status is the HTTP status of the link; zero if the request failed.
+	Status int `json:"status,omitempty"`
+	// Location is the redirect target of a redirected link.
+	Location string `json:"location,omitempty"`
+	// Error describes why the request failed, e.g. a DNS error.
+	Error string `json:"error,omitempty"`
+	// Referrers are the pages linking to URL.
+	Referrers []string `json:"referrers,omitempty"`
+}
+
+// Broken reports whether the link failed or returned an error status.
+func (l Link) Broken() bool {
+	return l.Error != "" || l.Status >= 400 || l.Status == 0
+}
+
+// Redirected reports whether the link returned a redirect.
+func (l Link) Redirected() bool {
+	return l.Status >= 300 && l.Status < 400
+}
+
+// Report lists the broken and redirected links of a check.
+type Report struct {
+	// Checked is the number of checked links.
+	Checked    int
+	Broken     []Link
+	Redirected []Link
+}
+
+// Check runs the plugin with req and returns a report of the broken and
+// redirected links. If fn is not nil, it is called with every link as
+// soon as it is checked; an error returned by fn stops the check.
+func Check(ctx context.Context, s Streamer, req Request, fn func(Link) error) (*Report, error) {
+	if req.URL == "" {
+		return nil, errors.New("URL is required")
+	}
+	if req.MaxDepth < 0 || req.MaxPages < 0 {
+		return nil, errors.New("max depth and max pages must not be negative")
+	}
+	params := map[string]any{"url": req.URL}
+	if req.MaxDepth > 0 {
+		params["maxDepth"] = req.MaxDepth
+	}
+	if req.MaxPages > 0 {
+		params["maxPages"] = req.MaxPages
+	}
+	if req.External {
+		params["external"] = true
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	items, err := s.RunPluginStream(ctx, Plugin, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check links: %w", err)
+	}
+
+	report := &Report{}
+	for item := range items {
+		if item.Err != nil {
+			return report, fmt.Errorf("failed to check links: %w", item.Err)
+		}
+		link, err := decodeLink(item.Output)
+		if err != nil {
+			return report, err
+		}
+		report.Checked++
+		switch {
+		case link.Redirected():
+			report.Redirected = append(report.Redirected, link)
+		case link.Broken():
+			report.Broken = append(report.Broken, link)
+		}
+		if fn != nil {
+			if err := fn(link); err != nil {
+				return report, err
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+func decodeLink(output map[string]any) (Link, error) {
+	var link Link
+	data, err := json.Marshal(output)
+	if err == nil {
+		err = json.Unmarshal(data, &link)
+	}
+	if err != nil {
+		return link, fmt.Errorf("failed to decode link: %w", err)
+	}
+	return link, nil
+}
@@ -0,0 +1,86 @@
+package linkcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+func streamServer(t *testing.T, params *map[string]any) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/plugins/linkcheck/stream", r.URL.Path)
+		_ = json.NewDecoder(r.Body).Decode(params)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"url": "https://example.com/about", "status": 200, "referrers": ["https://example.com"]}
+{"url": "https://example.com/old", "status": 301, "location": "https://example.com/new", "referrers": ["https://example.com"]}
+{"url": "https://example.com/gone", "status": 404, "referrers": ["https://example.com", "https://example.com/about"]}
+{"url": "https://unreachable.example", "error": "no such host"}
+`))
+	}))
+}
+
+func TestCheck(t *testing.T) {
+	var params map[string]any
+	server := streamServer(t, &params)
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	var seen []string
+	report, err := Check(context.Background(), c, Request{URL: "https://example.com", MaxDepth: 2, External: true},
+		func(l Link) error {
+			seen = append(seen, l.URL)
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"url": "https://example.com", "maxDepth": float64(2), "external": true}, params)
+	assert.Len(t, seen, 4)
+	assert.Equal(t, 4, report.Checked)
+	assert.Equal(t, []Link{{
+		URL:       "https://example.com/old",
+		Status:    301,
+		Location:  "https://example.com/new",
+		Referrers: []string{"https://example.com"},
+	}}, report.Redirected)
+	require.Len(t, report.Broken, 2)
+	assert.Equal(t, 404, report.Broken[0].Status)
+	assert.Equal(t, []string{"https://example.com", "https://example.com/about"}, report.Broken[0].Referrers)
+	assert.Equal(t, "no such host", report.Broken[1].Error)
+}
+
+func TestCheck_Stop(t *testing.T) {
+	server := streamServer(t, new(map[string]any))
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	stop := errors.New("stop")
+	report, err := Check(context.Background(), c, Request{URL: "https://example.com"}, func(l Link) error {
+		if l.Redirected() {
+			return stop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, stop)
+	assert.Equal(t, 2, report.Checked)
+}
+
+func TestLink(t *testing.T) {
+	assert.False(t, Link{Status: 200}.Broken())
+	assert.True(t, Link{Status: 500}.Broken())
+	assert.True(t, Link{Error: "timeout"}.Broken())
+	assert.True(t, Link{Status: 308}.Redirected())
+	assert.False(t, Link{Status: 200}.Redirected())
+}
@@ -0,0 +1,172 @@
+// Package pdf wraps the pdf plugin, which prints web pages to PDF.
+package pdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bazuker/browserbro-go-api/client"
+	"github.com/bazuker/browserbro-go-api/plugins/internal/pluginio"
+)
+
+// Plugin is the name of the wrapped plugin.
+const Plugin = "pdf"
+
+// PageSize is a paper size preset.
+type PageSize string
+
+const (
+	A3      PageSize = "A3"
+	A4      PageSize = "A4"
+	A5      PageSize = "A5"
+	Letter  PageSize = "Letter"
+	Legal   PageSize = "Legal"
+	Tabloid PageSize = "Tabloid"
+)
+
+// pageSizes are the paper sizes of the presets, in inches.
+var pageSizes = map[PageSize][2]float64{
+	A3:      {11.69, 16.54},
+	A4:      {8.27, 11.69},
+	A5:      {5.83, 8.27},
+	Letter:  {8.5, 11},
+	Legal:   {8.5, 14},
+	Tabloid: {11, 17},
+}
+
+// Margins are page margins, in inches.
+type Margins struct {
+	Top    float64 `json:"top"`
+	Right  float64 `json:"right"`
+	Bottom float64 `json:"bottom"`
+	Left   float64 `json:"left"`
+}
+
+// Request prints URL to PDF.
+type Request struct {
+	URL string `json:"url"`
+	// PageSize is a paper size preset; empty uses Letter unless
+	// PaperWidth and PaperHeight are set.
+	PageSize PageSize `json:"pageSize,omitempty"`
+	// PaperWidth and PaperHeight set a custom paper size, in inches.
+	PaperWidth  float64  `json:"paperWidth,omitempty"`
+	PaperHeight float64  `json:"paperHeight,omitempty"`
+	Landscape   bool     `json:"landscape,omitempty"`
+	Margins     *Margins `json:"margins,omitempty"`
+	// HeaderTemplate and FooterTemplate are HTML templates printed on
+	// every page. They may use elements with the classes date, title,
+	// url, pageNumber and totalPages to insert those values.
+	HeaderTemplate  string `json:"headerTemplate,omitempty"`
+	FooterTemplate  string `json:"footerTemplate,omitempty"`
+	PrintBackground bool   `json:"printBackground,omitempty"`
+	// PageRanges selects the pages to print, e.g. "1-5, 8, 11-13";
+	// empty prints all pages.
+	PageRanges string `json:"pageRanges,omitempty"`
+	// Scale is the rendering scale from 0.1 to 2; zero uses 1.
+	Scale float64 `json:"scale,omitempty"`
+}
+
+// Validate reports whether the request is valid.
+func (r Request) Validate() error {
+	if r.URL == "" {
+		return errors.New("URL is required")
+	}
+	width, height, err := r.paperSize()
+	if err != nil {
+		return err
+	}
+	if r.Landscape {
+		width, height = height, width
+	}
+	if m := r.Margins; m != nil {
+		if m.Top < 0 || m.Right < 0 || m.Bottom < 0 || m.Left < 0 {
+			return errors.New("margins must not be negative")
+		}
+		if m.Left+m.Right >= width || m.Top+m.Bottom >= height {
+			return errors.New("margins leave no printable area")
+		}
+	}
+	if err := validatePageRanges(r.PageRanges); err != nil {
+		return err
+	}
+	if r.Scale != 0 && (r.Scale < 0.1 || r.Scale > 2) {
+		return fmt.Errorf("scale %v is not between 0.1 and 2", r.Scale)
+	}
+	return nil
+}
+
+// paperSize returns the portrait paper size in inches.
+func (r Request) paperSize() (width, height float64, err error) {
+	custom := r.PaperWidth != 0 || r.PaperHeight != 0
+	switch {
+	case custom && r.PageSize != "":
+		return 0, 0, errors.New("page size and paper width and height are mutually exclusive")
+	case custom:
+		if r.PaperWidth <= 0 || r.PaperHeight <= 0 {
+			return 0, 0, errors.New("paper width and height must both be positive")
+		}
+		return r.PaperWidth, r.PaperHeight, nil
+	case r.PageSize == "":
+		size := pageSizes[Letter]
+		return size[0], size[1], nil
+	}
+	size, ok := pageSizes[r.PageSize]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown page size %q", r.PageSize)
+	}
+	return size[0], size[1], nil
+}
+
+// validatePageRanges validates comma-separated pages and ranges of pages,
+// numbered from 1.
+func validatePageRanges(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil || first < 1 {
+			return fmt.Errorf("invalid page range %q", part)
+		}
+		if !isRange {
+			continue
+		}
+		last, err := strconv.Atoi(strings.TrimSpace(to))
+		if err != nil || last < first {
+			return fmt.Errorf("invalid page range %q", part)
+		}
+	}
+	return nil
+}
+
+// Result is the outcome of a pdf run.
+type Result struct {
+	FileID string `json:"fileId"`
+	// Pages is the number of printed pages.
+	Pages int `json:"pages,omitempty"`
+}
+
+// Print runs the plugin with req.
+func Print(ctx context.Context, api client.API, req Request) (*Result, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	params, err := pluginio.Params(req)
+	if err != nil {
+		return nil, err
+	}
+	output, err := api.RunPlugin(ctx, Plugin, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to print PDF: %w", err)
+	}
+	var result Result
+	if err := pluginio.Decode(output, Plugin, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
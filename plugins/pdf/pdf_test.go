@@ -0,0 +1,73 @@
+package pdf
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+func TestPrint(t *testing.T) {
+	var params map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/plugins/pdf", r.URL.Path)
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		_, _ = w.Write([]byte(`{"pdf": {"fileId": "page.pdf", "pages": 3}}`))
+	}))
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	result, err := Print(context.Background(), c, Request{
+		URL:             "https://example.com",
+		PageSize:        A4,
+		Landscape:       true,
+		Margins:         &Margins{Top: 0.5, Right: 0.5, Bottom: 0.5, Left: 0.5},
+		FooterTemplate:  `<span class="pageNumber"></span>`,
+		PrintBackground: true,
+		PageRanges:      "1-3",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Result{FileID: "page.pdf", Pages: 3}, result)
+	assert.Equal(t, map[string]any{
+		"url":             "https://example.com",
+		"pageSize":        "A4",
+		"landscape":       true,
+		"margins":         map[string]any{"top": 0.5, "right": 0.5, "bottom": 0.5, "left": 0.5},
+		"footerTemplate":  `<span class="pageNumber"></span>`,
+		"printBackground": true,
+		"pageRanges":      "1-3",
+	}, params)
+}
+
+func TestRequest_Validate(t *testing.T) {
+	url := "https://example.com"
+	tests := []struct {
+		req  Request
+		want string
+	}{
+		{Request{}, "URL is required"},
+		{Request{URL: url, PageSize: "B5"}, `unknown page size "B5"`},
+		{Request{URL: url, PageSize: A4, PaperWidth: 8}, "page size and paper width and height are mutually exclusive"},
+		{Request{URL: url, PaperWidth: 8}, "paper width and height must both be positive"},
+		{Request{URL: url, Margins: &Margins{Top: -1}}, "margins must not be negative"},
+		{Request{URL: url, Margins: &Margins{Left: 4.5, Right: 4}}, "margins leave no printable area"},
+		{Request{URL: url, PageSize: A5, Landscape: true, Margins: &Margins{Top: 3, Bottom: 3}}, "margins leave no printable area"},
+		{Request{URL: url, PageRanges: "0-2"}, `invalid page range "0-2"`},
+		{Request{URL: url, PageRanges: "1-3, 5-4"}, `invalid page range "5-4"`},
+		{Request{URL: url, PageRanges: "x"}, `invalid page range "x"`},
+		{Request{URL: url, Scale: 3}, "scale 3 is not between 0.1 and 2"},
+	}
+	for _, tt := range tests {
+		assert.EqualError(t, tt.req.Validate(), tt.want)
+	}
+	assert.NoError(t, Request{URL: url}.Validate())
+	assert.NoError(t, Request{URL: url, PaperWidth: 4, PaperHeight: 6, Margins: &Margins{Top: 1}, PageRanges: "1, 3-4", Scale: 0.5}.Validate())
+}
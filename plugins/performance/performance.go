@@ -0,0 +1,156 @@
+// Package performance wraps the performance plugin, which runs a
+// Lighthouse-style performance audit on a page, and checks the measured
+// metrics against performance budgets.
+package performance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bazuker/browserbro-go-api/client"
+	"github.com/bazuker/browserbro-go-api/plugins/internal/pluginio"
+)
+
+// Plugin is the name of the wrapped plugin.
+const Plugin = "performance"
+
+// Device is the emulated device class.
+type Device string
+
+const (
+	Desktop Device = "desktop"
+	Mobile  Device = "mobile"
+)
+
+// Request audits URL.
+type Request struct {
+	URL string `json:"url"`
+	// Device is the emulated device; empty uses the plugin default.
+	Device Device `json:"device,omitempty"`
+	// Runs is the number of page loads the metrics are the median of;
+	// zero uses the plugin default.
+	Runs int `json:"runs,omitempty"`
+}
+
+// Metrics are the measured performance metrics of a page.
+type Metrics struct {
+	// LCP is the Largest Contentful Paint.
+	LCP time.Duration
+	// CLS is the Cumulative Layout Shift score.
+	CLS float64
+	// TTI is the Time to Interactive.
+	TTI time.Duration
+	// TotalBytes is the total transfer size of the page and its resources.
+	TotalBytes int64
+}
+
+// metricsJSON is the wire form of Metrics, with durations in milliseconds.
+type metricsJSON struct {
+	LCPMs      float64 `json:"lcpMs"`
+	CLS        float64 `json:"cls"`
+	TTIMs      float64 `json:"ttiMs"`
+	TotalBytes int64   `json:"totalBytes"`
+}
+
+func (m *Metrics) UnmarshalJSON(data []byte) error {
+	var raw metricsJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*m = Metrics{
+		LCP:        time.Duration(raw.LCPMs * float64(time.Millisecond)),
+		CLS:        raw.CLS,
+		TTI:        time.Duration(raw.TTIMs * float64(time.Millisecond)),
+		TotalBytes: raw.TotalBytes,
+	}
+	return nil
+}
+
+func (m Metrics) MarshalJSON() ([]byte, error) {
+	return json.Marshal(metricsJSON{
+		LCPMs:      float64(m.LCP) / float64(time.Millisecond),
+		CLS:        m.CLS,
+		TTIMs:      float64(m.TTI) / float64(time.Millisecond),
+		TotalBytes: m.TotalBytes,
+	})
+}
+
+// Budget is a performance budget. Zero limits are not checked.
+type Budget struct {
+	MaxLCP        time.Duration
+	MaxCLS        float64
+	MaxTTI        time.Duration
+	MaxTotalBytes int64
+}
+
+// Result is the outcome of an audit.
+type Result struct {
+	URL     string  `json:"url"`
+	Metrics Metrics `json:"metrics"`
+	// Score is the overall performance score from 0 to 100.
+	Score float64 `json:"score,omitempty"`
+}
+
+// Meets reports whether the metrics are within budget.
+func (r *Result) Meets(budget Budget) bool {
+	return len(r.Exceeded(budget)) == 0
+}
+
+// Exceeded describes the metrics over budget, e.g. "LCP 3.1s > 2.5s".
+func (r *Result) Exceeded(budget Budget) []string {
+	var exceeded []string
+	m := r.Metrics
+	if budget.MaxLCP > 0 && m.LCP > budget.MaxLCP {
+		exceeded = append(exceeded, fmt.Sprintf("LCP %s > %s", m.LCP, budget.MaxLCP))
+	}
+	if budget.MaxCLS > 0 && m.CLS > budget.MaxCLS {
+		exceeded = append(exceeded, fmt.Sprintf("CLS %g > %g", m.CLS, budget.MaxCLS))
+	}
+	if budget.MaxTTI > 0 && m.TTI > budget.MaxTTI {
+		exceeded = append(exceeded, fmt.Sprintf("TTI %s > %s", m.TTI, budget.MaxTTI))
+	}
+	if budget.MaxTotalBytes > 0 && m.TotalBytes > budget.MaxTotalBytes {
+		exceeded = append(exceeded, fmt.Sprintf("total weight %d bytes > %d bytes", m.TotalBytes, budget.MaxTotalBytes))
+	}
+	return exceeded
+}
+
+// Err returns an error describing the metrics over budget, or nil if the
+// budget is met, for failing pipelines.
+func (r *Result) Err(budget Budget) error {
+	exceeded := r.Exceeded(budget)
+	if len(exceeded) == 0 {
+		return nil
+	}
+	return fmt.Errorf("performance budget exceeded on %s: %s", r.URL, strings.Join(exceeded, ", "))
+}
+
+// Audit runs the plugin with req.
+func Audit(ctx context.Context, api client.API, req Request) (*Result, error) {
+	if req.URL == "" {
+		return nil, errors.New("URL is required")
+	}
+	if req.Runs < 0 {
+		return nil, fmt.Errorf("runs %d must not be negative", req.Runs)
+	}
+	params, err := pluginio.Params(req)
+	if err != nil {
+		return nil, err
+	}
+	output, err := api.RunPlugin(ctx, Plugin, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run performance audit: %w", err)
+	}
+	var result Result
+	if err := pluginio.Decode(output, Plugin, &result); err != nil {
+		return nil, err
+	}
+	if result.URL == "" {
+		result.URL = req.URL
+	}
+	return &result, nil
+}
@@ -0,0 +1,73 @@
+package performance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+func TestAudit(t *testing.T) {
+	var params map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/plugins/performance", r.URL.Path)
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		_, _ = w.Write([]byte(`{"performance": {
+			"metrics": {"lcpMs": 3100, "cls": 0.02, "ttiMs": 4200.5, "totalBytes": 1800000},
+			"score": 71
+		}}`))
+	}))
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	result, err := Audit(context.Background(), c, Request{URL: "https://example.com", Device: Mobile})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"url": "https://example.com", "device": "mobile"}, params)
+	assert.Equal(t, &Result{
+		URL: "https://example.com",
+		Metrics: Metrics{
+			LCP:        3100 * time.Millisecond,
+			CLS:        0.02,
+			TTI:        4200500 * time.Microsecond,
+			TotalBytes: 1800000,
+		},
+		Score: 71,
+	}, result)
+}
+
+func TestResult_Meets(t *testing.T) {
+	result := &Result{
+		URL:     "https://example.com",
+		Metrics: Metrics{LCP: 3100 * time.Millisecond, CLS: 0.2, TTI: 4 * time.Second, TotalBytes: 2 << 20},
+	}
+
+	assert.True(t, result.Meets(Budget{}))
+	assert.True(t, result.Meets(Budget{MaxLCP: 4 * time.Second, MaxTotalBytes: 3 << 20}))
+	assert.NoError(t, result.Err(Budget{MaxTTI: 5 * time.Second}))
+
+	budget := Budget{MaxLCP: 2500 * time.Millisecond, MaxCLS: 0.1, MaxTTI: 5 * time.Second, MaxTotalBytes: 1 << 20}
+	assert.False(t, result.Meets(budget))
+	assert.Equal(t, []string{"LCP 3.1s > 2.5s", "CLS 0.2 > 0.1", "total weight 2097152 bytes > 1048576 bytes"},
+		result.Exceeded(budget))
+	assert.EqualError(t, result.Err(Budget{MaxCLS: 0.1}), "performance budget exceeded on https://example.com: CLS 0.2 > 0.1")
+}
+
+func TestMetrics_JSON(t *testing.T) {
+	m := Metrics{LCP: 1500 * time.Millisecond, CLS: 0.05, TTI: 2 * time.Second, TotalBytes: 42}
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"lcpMs": 1500, "cls": 0.05, "ttiMs": 2000, "totalBytes": 42}`, string(data))
+
+	var decoded Metrics
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, m, decoded)
+}
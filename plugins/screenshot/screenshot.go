@@ -0,0 +1,231 @@
+// Package screenshot wraps the screenshot plugin, which captures
+// screenshots of web pages.
+package screenshot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"strings"
+
+	"github.com/bazuker/browserbro-go-api/client"
+	"github.com/bazuker/browserbro-go-api/plugins/internal/pluginio"
+)
+
+// Plugin is the name of the wrapped plugin.
+const Plugin = "screenshot"
+
+// Request captures screenshots of URLs.
+type Request struct {
+	URLs []string `json:"urls"`
+	// FullPage captures the full scrollable page instead of the viewport.
+	FullPage bool `json:"fullPage,omitempty"`
+	// Viewport is the size of the browser viewport; nil uses the plugin
+	// default.
+	Viewport *Viewport `json:"viewport,omitempty"`
+	// Format is the image format; empty uses the plugin default, PNG.
+	Format Format `json:"format,omitempty"`
+	// Quality is the compression quality of lossy formats from 1 to 100;
+	// zero uses the plugin default. It is invalid with PNG.
+	Quality int `json:"quality,omitempty"`
+	// Scale is the device scale factor, e.g. 2 for high-DPI captures;
+	// zero uses 1.
+	Scale float64 `json:"scale,omitempty"`
+	// Selectors captures the elements matching the CSS selectors instead
+	// of the viewport, one screenshot per element; see Result.Elements.
+	Selectors []string `json:"selectors,omitempty"`
+	// Clip captures a region of the page instead of the viewport.
+	Clip *Clip `json:"clip,omitempty"`
+	// Thumbnail requests an additional downscaled copy of every screenshot.
+	Thumbnail *Thumbnail `json:"thumbnail,omitempty"`
+}
+
+// Viewport is the size of a browser viewport, in CSS pixels.
+type Viewport struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Clip is a region of a page, in CSS pixels from its top left corner.
+type Clip struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Format is an image format.
+type Format string
+
+const (
+	PNG  Format = "png"
+	JPEG Format = "jpeg"
+	WebP Format = "webp"
+	AVIF Format = "avif"
+)
+
+// MaxScale is the largest supported device scale factor.
+const MaxScale = 4
+
+// lossy reports whether the format supports a quality setting.
+func (f Format) lossy() bool {
+	return f == JPEG || f == WebP || f == AVIF
+}
+
+// Thumbnail is the size and quality of thumbnails. If only one of Width and
+// Height is set, the other is derived from the aspect ratio.
+type Thumbnail struct {
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// Quality is the JPEG quality from 1 to 100; zero uses the plugin default.
+	Quality int `json:"quality,omitempty"`
+}
+
+// Validate reports whether the request is valid.
+func (r Request) Validate() error {
+	if len(r.URLs) == 0 {
+		return errors.New("at least one URL is required")
+	}
+	switch r.Format {
+	case "", PNG, JPEG, WebP, AVIF:
+	default:
+		return fmt.Errorf("unsupported format %q", r.Format)
+	}
+	if r.Quality != 0 {
+		if !r.Format.lossy() {
+			return fmt.Errorf("quality is not supported with format %s", r.format())
+		}
+		if r.Quality < 1 || r.Quality > 100 {
+			return fmt.Errorf("quality %d is not between 1 and 100", r.Quality)
+		}
+	}
+	if r.Scale < 0 || r.Scale > MaxScale {
+		return fmt.Errorf("scale %v is not between 0 and %d", r.Scale, MaxScale)
+	}
+	if len(r.Selectors) > 0 && r.Clip != nil {
+		return errors.New("selectors and clip are mutually exclusive")
+	}
+	if r.FullPage && (len(r.Selectors) > 0 || r.Clip != nil) {
+		return errors.New("full page is mutually exclusive with selectors and clip")
+	}
+	if v := r.Viewport; v != nil && (v.Width <= 0 || v.Height <= 0) {
+		return errors.New("viewport width and height must be positive")
+	}
+	for i, sel := range r.Selectors {
+		if strings.TrimSpace(sel) == "" {
+			return fmt.Errorf("selector %d is empty", i)
+		}
+	}
+	if c := r.Clip; c != nil {
+		if c.X < 0 || c.Y < 0 {
+			return errors.New("clip origin must not be negative")
+		}
+		if c.Width <= 0 || c.Height <= 0 {
+			return errors.New("clip width and height must be positive")
+		}
+	}
+	if t := r.Thumbnail; t != nil {
+		if t.Width < 0 || t.Height < 0 || t.Width == 0 && t.Height == 0 {
+			return errors.New("thumbnail width or height must be positive")
+		}
+		if t.Quality < 0 || t.Quality > 100 {
+			return fmt.Errorf("thumbnail quality %d is not between 1 and 100", t.Quality)
+		}
+	}
+	return nil
+}
+
+func (r Request) format() Format {
+	if r.Format == "" {
+		return PNG
+	}
+	return r.Format
+}
+
+// Result is the outcome of a screenshot run.
+type Result struct {
+	// FileIDs are the file IDs of the screenshots, in URL order.
+	FileIDs []string `json:"fileIds"`
+	// ThumbnailFileIDs are the file IDs of the thumbnails, in URL order,
+	// if thumbnails were requested.
+	ThumbnailFileIDs []string `json:"thumbnailFileIds,omitempty"`
+	// Elements are the element screenshots, if selectors were requested.
+	Elements []Element `json:"elements,omitempty"`
+}
+
+// Element is the screenshot of an element matching a selector.
+type Element struct {
+	URL      string `json:"url"`
+	Selector string `json:"selector"`
+	// Index is the position of the element among the matches of the
+	// selector on the page.
+	Index  int    `json:"index"`
+	FileID string `json:"fileId"`
+}
+
+// ElementFileIDs returns the file IDs of the screenshots of the elements
+// matching selector, in URL and match order.
+func (r *Result) ElementFileIDs(selector string) []string {
+	var ids []string
+	for _, e := range r.Elements {
+		if e.Selector == selector {
+			ids = append(ids, e.FileID)
+		}
+	}
+	return ids
+}
+
+// Download downloads the screenshots, in URL order.
+func (r *Result) Download(ctx context.Context, api client.API) ([][]byte, error) {
+	images := make([][]byte, len(r.FileIDs))
+	for i, id := range r.FileIDs {
+		data, err := api.DownloadFile(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download screenshot %s: %w", id, err)
+		}
+		images[i] = data
+	}
+	return images, nil
+}
+
+// Images downloads and decodes the screenshots, in URL order.
+// Only PNG and JPEG screenshots can be decoded.
+func (r *Result) Images(ctx context.Context, api client.API) ([]image.Image, error) {
+	data, err := r.Download(ctx, api)
+	if err != nil {
+		return nil, err
+	}
+	images := make([]image.Image, len(data))
+	for i, d := range data {
+		img, _, err := image.Decode(bytes.NewReader(d))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode screenshot %s: %w", r.FileIDs[i], err)
+		}
+		images[i] = img
+	}
+	return images, nil
+}
+
+// Take runs the plugin with req.
+func Take(ctx context.Context, api client.API, req Request) (*Result, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	params, err := pluginio.Params(req)
+	if err != nil {
+		return nil, err
+	}
+	output, err := api.RunPlugin(ctx, Plugin, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take screenshots: %w", err)
+	}
+	var result Result
+	if err := pluginio.Decode(output, Plugin, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,214 @@
+package screenshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	imagepng "image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+// fakeServer serves the screenshot plugin, returning one screenshot per
+// URL and a thumbnail per URL when requested. It stores the params of
+// the last run in params.
+func fakeServer(t *testing.T, params *map[string]any) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/plugins/screenshot", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(params)
+		urls, _ := (*params)["urls"].([]any)
+		out := map[string]any{}
+		var ids, thumbs []string
+		for i := range urls {
+			ids = append(ids, "shot"+string(rune('1'+i))+".png")
+			thumbs = append(thumbs, "thumb"+string(rune('1'+i))+".jpg")
+		}
+		out["fileIds"] = ids
+		if _, ok := (*params)["thumbnail"]; ok {
+			out["thumbnailFileIds"] = thumbs
+		}
+		if selectors, ok := (*params)["selectors"].([]any); ok {
+			var elements []map[string]any
+			for _, u := range urls {
+				for _, sel := range selectors {
+					for i := 0; i < 2; i++ {
+						elements = append(elements, map[string]any{
+							"url":      u,
+							"selector": sel,
+							"index":    i,
+							"fileId":   sel.(string)[1:] + string(rune('1'+i)) + ".png",
+						})
+					}
+				}
+			}
+			out["elements"] = elements
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{Plugin: out})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestTake(t *testing.T) {
+	var params map[string]any
+	server := fakeServer(t, &params)
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	result, err := Take(context.Background(), c, Request{URLs: []string{"https://a.example", "https://b.example"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"urls": []any{"https://a.example", "https://b.example"}}, params)
+	assert.Equal(t, &Result{FileIDs: []string{"shot1.png", "shot2.png"}}, result)
+}
+
+func TestTake_Format(t *testing.T) {
+	var params map[string]any
+	server := fakeServer(t, &params)
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = Take(context.Background(), c, Request{URLs: []string{"https://a.example"}, Format: WebP, Quality: 75, Scale: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "webp", params["format"])
+	assert.Equal(t, float64(75), params["quality"])
+	assert.Equal(t, float64(2), params["scale"])
+
+	// Invalid combinations never reach the server.
+	params = nil
+	_, err = Take(context.Background(), c, Request{URLs: []string{"https://a.example"}, Format: PNG, Quality: 75})
+	require.Error(t, err)
+	assert.Nil(t, params)
+}
+
+func TestTake_Thumbnail(t *testing.T) {
+	var params map[string]any
+	server := fakeServer(t, &params)
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	result, err := Take(context.Background(), c, Request{
+		URLs:      []string{"https://a.example"},
+		Thumbnail: &Thumbnail{Width: 320, Quality: 70},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"width": float64(320), "quality": float64(70)}, params["thumbnail"])
+	assert.Equal(t, []string{"shot1.png"}, result.FileIDs)
+	assert.Equal(t, []string{"thumb1.jpg"}, result.ThumbnailFileIDs)
+}
+
+func TestTake_Selectors(t *testing.T) {
+	var params map[string]any
+	server := fakeServer(t, &params)
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	result, err := Take(context.Background(), c, Request{URLs: []string{"https://a.example"}, Selectors: []string{"#header", ".card"}})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"#header", ".card"}, params["selectors"])
+	assert.Len(t, result.Elements, 4)
+	assert.Equal(t, Element{URL: "https://a.example", Selector: ".card", Index: 1, FileID: "card2.png"}, result.Elements[3])
+	assert.Equal(t, []string{"header1.png", "header2.png"}, result.ElementFileIDs("#header"))
+	assert.Empty(t, result.ElementFileIDs("#missing"))
+
+	_, err = Take(context.Background(), c, Request{URLs: []string{"https://a.example"}, Clip: &Clip{X: 10, Y: 20, Width: 300, Height: 200}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"x": float64(10), "y": float64(20), "width": float64(300), "height": float64(200)}, params["clip"])
+}
+
+func TestTake_FullPage(t *testing.T) {
+	var params map[string]any
+	server := fakeServer(t, &params)
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = Take(context.Background(), c, Request{
+		URLs:     []string{"https://a.example"},
+		FullPage: true,
+		Viewport: &Viewport{Width: 1280, Height: 720},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, true, params["fullPage"])
+	assert.Equal(t, map[string]any{"width": float64(1280), "height": float64(720)}, params["viewport"])
+}
+
+func TestResult_Images(t *testing.T) {
+	var png bytes.Buffer
+	require.NoError(t, imagepng.Encode(&png, image.NewRGBA(image.Rect(0, 0, 4, 3))))
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "text.png" {
+			_, _ = w.Write([]byte("not an image"))
+			return
+		}
+		_, _ = w.Write(png.Bytes())
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	result := &Result{FileIDs: []string{"a.png", "b.png"}}
+	data, err := result.Download(context.Background(), c)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{png.Bytes(), png.Bytes()}, data)
+
+	images, err := result.Images(context.Background(), c)
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+	assert.Equal(t, image.Rect(0, 0, 4, 3), images[1].Bounds())
+
+	result = &Result{FileIDs: []string{"text.png"}}
+	_, err = result.Images(context.Background(), c)
+	assert.ErrorContains(t, err, "failed to decode screenshot text.png")
+}
+
+func TestRequest_Validate(t *testing.T) {
+	urls := []string{"https://a.example"}
+	tests := []struct {
+		req  Request
+		want string
+	}{
+		{Request{}, "at least one URL is required"},
+		{Request{URLs: urls, Format: "gif"}, `unsupported format "gif"`},
+		{Request{URLs: urls, Quality: 80}, "quality is not supported with format png"},
+		{Request{URLs: urls, Format: PNG, Quality: 80}, "quality is not supported with format png"},
+		{Request{URLs: urls, Format: JPEG, Quality: 101}, "quality 101 is not between 1 and 100"},
+		{Request{URLs: urls, Scale: 5}, "scale 5 is not between 0 and 4"},
+		{Request{URLs: urls, Scale: -1}, "scale -1 is not between 0 and 4"},
+		{Request{URLs: urls, Selectors: []string{"#a"}, Clip: &Clip{Width: 1, Height: 1}}, "selectors and clip are mutually exclusive"},
+		{Request{URLs: urls, Selectors: []string{"#a", " "}}, "selector 1 is empty"},
+		{Request{URLs: urls, FullPage: true, Clip: &Clip{Width: 1, Height: 1}}, "full page is mutually exclusive with selectors and clip"},
+		{Request{URLs: urls, Viewport: &Viewport{Width: 1280}}, "viewport width and height must be positive"},
+		{Request{URLs: urls, Clip: &Clip{X: -1, Width: 1, Height: 1}}, "clip origin must not be negative"},
+		{Request{URLs: urls, Clip: &Clip{Width: 100}}, "clip width and height must be positive"},
+		{Request{URLs: urls, Thumbnail: &Thumbnail{}}, "thumbnail width or height must be positive"},
+		{Request{URLs: urls, Thumbnail: &Thumbnail{Width: -1, Height: 10}}, "thumbnail width or height must be positive"},
+		{Request{URLs: urls, Thumbnail: &Thumbnail{Height: 10, Quality: 101}}, "thumbnail quality 101 is not between 1 and 100"},
+	}
+	for _, tt := range tests {
+		assert.EqualError(t, tt.req.Validate(), tt.want)
+	}
+	assert.NoError(t, Request{URLs: urls, Thumbnail: &Thumbnail{Height: 10}}.Validate())
+	for _, f := range []Format{JPEG, WebP, AVIF} {
+		assert.NoError(t, Request{URLs: urls, Format: f, Quality: 80, Scale: 2}.Validate())
+	}
+}
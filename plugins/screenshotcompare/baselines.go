@@ -0,0 +1,167 @@
+package screenshotcompare
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+// ErrNoBaseline is returned when no baseline is recorded under a name.
+var ErrNoBaseline = errors.New("no baseline")
+
+// BaselineStore records the file IDs of baseline screenshots by name,
+// e.g. by page or by page and viewport.
+type BaselineStore interface {
+	// Get returns the file ID recorded under name, or ErrNoBaseline.
+	Get(name string) (string, error)
+	Put(name, fileID string) error
+	Delete(name string) error
+}
+
+// Baselines manages baseline screenshots stored on the server.
+type Baselines struct {
+	api   client.API
+	store BaselineStore
+}
+
+// NewBaselines returns Baselines using api and recording file IDs in store.
+func NewBaselines(api client.API, store BaselineStore) *Baselines {
+	return &Baselines{api: api, store: store}
+}
+
+// Capture takes a screenshot of url with the screenshot plugin and records
+// it as the baseline under name, replacing and deleting the previous one.
+// Use it to create a baseline and to approve an intended change.
+func (b *Baselines) Capture(ctx context.Context, name, url string) (string, error) {
+	output, err := b.api.RunPlugin(ctx, "screenshot", map[string]any{"urls": []string{url}})
+	if err != nil {
+		return "", fmt.Errorf("failed to capture baseline: %w", err)
+	}
+	ids := client.FileIDs(output)
+	if len(ids) == 0 {
+		return "", errors.New("failed to capture baseline: no screenshot produced")
+	}
+
+	prev, err := b.store.Get(name)
+	if err != nil && !errors.Is(err, ErrNoBaseline) {
+		return "", err
+	}
+	if err := b.store.Put(name, ids[0]); err != nil {
+		return "", fmt.Errorf("failed to record baseline: %w", err)
+	}
+	if prev != "" && prev != ids[0] {
+		if err := b.api.DeleteFile(ctx, prev); err != nil {
+			return ids[0], fmt.Errorf("failed to delete previous baseline: %w", err)
+		}
+	}
+	return ids[0], nil
+}
+
+// Compare compares a screenshot of url with the baseline under name.
+// Threshold is passed to the plugin as in Request.
+func (b *Baselines) Compare(ctx context.Context, name, url string, threshold float64) (*Result, error) {
+	id, err := b.store.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("baseline %q: %w", name, err)
+	}
+	return Compare(ctx, b.api, Request{BaselineFileID: id, URL: url, Threshold: threshold})
+}
+
+// Remove deletes the baseline under name from the server and the store.
+func (b *Baselines) Remove(ctx context.Context, name string) error {
+	id, err := b.store.Get(name)
+	if err != nil {
+		return fmt.Errorf("baseline %q: %w", name, err)
+	}
+	if err := b.api.DeleteFile(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete baseline: %w", err)
+	}
+	return b.store.Delete(name)
+}
+
+// FileStore is a BaselineStore persisted as a JSON object in a file.
+// It is safe for concurrent use within a process.
+type FileStore struct {
+	path string
+
+	mu sync.Mutex
+}
+
+// NewFileStore returns a FileStore at path. The file is created on the
+// first Put.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (s *FileStore) Get(name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	baselines, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	id, ok := baselines[name]
+	if !ok {
+		return "", ErrNoBaseline
+	}
+	return id, nil
+}
+
+func (s *FileStore) Put(name, fileID string) error {
+	return s.update(func(baselines map[string]string) {
+		baselines[name] = fileID
+	})
+}
+
+func (s *FileStore) Delete(name string) error {
+	return s.update(func(baselines map[string]string) {
+		delete(baselines, name)
+	})
+}
+
+func (s *FileStore) update(fn func(map[string]string)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	baselines, err := s.load()
+	if err != nil {
+		return err
+	}
+	fn(baselines)
+	data, err := json.MarshalIndent(baselines, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baselines: %w", err)
+	}
+	// Write a temporary file and rename it so a crash never leaves a
+	// truncated store behind.
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write baselines: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write baselines: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) load() (map[string]string, error) {
+	baselines := make(map[string]string)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return baselines, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baselines: %w", err)
+	}
+	if err := json.Unmarshal(data, &baselines); err != nil {
+		return nil, fmt.Errorf("failed to decode baselines %s: %w", filepath.Base(s.path), err)
+	}
+	return baselines, nil
+}
@@ -0,0 +1,62 @@
+package screenshotcompare
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+func TestBaselines(t *testing.T) {
+	var params map[string]any
+	var deleted []string
+	server := fakeServer(t, &params, &deleted)
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+	store := NewFileStore(filepath.Join(t.TempDir(), "baselines.json"))
+	b := NewBaselines(c, store)
+
+	_, err = b.Compare(context.Background(), "home", "https://example.com", 0)
+	require.ErrorIs(t, err, ErrNoBaseline)
+
+	id, err := b.Capture(context.Background(), "home", "https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "shot1.png", id)
+
+	result, err := b.Compare(context.Background(), "home", "https://example.com", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "shot1.png", params["baselineFileId"])
+	assert.Equal(t, "diff.png", result.DiffFileID)
+
+	// Approving a change replaces the baseline and deletes the old one.
+	id, err = b.Capture(context.Background(), "home", "https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "shot2.png", id)
+	assert.Equal(t, []string{"shot1.png"}, deleted)
+
+	require.NoError(t, b.Remove(context.Background(), "home"))
+	assert.Equal(t, []string{"shot1.png", "shot2.png"}, deleted)
+	_, err = store.Get("home")
+	require.ErrorIs(t, err, ErrNoBaseline)
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baselines.json")
+	require.NoError(t, NewFileStore(path).Put("home", "shot1.png"))
+
+	// A new store reads the recorded baselines.
+	id, err := NewFileStore(path).Get("home")
+	require.NoError(t, err)
+	assert.Equal(t, "shot1.png", id)
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+	_, err = NewFileStore(path).Get("home")
+	require.ErrorContains(t, err, "failed to decode baselines baselines.json")
+}
@@ -0,0 +1,78 @@
+// Package screenshotcompare wraps the screenshotcompare plugin, which
+// compares a screenshot of a page with a baseline screenshot, for visual
+// regression monitoring.
+package screenshotcompare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bazuker/browserbro-go-api/client"
+	"github.com/bazuker/browserbro-go-api/plugins/internal/pluginio"
+)
+
+// Plugin is the name of the wrapped plugin.
+const Plugin = "screenshotcompare"
+
+// Request compares a screenshot of URL with a baseline screenshot.
+type Request struct {
+	// BaselineFileID is the file ID of the baseline screenshot.
+	BaselineFileID string `json:"baselineFileId"`
+	// URL is the page to capture as the candidate.
+	URL string `json:"url"`
+	// Threshold is the per-pixel color distance, from 0 to 1, below which
+	// pixels count as unchanged. Zero uses the plugin default.
+	Threshold float64 `json:"threshold,omitempty"`
+}
+
+// Region is a rectangle of changed pixels, in candidate pixel coordinates.
+type Region struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Result is the outcome of a comparison.
+type Result struct {
+	// Score is the fraction of changed pixels, from 0 (identical) to 1.
+	Score float64 `json:"score"`
+	// CandidateFileID is the file ID of the candidate screenshot.
+	CandidateFileID string `json:"candidateFileId,omitempty"`
+	// DiffFileID is the file ID of an image highlighting the changes.
+	// It is empty when nothing changed.
+	DiffFileID string   `json:"diffFileId,omitempty"`
+	Regions    []Region `json:"regions,omitempty"`
+}
+
+// Changed reports whether the score exceeds maxScore.
+func (r *Result) Changed(maxScore float64) bool {
+	return r.Score > maxScore
+}
+
+// Compare runs the plugin with req.
+func Compare(ctx context.Context, api client.API, req Request) (*Result, error) {
+	if req.BaselineFileID == "" {
+		return nil, errors.New("baseline file ID is required")
+	}
+	if req.URL == "" {
+		return nil, errors.New("URL is required")
+	}
+	if req.Threshold < 0 || req.Threshold > 1 {
+		return nil, fmt.Errorf("threshold %v is not between 0 and 1", req.Threshold)
+	}
+	params, err := pluginio.Params(req)
+	if err != nil {
+		return nil, err
+	}
+	output, err := api.RunPlugin(ctx, Plugin, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare screenshots: %w", err)
+	}
+	var result Result
+	if err := pluginio.Decode(output, Plugin, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,76 @@
+package screenshotcompare
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+// fakeServer serves the screenshot and screenshotcompare plugins and
+// records the params of the last comparison and the deleted files.
+func fakeServer(t *testing.T, params *map[string]any, deleted *[]string) *httptest.Server {
+	t.Helper()
+
+	shots := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/plugins/screenshot", func(w http.ResponseWriter, r *http.Request) {
+		shots++
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"screenshot": map[string]any{"fileIds": []string{"shot" + string(rune('0'+shots)) + ".png"}},
+		})
+	})
+	mux.HandleFunc("POST /api/v1/plugins/screenshotcompare", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(params)
+		_, _ = w.Write([]byte(`{"screenshotcompare": {
+			"score": 0.04,
+			"candidateFileId": "candidate.png",
+			"diffFileId": "diff.png",
+			"regions": [{"x": 10, "y": 20, "width": 30, "height": 40}]
+		}}`))
+	})
+	mux.HandleFunc("DELETE /api/v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		*deleted = append(*deleted, r.PathValue("id"))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestCompare(t *testing.T) {
+	var params map[string]any
+	server := fakeServer(t, &params, new([]string))
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	result, err := Compare(context.Background(), c, Request{BaselineFileID: "base.png", URL: "https://example.com", Threshold: 0.1})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"baselineFileId": "base.png",
+		"url":            "https://example.com",
+		"threshold":      0.1,
+	}, params)
+	assert.Equal(t, &Result{
+		Score:           0.04,
+		CandidateFileID: "candidate.png",
+		DiffFileID:      "diff.png",
+		Regions:         []Region{{X: 10, Y: 20, Width: 30, Height: 40}},
+	}, result)
+	assert.True(t, result.Changed(0.01))
+	assert.False(t, result.Changed(0.05))
+}
+
+func TestCompare_Validation(t *testing.T) {
+	_, err := Compare(context.Background(), nil, Request{URL: "https://example.com"})
+	require.EqualError(t, err, "baseline file ID is required")
+	_, err = Compare(context.Background(), nil, Request{BaselineFileID: "base.png"})
+	require.EqualError(t, err, "URL is required")
+	_, err = Compare(context.Background(), nil, Request{BaselineFileID: "base.png", URL: "https://example.com", Threshold: 2})
+	require.EqualError(t, err, "threshold 2 is not between 0 and 1")
+}
@@ -0,0 +1,159 @@
+// Package search runs web searches through the search plugins of the
+// server, such as googlesearch, and returns their results in a common
+// model, so switching engines doesn't change result handling.
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/bazuker/browserbro-go-api/client"
+	"github.com/bazuker/browserbro-go-api/plugins/internal/pluginio"
+)
+
+// ErrUnknownEngine is returned for engines without a registered adapter.
+var ErrUnknownEngine = errors.New("unknown search engine")
+
+// Engine names a search engine.
+type Engine string
+
+const (
+	Google     Engine = "google"
+	Bing       Engine = "bing"
+	DuckDuckGo Engine = "duckduckgo"
+)
+
+// Options configures a search. Engines ignore options they don't support.
+type Options struct {
+	// Limit caps the number of results; zero uses the engine default.
+	Limit int
+	// Locale is the language and region of the results, e.g. "en-US".
+	Locale     string
+	SafeSearch bool
+}
+
+// Result is a search result.
+type Result struct {
+	Title   string
+	URL     string
+	Snippet string
+	// Rank is the 1-based position of the result on the engine.
+	Rank   int
+	Engine Engine
+}
+
+// Adapter maps the common search model to the plugin of an engine.
+type Adapter struct {
+	// Plugin is the name of the search plugin.
+	Plugin string
+	// Params returns the plugin params of a search.
+	Params func(query string, opts Options) map[string]any
+	// Parse returns the results of a plugin output, in rank order.
+	// Engine is set by Search.
+	Parse func(output map[string]any) ([]Result, error)
+}
+
+var (
+	adaptersMu sync.RWMutex
+	adapters   = map[Engine]Adapter{
+		Google:     pluginAdapter("googlesearch"),
+		Bing:       pluginAdapter("bingsearch"),
+		DuckDuckGo: pluginAdapter("duckduckgosearch"),
+	}
+)
+
+// Register registers the adapter of an engine, replacing any previous one,
+// for example when a new search plugin is deployed.
+func Register(engine Engine, a Adapter) {
+	adaptersMu.Lock()
+	defer adaptersMu.Unlock()
+
+	adapters[engine] = a
+}
+
+// Engines returns the engines with a registered adapter, sorted by name.
+func Engines() []Engine {
+	adaptersMu.RLock()
+	defer adaptersMu.RUnlock()
+
+	engines := make([]Engine, 0, len(adapters))
+	for e := range adapters {
+		engines = append(engines, e)
+	}
+	sort.Slice(engines, func(i, j int) bool { return engines[i] < engines[j] })
+	return engines
+}
+
+// Search searches engine for query.
+func Search(ctx context.Context, api client.API, engine Engine, query string, opts Options) ([]Result, error) {
+	if query == "" {
+		return nil, errors.New("query is required")
+	}
+	if opts.Limit < 0 {
+		return nil, fmt.Errorf("limit %d must not be negative", opts.Limit)
+	}
+	adaptersMu.RLock()
+	a, ok := adapters[engine]
+	adaptersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownEngine, engine)
+	}
+	output, err := api.RunPlugin(ctx, a.Plugin, a.Params(query, opts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", engine, err)
+	}
+	results, err := a.Parse(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s results: %w", engine, err)
+	}
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	for i := range results {
+		results[i].Engine = engine
+		if results[i].Rank == 0 {
+			results[i].Rank = i + 1
+		}
+	}
+	return results, nil
+}
+
+// pluginAdapter is the adapter of the bundled search plugins, which share
+// their params and output format.
+func pluginAdapter(plugin string) Adapter {
+	return Adapter{
+		Plugin: plugin,
+		Params: func(query string, opts Options) map[string]any {
+			params := map[string]any{"query": query}
+			if opts.Limit > 0 {
+				params["limit"] = opts.Limit
+			}
+			if opts.Locale != "" {
+				params["locale"] = opts.Locale
+			}
+			if opts.SafeSearch {
+				params["safeSearch"] = true
+			}
+			return params
+		},
+		Parse: func(output map[string]any) ([]Result, error) {
+			return parseResults(output, plugin)
+		},
+	}
+}
+
+// parseResults parses the results of output[plugin], sorted by rank.
+func parseResults(output map[string]any, plugin string) ([]Result, error) {
+	found, err := pluginio.SearchResults(output, plugin)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Result, len(found))
+	for i, r := range found {
+		results[i] = Result{Title: r.Title, URL: r.URL, Snippet: r.Snippet, Rank: r.Rank}
+	}
+	return results, nil
+}
@@ -0,0 +1,102 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+// fakeServer serves googlesearch and bingsearch with results in the two
+// field spellings and stores the params of the last search.
+func fakeServer(t *testing.T, params *map[string]any) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/plugins/googlesearch", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(params)
+		_, _ = w.Write([]byte(`{"googlesearch": {"results": [
+			{"title": "Go", "url": "https://go.dev", "snippet": "The Go language"},
+			{"title": "No URL"},
+			{"title": "Blog", "link": "https://go.dev/blog", "description": "The Go blog"},
+			{"title": "Tour", "url": "https://go.dev/tour"}
+		]}}`))
+	})
+	mux.HandleFunc("POST /api/v1/plugins/bingsearch", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(params)
+		_, _ = w.Write([]byte(`{"bingsearch": {"results": [
+			{"title": "Go", "url": "https://go.dev", "rank": 2}
+		]}}`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestSearch(t *testing.T) {
+	var params map[string]any
+	server := fakeServer(t, &params)
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	results, err := Search(context.Background(), c, Google, "golang", Options{Limit: 2, Locale: "en-US", SafeSearch: true})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"query": "golang", "limit": float64(2), "locale": "en-US", "safeSearch": true}, params)
+	assert.Equal(t, []Result{
+		{Title: "Go", URL: "https://go.dev", Snippet: "The Go language", Rank: 1, Engine: Google},
+		{Title: "Blog", URL: "https://go.dev/blog", Snippet: "The Go blog", Rank: 2, Engine: Google},
+	}, results)
+
+	params = nil
+	results, err = Search(context.Background(), c, Bing, "golang", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"query": "golang"}, params)
+	assert.Equal(t, []Result{{Title: "Go", URL: "https://go.dev", Rank: 2, Engine: Bing}}, results)
+}
+
+func TestSearch_Errors(t *testing.T) {
+	_, err := Search(context.Background(), nil, Google, "", Options{})
+	require.EqualError(t, err, "query is required")
+	_, err = Search(context.Background(), nil, "altavista", "golang", Options{})
+	require.ErrorIs(t, err, ErrUnknownEngine)
+
+	var params map[string]any
+	server := fakeServer(t, &params)
+	defer server.Close()
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Search(ctx, c, Google, "golang", Options{})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRegister(t *testing.T) {
+	var params map[string]any
+	server := fakeServer(t, &params)
+	defer server.Close()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+
+	Register("custom", Adapter{
+		Plugin: "googlesearch",
+		Params: func(query string, _ Options) map[string]any { return map[string]any{"q": query} },
+		Parse: func(map[string]any) ([]Result, error) {
+			return []Result{{Title: "Custom", URL: "https://example.com"}}, nil
+		},
+	})
+	assert.Contains(t, Engines(), Engine("custom"))
+
+	results, err := Search(context.Background(), c, "custom", "golang", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"q": "golang"}, params)
+	assert.Equal(t, []Result{{Title: "Custom", URL: "https://example.com", Rank: 1, Engine: "custom"}}, results)
+}
@@ -0,0 +1,39 @@
+package browserbrov1
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestJobEvent_JSON(t *testing.T) {
+	event := &JobEvent{
+		Id:      "evt-1",
+		Type:    JobEventType_JOB_EVENT_TYPE_COMPLETED,
+		Subject: "job-1",
+		Data: &JobEvent_Result{Result: &PluginResult{
+			Plugin:   "screenshot",
+			JobId:    "job-1",
+			FileIds:  []string{"a.png"},
+			Duration: durationpb.New(1500 * time.Millisecond),
+		}},
+	}
+	data, err := protojson.Marshal(event)
+	require.NoError(t, err)
+	// The JSON names match the client's JSON shapes.
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields))
+	result := fields["result"].(map[string]any)
+	assert.Equal(t, []any{"a.png"}, result["fileIds"])
+	assert.Equal(t, "1.500s", result["duration"])
+
+	var decoded JobEvent
+	require.NoError(t, protojson.Unmarshal(data, &decoded))
+	assert.True(t, proto.Equal(event, &decoded))
+}
@@ -0,0 +1,6 @@
+// Package browserbrov1 contains the Go types generated from
+// browserbro.proto, the schema of plugin requests, results, job events
+// and file metadata shared with clients in other languages.
+package browserbrov1
+
+//go:generate protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative ../../../proto/browserbro/v1/browserbro.proto
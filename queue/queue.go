@@ -0,0 +1,203 @@
+// Package queue submits BrowserBro plugin jobs through a message queue,
+// such as RabbitMQ, when the server runs a queue frontend.
+//
+// The package does not depend on a specific driver. An AMQP connection is
+// adapted by implementing Publisher and Consumer on top of a channel:
+// Message maps onto amqp.Publishing (Body, CorrelationId, ReplyTo,
+// ContentType) and Consume onto Channel.Consume.
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+// Message is a queue message.
+type Message struct {
+	Body          []byte
+	ContentType   string
+	CorrelationID string
+	ReplyTo       string
+}
+
+// Publisher publishes messages to a queue.
+type Publisher interface {
+	Publish(ctx context.Context, queue string, msg Message) error
+}
+
+// Consumer delivers messages from a queue until ctx is done.
+type Consumer interface {
+	Consume(ctx context.Context, queue string) (<-chan Message, error)
+}
+
+// ErrSubmitterStopped is returned for jobs waiting on a reply
+// when the reply consumer stops. If it stopped because of a panic,
+// the error also wraps a *client.PanicError.
+var ErrSubmitterStopped = errors.New("submitter stopped")
+
+// ErrJobPending is returned by Submit for a job whose ID is the ID of
+// another job still waiting on a reply.
+var ErrJobPending = errors.New("job already pending")
+
+// Submitter publishes client.JobRequest messages to a request queue and
+// matches client.JobResponse replies from a reply queue by correlation ID.
+// It is safe for concurrent use.
+type Submitter struct {
+	pub          Publisher
+	cons         Consumer
+	requestQueue string
+	replyQueue   string
+	idPrefix     string
+	nextID       atomic.Uint64
+
+	mu      sync.Mutex
+	pending map[string]chan client.JobResponse
+	stopped bool
+	err     error
+}
+
+// NewSubmitter creates a Submitter. Start must be called before Submit.
+func NewSubmitter(pub Publisher, cons Consumer, requestQueue, replyQueue string) *Submitter {
+	return &Submitter{
+		pub:          pub,
+		cons:         cons,
+		requestQueue: requestQueue,
+		replyQueue:   replyQueue,
+		idPrefix:     newIDPrefix(),
+		pending:      make(map[string]chan client.JobResponse),
+	}
+}
+
+// Start consumes replies in the background until ctx is done.
+func (s *Submitter) Start(ctx context.Context) error {
+	replies, err := s.cons.Consume(ctx, s.replyQueue)
+	if err != nil {
+		return fmt.Errorf("failed to consume replies: %w", err)
+	}
+	go func() {
+		var err error
+		defer func() {
+			if v := recover(); v != nil {
+				err = fmt.Errorf("%w: %w", ErrSubmitterStopped, client.NewPanicError(v))
+			}
+			s.stop(err)
+		}()
+		for msg := range replies {
+			var resp client.JobResponse
+			if err := json.Unmarshal(msg.Body, &resp); err != nil {
+				continue
+			}
+			id := msg.CorrelationID
+			if id == "" {
+				id = resp.ID
+			}
+			s.mu.Lock()
+			ch, ok := s.pending[id]
+			delete(s.pending, id)
+			s.mu.Unlock()
+			if ok {
+				ch <- resp
+			}
+		}
+	}()
+	return nil
+}
+
+// Submit publishes a job and waits for its reply.
+// A job ID is generated when req.ID is empty; generated IDs start with
+// a random prefix, so replies to other submitters sharing the reply
+// queue aren't mistaken for replies to this one.
+func (s *Submitter) Submit(ctx context.Context, req client.JobRequest) (client.JobResponse, error) {
+	if req.ID == "" {
+		req.ID = s.idPrefix + "-" + strconv.FormatUint(s.nextID.Add(1), 10)
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return client.JobResponse{}, fmt.Errorf("failed to JSON encode job: %w", err)
+	}
+
+	reply := make(chan client.JobResponse, 1)
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return client.JobResponse{}, s.errLocked()
+	}
+	if _, ok := s.pending[req.ID]; ok {
+		s.mu.Unlock()
+		return client.JobResponse{}, fmt.Errorf("job %s: %w", req.ID, ErrJobPending)
+	}
+	s.pending[req.ID] = reply
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		// Once answered, the ID may be pending for another job.
+		if s.pending[req.ID] == reply {
+			delete(s.pending, req.ID)
+		}
+		s.mu.Unlock()
+	}()
+
+	err = s.pub.Publish(ctx, s.requestQueue, Message{
+		Body:          body,
+		ContentType:   "application/json",
+		CorrelationID: req.ID,
+		ReplyTo:       s.replyQueue,
+	})
+	if err != nil {
+		return client.JobResponse{}, fmt.Errorf("failed to publish job: %w", err)
+	}
+
+	select {
+	case resp, ok := <-reply:
+		if !ok {
+			return client.JobResponse{}, s.stopErr()
+		}
+		if resp.Error != "" {
+			return resp, fmt.Errorf("job %s failed: %s", req.ID, resp.Error)
+		}
+		return resp, nil
+	case <-ctx.Done():
+		return client.JobResponse{}, ctx.Err()
+	}
+}
+
+func (s *Submitter) stop(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopped = true
+	s.err = err
+	for id, ch := range s.pending {
+		close(ch)
+		delete(s.pending, id)
+	}
+}
+
+func (s *Submitter) stopErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.errLocked()
+}
+
+func (s *Submitter) errLocked() error {
+	if s.err != nil {
+		return s.err
+	}
+	return ErrSubmitterStopped
+}
+
+// newIDPrefix returns a random prefix for generated job IDs.
+func newIDPrefix() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,162 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bazuker/browserbro-go-api/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryBroker is an in-memory queue broker with a fake BrowserBro
+// worker answering jobs published to the "jobs" queue.
+type memoryBroker struct {
+	mu     sync.Mutex
+	queues map[string]chan Message
+}
+
+func newMemoryBroker() *memoryBroker {
+	return &memoryBroker{queues: make(map[string]chan Message)}
+}
+
+func (b *memoryBroker) queue(name string) chan Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	q, ok := b.queues[name]
+	if !ok {
+		q = make(chan Message, 16)
+		b.queues[name] = q
+	}
+	return q
+}
+
+func (b *memoryBroker) Publish(ctx context.Context, queue string, msg Message) error {
+	if queue == "broken" {
+		return errors.New("channel closed")
+	}
+	b.queue(queue) <- msg
+	return nil
+}
+
+func (b *memoryBroker) Consume(ctx context.Context, queue string) (<-chan Message, error) {
+	out := make(chan Message)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case msg := <-b.queue(queue):
+				out <- msg
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (b *memoryBroker) serve(ctx context.Context) {
+	jobs, _ := b.Consume(ctx, "jobs")
+	for msg := range jobs {
+		var req client.JobRequest
+		_ = json.Unmarshal(msg.Body, &req)
+		resp := client.JobResponse{ID: req.ID}
+		if req.Plugin == "broken" {
+			resp.Error = "plugin crashed"
+		} else if req.Plugin != "slow" {
+			resp.Output = map[string]any{req.Plugin: req.Params["query"]}
+		} else {
+			continue
+		}
+		body, _ := json.Marshal(resp)
+		_ = b.Publish(ctx, msg.ReplyTo, Message{Body: body, CorrelationID: msg.CorrelationID})
+	}
+}
+
+func TestSubmitter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broker := newMemoryBroker()
+	go broker.serve(ctx)
+
+	s := NewSubmitter(broker, broker, "jobs", "replies")
+	require.NoError(t, s.Start(ctx))
+
+	t.Run("success", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for _, query := range []string{"golang", "rust"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := s.Submit(ctx, client.JobRequest{
+					Plugin: "googlesearch",
+					Params: map[string]any{"query": query},
+				})
+				assert.NoError(t, err)
+				assert.Equal(t, map[string]any{"googlesearch": query}, resp.Output)
+				assert.True(t, strings.HasPrefix(resp.ID, s.idPrefix+"-"), resp.ID)
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("job error", func(t *testing.T) {
+		resp, err := s.Submit(ctx, client.JobRequest{ID: "j1", Plugin: "broken"})
+		require.EqualError(t, err, "job j1 failed: plugin crashed")
+		assert.Equal(t, "j1", resp.ID)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		waitCtx, waitCancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer waitCancel()
+
+		_, err := s.Submit(waitCtx, client.JobRequest{Plugin: "slow"})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("duplicate ID", func(t *testing.T) {
+		waitCtx, waitCancel := context.WithTimeout(ctx, time.Second)
+		defer waitCancel()
+
+		go func() { _, _ = s.Submit(waitCtx, client.JobRequest{ID: "dup", Plugin: "slow"}) }()
+		require.Eventually(t, func() bool {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return s.pending["dup"] != nil
+		}, time.Second, time.Millisecond)
+
+		_, err := s.Submit(ctx, client.JobRequest{ID: "dup", Plugin: "googlesearch"})
+		require.ErrorIs(t, err, ErrJobPending)
+	})
+
+	t.Run("publish error", func(t *testing.T) {
+		broken := NewSubmitter(broker, broker, "broken", "replies-2")
+		require.NoError(t, broken.Start(ctx))
+
+		_, err := broken.Submit(ctx, client.JobRequest{Plugin: "googlesearch"})
+		require.EqualError(t, err, "failed to publish job: channel closed")
+	})
+
+	t.Run("unique IDs", func(t *testing.T) {
+		other := NewSubmitter(broker, broker, "jobs", "replies")
+		assert.NotEqual(t, s.idPrefix, other.idPrefix)
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		stopCtx, stop := context.WithCancel(ctx)
+		stopped := NewSubmitter(broker, broker, "jobs", "replies-3")
+		require.NoError(t, stopped.Start(stopCtx))
+		stop()
+
+		require.Eventually(t, func() bool {
+			_, err := stopped.Submit(ctx, client.JobRequest{Plugin: "slow"})
+			return errors.Is(err, ErrSubmitterStopped)
+		}, time.Second, 5*time.Millisecond)
+	})
+}
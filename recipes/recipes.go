@@ -0,0 +1,150 @@
+// Package recipes implements common BrowserBro workflows on top of the
+// public client API. Each recipe is small enough to serve as an example
+// and composes with the others.
+package recipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bazuker/browserbro-go-api/client"
+	"github.com/bazuker/browserbro-go-api/results"
+)
+
+// ScreenshotURLs takes screenshots of urls with the screenshot plugin and
+// saves every produced file in dir, named after its file ID. It returns
+// the paths of the saved files.
+func ScreenshotURLs(ctx context.Context, api client.API, urls []string, dir string) ([]string, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("at least one URL is required")
+	}
+	output, err := api.RunPlugin(ctx, "screenshot", map[string]any{"urls": urls})
+	if err != nil {
+		return nil, fmt.Errorf("failed to take screenshots: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	var paths []string
+	for _, id := range client.FileIDs(output) {
+		data, err := api.DownloadFile(ctx, id)
+		if err != nil {
+			return paths, err
+		}
+		path := filepath.Join(dir, filepath.Base(id))
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return paths, fmt.Errorf("failed to save screenshot: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// SearchURLs runs the googlesearch plugin and returns up to limit result
+// URLs, in result order. Zero means no limit.
+func SearchURLs(ctx context.Context, api client.API, query string, limit int) ([]string, error) {
+	output, err := api.RunPlugin(ctx, "googlesearch", map[string]any{"query": query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	urls := ResultURLs(output)
+	if limit > 0 && len(urls) > limit {
+		urls = urls[:limit]
+	}
+	return urls, nil
+}
+
+// SearchAndScreenshot searches for query and saves screenshots of up to
+// limit result pages in dir.
+func SearchAndScreenshot(ctx context.Context, api client.API, query string, limit int, dir string) ([]string, error) {
+	urls, err := SearchURLs(ctx, api, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	return ScreenshotURLs(ctx, api, urls, dir)
+}
+
+// ResultURLs returns the distinct http(s) URLs found in the "url" and
+// "link" fields of a plugin output at any depth. Arrays are visited in
+// order and object keys in sorted order.
+func ResultURLs(output map[string]any) []string {
+	var urls []string
+	seen := map[string]bool{}
+	var walk func(v any)
+	walk = func(v any) {
+		switch t := v.(type) {
+		case map[string]any:
+			keys := make([]string, 0, len(t))
+			for k := range t {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				if s, ok := t[k].(string); ok && (k == "url" || k == "link") {
+					if (strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")) && !seen[s] {
+						seen[s] = true
+						urls = append(urls, s)
+					}
+					continue
+				}
+				walk(t[k])
+			}
+		case []any:
+			for _, child := range t {
+				walk(child)
+			}
+		}
+	}
+	walk(output)
+	return urls
+}
+
+// Monitor runs a plugin every interval and calls onChange with the
+// difference between consecutive outputs whenever they differ. It returns
+// when ctx is done, a run fails, or onChange returns an error.
+func Monitor(
+	ctx context.Context,
+	api client.API,
+	pluginName string,
+	params map[string]any,
+	interval time.Duration,
+	onChange func(results.Report) error,
+) error {
+	if interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+	prev, err := api.RunPlugin(ctx, pluginName, params)
+	if err != nil {
+		return fmt.Errorf("failed to run plugin: %w", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		curr, err := api.RunPlugin(ctx, pluginName, params)
+		if err != nil {
+			return fmt.Errorf("failed to run plugin: %w", err)
+		}
+		if report := results.Diff(prev, curr); !report.Empty() {
+			if err := onChange(report); err != nil {
+				return err
+			}
+		}
+		prev = curr
+	}
+}
@@ -0,0 +1,128 @@
+package recipes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bazuker/browserbro-go-api/client"
+	"github.com/bazuker/browserbro-go-api/results"
+)
+
+// fakeServer serves the screenshot and googlesearch plugins and a page
+// plugin whose title changes on every other run.
+func fakeServer(t *testing.T, screenshotURLs *[]string) *httptest.Server {
+	t.Helper()
+
+	var pageRuns atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/plugins/screenshot", func(w http.ResponseWriter, r *http.Request) {
+		var params struct {
+			URLs []string `json:"urls"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		*screenshotURLs = params.URLs
+		var ids []string
+		for i := range params.URLs {
+			ids = append(ids, "shot"+string(rune('1'+i))+".png")
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"screenshot": map[string]any{"fileIds": ids}})
+	})
+	mux.HandleFunc("POST /api/v1/plugins/googlesearch", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"googlesearch": {"results": [
+			{"title": "Go", "url": "https://go.dev"},
+			{"title": "Go again", "url": "https://go.dev"},
+			{"title": "Blog", "link": "https://go.dev/blog"},
+			{"title": "Tour", "url": "https://go.dev/tour"},
+			{"title": "Relative", "url": "/relative"}
+		]}}`))
+	})
+	mux.HandleFunc("POST /api/v1/plugins/page", func(w http.ResponseWriter, r *http.Request) {
+		n := pageRuns.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"title": n / 2})
+	})
+	mux.HandleFunc("GET /api/v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("png:" + r.PathValue("id")))
+	})
+	return httptest.NewServer(mux)
+}
+
+func newClient(t *testing.T, server *httptest.Server) *client.Client {
+	t.Helper()
+
+	c, err := client.New(server.URL, nil)
+	require.NoError(t, err)
+	return c
+}
+
+func TestScreenshotURLs(t *testing.T) {
+	var urls []string
+	server := fakeServer(t, &urls)
+	defer server.Close()
+	c := newClient(t, server)
+
+	dir := filepath.Join(t.TempDir(), "shots")
+	paths, err := ScreenshotURLs(context.Background(), c, []string{"https://a.example", "https://b.example"}, dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "shot1.png"), filepath.Join(dir, "shot2.png")}, paths)
+	data, err := os.ReadFile(paths[1])
+	require.NoError(t, err)
+	assert.Equal(t, "png:shot2.png", string(data))
+
+	_, err = ScreenshotURLs(context.Background(), c, nil, dir)
+	assert.EqualError(t, err, "at least one URL is required")
+}
+
+func TestSearchAndScreenshot(t *testing.T) {
+	var urls []string
+	server := fakeServer(t, &urls)
+	defer server.Close()
+	c := newClient(t, server)
+
+	paths, err := SearchAndScreenshot(context.Background(), c, "golang", 2, t.TempDir())
+	require.NoError(t, err)
+	assert.Len(t, paths, 2)
+	assert.Equal(t, []string{"https://go.dev", "https://go.dev/blog"}, urls)
+
+	all, err := SearchURLs(context.Background(), c, "golang", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://go.dev", "https://go.dev/blog", "https://go.dev/tour"}, all)
+}
+
+func TestMonitor(t *testing.T) {
+	var urls []string
+	server := fakeServer(t, &urls)
+	defer server.Close()
+	c := newClient(t, server)
+
+	errDone := errors.New("done")
+	var reports []results.Report
+	err := Monitor(context.Background(), c, "page", nil, time.Millisecond, func(r results.Report) error {
+		reports = append(reports, r)
+		if len(reports) == 2 {
+			return errDone
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errDone)
+	require.Len(t, reports, 2)
+	assert.Equal(t, "changed title", reports[0].Changes[0].String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	err = Monitor(ctx, c, "page", nil, time.Millisecond, func(results.Report) error { return nil })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	err = Monitor(ctx, c, "page", nil, 0, nil)
+	assert.EqualError(t, err, "interval must be positive")
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+func replay(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	server := fs.String("server", "http://localhost:10001", "BrowserBro server address")
+	file := fs.String("file", "", "path to the calls exported by a client.Recorder")
+	id := fs.Uint64("id", 0, "ID of the call to replay; without it, the calls are listed")
+	_ = fs.Parse(args)
+
+	if *file == "" {
+		fmt.Println("the -file flag is required")
+		os.Exit(2)
+	}
+	f, err := os.Open(*file)
+	if err != nil {
+		fmt.Println("failed to open calls:", err)
+		os.Exit(1)
+	}
+	calls, err := client.ReadRecordedCalls(f)
+	_ = f.Close()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if *id == 0 {
+		for _, call := range calls {
+			printCall(call)
+		}
+		return
+	}
+	var original *client.RecordedCall
+	for i := range calls {
+		if calls[i].ID == *id {
+			original = &calls[i]
+		}
+	}
+	if original == nil {
+		fmt.Printf("call %d not found in %s\n", *id, *file)
+		os.Exit(1)
+	}
+
+	c, err := client.New(*server, nil)
+	if err != nil {
+		fmt.Println("failed to create client:", err)
+		os.Exit(1)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	replayed, err := c.Replay(ctx, *original)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Print("original: ")
+	printCall(*original)
+	fmt.Printf("%s\n\n", original.ResponseBody)
+	fmt.Print("replayed: ")
+	printCall(*replayed)
+	fmt.Printf("%s\n", replayed.ResponseBody)
+}
+
+func printCall(call client.RecordedCall) {
+	status := fmt.Sprint(call.StatusCode)
+	if call.Error != "" {
+		status = "error: " + call.Error
+	}
+	fmt.Printf("%d\t%s\t%s %s\t%s\t%s\t%s\n",
+		call.ID,
+		call.Start.Format("2006-01-02T15:04:05.000Z07:00"),
+		call.Method,
+		call.Path,
+		status,
+		call.Duration,
+		call.RequestID,
+	)
+}
@@ -0,0 +1,147 @@
+// Package results provides helpers for working with plugin outputs.
+package results
+
+import (
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind is the kind of a change between two plugin outputs.
+type ChangeKind string
+
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+// Change is a single difference between two plugin outputs.
+type Change struct {
+	// Path is the sequence of object keys and array indexes
+	// leading to the changed value.
+	Path []string
+	Kind ChangeKind
+	Old  any
+	New  any
+}
+
+// String returns the path joined with dots.
+func (c Change) String() string {
+	return string(c.Kind) + " " + strings.Join(c.Path, ".")
+}
+
+// Report is the structured difference between two plugin outputs.
+type Report struct {
+	Changes []Change
+}
+
+// Empty reports whether the outputs are identical.
+func (r Report) Empty() bool {
+	return len(r.Changes) == 0
+}
+
+// ByURL groups changes by the first path element that is an absolute
+// http(s) URL, which is how multi-URL plugins key their outputs.
+// Changes outside any URL are omitted.
+func (r Report) ByURL() map[string][]Change {
+	grouped := make(map[string][]Change)
+	for _, c := range r.Changes {
+		for _, elem := range c.Path {
+			if isURL(elem) {
+				grouped[elem] = append(grouped[elem], c)
+				break
+			}
+		}
+	}
+	return grouped
+}
+
+// Diff compares two plugin outputs and reports added, removed and changed
+// values. Objects are compared key by key and arrays index by index.
+// Changes are sorted by path.
+func Diff(prev, curr map[string]any) Report {
+	var changes []Change
+	diffValue(nil, prev, curr, &changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return lessPath(changes[i].Path, changes[j].Path)
+	})
+	return Report{Changes: changes}
+}
+
+func diffValue(path []string, prev, curr any, changes *[]Change) {
+	switch p := prev.(type) {
+	case map[string]any:
+		if c, ok := curr.(map[string]any); ok {
+			diffMaps(path, p, c, changes)
+			return
+		}
+	case []any:
+		if c, ok := curr.([]any); ok {
+			diffSlices(path, p, c, changes)
+			return
+		}
+	}
+	if !reflect.DeepEqual(prev, curr) {
+		*changes = append(*changes, Change{Path: path, Kind: Changed, Old: prev, New: curr})
+	}
+}
+
+func diffMaps(path []string, prev, curr map[string]any, changes *[]Change) {
+	for k, pv := range prev {
+		cv, ok := curr[k]
+		if !ok {
+			*changes = append(*changes, Change{Path: appendPath(path, k), Kind: Removed, Old: pv})
+			continue
+		}
+		diffValue(appendPath(path, k), pv, cv, changes)
+	}
+	for k, cv := range curr {
+		if _, ok := prev[k]; !ok {
+			*changes = append(*changes, Change{Path: appendPath(path, k), Kind: Added, New: cv})
+		}
+	}
+}
+
+func diffSlices(path []string, prev, curr []any, changes *[]Change) {
+	for i := 0; i < len(prev) || i < len(curr); i++ {
+		p := appendPath(path, strconv.Itoa(i))
+		switch {
+		case i >= len(curr):
+			*changes = append(*changes, Change{Path: p, Kind: Removed, Old: prev[i]})
+		case i >= len(prev):
+			*changes = append(*changes, Change{Path: p, Kind: Added, New: curr[i]})
+		default:
+			diffValue(p, prev[i], curr[i], changes)
+		}
+	}
+}
+
+func appendPath(path []string, elem string) []string {
+	p := make([]string, len(path)+1)
+	copy(p, path)
+	p[len(path)] = elem
+	return p
+}
+
+func lessPath(a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] == b[i] {
+			continue
+		}
+		ai, aErr := strconv.Atoi(a[i])
+		bi, bErr := strconv.Atoi(b[i])
+		if aErr == nil && bErr == nil {
+			return ai < bi
+		}
+		return a[i] < b[i]
+	}
+	return len(a) < len(b)
+}
+
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,62 @@
+package results
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	t.Run("identical", func(t *testing.T) {
+		out := map[string]any{"a": []any{1.0, map[string]any{"b": "c"}}}
+		assert.True(t, Diff(out, out).Empty())
+	})
+
+	t.Run("changes", func(t *testing.T) {
+		prev := map[string]any{
+			"title":   "old",
+			"removed": true,
+			"items":   []any{"a", "b", "c"},
+			"nested":  map[string]any{"x": 1.0},
+		}
+		curr := map[string]any{
+			"title":  "new",
+			"added":  1.0,
+			"items":  []any{"a", "B"},
+			"nested": "flat",
+		}
+		report := Diff(prev, curr)
+		assert.Equal(t, []Change{
+			{Path: []string{"added"}, Kind: Added, New: 1.0},
+			{Path: []string{"items", "1"}, Kind: Changed, Old: "b", New: "B"},
+			{Path: []string{"items", "2"}, Kind: Removed, Old: "c"},
+			{Path: []string{"nested"}, Kind: Changed, Old: map[string]any{"x": 1.0}, New: "flat"},
+			{Path: []string{"removed"}, Kind: Removed, Old: true},
+			{Path: []string{"title"}, Kind: Changed, Old: "old", New: "new"},
+		}, report.Changes)
+		assert.Equal(t, "changed items.1", report.Changes[1].String())
+	})
+}
+
+func TestReport_ByURL(t *testing.T) {
+	prev := map[string]any{
+		"screenshot": map[string]any{
+			"https://a.com/": map[string]any{"title": "A"},
+			"https://b.com/": map[string]any{"title": "B"},
+		},
+		"version": 1.0,
+	}
+	curr := map[string]any{
+		"screenshot": map[string]any{
+			"https://a.com/": map[string]any{"title": "A2"},
+			"https://b.com/": map[string]any{"title": "B"},
+			"https://c.com/": map[string]any{"title": "C"},
+		},
+		"version": 2.0,
+	}
+	grouped := Diff(prev, curr).ByURL()
+	require.Len(t, grouped, 2)
+	assert.Equal(t, Changed, grouped["https://a.com/"][0].Kind)
+	assert.Equal(t, Added, grouped["https://c.com/"][0].Kind)
+}
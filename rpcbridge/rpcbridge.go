@@ -0,0 +1,189 @@
+// Package rpcbridge exposes a BrowserBro client as a JSON-RPC 2.0 server,
+// so tools written in other languages can reach BrowserBro through one
+// local process that applies the client's auth and retry policies.
+//
+// Supported methods:
+//
+//	plugins                                  → ["screenshot", ...]
+//	runPlugin     {"plugin": ..., "params": {...}} → plugin output
+//	downloadFile  {"fileId": ...}            → base64-encoded file content
+//	deleteFile    {"fileId": ...}            → null
+//	healthcheck                              → null
+package rpcbridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/bazuker/browserbro-go-api/client"
+)
+
+// JSON-RPC 2.0 error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	// CodeServerError is used for errors returned by the BrowserBro client.
+	CodeServerError = -32000
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Error is a JSON-RPC 2.0 error object.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Handler is an http.Handler serving JSON-RPC 2.0 requests, including
+// batches, by calling the wrapped client.
+type Handler struct {
+	api client.API
+}
+
+// NewHandler creates a Handler calling api.
+func NewHandler(api client.API) *Handler {
+	return &Handler{api: api}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+	var out any
+	if len(body) > 0 && body[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			out = errorResponse(nil, CodeParseError, "parse error")
+		} else if len(batch) == 0 {
+			out = errorResponse(nil, CodeInvalidRequest, "empty batch")
+		} else {
+			responses := make([]response, 0, len(batch))
+			for _, raw := range batch {
+				if resp := h.handle(r.Context(), raw); resp != nil {
+					responses = append(responses, *resp)
+				}
+			}
+			if len(responses) > 0 {
+				out = responses
+			}
+		}
+	} else if resp := h.handle(r.Context(), body); resp != nil {
+		out = resp
+	}
+
+	if out == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// handle processes a single request. It returns nil for notifications.
+// Calls are canceled with ctx, the context of the HTTP request.
+func (h *Handler) handle(ctx context.Context, raw json.RawMessage) *response {
+	var req request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return errorResponse(nil, CodeParseError, "parse error")
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, CodeInvalidRequest, "invalid request")
+	}
+
+	result, rpcErr := h.call(ctx, req.Method, req.Params)
+	if req.ID == nil {
+		return nil
+	}
+	if rpcErr != nil {
+		return &response{JSONRPC: "2.0", Error: rpcErr, ID: req.ID}
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return &response{
+			JSONRPC: "2.0",
+			Error:   &Error{Code: CodeServerError, Message: "failed to JSON encode result"},
+			ID:      req.ID,
+		}
+	}
+	return &response{JSONRPC: "2.0", Result: data, ID: req.ID}
+}
+
+func (h *Handler) call(ctx context.Context, method string, raw json.RawMessage) (any, *Error) {
+	switch method {
+	case "plugins":
+		plugins, err := h.api.Plugins(ctx)
+		return plugins, serverError(err)
+	case "runPlugin":
+		var params struct {
+			Plugin string         `json:"plugin"`
+			Params map[string]any `json:"params"`
+		}
+		if err := decodeParams(raw, &params); err != nil || params.Plugin == "" {
+			return nil, &Error{Code: CodeInvalidParams, Message: "plugin is required"}
+		}
+		output, err := h.api.RunPlugin(ctx, params.Plugin, params.Params)
+		return output, serverError(err)
+	case "downloadFile", "deleteFile":
+		var params struct {
+			FileID string `json:"fileId"`
+		}
+		if err := decodeParams(raw, &params); err != nil || params.FileID == "" {
+			return nil, &Error{Code: CodeInvalidParams, Message: "fileId is required"}
+		}
+		if method == "deleteFile" {
+			return nil, serverError(h.api.DeleteFile(ctx, params.FileID))
+		}
+		data, err := h.api.DownloadFile(ctx, params.FileID)
+		return data, serverError(err)
+	case "healthcheck":
+		return nil, serverError(h.api.Healthcheck(ctx))
+	}
+	return nil, &Error{Code: CodeMethodNotFound, Message: "method not found"}
+}
+
+func decodeParams(raw json.RawMessage, v any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, v)
+}
+
+func serverError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: CodeServerError, Message: err.Error()}
+}
+
+func errorResponse(id json.RawMessage, code int, msg string) *response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: "2.0", Error: &Error{Code: code, Message: msg}, ID: id}
+}
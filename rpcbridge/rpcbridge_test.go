@@ -0,0 +1,125 @@
+package rpcbridge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bazuker/browserbro-go-api/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAPI struct{}
+
+func (fakeAPI) Plugins(context.Context) ([]string, error) { return []string{"screenshot"}, nil }
+
+func (fakeAPI) RunPlugin(_ context.Context, name string, params map[string]any, _ ...client.RunOption) (map[string]any, error) {
+	if name == "broken" {
+		return nil, errors.New("unexpected response status: 500 Internal Server Error")
+	}
+	return map[string]any{name: params["query"]}, nil
+}
+
+func (fakeAPI) DownloadFile(_ context.Context, fileID string) ([]byte, error) {
+	return []byte("content"), nil
+}
+func (fakeAPI) DeleteFile(_ context.Context, fileID string) error { return nil }
+func (fakeAPI) Healthcheck(context.Context) error                 { return nil }
+
+func call(t *testing.T, server *httptest.Server, body string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Post(server.URL, "application/json", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(data)
+}
+
+func TestHandler(t *testing.T) {
+	server := httptest.NewServer(NewHandler(fakeAPI{}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "plugins",
+			body: `{"jsonrpc": "2.0", "method": "plugins", "id": 1}`,
+			want: `{"jsonrpc":"2.0","result":["screenshot"],"id":1}`,
+		},
+		{
+			name: "run plugin",
+			body: `{"jsonrpc": "2.0", "method": "runPlugin", "params": {"plugin": "googlesearch", "params": {"query": "go"}}, "id": "a"}`,
+			want: `{"jsonrpc":"2.0","result":{"googlesearch":"go"},"id":"a"}`,
+		},
+		{
+			name: "download file",
+			body: `{"jsonrpc": "2.0", "method": "downloadFile", "params": {"fileId": "f1"}, "id": 2}`,
+			want: `{"jsonrpc":"2.0","result":"Y29udGVudA==","id":2}`,
+		},
+		{
+			name: "delete file",
+			body: `{"jsonrpc": "2.0", "method": "deleteFile", "params": {"fileId": "f1"}, "id": 3}`,
+			want: `{"jsonrpc":"2.0","result":null,"id":3}`,
+		},
+		{
+			name: "client error",
+			body: `{"jsonrpc": "2.0", "method": "runPlugin", "params": {"plugin": "broken"}, "id": 4}`,
+			want: `{"jsonrpc":"2.0","error":{"code":-32000,"message":"unexpected response status: 500 Internal Server Error"},"id":4}`,
+		},
+		{
+			name: "invalid params",
+			body: `{"jsonrpc": "2.0", "method": "deleteFile", "params": {}, "id": 5}`,
+			want: `{"jsonrpc":"2.0","error":{"code":-32602,"message":"fileId is required"},"id":5}`,
+		},
+		{
+			name: "method not found",
+			body: `{"jsonrpc": "2.0", "method": "nope", "id": 6}`,
+			want: `{"jsonrpc":"2.0","error":{"code":-32601,"message":"method not found"},"id":6}`,
+		},
+		{
+			name: "invalid request",
+			body: `{"method": "plugins", "id": 7}`,
+			want: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":7}`,
+		},
+		{
+			name: "parse error",
+			body: `{`,
+			want: `{"jsonrpc":"2.0","error":{"code":-32700,"message":"parse error"},"id":null}`,
+		},
+		{
+			name: "batch",
+			body: `[{"jsonrpc": "2.0", "method": "healthcheck", "id": 1}, {"jsonrpc": "2.0", "method": "healthcheck"}]`,
+			want: `[{"jsonrpc":"2.0","result":null,"id":1}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := call(t, server, tt.body)
+			assert.Equal(t, http.StatusOK, status)
+			assert.JSONEq(t, tt.want, body)
+		})
+	}
+
+	t.Run("notification", func(t *testing.T) {
+		status, body := call(t, server, `{"jsonrpc": "2.0", "method": "healthcheck"}`)
+		assert.Equal(t, http.StatusNoContent, status)
+		assert.Empty(t, body)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}